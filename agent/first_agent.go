@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"video_agent/internal/llm"
 	"video_agent/tool" // 导入本地tool包
 
-	"github.com/cloudwego/eino-ext/components/model/ollama"
 	einotool "github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
@@ -23,10 +23,7 @@ func NewAgent() {
 		&tool.SearchRepoTool{}, // 搜索仓库
 	}
 	// 创建并配置 ChatModel
-	chatModel, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: "http://localhost:11434", // Ollama 服务地址
-		Model:   "qwen3:0.6b",             // 模型名称
-	})
+	chatModel, err := llm.NewChatModel(ctx, llm.DefaultConfig()) // 默认本地 Ollama（qwen3:0.6b）
 	if err != nil {
 		log.Fatal(err)
 	}
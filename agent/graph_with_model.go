@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/cloudwego/eino-ext/components/model/ollama"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"video_agent/internal/llm"
 )
 
 func NewGraphWithModel() {
@@ -47,10 +47,7 @@ func NewGraphWithModel() {
 		}, nil
 	})
 
-	model, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: "http://localhost:11434", // Ollama 服务地址
-		Model:   "qwen3:0.6b",             // 模型名称
-	})
+	model, err := llm.NewChatModel(ctx, llm.DefaultConfig()) // 默认本地 Ollama（qwen3:0.6b）
 	if err != nil {
 		panic(err)
 	}
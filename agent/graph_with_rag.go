@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/cloudwego/eino-ext/components/model/ollama"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"video_agent/internal/llm"
 	"video_agent/rag"
 	"video_agent/tool"
 )
@@ -19,6 +19,16 @@ type RAGConfig struct {
 	TopK            int
 	ModelName       string
 	BaseURL         string
+	// LLM 模型配置（提供方、密钥等），为空时按 ModelName/BaseURL 使用 Ollama
+	LLM *llm.Config
+	// KeywordFallbackScore 向量检索最高分低于该值时回退到关键词匹配，<=0 关闭
+	KeywordFallbackScore float64
+	// MaxContextTokens 注入检索文档的 token 预算，0 使用 tool.DefaultContextTokenBudget，<0 不限制
+	MaxContextTokens int
+	// ContextTemplate 注入检索结果的系统提示词模板（需包含 {context}），为空使用 tool.DefaultContextTemplate
+	ContextTemplate string
+	// MetadataKeys 允许进入模型上下文的元数据字段，为空时不输出元数据
+	MetadataKeys []string
 }
 
 // NewRAGGraph 创建带有RAG功能的图代理
@@ -30,6 +40,7 @@ func NewRAGGraph(config *RAGConfig) error {
 	if err != nil {
 		return fmt.Errorf("failed to create RAG manager: %w", err)
 	}
+	ragManager.SetKeywordFallback(config.KeywordFallbackScore)
 
 	// 创建图
 	g := compose.NewGraph[map[string]string, *schema.Message]()
@@ -59,13 +70,14 @@ func NewRAGGraph(config *RAGConfig) error {
 	})
 
 	// 创建RAG增强节点
-	ragEnhancer := tool.CreateEnhancedRAGNode(ragManager, config.TopK)
+	ragTool, maxContextTokens, err := newRAGTool(config, ragManager)
+	if err != nil {
+		return err
+	}
+	ragEnhancer := tool.CreateEnhancedRAGNodeWithTool(ragTool, maxContextTokens)
 
 	// 创建模型节点
-	model, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: config.BaseURL,
-		Model:   config.ModelName,
-	})
+	model, err := llm.NewChatModel(ctx, config.chatModelConfig())
 	if err != nil {
 		return fmt.Errorf("failed to create chat model: %w", err)
 	}
@@ -147,6 +159,35 @@ func NewRAGGraph(config *RAGConfig) error {
 	return nil
 }
 
+// chatModelConfig 返回构建模型使用的配置，未设置 LLM 时由 ModelName/BaseURL 生成 Ollama 配置
+func (c *RAGConfig) chatModelConfig() *llm.Config {
+	if c.LLM != nil {
+		return c.LLM
+	}
+	cfg := llm.DefaultConfig()
+	cfg.Model = c.ModelName
+	if c.BaseURL != "" {
+		cfg.BaseURL = c.BaseURL
+	}
+	return cfg
+}
+
+// newRAGTool 按配置创建 RAGTool（提示词模板、元数据字段），并返回注入上下文的 token 预算
+func newRAGTool(config *RAGConfig, ragManager *rag.RAGManager) (*tool.RAGTool, int, error) {
+	maxContextTokens := config.MaxContextTokens
+	if maxContextTokens == 0 {
+		maxContextTokens = tool.DefaultContextTokenBudget
+	}
+	ragTool := tool.NewRAGTool(ragManager, config.TopK)
+	ragTool.SetMetadataKeys(config.MetadataKeys...)
+	if config.ContextTemplate != "" {
+		if err := ragTool.SetContextTemplate(config.ContextTemplate); err != nil {
+			return nil, 0, err
+		}
+	}
+	return ragTool, maxContextTokens, nil
+}
+
 // NewAdvancedRAGGraph 创建高级RAG图，包含更多功能
 func NewAdvancedRAGGraph(config *RAGConfig) error {
 	ctx := context.Background()
@@ -156,6 +197,12 @@ func NewAdvancedRAGGraph(config *RAGConfig) error {
 	if err != nil {
 		return fmt.Errorf("failed to create RAG manager: %w", err)
 	}
+	ragManager.SetKeywordFallback(config.KeywordFallbackScore)
+
+	ragTool, maxContextTokens, err := newRAGTool(config, ragManager)
+	if err != nil {
+		return err
+	}
 
 	// 创建图
 	g := compose.NewGraph[map[string]string, *schema.Message]()
@@ -169,20 +216,15 @@ func NewAdvancedRAGGraph(config *RAGConfig) error {
 	// 搜索处理节点
 	searchProcessor := compose.InvokableLambda(func(ctx context.Context, input map[string]string) (output []*schema.Message, err error) {
 		query := input["query"]
-		docs, err := ragManager.SearchSimilarDocuments(query, config.TopK)
+		contextStr, err := ragTool.BuildContext(ctx, query, maxContextTokens)
 		if err != nil {
 			return nil, err
 		}
 
-		var contextStr string
-		for i, doc := range docs {
-			contextStr += fmt.Sprintf("文档 %d: %s\n", i+1, doc.Content)
-		}
-
 		return []*schema.Message{
 			{
 				Role:    schema.System,
-				Content: fmt.Sprintf("基于以下检索到的文档回答问题：\n\n%s", contextStr),
+				Content: ragTool.RenderContext(contextStr),
 			},
 			{
 				Role:    schema.User,
@@ -239,10 +281,7 @@ func NewAdvancedRAGGraph(config *RAGConfig) error {
 	})
 
 	// 创建模型节点
-	model, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: config.BaseURL,
-		Model:   config.ModelName,
-	})
+	model, err := llm.NewChatModel(ctx, config.chatModelConfig())
 	if err != nil {
 		return fmt.Errorf("failed to create chat model: %w", err)
 	}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"testing"
+
+	"video_agent/internal/llm"
+	"video_agent/tool"
+)
+
+func TestNewRAGToolFromConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     RAGConfig
+		wantTokens int
+		wantErr    bool
+	}{
+		{name: "default budget", config: RAGConfig{}, wantTokens: tool.DefaultContextTokenBudget},
+		{name: "explicit budget", config: RAGConfig{MaxContextTokens: 500}, wantTokens: 500},
+		{name: "unlimited", config: RAGConfig{MaxContextTokens: -1}, wantTokens: -1},
+		{name: "custom template", config: RAGConfig{ContextTemplate: "资料：{context}"}, wantTokens: tool.DefaultContextTokenBudget},
+		{name: "template without placeholder", config: RAGConfig{ContextTemplate: "资料"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ragTool, tokens, err := newRAGTool(&tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newRAGTool err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tokens != tt.wantTokens {
+				t.Errorf("maxContextTokens = %d, want %d", tokens, tt.wantTokens)
+			}
+			if tt.config.ContextTemplate != "" && ragTool.RenderContext("x") != "资料：x" {
+				t.Errorf("RenderContext = %q, template not applied", ragTool.RenderContext("x"))
+			}
+		})
+	}
+}
+
+func TestRAGConfigChatModelConfig(t *testing.T) {
+	openai := &llm.Config{Provider: llm.ProviderOpenAI, BaseURL: "https://api.example.com/v1", Model: "gpt-4o-mini", APIKey: "sk-test"}
+	tests := []struct {
+		name         string
+		config       RAGConfig
+		wantProvider string
+		wantBaseURL  string
+		wantModel    string
+	}{
+		{name: "ollama from model fields", config: RAGConfig{ModelName: "qwen3:4b", BaseURL: "http://ollama:11434"},
+			wantProvider: llm.ProviderOllama, wantBaseURL: "http://ollama:11434", wantModel: "qwen3:4b"},
+		{name: "default base url", config: RAGConfig{ModelName: "qwen3:4b"},
+			wantProvider: llm.ProviderOllama, wantBaseURL: llm.DefaultConfig().BaseURL, wantModel: "qwen3:4b"},
+		{name: "explicit llm config wins", config: RAGConfig{ModelName: "qwen3:4b", LLM: openai},
+			wantProvider: llm.ProviderOpenAI, wantBaseURL: openai.BaseURL, wantModel: openai.Model},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.chatModelConfig()
+			if got.ProviderName() != tt.wantProvider || got.BaseURL != tt.wantBaseURL || got.Model != tt.wantModel {
+				t.Errorf("chatModelConfig = %s %s %s, want %s %s %s",
+					got.ProviderName(), got.BaseURL, got.Model, tt.wantProvider, tt.wantBaseURL, tt.wantModel)
+			}
+		})
+	}
+}
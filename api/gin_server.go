@@ -1,7 +1,6 @@
 package api
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -13,12 +12,23 @@ import (
 
 // GinServer Gin HTTP服务器
 type GinServer struct {
-	router *gin.Engine
-	graph  *compose.Graph[string, interface{}]
+	httpServer
+	router  *gin.Engine
+	graph   *compose.Graph[string, interface{}]
+	metrics *Metrics
 }
 
 // NewGinServer 创建新的Gin服务器
 func NewGinServer() *GinServer {
+	return NewGinServerWithMetrics(nil)
+}
+
+// NewGinServerWithMetrics 使用指定的请求指标创建Gin服务器，为 nil 时使用独立 registry
+func NewGinServerWithMetrics(metrics *Metrics) *GinServer {
+	if metrics == nil {
+		metrics = NewMetrics(nil)
+	}
+
 	g := compose.NewGraph[string, interface{}]()
 
 	// 创建完整工作流
@@ -31,10 +41,12 @@ func NewGinServer() *GinServer {
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(CORSMiddleware())
+	router.Use(metrics.Middleware())
 
 	return &GinServer{
-		router: router,
-		graph:  g,
+		router:  router,
+		graph:   g,
+		metrics: metrics,
 	}
 }
 
@@ -83,8 +95,8 @@ func (s *GinServer) ProcessInput(c *gin.Context) {
 		return
 	}
 
-	// 执行Graph工作流
-	ctx := context.Background()
+	// 执行Graph工作流，跟随请求上下文以便客户端断开时取消
+	ctx := c.Request.Context()
 	compiledGraph, err := s.graph.Compile(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ResponsePayload{
@@ -177,6 +189,8 @@ func (s *GinServer) SetupRoutes() {
 		api.GET("/graph/info", s.GetGraphInfo)
 	}
 
+	s.router.GET("/metrics", s.metrics.Handler())
+
 	// 文档路由
 	s.router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -200,5 +214,10 @@ func (s *GinServer) Start(addr string) error {
 	fmt.Printf("🔧 Graph信息: http://%s/api/v1/graph/info\n", addr)
 	fmt.Printf("🎯 处理接口: POST http://%s/api/v1/process\n", addr)
 
-	return s.router.Run(addr)
+	return s.serve(addr, s.router)
+}
+
+// Run 启动服务器并在收到 SIGINT/SIGTERM 时优雅关闭，等待进行中的请求处理完成
+func (s *GinServer) Run(addr string) error {
+	return runUntilSignal(func() error { return s.Start(addr) }, s.Shutdown, DefaultShutdownTimeout)
 }
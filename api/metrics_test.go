@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		router func(t *testing.T) *gin.Engine
+		path   string
+		want   []string
+	}{
+		{
+			name: "RAG 服务",
+			router: func(t *testing.T) *gin.Engine {
+				return NewRAGServerWithConfig(newTestRAGManager(t), &RAGServerConfig{APIKeys: []string{"secret"}}).router
+			},
+			path: "/health",
+			want: []string{
+				`http_requests_total{method="GET",route="/health",status="200"} 2`,
+				// 鉴权先于路由匹配，未知路由也记为 401
+				`http_requests_total{method="GET",route="unmatched",status="401"} 1`,
+				`http_request_duration_seconds_count{method="GET",route="/health"} 2`,
+			},
+		},
+		{
+			name: "Gin 服务",
+			router: func(t *testing.T) *gin.Engine {
+				server := NewGinServer()
+				server.SetupRoutes()
+				return server.router
+			},
+			path: "/api/v1/health",
+			want: []string{
+				`http_requests_total{method="GET",route="/api/v1/health",status="200"} 2`,
+				`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+				`http_request_duration_seconds_count{method="GET",route="/api/v1/health"} 2`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := tt.router(t)
+			serve := func(path string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				return rec
+			}
+
+			for i := 0; i < 2; i++ {
+				if rec := serve(tt.path); rec.Code != http.StatusOK {
+					t.Fatalf("GET %s status = %d", tt.path, rec.Code)
+				}
+			}
+			serve("/no-such-route")
+
+			// /metrics 免鉴权
+			rec := serve("/metrics")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /metrics status = %d", rec.Code)
+			}
+			body := rec.Body.String()
+			for _, line := range tt.want {
+				if !strings.Contains(body, line) {
+					t.Errorf("/metrics missing %q:\n%s", line, body)
+				}
+			}
+		})
+	}
+}
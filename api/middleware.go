@@ -0,0 +1,81 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader API Key 请求头
+const APIKeyHeader = "X-API-Key"
+
+// OriginCORSMiddleware 仅对白名单内的 Origin 返回 CORS 头，不使用 "*"，
+// 白名单外的跨域预检请求直接拒绝
+func OriginCORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin != "" && allowed[origin] {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, Authorization, accept, origin, Cache-Control, X-Requested-With, "+APIKeyHeader)
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET")
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		if isPreflight(c.Request) {
+			if !allowed[origin] {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyMiddleware 校验 X-API-Key 请求头，缺失或无效时返回 401；exemptPaths 中的路径（如 /health）
+// 与浏览器的 CORS 预检请求（预检不会携带自定义请求头）不校验
+func APIKeyMiddleware(apiKeys []string, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exempt[c.Request.URL.Path] || isPreflight(c.Request) {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" || !validAPIKey(apiKeys, key) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// isPreflight 是否为 CORS 预检请求：OPTIONS 且带 Origin 与 Access-Control-Request-Method，
+// 普通的 OPTIONS 请求仍需鉴权
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// validAPIKey 使用常量时间比较，避免通过响应耗时猜测 Key
+func validAPIKey(apiKeys []string, key string) bool {
+	for _, k := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
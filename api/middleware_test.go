@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRAGServerCORSAndAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const allowedOrigin = "https://studio.example.com"
+	preflight := map[string]string{"Origin": allowedOrigin, "Access-Control-Request-Method": http.MethodPost}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantCORS   bool
+	}{
+		{name: "预检请求无需 Key", method: http.MethodOptions, path: "/api/rag/search", headers: preflight, wantStatus: http.StatusNoContent, wantCORS: true},
+		{name: "白名单外的预检", method: http.MethodOptions, path: "/api/rag/search", headers: map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": http.MethodPost}, wantStatus: http.StatusForbidden},
+		{name: "普通 OPTIONS 仍需 Key", method: http.MethodOptions, path: "/api/rag/documents", wantStatus: http.StatusUnauthorized},
+		{name: "带 Origin 但非预检", method: http.MethodOptions, path: "/api/rag/documents", headers: map[string]string{"Origin": allowedOrigin}, wantStatus: http.StatusUnauthorized, wantCORS: true},
+		{name: "缺少 Key", method: http.MethodGet, path: "/api/rag/documents", wantStatus: http.StatusUnauthorized},
+		{name: "Key 错误", method: http.MethodGet, path: "/api/rag/documents", headers: map[string]string{APIKeyHeader: "wrong"}, wantStatus: http.StatusUnauthorized},
+		{name: "Key 正确", method: http.MethodGet, path: "/api/rag/documents", headers: map[string]string{APIKeyHeader: "secret", "Origin": allowedOrigin}, wantStatus: http.StatusOK, wantCORS: true},
+		{name: "健康检查免鉴权", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+	}
+
+	server := NewRAGServerWithConfig(newTestRAGManager(t), &RAGServerConfig{
+		AllowedOrigins: []string{allowedOrigin},
+		APIKeys:        []string{"secret"},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin") == allowedOrigin; got != tt.wantCORS {
+				t.Errorf("CORS header present = %v, want %v", got, tt.wantCORS)
+			}
+		})
+	}
+}
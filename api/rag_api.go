@@ -1,25 +1,76 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"video_agent/rag"
 
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
 	"github.com/gin-gonic/gin"
 )
 
 // RAGServer RAG API服务器
 type RAGServer struct {
+	httpServer
 	ragManager *rag.RAGManager
 	router     *gin.Engine
+	config     *RAGServerConfig
+	// streamGraph RAG 问答图，/chat 与 /chat/stream 共用，未配置 ChatModel 时为 nil
+	streamGraph compose.Runnable[map[string]string, *schema.Message]
 }
 
+// RAGServerConfig RAG服务器安全配置
+type RAGServerConfig struct {
+	// AllowedOrigins 允许跨域访问的 Origin 列表，为空时不返回 CORS 头
+	AllowedOrigins []string
+	// APIKeys 合法的 X-API-Key 列表，为空时不启用鉴权
+	APIKeys []string
+	// Metrics 请求指标，为 nil 时使用独立 registry
+	Metrics *Metrics
+	// ChatModel RAG 回答使用的模型，为 nil 时 /api/rag/chat 返回模拟回答，/api/rag/chat/stream 返回 503
+	ChatModel model.BaseChatModel
+	// ModelName ChatModel 的模型名称，用于响应中的 model 字段
+	ModelName string
+	// ExpirySweepInterval 服务运行期间清理过期文档的间隔，0 为 rag.DefaultExpirySweepInterval，<0 不清理
+	ExpirySweepInterval time.Duration
+}
+
+// mockModelName 未配置对话模型时响应中的 model 字段
+const mockModelName = "mock"
+
 // NewRAGServer 创建新的RAG服务器
 func NewRAGServer(ragManager *rag.RAGManager) *RAGServer {
+	return NewRAGServerWithConfig(ragManager, nil)
+}
+
+// NewRAGServerWithConfig 按安全配置创建RAG服务器
+func NewRAGServerWithConfig(ragManager *rag.RAGManager, config *RAGServerConfig) *RAGServer {
+	if config == nil {
+		config = &RAGServerConfig{}
+	}
+	if config.Metrics == nil {
+		config.Metrics = NewMetrics(nil)
+	}
+
 	server := &RAGServer{
 		ragManager: ragManager,
 		router:     gin.Default(),
+		config:     config,
+	}
+
+	if config.ChatModel != nil {
+		streamGraph, err := compileRAGStreamGraph(context.Background(), config.ChatModel)
+		if err != nil {
+			log.Printf("[RAGServer] compile stream graph failed, streaming chat disabled: %v", err)
+		} else {
+			server.streamGraph = streamGraph
+		}
 	}
 
 	server.setupRoutes()
@@ -28,8 +79,17 @@ func NewRAGServer(ragManager *rag.RAGManager) *RAGServer {
 
 // setupRoutes 设置路由
 func (s *RAGServer) setupRoutes() {
+	s.router.Use(s.config.Metrics.Middleware())
+	if len(s.config.AllowedOrigins) > 0 {
+		s.router.Use(OriginCORSMiddleware(s.config.AllowedOrigins))
+	}
+	if len(s.config.APIKeys) > 0 {
+		s.router.Use(APIKeyMiddleware(s.config.APIKeys, "/health", "/metrics"))
+	}
+
 	// 健康检查
 	s.router.GET("/health", s.healthCheck)
+	s.router.GET("/metrics", s.config.Metrics.Handler())
 
 	// RAG相关API
 	ragGroup := s.router.Group("/api/rag")
@@ -37,7 +97,9 @@ func (s *RAGServer) setupRoutes() {
 		ragGroup.POST("/search", s.searchDocuments)
 		ragGroup.POST("/add", s.addDocument)
 		ragGroup.GET("/documents", s.getAllDocuments)
+		ragGroup.PUT("/documents", s.upsertDocument)
 		ragGroup.GET("/documents/:id", s.getDocument)
+		ragGroup.POST("/chat/stream", s.chatWithRAGStream)
 	}
 
 	// 聊天API
@@ -61,6 +123,8 @@ func (s *RAGServer) healthCheck(c *gin.Context) {
 type SearchRequest struct {
 	Query string `json:"query" binding:"required"`
 	TopK  int    `json:"top_k"`
+	// Collection 检索的文档集合，为空时为 rag.DefaultCollection
+	Collection string `json:"collection"`
 }
 
 // SearchResponse 搜索响应
@@ -76,6 +140,8 @@ type DocumentResponse struct {
 	Metadata  map[string]interface{} `json:"metadata"`
 	Score     float64                `json:"score,omitempty"`
 	CreatedAt string                 `json:"created_at"`
+	// Collection 文档所属集合
+	Collection string `json:"collection,omitempty"`
 }
 
 // searchDocuments 搜索文档
@@ -91,7 +157,7 @@ func (s *RAGServer) searchDocuments(c *gin.Context) {
 	}
 
 	// ctx := context.Background()
-	documents, err := s.ragManager.SearchSimilarDocuments(req.Query, req.TopK)
+	documents, err := s.ragManager.SearchCollection(req.Collection, req.Query, req.TopK, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -104,10 +170,12 @@ func (s *RAGServer) searchDocuments(c *gin.Context) {
 
 	for i, doc := range documents {
 		response.Documents[i] = DocumentResponse{
-			ID:        doc.ID,
-			Content:   doc.Content,
-			Metadata:  doc.Metadata,
-			CreatedAt: doc.CreatedAt.Format("2006-01-02 15:04:05"),
+			ID:         doc.ID,
+			Content:    doc.Content,
+			Metadata:   doc.Metadata,
+			Score:      doc.Score,
+			CreatedAt:  doc.CreatedAt.Format("2006-01-02 15:04:05"),
+			Collection: doc.CollectionName(),
 		}
 	}
 
@@ -118,6 +186,8 @@ func (s *RAGServer) searchDocuments(c *gin.Context) {
 type AddDocumentRequest struct {
 	Content  string                 `json:"content" binding:"required"`
 	Metadata map[string]interface{} `json:"metadata"`
+	// Collection 文档所属集合，为空时为 rag.DefaultCollection
+	Collection string `json:"collection"`
 }
 
 // AddDocumentResponse 添加文档响应
@@ -135,7 +205,7 @@ func (s *RAGServer) addDocument(c *gin.Context) {
 	}
 
 	// ctx := context.Background()
-	if err := s.ragManager.AddDocument(req.Content, req.Metadata); err != nil {
+	if err := s.ragManager.AddDocumentToCollection(req.Collection, req.Content, req.Metadata); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -145,6 +215,45 @@ func (s *RAGServer) addDocument(c *gin.Context) {
 	})
 }
 
+// UpsertDocumentRequest 写入文档请求，id 为空时按内容哈希确定，重复提交相同内容不会产生重复文档
+type UpsertDocumentRequest struct {
+	ID       string                 `json:"id"`
+	Content  string                 `json:"content" binding:"required"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// UpsertDocumentResponse 写入文档响应
+type UpsertDocumentResponse struct {
+	ID      string `json:"id"`
+	Created bool   `json:"created"`
+	Message string `json:"message"`
+}
+
+// upsertDocument 创建或更新文档
+func (s *RAGServer) upsertDocument(c *gin.Context) {
+	var req UpsertDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, created, err := s.ragManager.Upsert(req.ID, req.Content, req.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "文档更新成功"
+	if created {
+		message = "文档创建成功"
+	}
+	c.JSON(http.StatusOK, UpsertDocumentResponse{
+		ID:      doc.ID,
+		Created: created,
+		Message: message,
+	})
+}
+
 // getAllDocuments 获取所有文档
 func (s *RAGServer) getAllDocuments(c *gin.Context) {
 	documents := s.ragManager.GetAllDocuments()
@@ -156,10 +265,11 @@ func (s *RAGServer) getAllDocuments(c *gin.Context) {
 
 	for i, doc := range documents {
 		response.Documents[i] = DocumentResponse{
-			ID:        doc.ID,
-			Content:   doc.Content,
-			Metadata:  doc.Metadata,
-			CreatedAt: doc.CreatedAt.Format("2006-01-02 15:04:05"),
+			ID:         doc.ID,
+			Content:    doc.Content,
+			Metadata:   doc.Metadata,
+			CreatedAt:  doc.CreatedAt.Format("2006-01-02 15:04:05"),
+			Collection: doc.CollectionName(),
 		}
 	}
 
@@ -176,10 +286,11 @@ func (s *RAGServer) getDocument(c *gin.Context) {
 	}
 
 	response := DocumentResponse{
-		ID:        doc.ID,
-		Content:   doc.Content,
-		Metadata:  doc.Metadata,
-		CreatedAt: doc.CreatedAt.Format("2006-01-02 15:04:05"),
+		ID:         doc.ID,
+		Content:    doc.Content,
+		Metadata:   doc.Metadata,
+		CreatedAt:  doc.CreatedAt.Format("2006-01-02 15:04:05"),
+		Collection: doc.CollectionName(),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -190,14 +301,18 @@ type ChatRequest struct {
 	Query   string `json:"query" binding:"required"`
 	TopK    int    `json:"top_k"`
 	Context string `json:"context"`
+	// Collection 检索的文档集合，为空时为 rag.DefaultCollection
+	Collection string `json:"collection"`
 }
 
 // ChatResponse 聊天响应
 type ChatResponse struct {
-	Answer   string `json:"answer"`
-	Context  string `json:"context,omitempty"`
-	Model    string `json:"model"`
-	Duration int64  `json:"duration_ms"`
+	Answer  string `json:"answer"`
+	Context string `json:"context,omitempty"`
+	// Sources 支撑回答的检索文档，回答中的 [n] 引用对应 index
+	Sources  []RAGSource `json:"sources"`
+	Model    string      `json:"model"`
+	Duration int64       `json:"duration_ms"`
 }
 
 // chatWithRAG 带RAG的聊天
@@ -212,26 +327,39 @@ func (s *RAGServer) chatWithRAG(c *gin.Context) {
 		req.TopK = 3
 	}
 
-	// 这里应该调用RAG图代理来处理请求
-	// 为了简化，这里返回一个模拟的响应
-	// ctx := context.Background()
-	documents, err := s.ragManager.SearchSimilarDocuments(req.Query, req.TopK)
+	start := time.Now()
+	documents, err := s.ragManager.SearchCollection(req.Collection, req.Query, req.TopK, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 构建上下文
-	var contextStr string
-	for i, doc := range documents {
-		contextStr += fmt.Sprintf("文档 %d: %s\n", i+1, doc.Content)
+	// 构建上下文，文档编号与 sources 的 index 一致
+	contextStr, sources := buildRAGContext(documents)
+	contextStr += req.Context
+
+	// 未配置对话模型时返回模拟回答，sources 仍反映实际检索结果
+	answer := fmt.Sprintf("基于检索到的%d个文档，我可以回答你的问题：%s", len(documents), req.Query)
+	modelName := mockModelName
+	if s.streamGraph != nil {
+		msg, err := s.streamGraph.Invoke(c.Request.Context(), map[string]string{
+			"query":   req.Query,
+			"context": contextStr,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		answer = msg.Content
+		modelName = s.config.ModelName
 	}
 
 	response := ChatResponse{
-		Answer:   fmt.Sprintf("基于检索到的%d个文档，我可以回答你的问题：%s", len(documents), req.Query),
+		Answer:   answer,
 		Context:  contextStr,
-		Model:    "qwen3:0.6b",
-		Duration: 100, // 模拟处理时间
+		Sources:  sources,
+		Model:    modelName,
+		Duration: time.Since(start).Milliseconds(),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -254,7 +382,24 @@ func (s *RAGServer) simpleChat(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// Start 启动服务器
+// Start 启动服务器，阻塞直到出错或 Shutdown 被调用；运行期间定期清理过期文档
 func (s *RAGServer) Start(addr string) error {
-	return s.router.Run(addr)
+	if interval := s.expirySweepInterval(); interval > 0 && s.ragManager != nil {
+		stop := s.ragManager.StartExpirySweep(interval)
+		defer stop()
+	}
+	return s.serve(addr, s.router)
+}
+
+// expirySweepInterval 过期文档清理间隔，<=0 表示不清理
+func (s *RAGServer) expirySweepInterval() time.Duration {
+	if s.config.ExpirySweepInterval == 0 {
+		return rag.DefaultExpirySweepInterval
+	}
+	return s.config.ExpirySweepInterval
+}
+
+// Run 启动服务器并在收到 SIGINT/SIGTERM 时优雅关闭
+func (s *RAGServer) Run(addr string) error {
+	return runUntilSignal(func() error { return s.Start(addr) }, s.Shutdown, DefaultShutdownTimeout)
 }
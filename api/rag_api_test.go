@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpsertDocument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		bodies      []string
+		wantStatus  int
+		wantCreated []bool
+		wantDocs    int
+		wantContent string
+	}{
+		{name: "missing content", bodies: []string{`{"id":"faq-1"}`}, wantStatus: http.StatusBadRequest},
+		{name: "create", bodies: []string{`{"id":"faq-1","content":"video tips"}`}, wantStatus: http.StatusOK, wantCreated: []bool{true}, wantDocs: 1, wantContent: "video tips"},
+		{
+			name:        "update keeps a single document",
+			bodies:      []string{`{"id":"faq-1","content":"video tips"}`, `{"id":"faq-1","content":"danmaku tips"}`},
+			wantStatus:  http.StatusOK,
+			wantCreated: []bool{true, false},
+			wantDocs:    1,
+			wantContent: "danmaku tips",
+		},
+		{
+			name:        "resubmitting content without id is idempotent",
+			bodies:      []string{`{"content":"video tips"}`, `{"content":"video tips"}`},
+			wantStatus:  http.StatusOK,
+			wantCreated: []bool{true, false},
+			wantDocs:    1,
+			wantContent: "video tips",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestRAGManager(t)
+			server := NewRAGServerWithConfig(rm, nil)
+
+			var ids []string
+			for i, body := range tt.bodies {
+				rec := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPut, "/api/rag/documents", bytes.NewBufferString(body))
+				req.Header.Set("Content-Type", "application/json")
+				server.router.ServeHTTP(rec, req)
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var resp UpsertDocumentResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if resp.Created != tt.wantCreated[i] || resp.ID == "" {
+					t.Errorf("response %d = %+v, want created %v with an id", i, resp, tt.wantCreated[i])
+				}
+				ids = append(ids, resp.ID)
+			}
+
+			if docs := rm.GetAllDocuments(); len(docs) != tt.wantDocs {
+				t.Fatalf("stored %d documents, want %d", len(docs), tt.wantDocs)
+			}
+			for _, id := range ids[1:] {
+				if id != ids[0] {
+					t.Errorf("ids = %v, want the same document each time", ids)
+				}
+			}
+			if doc, ok := rm.GetDocument(ids[0]); !ok || doc.Content != tt.wantContent {
+				t.Errorf("document %s = %+v, want content %q", ids[0], doc, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestSearchDocumentsCollection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := newTestRAGManager(t)
+	if err := rm.AddDocumentToCollection("kb", "danmaku guide", nil); err != nil {
+		t.Fatalf("AddDocumentToCollection: %v", err)
+	}
+	if err := rm.AddDocument("danmaku report", nil); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	server := NewRAGServerWithConfig(rm, nil)
+
+	tests := []struct {
+		name           string
+		body           string
+		wantContent    string
+		wantCollection string
+	}{
+		{name: "named collection", body: `{"query":"danmaku","collection":"kb"}`, wantContent: "danmaku guide", wantCollection: "kb"},
+		{name: "default collection", body: `{"query":"danmaku"}`, wantContent: "danmaku report", wantCollection: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/rag/search", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var resp SearchResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Count != 1 || resp.Documents[0].Content != tt.wantContent || resp.Documents[0].Collection != tt.wantCollection {
+				t.Errorf("documents = %+v, want only %q from %s", resp.Documents, tt.wantContent, tt.wantCollection)
+			}
+		})
+	}
+}
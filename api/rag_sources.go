@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"video_agent/internal/agent/types"
+	"video_agent/rag"
+)
+
+// ragCitationInstruction 要求模型按编号引用来源，编号与 sources 中的 index 对应
+const ragCitationInstruction = "回答中使用了某篇文档的内容时，在对应句子末尾用方括号标注文档编号，如 [1]；不要引用未使用的文档。"
+
+// RAGSource 支撑回答的检索文档，与 gRPC 接口 metadata["sources"] 中的结构一致
+type RAGSource = types.RAGSource
+
+// buildRAGContext 按编号拼接检索文档作为模型上下文，同时返回对应的来源列表
+func buildRAGContext(documents []*rag.ScoredDocument) (string, []RAGSource) {
+	var sb strings.Builder
+	sources := make([]RAGSource, 0, len(documents))
+	for i, doc := range documents {
+		sb.WriteString(fmt.Sprintf("[%d] %s\n", i+1, doc.Content))
+		sources = append(sources, types.NewRAGSource(i+1, doc.ID, doc.Content, doc.Score))
+	}
+	return sb.String(), sources
+}
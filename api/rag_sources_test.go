@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gin-gonic/gin"
+
+	"video_agent/rag"
+)
+
+// fakeChatModel 返回固定回答，并记录收到的系统提示词
+type fakeChatModel struct {
+	answer string
+	system string
+}
+
+func (m *fakeChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	for _, msg := range input {
+		if msg.Role == schema.System {
+			m.system = msg.Content
+		}
+	}
+	return schema.AssistantMessage(m.answer, nil), nil
+}
+
+func (m *fakeChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+// vocabEmbedder 按固定词表计数生成向量，检索排序可预期
+type vocabEmbedder []string
+
+func (v vocabEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(v))
+		for _, word := range strings.Fields(text) {
+			for j, term := range v {
+				if word == term {
+					vectors[i][j]++
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+var testVocab = vocabEmbedder{"video", "danmaku", "creator"}
+
+func newTestRAGManager(t *testing.T, contents ...string) *rag.RAGManager {
+	t.Helper()
+	dir := t.TempDir()
+	rm, err := rag.NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&rag.EmbeddingConfig{Embedder: testVocab, Dimension: len(testVocab), CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManager: %v", err)
+	}
+	for _, content := range contents {
+		if err := rm.AddDocument(content, nil); err != nil {
+			t.Fatalf("AddDocument: %v", err)
+		}
+	}
+	return rm
+}
+
+func TestBuildRAGContext(t *testing.T) {
+	long := strings.Repeat("长", 250)
+	docs := []*rag.ScoredDocument{
+		{Document: &rag.Document{ID: "a", Content: " 第一篇 "}, Score: 0.9},
+		{Document: &rag.Document{ID: "b", Content: long}, Score: 0.5},
+	}
+
+	context, sources := buildRAGContext(docs)
+
+	if !strings.HasPrefix(context, "[1]  第一篇 \n[2] ") {
+		t.Errorf("context = %q, want documents numbered from [1]", context[:40])
+	}
+	if len(sources) != 2 {
+		t.Fatalf("len(sources) = %d, want 2", len(sources))
+	}
+	tests := []struct {
+		got  RAGSource
+		want RAGSource
+	}{
+		{sources[0], RAGSource{Index: 1, ID: "a", Snippet: "第一篇", Score: 0.9}},
+		{sources[1], RAGSource{Index: 2, ID: "b", Snippet: strings.Repeat("长", 200) + "...", Score: 0.5}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("source = %+v, want %+v", tt.got, tt.want)
+		}
+	}
+}
+
+func TestChatWithRAGSourcesMatchRetrievedDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		config    *RAGServerConfig
+		wantModel string
+	}{
+		{name: "mock answer", config: nil, wantModel: mockModelName},
+		{name: "configured model", config: &RAGServerConfig{ChatModel: &fakeChatModel{answer: "回答 [1]"}, ModelName: "test-model"}, wantModel: "test-model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestRAGManager(t, "video video danmaku", "danmaku creator", "creator")
+			server := NewRAGServerWithConfig(rm, tt.config)
+
+			body, _ := json.Marshal(ChatRequest{Query: "video danmaku", TopK: 2})
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/chat/rag", bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var resp ChatResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			retrieved, err := rm.SearchWithScores("video danmaku", 2, 0)
+			if err != nil {
+				t.Fatalf("SearchWithScores: %v", err)
+			}
+			if len(resp.Sources) != len(retrieved) {
+				t.Fatalf("len(sources) = %d, want %d", len(resp.Sources), len(retrieved))
+			}
+			for i, doc := range retrieved {
+				src := resp.Sources[i]
+				if src.Index != i+1 || src.ID != doc.ID || src.Score != doc.Score {
+					t.Errorf("sources[%d] = %+v, want index %d id %s score %v", i, src, i+1, doc.ID, doc.Score)
+				}
+				if !strings.Contains(resp.Context, fmt.Sprintf("[%d] %s\n", i+1, doc.Content)) {
+					t.Errorf("context missing [%d] %s", i+1, doc.Content)
+				}
+			}
+			if resp.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", resp.Model, tt.wantModel)
+			}
+			if fake, ok := tt.config.chatModel().(*fakeChatModel); ok {
+				if resp.Answer != fake.answer {
+					t.Errorf("answer = %q, want %q", resp.Answer, fake.answer)
+				}
+				if !strings.Contains(fake.system, resp.Context) {
+					t.Errorf("model prompt does not contain the numbered context")
+				}
+			}
+		})
+	}
+}
+
+func (c *RAGServerConfig) chatModel() model.BaseChatModel {
+	if c == nil {
+		return nil
+	}
+	return c.ChatModel
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// ragStreamSystemPrompt 流式 RAG 回答的系统提示词，%s 为检索到的文档
+const ragStreamSystemPrompt = "基于以下检索到的文档内容回答问题：\n\n%s\n\n请根据这些文档信息提供准确、相关的回答。如果文档中没有相关信息，请明确说明。" + ragCitationInstruction
+
+// RAGStreamContextEvent 流式回答开始前推送的检索上下文
+type RAGStreamContextEvent struct {
+	Documents []DocumentResponse `json:"documents"`
+	Context   string             `json:"context"`
+	// Sources 与回答中的引用编号 [n] 对应的来源
+	Sources []RAGSource `json:"sources"`
+}
+
+// compileRAGStreamGraph 编译流式 RAG 图：{query, context} -> 消息构建 -> 模型，服务启动时编译一次供所有请求复用
+func compileRAGStreamGraph(ctx context.Context, chatModel model.BaseChatModel) (compose.Runnable[map[string]string, *schema.Message], error) {
+	g := compose.NewGraph[map[string]string, *schema.Message]()
+
+	messageBuilder := compose.InvokableLambda(func(ctx context.Context, input map[string]string) (output []*schema.Message, err error) {
+		var messages []*schema.Message
+		if input["context"] != "" {
+			messages = append(messages, schema.SystemMessage(fmt.Sprintf(ragStreamSystemPrompt, input["context"])))
+		}
+		return append(messages, schema.UserMessage(input["query"])), nil
+	})
+
+	if err := g.AddLambdaNode("message_builder", messageBuilder); err != nil {
+		return nil, err
+	}
+	if err := g.AddChatModelNode("model", chatModel); err != nil {
+		return nil, err
+	}
+	if err := g.AddEdge(compose.START, "message_builder"); err != nil {
+		return nil, err
+	}
+	if err := g.AddEdge("message_builder", "model"); err != nil {
+		return nil, err
+	}
+	if err := g.AddEdge("model", compose.END); err != nil {
+		return nil, err
+	}
+
+	return g.Compile(ctx)
+}
+
+// chatWithRAGStream 带RAG的流式聊天（SSE）：先推送 context 事件，再逐段推送 message 事件，
+// 结束时推送 done；客户端断开后请求 context 取消，模型流随之关闭
+func (s *RAGServer) chatWithRAGStream(c *gin.Context) {
+	if s.streamGraph == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "chat model not configured"})
+		return
+	}
+
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.TopK <= 0 {
+		req.TopK = 3
+	}
+
+	documents, err := s.ragManager.SearchCollection(req.Collection, req.Query, req.TopK, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	event := RAGStreamContextEvent{Documents: make([]DocumentResponse, len(documents))}
+	for i, doc := range documents {
+		event.Documents[i] = DocumentResponse{
+			ID:        doc.ID,
+			Content:   doc.Content,
+			Metadata:  doc.Metadata,
+			Score:     doc.Score,
+			CreatedAt: doc.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	event.Context, event.Sources = buildRAGContext(documents)
+	event.Context += req.Context
+
+	ctx := c.Request.Context()
+	stream, err := s.streamGraph.Stream(ctx, map[string]string{
+		"query":   req.Query,
+		"context": event.Context,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.SSEvent("context", event)
+	c.Writer.Flush()
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			c.SSEvent("done", "")
+			c.Writer.Flush()
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				c.SSEvent("error", err.Error())
+				c.Writer.Flush()
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if chunk.Content == "" {
+			continue
+		}
+
+		c.SSEvent("message", chunk.Content)
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// chunkedChatModel 流式输出时按 chunks 逐段返回
+type chunkedChatModel struct {
+	fakeChatModel
+	chunks []string
+}
+
+func (m *chunkedChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msgs := make([]*schema.Message, len(m.chunks))
+	for i, chunk := range m.chunks {
+		msgs[i] = schema.AssistantMessage(chunk, nil)
+	}
+	return schema.StreamReaderFromArray(msgs), nil
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			current.name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			current.data = strings.TrimPrefix(line, "data:")
+		case line == "" && current.name != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	if current.name != "" {
+		events = append(events, current)
+	}
+	return events
+}
+
+func TestChatWithRAGStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		config     *RAGServerConfig
+		wantStatus int
+		wantTokens []string
+	}{
+		{name: "未配置模型", config: nil, wantStatus: http.StatusServiceUnavailable},
+		{
+			name:       "逐段推送",
+			config:     &RAGServerConfig{ChatModel: &chunkedChatModel{chunks: []string{"弹幕", "", "很多"}}},
+			wantStatus: http.StatusOK,
+			wantTokens: []string{"弹幕", "很多"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewRAGServerWithConfig(newTestRAGManager(t, "video danmaku", "creator"), tt.config)
+
+			body, _ := json.Marshal(ChatRequest{Query: "danmaku", TopK: 1})
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/rag/chat/stream", bytes.NewReader(body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			events := parseSSE(t, rec.Body.String())
+			if len(events) != len(tt.wantTokens)+2 {
+				t.Fatalf("events = %+v, want context + %d messages + done", events, len(tt.wantTokens))
+			}
+			if events[0].name != "context" {
+				t.Fatalf("first event = %q, want context", events[0].name)
+			}
+			var ctxEvent RAGStreamContextEvent
+			if err := json.Unmarshal([]byte(events[0].data), &ctxEvent); err != nil {
+				t.Fatalf("decode context event: %v", err)
+			}
+			if len(ctxEvent.Sources) != 1 || !strings.Contains(ctxEvent.Context, "danmaku") {
+				t.Errorf("context event = %+v, want the retrieved document", ctxEvent)
+			}
+			for i, token := range tt.wantTokens {
+				if ev := events[i+1]; ev.name != "message" || ev.data != token {
+					t.Errorf("events[%d] = %+v, want message %q", i+1, ev, token)
+				}
+			}
+			if last := events[len(events)-1]; last.name != "done" {
+				t.Errorf("last event = %q, want done", last.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout 优雅关闭时等待进行中请求完成的最长时间。模型单次请求超时（llm.DefaultTimeout）
+// 可达数分钟，这里不等满一次完整的 LLM 调用，到期后仍未完成的请求会被中断
+const DefaultShutdownTimeout = 30 * time.Second
+
+// httpServer 封装 http.Server，提供可优雅关闭的启动方式，供 GinServer 与 RAGServer 复用
+type httpServer struct {
+	mu  sync.Mutex
+	srv *http.Server
+	// closed Shutdown 已调用；之后的 serve 不再启动，避免 Shutdown 早于 serve 时服务继续运行
+	closed bool
+}
+
+// serve 阻塞监听，调用 Shutdown 后返回 nil；Shutdown 先于 serve 调用时直接返回 nil
+func (h *httpServer) serve(addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.srv = srv
+	h.mu.Unlock()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 停止接受新连接并等待进行中的请求处理完成，ctx 到期后强制返回
+func (h *httpServer) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	srv := h.srv
+	h.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// runUntilSignal 启动服务并在收到 SIGINT/SIGTERM 时优雅关闭，最多等待 timeout
+func runUntilSignal(start func() error, shutdown func(context.Context) error, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- start()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-errCh:
+		return err
+	case sig := <-quit:
+		log.Printf("[Server] received %s, draining in-flight requests (timeout %s)...", sig, timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		return err
+	}
+	return <-errCh
+}
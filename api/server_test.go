@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPServerShutdown(t *testing.T) {
+	tests := []struct {
+		name          string
+		shutdownFirst bool
+	}{
+		{name: "shutdown before serve", shutdownFirst: true},
+		{name: "shutdown while serving", shutdownFirst: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h httpServer
+			if tt.shutdownFirst {
+				if err := h.Shutdown(context.Background()); err != nil {
+					t.Fatalf("Shutdown: %v", err)
+				}
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- h.serve("127.0.0.1:0", http.NotFoundHandler()) }()
+
+			if !tt.shutdownFirst {
+				// 等待 serve 登记 http.Server 后再关闭
+				deadline := time.Now().Add(time.Second)
+				for {
+					h.mu.Lock()
+					started := h.srv != nil
+					h.mu.Unlock()
+					if started || time.Now().After(deadline) {
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				if err := h.Shutdown(context.Background()); err != nil {
+					t.Fatalf("Shutdown: %v", err)
+				}
+			}
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("serve = %v, want nil", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("serve kept running after Shutdown")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	agent_biz "video_agent/internal/agent/biz"
+	pb "video_agent/proto_gen/proto"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// answerModel 所有调用都返回固定回答，充当不调用工具的 Report Agent
+type answerModel struct {
+	answer string
+}
+
+func (m answerModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return schema.AssistantMessage(m.answer, nil), nil
+}
+
+func (m answerModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(m.answer, nil)}), nil
+}
+
+func (answerModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+const structuredAnswer = `{"summary":"播放量稳步增长","metrics":{"views":1000,"likes":80,"comments":12},"sentiment":"positive","key_points":["完播率高"],"suggestions":["保持更新频率"]}`
+
+func TestAnalyzeVideoRPC(t *testing.T) {
+	uc, err := agent_biz.NewVideoAssistantUsecase(nil, answerModel{answer: structuredAnswer}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	srv := &XiaovGRPCServer{usecase: uc}
+
+	tests := []struct {
+		name         string
+		req          *pb.AnalyzeVideoRequest
+		wantCode     codes.Code
+		wantAnalysis bool
+	}{
+		{name: "missing video_id", req: &pb.AnalyzeVideoRequest{UserId: "u1"}, wantCode: codes.InvalidArgument},
+		{name: "blank video_id", req: &pb.AnalyzeVideoRequest{VideoId: "  ", UserId: "u1"}, wantCode: codes.InvalidArgument},
+		{name: "missing user_id", req: &pb.AnalyzeVideoRequest{VideoId: "BV1"}, wantCode: codes.InvalidArgument},
+		{name: "blank user_id", req: &pb.AnalyzeVideoRequest{VideoId: "BV1", UserId: "  "}, wantCode: codes.InvalidArgument},
+		{name: "unsupported analysis_type", req: &pb.AnalyzeVideoRequest{VideoId: "BV1", UserId: "u1", AnalysisType: "deep"}, wantCode: codes.InvalidArgument},
+		{name: "structured by default", req: &pb.AnalyzeVideoRequest{VideoId: "BV1", UserId: "u1"}, wantCode: codes.OK, wantAnalysis: true},
+		{name: "report only", req: &pb.AnalyzeVideoRequest{VideoId: "BV1", UserId: "u1", AnalysisType: analysisTypeReport}, wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.AnalyzeVideo(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (err %v)", status.Code(err), tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.VideoId != "BV1" || resp.Report == "" || resp.SessionId == "" {
+				t.Errorf("resp = %+v, want video id, report and session id", resp)
+			}
+			if (resp.Analysis != nil) != tt.wantAnalysis {
+				t.Fatalf("analysis = %+v, want present %v", resp.Analysis, tt.wantAnalysis)
+			}
+			if tt.wantAnalysis && (resp.Analysis.Summary != "播放量稳步增长" || resp.Analysis.ViewCount != 1000 || resp.Analysis.Sentiment != "positive") {
+				t.Errorf("analysis = %+v, want the structured report", resp.Analysis)
+			}
+		})
+	}
+}
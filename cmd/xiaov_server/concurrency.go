@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// concurrencyLimiter 限制同时执行的图（LLM）调用数，超出时最多排队 maxQueue 个请求，
+// 队列已满时返回 codes.ResourceExhausted，避免突发请求压垮模型后端导致全部超时
+type concurrencyLimiter struct {
+	slots chan struct{}
+	queue chan struct{}
+}
+
+// newConcurrencyLimiter maxConcurrent<=0 时不限制并发；maxQueue<=0 时不排队，满载直接拒绝
+func newConcurrencyLimiter(maxConcurrent, maxQueue int) *concurrencyLimiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &concurrencyLimiter{
+		slots: make(chan struct{}, maxConcurrent),
+		queue: make(chan struct{}, maxQueue),
+	}
+}
+
+// Acquire 获取执行名额，返回的 release 必须在图执行结束后调用；
+// 排队期间 ctx 结束时返回对应的 gRPC 状态错误
+func (l *concurrencyLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return nil, status.Error(codes.ResourceExhausted, "server is busy, too many concurrent requests")
+	}
+	defer func() { <-l.queue }()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
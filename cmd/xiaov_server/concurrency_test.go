@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	agent_biz "video_agent/internal/agent/biz"
+	pb "video_agent/proto_gen/proto"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gatedModel 在 gate 关闭前阻塞且不响应 ctx 取消，模拟取消后仍在收尾的模型调用
+type gatedModel struct {
+	gate chan struct{}
+}
+
+func (m *gatedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	<-m.gate
+	return schema.AssistantMessage("Chat", nil), nil
+}
+
+func (m *gatedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, _ := m.Generate(ctx, input, opts...)
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *gatedModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// disconnectedStream 第一次 Send 时模拟客户端断开
+type disconnectedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *disconnectedStream) Context() context.Context    { return s.ctx }
+func (s *disconnectedStream) SetHeader(metadata.MD) error { return nil }
+func (s *disconnectedStream) Send(*pb.ChatStreamResponse) error {
+	s.cancel()
+	return errors.New("client disconnected")
+}
+
+func TestChatStreamHoldsSlotUntilGenerationEnds(t *testing.T) {
+	llm := &gatedModel{gate: make(chan struct{})}
+	uc, err := agent_biz.NewVideoAssistantUsecase(nil, llm, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	srv := &XiaovGRPCServer{usecase: uc, limiter: newConcurrencyLimiter(1, 0)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = srv.ChatStream(&pb.ChatRequest{SessionId: "s1", Message: "你好"}, &disconnectedStream{ctx: ctx, cancel: cancel})
+	if err == nil {
+		t.Fatal("ChatStream should fail once the client disconnects")
+	}
+
+	// 客户端已断开但生成仍在进行，名额不能提前释放
+	if _, err := srv.limiter.Acquire(context.Background()); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Acquire while generation runs: err = %v, want ResourceExhausted", err)
+	}
+
+	close(llm.gate)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		release, err := srv.limiter.Acquire(context.Background())
+		if err == nil {
+			release()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slot not released after generation ended: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimiterQueue(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		maxQueue      int
+		// wantQueued 名额占满后下一个请求应排队等待（否则立即被拒绝）
+		wantQueued bool
+	}{
+		{name: "no queue rejects the extra request", maxConcurrent: 2, maxQueue: 0},
+		{name: "queue holds the extra request", maxConcurrent: 2, maxQueue: 1, wantQueued: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newConcurrencyLimiter(tt.maxConcurrent, tt.maxQueue)
+			var releases []func()
+			for i := 0; i < tt.maxConcurrent; i++ {
+				release, err := l.Acquire(context.Background())
+				if err != nil {
+					t.Fatalf("Acquire %d: %v", i+1, err)
+				}
+				releases = append(releases, release)
+			}
+
+			acquired := make(chan error, 1)
+			go func() {
+				release, err := l.Acquire(context.Background())
+				if err == nil {
+					release()
+				}
+				acquired <- err
+			}()
+
+			if !tt.wantQueued {
+				if err := <-acquired; status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("extra request: err = %v, want ResourceExhausted", err)
+				}
+				return
+			}
+
+			select {
+			case err := <-acquired:
+				t.Fatalf("extra request should wait in the queue, got err = %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			// 排队期间再来的请求超出队列长度，直接拒绝
+			if _, err := l.Acquire(context.Background()); status.Code(err) != codes.ResourceExhausted {
+				t.Fatalf("request beyond the queue: err = %v, want ResourceExhausted", err)
+			}
+			releases[0]()
+			if err := <-acquired; err != nil {
+				t.Fatalf("queued request: %v", err)
+			}
+		})
+	}
+}
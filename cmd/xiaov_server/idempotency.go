@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "video_agent/proto_gen/proto"
+)
+
+// idempotencyCache 按幂等键缓存已完成的 Chat 响应，并保证同一键的并发请求只执行一次
+type idempotencyCache struct {
+	ttl time.Duration
+	// callTimeout 共享执行的超时；执行与任何单个请求的生命周期解绑
+	callTimeout time.Duration
+
+	mu       sync.Mutex
+	entries  map[string]*idempotencyEntry
+	inflight map[string]*idempotencyCall
+}
+
+type idempotencyEntry struct {
+	resp      *pb.ChatResponse
+	expiresAt time.Time
+}
+
+type idempotencyCall struct {
+	done chan struct{}
+	resp *pb.ChatResponse
+	err  error
+}
+
+func newIdempotencyCache(ttl, callTimeout time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:         ttl,
+		callTimeout: callTimeout,
+		entries:     make(map[string]*idempotencyEntry),
+		inflight:    make(map[string]*idempotencyCall),
+	}
+}
+
+// Do 返回 key 对应的缓存响应；未命中时执行 fn，同一 key 的并发调用共享同一次执行结果。
+// fn 在脱离调用方取消的 ctx（保留 trace 等值，最长 callTimeout）上执行，首个请求断开不会让其他等待者失败；
+// 每个调用方只等到自己的 ctx 结束为止。失败的结果不会缓存，客户端可以用相同的键重试
+func (c *idempotencyCache) Do(ctx context.Context, key string, fn func(ctx context.Context) (*pb.ChatResponse, error)) (*pb.ChatResponse, error) {
+	c.mu.Lock()
+	now := time.Now()
+	c.evictExpired(now)
+
+	if entry, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		return entry.resp, nil
+	}
+
+	call, ok := c.inflight[key]
+	if !ok {
+		call = &idempotencyCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		go c.run(ctx, key, call, fn)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.resp, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run 执行共享调用并在成功时缓存结果
+func (c *idempotencyCache) run(ctx context.Context, key string, call *idempotencyCall, fn func(ctx context.Context) (*pb.ChatResponse, error)) {
+	ctx = context.WithoutCancel(ctx)
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+
+	call.resp, call.err = fn(ctx)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil {
+		c.entries[key] = &idempotencyEntry{
+			resp:      call.resp,
+			expiresAt: time.Now().Add(c.ttl),
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+func (c *idempotencyCache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
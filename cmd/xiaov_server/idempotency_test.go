@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "video_agent/proto_gen/proto"
+)
+
+func TestIdempotencyCacheSharesOneCall(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute, time.Minute)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(ctx context.Context) (*pb.ChatResponse, error) {
+		calls.Add(1)
+		<-release
+		return &pb.ChatResponse{Reply: "ok"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := cache.Do(context.Background(), "k", fn)
+			if err != nil || resp.Reply != "ok" {
+				t.Errorf("Do() = %v, %v", resp, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := cache.Do(context.Background(), "k", fn); err != nil {
+		t.Fatalf("cached Do() error: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("fn called %d times, want 1", got)
+	}
+}
+
+func TestIdempotencyCacheCallerCancellation(t *testing.T) {
+	tests := []struct {
+		name string
+		// cancelLeader 取消首个请求（发起执行的一方），否则取消后到的等待者
+		cancelLeader bool
+	}{
+		{name: "首个请求断开，等待者仍拿到结果", cancelLeader: true},
+		{name: "等待者断开，首个请求仍拿到结果", cancelLeader: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newIdempotencyCache(time.Minute, time.Minute)
+			release := make(chan struct{})
+			started := make(chan struct{})
+			var fnErr atomic.Value
+			fn := func(ctx context.Context) (*pb.ChatResponse, error) {
+				close(started)
+				select {
+				case <-release:
+				case <-ctx.Done():
+					fnErr.Store(ctx.Err())
+					return nil, ctx.Err()
+				}
+				return &pb.ChatResponse{Reply: "ok"}, nil
+			}
+
+			leaderCtx, cancelLeader := context.WithCancel(context.Background())
+			waiterCtx, cancelWaiter := context.WithCancel(context.Background())
+			defer cancelLeader()
+			defer cancelWaiter()
+
+			type result struct {
+				resp *pb.ChatResponse
+				err  error
+			}
+			leader := make(chan result, 1)
+			go func() {
+				resp, err := cache.Do(leaderCtx, "k", fn)
+				leader <- result{resp, err}
+			}()
+			<-started
+			waiter := make(chan result, 1)
+			go func() {
+				resp, err := cache.Do(waiterCtx, "k", fn)
+				waiter <- result{resp, err}
+			}()
+			time.Sleep(10 * time.Millisecond)
+
+			cancelled, survivor := leader, waiter
+			if tt.cancelLeader {
+				cancelLeader()
+			} else {
+				cancelled, survivor = waiter, leader
+				cancelWaiter()
+			}
+			if r := <-cancelled; !errors.Is(r.err, context.Canceled) {
+				t.Fatalf("cancelled caller err = %v, want context.Canceled", r.err)
+			}
+
+			close(release)
+			if r := <-survivor; r.err != nil || r.resp.Reply != "ok" {
+				t.Fatalf("surviving caller = %v, %v, want ok", r.resp, r.err)
+			}
+			if err := fnErr.Load(); err != nil {
+				t.Fatalf("shared call was cancelled: %v", err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyCacheCallTimeout(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute, 20*time.Millisecond)
+	var calls atomic.Int32
+	fn := func(ctx context.Context) (*pb.ChatResponse, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &pb.ChatResponse{Reply: "retry"}, nil
+	}
+
+	if _, err := cache.Do(context.Background(), "k", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() err = %v, want DeadlineExceeded", err)
+	}
+	// 失败不缓存，相同的键可以重试
+	resp, err := cache.Do(context.Background(), "k", fn)
+	if err != nil || resp.Reply != "retry" {
+		t.Fatalf("retry Do() = %v, %v", resp, err)
+	}
+}
@@ -2,30 +2,72 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/cloudwego/eino-ext/components/model/ollama"
 	"github.com/cloudwego/eino/components/model"
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"video_agent/api"
+	"video_agent/internal/agent/agents/report"
 	agent_biz "video_agent/internal/agent/biz"
+	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/moderation"
+	agentprompt "video_agent/internal/agent/prompt"
 	"video_agent/internal/agent/types"
+	"video_agent/internal/handler"
+	"video_agent/internal/health"
+	"video_agent/internal/llm"
+	"video_agent/internal/logger"
+	mcptools "video_agent/internal/mcp"
+	"video_agent/internal/memory"
 	"video_agent/mcp"
 	pb "video_agent/proto_gen/proto"
+	"video_agent/rag"
+)
+
+const (
+	serverVersion = "1.0.0"
+	ollamaBaseURL = "http://localhost:11434"
+
+	// idempotencyTTL 幂等响应的缓存时长，需覆盖客户端的重试窗口
+	idempotencyTTL = 10 * time.Minute
+	// idempotencyCallTimeout 带幂等键的对话执行的超时，执行不随单个请求断开而取消
+	idempotencyCallTimeout = 5 * time.Minute
+
+	// 会话历史默认/最大返回条数
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
 )
 
 func main() {
 	ctx := context.Background()
 
+	// LOG_LEVEL 控制分级日志的最低输出级别（debug/info/warn/error），默认 info
+	logLevel, err := logger.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		log.Printf("invalid LOG_LEVEL, using info: %v", err)
+	}
+	logger.SetDefault(logger.NewStdLogger(logLevel))
+
 	mcpConfig := &mcp.MCPConfig{
 		Transport: "sse",
 		Server: mcp.ServerConfig{
@@ -38,11 +80,16 @@ func main() {
 		log.Printf("init MCP warning: %v", err)
 	}
 
-	fmt.Println("⏳ 初始化 Ollama 大模型...")
-	llm, err := getChatModel(ctx)
+	llmConfig := getLLMConfig()
+	fmt.Printf("⏳ 初始化大模型 (%s: %s)...\n", llmConfig.Provider, llmConfig.Model)
+	chatModel, err := llm.NewChatModel(ctx, llmConfig)
 	if err != nil {
 		log.Fatalf("get chat model failed: %v", err)
 	}
+	fallbackModels := getFallbackModels(ctx, llmConfig)
+	chatModel = withFallbackModels(chatModel, fallbackModels)
+	// 最外层按请求追加 temperature/top_p/seed，备用模型同样生效
+	chatModel = llm.NewSamplingChatModel(chatModel)
 
 	mcpServers := []types.MCPServer{
 		{
@@ -54,18 +101,54 @@ func main() {
 	}
 
 	fmt.Println("⏳ 初始化 Agent...")
-	uc, err := agent_biz.NewVideoAssistantUsecase(nil, llm, nil, mcpServers)
+	// 工具调用指标与 RAG HTTP 服务的请求指标共用 registry，设置 RAG_HTTP_ADDR 时通过其 /metrics 暴露
+	metricsRegistry := prometheus.NewRegistry()
+	graphOpts := getGraphOptions()
+	graphOpts = append(graphOpts, graph.WithToolMetrics(mcptools.NewToolMetrics(metricsRegistry)))
+	if nodeModels := getNodeModels(ctx, llmConfig, fallbackModels, llm.NewChatModel); len(nodeModels) > 0 {
+		graphOpts = append(graphOpts, graph.WithNodeModels(nodeModels))
+	}
+	uc, err := agent_biz.NewVideoAssistantUsecaseWithGraphOptions(nil, chatModel, nil, mcpServers, graphOpts...)
 	if err != nil {
 		log.Fatalf("create usecase failed: %v", err)
 	}
+	uc.StartMCPRetry(30 * time.Second)
+	if maxRunes, err := strconv.Atoi(getEnv("CHAT_MAX_MESSAGE_RUNES", "")); err == nil {
+		uc.SetMessageLimit(maxRunes, getEnv("CHAT_TRUNCATE_MESSAGE", "") == "true")
+	}
+	defer uc.Close()
+	memoryEmbed := getMemoryEmbedFunc()
+	memoryManager := memory.NewMemoryManager(
+		getShortTermMemory(ctx),
+		getLongTermMemory(memoryEmbed),
+		memory.NewWorkingMemory(20),
+	)
+	if memoryEmbed != nil {
+		memoryManager.SetEmbeddingFunc(memoryEmbed)
+	}
+	memoryManager.SetTitleModel(chatModel)
+	// MEMORY_IMPORTANCE_SCORER=heuristic|llm 为对话打分，重要的内容写入长期记忆；未设置时按固定重要性 0.5。
+	// llm 每条记忆多一次模型调用，在后台执行，不增加对话延迟
+	switch scorer := getEnv("MEMORY_IMPORTANCE_SCORER", ""); scorer {
+	case "heuristic":
+		memoryManager.SetImportanceScorer(memory.HeuristicScorer{})
+	case "llm":
+		memoryManager.SetBackgroundImportanceScorer(memory.NewLLMScorer(chatModel, nil))
+	case "":
+	default:
+		log.Printf("[Server] unknown MEMORY_IMPORTANCE_SCORER=%q, importance scoring disabled", scorer)
+	}
+	uc.SetMemoryManager(memoryManager)
+	uc.SetUserMemoryTopK(getEnvInt("USER_MEMORY_TOP_K", 0))
 
 	lis, err := net.Listen("tcp", ":50090")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
+	checker := newHealthChecker(llmConfig)
 	grpcServer := grpc.NewServer()
-	pb.RegisterXiaovServiceServer(grpcServer, NewXiaovGRPCServer(uc))
+	pb.RegisterXiaovServiceServer(grpcServer, NewXiaovGRPCServer(uc, checker))
 
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
@@ -75,32 +158,296 @@ func main() {
 
 	log.Println("Server started on :50090")
 
+	httpServer := startHTTPServer(uc, checker)
+	ragServer := startRAGServer(chatModel, llmConfig.Model, metricsRegistry)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	if httpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), api.DefaultShutdownTimeout)
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[Server] shutdown HTTP server warning: %v", err)
+		}
+		cancel()
+	}
+	if ragServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), api.DefaultShutdownTimeout)
+		if err := ragServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[Server] shutdown RAG server warning: %v", err)
+		}
+		cancel()
+	}
 	grpcServer.GracefulStop()
 }
 
+// startHTTPServer XIAOV_HTTP_ADDR（如 ":8080"）设置时启动小V助手 HTTP 服务（对话、视频分析、会话、WebSocket 与 MCP 管理接口），
+// 与 gRPC 共用 usecase 和健康检查；未设置 XIAOV_HTTP_ADDR 时返回 nil
+func startHTTPServer(uc *agent_biz.VideoAssistantUsecase, checker *health.Checker) *http.Server {
+	addr := getEnv("XIAOV_HTTP_ADDR", "")
+	if addr == "" {
+		return nil
+	}
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: newHTTPRouter(uc, checker),
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Server] xiaov HTTP server stopped: %v", err)
+		}
+	}()
+	log.Printf("Xiaov HTTP server started on %s", addr)
+	return server
+}
+
+// newHTTPRouter 注册小V助手 HTTP 路由，XIAOV_ADMIN_API_KEYS、XIAOV_WS_ORIGINS 为逗号分隔的
+// 管理接口 X-API-Key 与 WebSocket Origin 白名单（未设置时管理接口拒绝访问、WebSocket 只允许同源）
+func newHTTPRouter(uc *agent_biz.VideoAssistantUsecase, checker *health.Checker) *gin.Engine {
+	h := handler.NewXiaovHandler(uc)
+	h.SetHealthChecker(checker)
+	h.SetAdminAPIKeys(getEnvList("XIAOV_ADMIN_API_KEYS")...)
+	if origins := getEnvList("XIAOV_WS_ORIGINS"); len(origins) > 0 {
+		h.SetWebSocketOrigins(origins...)
+	}
+
+	router := gin.Default()
+	h.RegisterRoutes(router)
+	return router
+}
+
+// startRAGServer RAG_HTTP_ADDR（如 ":8082"）设置时启动知识库 HTTP 服务，/api/chat/rag 与 /api/rag/chat/stream 使用 chatModel 回答；
+// RAG_VECTOR_STORE/RAG_STORE 为文档存储路径，RAG_ALLOWED_ORIGINS、RAG_API_KEYS 为逗号分隔的 CORS 白名单与 X-API-Key，
+// 请求指标注册到 registry；未设置 RAG_HTTP_ADDR 时返回 nil
+func startRAGServer(chatModel model.ChatModel, modelName string, registry *prometheus.Registry) *api.RAGServer {
+	addr := getEnv("RAG_HTTP_ADDR", "")
+	if addr == "" {
+		return nil
+	}
+
+	ragManager, err := rag.NewRAGManager(
+		getEnv("RAG_VECTOR_STORE", "./data/vector_store/documents.json"),
+		getEnv("RAG_STORE", "./data/rag_store/documents.json"),
+	)
+	if err != nil {
+		log.Printf("[Server] create RAG manager failed, RAG HTTP server disabled: %v", err)
+		return nil
+	}
+
+	server := api.NewRAGServerWithConfig(ragManager, &api.RAGServerConfig{
+		AllowedOrigins:      getEnvList("RAG_ALLOWED_ORIGINS"),
+		APIKeys:             getEnvList("RAG_API_KEYS"),
+		ChatModel:           chatModel,
+		ModelName:           modelName,
+		ExpirySweepInterval: getEnvDuration("RAG_EXPIRY_SWEEP_INTERVAL", 0),
+		Metrics:             api.NewMetrics(registry),
+	})
+	go func() {
+		if err := server.Start(addr); err != nil {
+			log.Printf("[Server] RAG HTTP server stopped: %v", err)
+		}
+	}()
+	log.Printf("RAG HTTP server started on %s", addr)
+	return server
+}
+
+const (
+	// 短期记忆每个会话保留的条数与过期时间
+	shortTermMaxItems = 50
+	shortTermTTL      = 24 * time.Hour
+)
+
+// getMemoryEmbedFunc MEMORY_EMBEDDINGS=true 时返回记忆的嵌入函数，短期记忆也按语义相似度检索，长期记忆（MEMORY_DIR）依赖它检索；
+// 每条记忆多一次嵌入调用，默认关闭，返回 nil
+func getMemoryEmbedFunc() func(ctx context.Context, text string) ([]float64, error) {
+	if getEnv("MEMORY_EMBEDDINGS", "") != "true" {
+		return nil
+	}
+	embedder, err := rag.NewOllamaEmbedder(&rag.OllamaEmbedderConfig{
+		BaseURL: getEnv("MEMORY_EMBEDDING_BASE_URL", ollamaBaseURL),
+		Model:   getEnv("MEMORY_EMBEDDING_MODEL", ""),
+	})
+	if err != nil {
+		log.Printf("[Server] create memory embedder failed, memory embeddings disabled: %v", err)
+		return nil
+	}
+	return rag.EmbedFunc(embedder)
+}
+
+// getShortTermMemory REDIS_ADDR（如 "localhost:6379"）设置时短期记忆存入 Redis，多副本共享会话历史，
+// REDIS_PASSWORD、REDIS_DB 为密码与库号；未设置时保存在进程内存
+func getShortTermMemory(ctx context.Context) memory.ShortTermStore {
+	addr := getEnv("REDIS_ADDR", "")
+	if addr == "" {
+		return memory.NewShortTermMemory(shortTermMaxItems, shortTermTTL)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: getEnv("REDIS_PASSWORD", ""),
+		DB:       getEnvInt("REDIS_DB", 0),
+	})
+	// 启动时连不上只告警，Redis 恢复后请求即可正常读写
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		log.Printf("[Server] ping redis %s warning: %v", addr, err)
+	}
+	return memory.NewRedisShortTermMemory(client, shortTermMaxItems, shortTermTTL)
+}
+
+// getLongTermMemory MEMORY_DIR 设置时长期记忆以 JSON 文件保存在该目录，检索需要 embedFunc（MEMORY_EMBEDDINGS=true）；
+// 未设置、缺少嵌入函数或创建失败时返回未配置存储的长期记忆，重要记忆不会持久化
+func getLongTermMemory(embedFunc func(ctx context.Context, text string) ([]float64, error)) *memory.LongTermMemory {
+	dir := getEnv("MEMORY_DIR", "")
+	if dir == "" {
+		return memory.NewLongTermMemory(nil, nil, nil)
+	}
+	if embedFunc == nil {
+		log.Printf("[Server] MEMORY_DIR requires MEMORY_EMBEDDINGS=true, long term memory disabled")
+		return memory.NewLongTermMemory(nil, nil, nil)
+	}
+
+	longTerm, err := memory.NewLocalLongTermMemory(dir, embedFunc)
+	if err != nil {
+		log.Printf("[Server] create long term memory failed, long term memory disabled: %v", err)
+		return memory.NewLongTermMemory(nil, nil, nil)
+	}
+	return longTerm
+}
+
 type XiaovGRPCServer struct {
 	pb.UnimplementedXiaovServiceServer
-	usecase *agent_biz.VideoAssistantUsecase
+	usecase     *agent_biz.VideoAssistantUsecase
+	health      *health.Checker
+	idempotency *idempotencyCache
+	limiter     *concurrencyLimiter
 }
 
-func NewXiaovGRPCServer(uc *agent_biz.VideoAssistantUsecase) *XiaovGRPCServer {
+func NewXiaovGRPCServer(uc *agent_biz.VideoAssistantUsecase, checker *health.Checker) *XiaovGRPCServer {
 	return &XiaovGRPCServer{
-		usecase: uc,
+		usecase:     uc,
+		health:      checker,
+		idempotency: newIdempotencyCache(idempotencyTTL, idempotencyCallTimeout),
+		// GRPC_MAX_CONCURRENT_LLM 同时执行的对话数上限（0 不限制），GRPC_LLM_QUEUE_SIZE 满载时允许排队的请求数
+		limiter: newConcurrencyLimiter(getEnvInt("GRPC_MAX_CONCURRENT_LLM", 0), getEnvInt("GRPC_LLM_QUEUE_SIZE", 0)),
+	}
+}
+
+// newHealthChecker 使用 Ollama 时其为关键依赖；MCP 不可用时 Agent 仍可无工具运行，视为降级
+func newHealthChecker(llmConfig *llm.Config) *health.Checker {
+	deps := []health.Dependency{
+		{
+			Name: "mcp",
+			Check: func(ctx context.Context) error {
+				_, err := mcp.GetMCPTool(ctx)
+				return err
+			},
+		},
+	}
+	if llmConfig.ProviderName() == llm.ProviderOllama {
+		deps = append(deps, health.Dependency{
+			Name:     "ollama",
+			Check:    health.OllamaCheck(llmConfig.BaseURL),
+			Critical: true,
+		})
+	}
+	return health.NewChecker(5*time.Second, deps...)
+}
+
+func (s *XiaovGRPCServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	report := s.health.Check(ctx)
+
+	deps := make(map[string]string, len(report.Dependencies))
+	for _, dep := range report.Dependencies {
+		if dep.Error != "" {
+			deps[dep.Name] = fmt.Sprintf("%s: %s", dep.Status, dep.Error)
+			continue
+		}
+		deps[dep.Name] = string(dep.Status)
+	}
+
+	var code int32
+	if report.Status == health.StatusUnhealthy {
+		code = int32(report.HTTPStatus())
+	}
+
+	return &pb.HealthCheckResponse{
+		Code:         code,
+		Status:       string(report.Status),
+		Version:      serverVersion,
+		Timestamp:    report.CheckedAt.UnixMilli(),
+		Features:     []string{"chat", "chat_stream"},
+		Dependencies: deps,
+	}, nil
+}
+
+// validateMessage 校验消息非空且不超过长度上限，开启截断时会改写 req.Message
+func (s *XiaovGRPCServer) validateMessage(req *pb.ChatRequest) error {
+	if req.Message == "" {
+		return status.Error(codes.InvalidArgument, "message is required")
 	}
+	message, err := s.usecase.CheckMessage(req.Message)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	req.Message = message
+	return nil
+}
+
+// traceContext 沿用 gRPC metadata 中的 x-trace-id（没有或不合法时生成），并写入响应 header 便于客户端关联日志
+func traceContext(ctx context.Context, setHeader func(metadata.MD) error) context.Context {
+	key := strings.ToLower(logger.TraceIDHeader)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(key); len(ids) > 0 && logger.ValidTraceID(ids[0]) {
+			ctx = logger.WithTraceID(ctx, ids[0])
+		}
+	}
+	ctx, traceID := logger.EnsureTraceID(ctx)
+	if err := setHeader(metadata.Pairs(key, traceID)); err != nil {
+		log.Printf("[Server] set trace header warning: %v", err)
+	}
+	return ctx
 }
 
 func (s *XiaovGRPCServer) Chat(ctx context.Context, req *pb.ChatRequest) (*pb.ChatResponse, error) {
+	if err := s.validateMessage(req); err != nil {
+		return nil, err
+	}
+
+	ctx = traceContext(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+
+	if req.IdempotencyKey == "" {
+		return s.chat(ctx, req)
+	}
+
+	// 幂等键按用户隔离，避免不同用户的键冲突
+	key := req.UserId + ":" + req.IdempotencyKey
+	resp, err := s.idempotency.Do(ctx, key, func(ctx context.Context) (*pb.ChatResponse, error) {
+		return s.chat(ctx, req)
+	})
+	if err != nil && ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	return resp, err
+}
+
+func (s *XiaovGRPCServer) chat(ctx context.Context, req *pb.ChatRequest) (*pb.ChatResponse, error) {
 	sessionID := req.SessionId
 	if sessionID == "" {
 		sessionID = uuid.New().String()
 	}
 
-	reply, err := s.usecase.Chat(ctx, sessionID, req.UserId, req.Message)
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	detail, err := s.usecase.ChatWithDetail(ctx, sessionID, req.UserId, req.Message)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "chat failed: %v", err)
 	}
@@ -108,39 +455,399 @@ func (s *XiaovGRPCServer) Chat(ctx context.Context, req *pb.ChatRequest) (*pb.Ch
 	return &pb.ChatResponse{
 		Code:      0,
 		Message:   "success",
-		Reply:     reply,
+		Reply:     detail.Reply,
 		SessionId: sessionID,
+		Intent:    detail.Intent,
 		Timestamp: time.Now().UnixMilli(),
+		Analysis:  toPBVideoAnalysis(detail.Analysis),
+		Metadata:  sourcesMetadata(detail.Sources),
 	}, nil
 }
 
+// sourcesMetadata 将支撑回答的知识库文档编码为 JSON 数组写入 metadata["sources"]，没有来源时返回 nil
+func sourcesMetadata(docs []types.RAGDocument) map[string]string {
+	if len(docs) == 0 {
+		return nil
+	}
+	sources := make([]types.RAGSource, len(docs))
+	for i, doc := range docs {
+		sources[i] = types.NewRAGSource(i+1, doc.ID, doc.Content, doc.Score)
+	}
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{"sources": string(data)}
+}
+
+// toPBVideoAnalysis 转换结构化分析结果，计数字段取自 views/likes/comments 指标
+func toPBVideoAnalysis(a *report.StructuredAnalysis) *pb.VideoAnalysis {
+	if a == nil {
+		return nil
+	}
+	return &pb.VideoAnalysis{
+		ViewCount:    int64(a.Metrics["views"]),
+		LikeCount:    int64(a.Metrics["likes"]),
+		CommentCount: int64(a.Metrics["comments"]),
+		Sentiment:    a.Sentiment,
+		Summary:      a.Summary,
+		KeyPoints:    a.KeyPoints,
+		Suggestions:  a.Suggestions,
+		Metrics:      a.Metrics,
+	}
+}
+
 func (s *XiaovGRPCServer) ChatStream(req *pb.ChatRequest, stream pb.XiaovService_ChatStreamServer) error {
+	if err := s.validateMessage(req); err != nil {
+		return err
+	}
+
 	sessionID := req.SessionId
 	if sessionID == "" {
 		sessionID = uuid.New().String()
 	}
 
-	result, err := s.usecase.StreamChat(stream.Context(), sessionID, req.UserId, req.Message)
+	ctx := traceContext(stream.Context(), stream.SetHeader)
+	// 流式对话在后台执行图，名额持有到后台生成结束：客户端断开时 ctx 取消，
+	// 但模型调用与会话记录写入可能仍在收尾，提前释放会让新请求与其叠加超出并发上限
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.usecase.StreamChat(ctx, sessionID, req.UserId, req.Message)
 	if err != nil {
+		release()
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
 		return status.Errorf(codes.Internal, "stream chat failed: %v", err)
 	}
+	defer func() {
+		go func() {
+			<-reader.Done()
+			release()
+		}()
+	}()
 
-	return stream.Send(&pb.ChatStreamResponse{
-		Payload: &pb.ChatStreamResponse_Content{
-			Content: &pb.StreamContent{
-				Content: result,
+	return sendChatStream(ctx, stream, sessionID, reader)
+}
+
+// sendChatStream 将流式结果逐块转发给客户端，读到 io.EOF（包括被包装的）时发送 done 帧结束
+func sendChatStream(ctx context.Context, stream pb.XiaovService_ChatStreamServer, sessionID string, reader agent_biz.ChatStreamReader) error {
+	for {
+		chunk, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.Send(&pb.ChatStreamResponse{
+				Payload: &pb.ChatStreamResponse_Done{
+					Done: &pb.StreamDone{
+						SessionId: sessionID,
+						Timestamp: time.Now().UnixMilli(),
+					},
+				},
+			})
+		}
+		if err != nil {
+			// 客户端断开或超时，无需再回写错误
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			log.Printf("[Server] chat stream recv error: %v", err)
+			return stream.Send(&pb.ChatStreamResponse{
+				Payload: &pb.ChatStreamResponse_Error{
+					Error: &pb.StreamError{
+						Code:      int32(codes.Internal),
+						Message:   err.Error(),
+						SessionId: sessionID,
+					},
+				},
+			})
+		}
+
+		if err := stream.Send(&pb.ChatStreamResponse{
+			Payload: &pb.ChatStreamResponse_Content{
+				Content: &pb.StreamContent{
+					Content:   chunk.Content,
+					SessionId: sessionID,
+					Phase:     chunk.Phase,
+				},
 			},
-		},
-	})
+		}); err != nil {
+			return err
+		}
+	}
 }
 
-func getChatModel(ctx context.Context) (model.ChatModel, error) {
-	llm, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: "http://localhost:11434",
-		Model:   "qwen3:0.6b",
-	})
+// AnalyzeVideo 分析类型
+const (
+	analysisTypeStructured = "structured"
+	analysisTypeReport     = "report"
+)
+
+// AnalyzeVideo 直接调用 Report Agent 分析指定视频，不经过意图识别；
+// 默认返回报告与结构化结果，analysis_type=report 时只返回报告文本
+func (s *XiaovGRPCServer) AnalyzeVideo(ctx context.Context, req *pb.AnalyzeVideoRequest) (*pb.VideoAnalysisResponse, error) {
+	videoID := strings.TrimSpace(req.VideoId)
+	if videoID == "" {
+		return nil, status.Error(codes.InvalidArgument, "video_id is required")
+	}
+	if strings.TrimSpace(req.UserId) == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	analysisType := req.AnalysisType
+	if analysisType == "" {
+		analysisType = analysisTypeStructured
+	}
+	if analysisType != analysisTypeStructured && analysisType != analysisTypeReport {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported analysis_type %q", req.AnalysisType)
+	}
+
+	sessionID := req.SessionId
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	ctx = traceContext(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp := &pb.VideoAnalysisResponse{
+		Code:      0,
+		Message:   "success",
+		SessionId: sessionID,
+		VideoId:   videoID,
+	}
+
+	if analysisType == analysisTypeReport {
+		result, err := s.usecase.AnalyzeVideo(ctx, sessionID, req.UserId, videoID, req.Query)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "analyze video failed: %v", err)
+		}
+		resp.Report = result.Content
+		resp.ToolsUsed = result.ToolsUsed
+		resp.ProcessingTimeMs = result.ProcessingTime.Milliseconds()
+		resp.Timestamp = time.Now().UnixMilli()
+		return resp, nil
+	}
+
+	result, err := s.usecase.AnalyzeStructured(ctx, sessionID, req.UserId, videoID, req.Query)
 	if err != nil {
-		return nil, fmt.Errorf("create ollama chat model failed: %w", err)
+		return nil, status.Errorf(codes.Internal, "analyze video failed: %v", err)
+	}
+	resp.Report = result.Report.Content
+	resp.Analysis = toPBVideoAnalysis(result.Analysis)
+	resp.ToolsUsed = result.Report.ToolsUsed
+	resp.ProcessingTimeMs = result.Report.ProcessingTime.Milliseconds()
+	resp.Timestamp = time.Now().UnixMilli()
+	return resp, nil
+}
+
+func (s *XiaovGRPCServer) GetSessionHistory(ctx context.Context, req *pb.GetSessionHistoryRequest) (*pb.GetSessionHistoryResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	history, err := s.usecase.GetSessionHistory(ctx, req.SessionId, limit)
+	if err != nil {
+		if errors.Is(err, agent_biz.ErrMemoryNotConfigured) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "get session history failed: %v", err)
+	}
+
+	messages := make([]*pb.ChatMessage, 0, len(history.Messages))
+	for _, mem := range history.Messages {
+		messages = append(messages, &pb.ChatMessage{
+			Id:        mem.ID,
+			SessionId: mem.SessionID,
+			Role:      string(mem.Type),
+			Content:   mem.Content,
+			Timestamp: mem.CreatedAt.UnixMilli(),
+		})
+	}
+
+	return &pb.GetSessionHistoryResponse{
+		Code:     0,
+		Message:  "success",
+		Messages: messages,
+		Total:    int32(len(messages)),
+		Title:    history.Title,
+	}, nil
+}
+
+// getLLMConfig 从环境变量读取大模型配置，默认使用本地 Ollama
+func getLLMConfig() *llm.Config {
+	return &llm.Config{
+		Provider:  getEnv("LLM_PROVIDER", llm.ProviderOllama),
+		BaseURL:   getEnv("LLM_BASE_URL", ollamaBaseURL),
+		Model:     getEnv("LLM_MODEL", "qwen3:0.6b"),
+		APIKey:    os.Getenv("LLM_API_KEY"),
+		Timeout:   getEnvDuration("LLM_TIMEOUT", llm.DefaultTimeout),
+		KeepAlive: getEnvDuration("OLLAMA_KEEP_ALIVE", llm.DefaultKeepAlive),
+	}
+}
+
+// getGraphOptions 按部署环境变量组装图选项
+func getGraphOptions() []graph.GraphOption {
+	var opts []graph.GraphOption
+
+	if maxCalls := getEnvInt("AGENT_MAX_TOOL_CALLS", 0); maxCalls > 0 {
+		opts = append(opts, graph.WithMaxToolCalls(maxCalls))
+	}
+
+	// MODERATION_KEYWORDS 逗号分隔的敏感词，命中时拦截回复；MODERATION_REDACT=true 时改为脱敏
+	if keywords := getEnv("MODERATION_KEYWORDS", ""); keywords != "" {
+		moderator := moderation.NewKeywordModerator(strings.Split(keywords, ","), getEnv("MODERATION_REDACT", "") == "true")
+		opts = append(opts, graph.WithModerator(moderator))
+	}
+
+	// PROMPT_DIR 下的 <模板名>.tmpl 覆盖内置提示词模板，缺少必需变量时拒绝启动
+	if dir := getEnv("PROMPT_DIR", ""); dir != "" {
+		prompts, err := agentprompt.LoadRegistry(dir)
+		if err != nil {
+			log.Fatalf("[Server] load prompt templates: %v", err)
+		}
+		opts = append(opts, graph.WithPromptRegistry(prompts))
+	}
+
+	// CHAT_RAG_THRESHOLD 通用对话先检索知识库的相似度阈值（如 0.4），未设置时不检索
+	if value := getEnv("CHAT_RAG_THRESHOLD", ""); value != "" {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Printf("[Server] invalid CHAT_RAG_THRESHOLD=%q, chat RAG disabled", value)
+		} else {
+			opts = append(opts, graph.WithChatRAG(threshold))
+		}
+	}
+	return opts
+}
+
+// getEnvInt 读取整数类型的环境变量，未设置或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("[Server] invalid %s=%q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvDuration 读取时长类型的环境变量（如 "5m"），未设置或格式错误时返回默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("[Server] invalid %s=%q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// withFallbackModels 为模型加上熔断，并构建备用模型链，模型失败或单次超时时依次回退
+func withFallbackModels(primary model.ChatModel, fallbacks []model.ChatModel) model.ChatModel {
+	// 主模型连续失败后熔断，冷却期内直接切到备用模型，避免每个节点都等满超时
+	primary = llm.NewCircuitBreakerChatModel(primary, llm.BreakerConfig{
+		FailureThreshold: getEnvInt("LLM_BREAKER_THRESHOLD", llm.DefaultBreakerThreshold),
+		Cooldown:         getEnvDuration("LLM_BREAKER_COOLDOWN", llm.DefaultBreakerCooldown),
+	})
+	// LLM_ATTEMPT_TIMEOUT 单个模型的超时，超时后切换到备用模型，未设置时只受模型客户端自身超时限制
+	return llm.NewFallbackChatModelWithConfig(llm.FallbackConfig{
+		AttemptTimeout: getEnvDuration("LLM_ATTEMPT_TIMEOUT", 0),
+	}, primary, fallbacks...)
+}
+
+// getFallbackModels 按 LLM_FALLBACK_MODELS（逗号分隔）创建备用模型，与主模型使用相同的提供方与地址
+func getFallbackModels(ctx context.Context, primaryConfig *llm.Config) []model.ChatModel {
+	var fallbacks []model.ChatModel
+	for _, name := range strings.Split(os.Getenv("LLM_FALLBACK_MODELS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		cfg := *primaryConfig
+		cfg.Model = name
+		m, err := llm.NewChatModel(ctx, &cfg)
+		if err != nil {
+			log.Printf("[Server] create fallback model %s warning: %v", name, err)
+			continue
+		}
+		fallbacks = append(fallbacks, m)
+		log.Printf("[Server] fallback model registered: %s", name)
+	}
+	return fallbacks
+}
+
+// getNodeModels 按环境变量为不同职责的节点指定模型，与主模型使用相同的提供方与地址（LLM_NODE_BASE_URL 可单独指定地址）：
+// LLM_TOOL_SELECTION_MODEL 用于意图识别与工具选择，LLM_ANSWER_MODEL 用于通用对话、结果整合与知识库回答；未设置的节点使用主模型。
+// newModel 按配置创建模型，通常为 llm.NewChatModel
+func getNodeModels(ctx context.Context, primaryConfig *llm.Config, fallbacks []model.ChatModel,
+	newModel func(ctx context.Context, cfg *llm.Config) (model.ChatModel, error)) map[string]model.ChatModel {
+	roles := []struct {
+		env   string
+		nodes []string
+	}{
+		{"LLM_TOOL_SELECTION_MODEL", []string{graph.NodeIntentModel, graph.NodeToolSelection}},
+		{"LLM_ANSWER_MODEL", []string{graph.NodeSummary, graph.NodeRAG}},
+	}
+
+	models := make(map[string]model.ChatModel)
+	for _, role := range roles {
+		name := strings.TrimSpace(os.Getenv(role.env))
+		if name == "" {
+			continue
+		}
+		cfg := *primaryConfig
+		cfg.Model = name
+		cfg.BaseURL = getEnv("LLM_NODE_BASE_URL", primaryConfig.BaseURL)
+		m, err := newModel(ctx, &cfg)
+		if err != nil {
+			log.Printf("[Server] create %s=%s warning, using primary model: %v", role.env, name, err)
+			continue
+		}
+		// 节点模型与主模型一样带熔断与备用模型
+		m = llm.NewSamplingChatModel(withFallbackModels(m, fallbacks))
+		for _, node := range role.nodes {
+			models[node] = m
+		}
+		log.Printf("[Server] node model %s registered for %v", name, role.nodes)
+	}
+	return models
+}
+
+// getEnvList 读取逗号分隔的环境变量，忽略空项
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnv 获取环境变量，如果不存在返回默认值
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
 	}
-	return llm, nil
+	return value
 }
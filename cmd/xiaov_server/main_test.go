@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"video_agent/internal/agent/agents/report"
+	agent_biz "video_agent/internal/agent/biz"
+	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/types"
+	"video_agent/internal/health"
+	"video_agent/internal/llm"
+	"video_agent/internal/memory"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestSourcesMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		docs []types.RAGDocument
+		want []types.RAGSource
+	}{
+		{name: "no sources", docs: nil, want: nil},
+		{
+			name: "numbered from one",
+			docs: []types.RAGDocument{{ID: "a", Content: " 第一篇 ", Score: 0.8}, {ID: "b", Content: "第二篇", Score: 0.4}},
+			want: []types.RAGSource{{Index: 1, ID: "a", Snippet: "第一篇", Score: 0.8}, {Index: 2, ID: "b", Snippet: "第二篇", Score: 0.4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := sourcesMetadata(tt.docs)
+			if tt.want == nil {
+				if md != nil {
+					t.Fatalf("metadata = %v, want nil", md)
+				}
+				return
+			}
+			var got []types.RAGSource
+			if err := json.Unmarshal([]byte(md["sources"]), &got); err != nil {
+				t.Fatalf("decode sources: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sources[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewHealthCheckerProvider(t *testing.T) {
+	tests := []struct {
+		provider   string
+		wantOllama bool
+	}{
+		{provider: "", wantOllama: true},
+		{provider: "ollama", wantOllama: true},
+		{provider: "Ollama", wantOllama: true},
+		{provider: "openai", wantOllama: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			report := newHealthChecker(&llm.Config{Provider: tt.provider, BaseURL: "http://127.0.0.1:1"}).Check(ctx)
+
+			gotOllama := false
+			for _, dep := range report.Dependencies {
+				if dep.Name == "ollama" {
+					gotOllama = true
+				}
+			}
+			if gotOllama != tt.wantOllama {
+				t.Fatalf("ollama dependency = %v, want %v", gotOllama, tt.wantOllama)
+			}
+		})
+	}
+}
+
+func TestToPBVideoAnalysisUsesToolCounts(t *testing.T) {
+	analysis := &report.StructuredAnalysis{
+		Summary:   "表现良好",
+		Metrics:   map[string]float64{"views": 1.2},
+		Sentiment: report.SentimentPositive,
+	}
+	report.ApplyToolStats(analysis, []types.ToolOutput{
+		{Tool: "get_video_info", Output: `{"view_count":12000,"like_count":800,"comment_count":120}`},
+	})
+
+	got := toPBVideoAnalysis(analysis)
+	if got.ViewCount != 12000 || got.LikeCount != 800 || got.CommentCount != 120 {
+		t.Fatalf("counts = %d/%d/%d, want the tool data 12000/800/120", got.ViewCount, got.LikeCount, got.CommentCount)
+	}
+	if got.Summary != analysis.Summary || got.Sentiment != analysis.Sentiment {
+		t.Errorf("text fields = %q/%q, want them kept from the structured report", got.Summary, got.Sentiment)
+	}
+}
+
+func TestGetEnvIntAndDuration(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		wantInt      int
+		wantDuration time.Duration
+	}{
+		{name: "unset", value: "", wantInt: 7, wantDuration: time.Minute},
+		{name: "int", value: "3", wantInt: 3, wantDuration: time.Minute},
+		{name: "duration", value: "5s", wantInt: 7, wantDuration: 5 * time.Second},
+		{name: "malformed", value: "abc", wantInt: 7, wantDuration: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XIAOV_TEST_ENV", tt.value)
+			if got := getEnvInt("XIAOV_TEST_ENV", 7); got != tt.wantInt {
+				t.Errorf("getEnvInt = %d, want %d", got, tt.wantInt)
+			}
+			if got := getEnvDuration("XIAOV_TEST_ENV", time.Minute); got != tt.wantDuration {
+				t.Errorf("getEnvDuration = %s, want %s", got, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestGetLLMConfigDurations(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       string
+		keepAlive     string
+		wantTimeout   time.Duration
+		wantKeepAlive time.Duration
+	}{
+		{name: "defaults", wantTimeout: llm.DefaultTimeout, wantKeepAlive: llm.DefaultKeepAlive},
+		{name: "from env", timeout: "90s", keepAlive: "30m", wantTimeout: 90 * time.Second, wantKeepAlive: 30 * time.Minute},
+		{name: "invalid values fall back", timeout: "soon", keepAlive: "forever", wantTimeout: llm.DefaultTimeout, wantKeepAlive: llm.DefaultKeepAlive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LLM_TIMEOUT", tt.timeout)
+			t.Setenv("OLLAMA_KEEP_ALIVE", tt.keepAlive)
+
+			cfg := getLLMConfig()
+			if cfg.Timeout != tt.wantTimeout || cfg.KeepAlive != tt.wantKeepAlive {
+				t.Errorf("timeout/keep-alive = %s/%s, want %s/%s", cfg.Timeout, cfg.KeepAlive, tt.wantTimeout, tt.wantKeepAlive)
+			}
+		})
+	}
+}
+
+func TestGetNodeModels(t *testing.T) {
+	primary := &llm.Config{Provider: llm.ProviderOllama, BaseURL: "http://primary:11434", Model: "qwen3:8b", Timeout: time.Minute}
+
+	tests := []struct {
+		name          string
+		toolSelection string
+		answer        string
+		baseURL       string
+		failModel     string
+		want          map[string]string
+		wantBaseURL   string
+	}{
+		{name: "unset keeps the primary model", want: map[string]string{}},
+		{
+			name:          "tool selection model",
+			toolSelection: "qwen3:0.6b",
+			want:          map[string]string{graph.NodeIntentModel: "qwen3:0.6b", graph.NodeToolSelection: "qwen3:0.6b"},
+			wantBaseURL:   "http://primary:11434",
+		},
+		{
+			name:          "both roles on a separate address",
+			toolSelection: "qwen3:0.6b",
+			answer:        "qwen3:14b",
+			baseURL:       "http://nodes:11434",
+			want: map[string]string{
+				graph.NodeIntentModel: "qwen3:0.6b", graph.NodeToolSelection: "qwen3:0.6b",
+				graph.NodeSummary: "qwen3:14b", graph.NodeRAG: "qwen3:14b",
+			},
+			wantBaseURL: "http://nodes:11434",
+		},
+		{
+			name:          "failed model falls back to the primary",
+			toolSelection: "qwen3:0.6b",
+			answer:        "missing",
+			failModel:     "missing",
+			want:          map[string]string{graph.NodeIntentModel: "qwen3:0.6b", graph.NodeToolSelection: "qwen3:0.6b"},
+			wantBaseURL:   "http://primary:11434",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LLM_TOOL_SELECTION_MODEL", tt.toolSelection)
+			t.Setenv("LLM_ANSWER_MODEL", tt.answer)
+			t.Setenv("LLM_NODE_BASE_URL", tt.baseURL)
+
+			// 工厂记录收到的配置，创建的模型以模型名作为回答
+			var configs []llm.Config
+			factory := func(ctx context.Context, cfg *llm.Config) (model.ChatModel, error) {
+				configs = append(configs, *cfg)
+				if cfg.Model == tt.failModel {
+					return nil, errors.New("model not found")
+				}
+				return answerModel{answer: cfg.Model}, nil
+			}
+
+			models := getNodeModels(context.Background(), primary, nil, factory)
+
+			got := make(map[string]string, len(models))
+			for node, m := range models {
+				resp, err := m.Generate(context.Background(), []*schema.Message{schema.UserMessage("你好")})
+				if err != nil {
+					t.Fatalf("%s Generate: %v", node, err)
+				}
+				got[node] = resp.Content
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("node models = %v, want %v", got, tt.want)
+			}
+
+			var names []string
+			for _, cfg := range configs {
+				names = append(names, cfg.Model)
+				if cfg.BaseURL != tt.wantBaseURL || cfg.Provider != primary.Provider || cfg.Timeout != primary.Timeout {
+					t.Errorf("config for %s = %+v, want the primary settings at %s", cfg.Model, cfg, tt.wantBaseURL)
+				}
+			}
+			sort.Strings(names)
+			var wantNames []string
+			for _, name := range []string{tt.toolSelection, tt.answer} {
+				if name != "" {
+					wantNames = append(wantNames, name)
+				}
+			}
+			sort.Strings(wantNames)
+			if !reflect.DeepEqual(names, wantNames) {
+				t.Errorf("factory built %v, want %v", names, wantNames)
+			}
+		})
+	}
+}
+
+func TestGetShortTermMemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      string
+		wantRedis bool
+	}{
+		{name: "in-process by default", addr: ""},
+		// 端口不可达时只告警，仍使用 Redis
+		{name: "redis when configured", addr: "127.0.0.1:1", wantRedis: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REDIS_ADDR", tt.addr)
+
+			store := getShortTermMemory(context.Background())
+			if _, isRedis := store.(*memory.RedisShortTermMemory); isRedis != tt.wantRedis {
+				t.Fatalf("short term store = %T, want redis %v", store, tt.wantRedis)
+			}
+			if _, isLocal := store.(*memory.ShortTermMemory); isLocal == tt.wantRedis {
+				t.Fatalf("short term store = %T, want in-process %v", store, !tt.wantRedis)
+			}
+		})
+	}
+}
+
+func TestGetLongTermMemory(t *testing.T) {
+	embed := func(ctx context.Context, text string) ([]float64, error) { return []float64{1, 0}, nil }
+
+	tests := []struct {
+		name      string
+		dir       bool
+		embed     func(ctx context.Context, text string) ([]float64, error)
+		wantReady bool
+	}{
+		{name: "disabled without dir", embed: embed},
+		{name: "disabled without embeddings", dir: true},
+		{name: "local files", dir: true, embed: embed, wantReady: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := ""
+			if tt.dir {
+				dir = t.TempDir()
+			}
+			t.Setenv("MEMORY_DIR", dir)
+
+			longTerm := getLongTermMemory(tt.embed)
+			if longTerm.Ready() != tt.wantReady {
+				t.Fatalf("Ready = %v, want %v", longTerm.Ready(), tt.wantReady)
+			}
+			if !tt.wantReady {
+				return
+			}
+
+			// 写入后重新打开同一目录，记忆仍可按用户检索
+			if err := longTerm.Store(context.Background(), memory.Memory{
+				ID: "m1", SessionID: "s1", Content: "喜欢科技区视频", Metadata: map[string]interface{}{memory.MetadataUserID: "u1"},
+			}); err != nil {
+				t.Fatalf("Store: %v", err)
+			}
+			got, err := getLongTermMemory(tt.embed).SearchByUser(context.Background(), "科技", "u1", 1)
+			if err != nil {
+				t.Fatalf("SearchByUser: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != "m1" {
+				t.Errorf("SearchByUser = %+v, want the stored memory", got)
+			}
+		})
+	}
+}
+
+func TestNewHTTPRouter(t *testing.T) {
+	t.Setenv("XIAOV_ADMIN_API_KEYS", "admin-key")
+	uc, err := agent_biz.NewVideoAssistantUsecase(nil, answerModel{answer: "你好"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	defer uc.Close()
+	router := newHTTPRouter(uc, health.NewChecker(time.Second))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "health", method: http.MethodGet, path: "/api/health", wantStatus: http.StatusOK},
+		{name: "chat", method: http.MethodPost, path: "/api/chat", body: `{"message":"你好","session_id":"s1"}`, wantStatus: http.StatusOK},
+		{name: "admin without key", method: http.MethodGet, path: "/api/mcp/tools", wantStatus: http.StatusUnauthorized},
+		{name: "admin with key", method: http.MethodGet, path: "/api/mcp/tools", apiKey: "admin-key", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d: %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStartHTTPServerDisabledWithoutAddr(t *testing.T) {
+	t.Setenv("XIAOV_HTTP_ADDR", "")
+	if server := startHTTPServer(nil, nil); server != nil {
+		t.Fatalf("startHTTPServer = %v, want nil without XIAOV_HTTP_ADDR", server)
+	}
+}
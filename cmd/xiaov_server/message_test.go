@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agent_biz "video_agent/internal/agent/biz"
+	pb "video_agent/proto_gen/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestChatMessageLimit(t *testing.T) {
+	const limit = 10
+
+	tests := []struct {
+		name        string
+		message     string
+		truncate    bool
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "empty message", message: "", wantCode: codes.InvalidArgument},
+		{name: "boundary length", message: strings.Repeat("播", limit), wantCode: codes.OK, wantMessage: strings.Repeat("播", limit)},
+		{name: "over limit", message: strings.Repeat("播", limit+1), wantCode: codes.InvalidArgument},
+		{name: "over limit with truncation", message: strings.Repeat("播", limit+1), truncate: true, wantCode: codes.OK, wantMessage: strings.Repeat("播", limit)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, err := agent_biz.NewVideoAssistantUsecase(nil, answerModel{answer: "你好"}, nil, nil)
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecase: %v", err)
+			}
+			uc.SetMessageLimit(limit, tt.truncate)
+			srv := &XiaovGRPCServer{usecase: uc}
+
+			req := &pb.ChatRequest{SessionId: "s1", UserId: "u1", Message: tt.message}
+			resp, err := srv.Chat(context.Background(), req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (err %v)", status.Code(err), tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Reply == "" {
+				t.Errorf("resp = %+v, want a reply", resp)
+			}
+			if req.Message != tt.wantMessage {
+				t.Errorf("processed message has %d runes, want %d", len([]rune(req.Message)), len([]rune(tt.wantMessage)))
+			}
+		})
+	}
+}
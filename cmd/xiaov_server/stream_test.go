@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	agent_biz "video_agent/internal/agent/biz"
+	pb "video_agent/proto_gen/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeChatStreamReader 依次返回 chunks，之后返回 err
+type fakeChatStreamReader struct {
+	chunks []*agent_biz.StreamChunk
+	err    error
+}
+
+func (r *fakeChatStreamReader) Recv() (*agent_biz.StreamChunk, error) {
+	if len(r.chunks) == 0 {
+		return nil, r.err
+	}
+	chunk := r.chunks[0]
+	r.chunks = r.chunks[1:]
+	return chunk, nil
+}
+
+func (r *fakeChatStreamReader) StreamID() string { return "" }
+
+func (r *fakeChatStreamReader) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+// recordingStream 记录发送给客户端的消息
+type recordingStream struct {
+	grpc.ServerStream
+	sent []*pb.ChatStreamResponse
+}
+
+func (s *recordingStream) Send(resp *pb.ChatStreamResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestSendChatStream(t *testing.T) {
+	chunks := func() []*agent_biz.StreamChunk {
+		return []*agent_biz.StreamChunk{{Phase: "content", Content: "播放量"}, {Phase: "content", Content: "稳步增长"}}
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		err       error
+		wantCode  codes.Code
+		wantFinal string
+	}{
+		{name: "plain EOF", ctx: context.Background(), err: io.EOF, wantFinal: "done"},
+		{name: "wrapped EOF", ctx: context.Background(), err: fmt.Errorf("read stream: %w", io.EOF), wantFinal: "done"},
+		{name: "stream error", ctx: context.Background(), err: errors.New("model unavailable"), wantFinal: "error"},
+		{name: "client gone", ctx: canceled, err: context.Canceled, wantCode: codes.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &recordingStream{}
+			err := sendChatStream(tt.ctx, stream, "s1", &fakeChatStreamReader{chunks: chunks(), err: tt.err})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+			}
+
+			var content string
+			for _, resp := range stream.sent[:min(2, len(stream.sent))] {
+				content += resp.GetContent().GetContent()
+			}
+			if content != "播放量稳步增长" {
+				t.Errorf("content = %q, want both chunks", content)
+			}
+
+			var final string
+			if len(stream.sent) == 3 {
+				switch last := stream.sent[2]; {
+				case last.GetDone() != nil:
+					final = "done"
+				case last.GetError() != nil:
+					final = "error"
+				}
+			}
+			if final != tt.wantFinal || len(stream.sent) > 3 {
+				t.Errorf("sent %d messages ending with %q, want 2 chunks then %q", len(stream.sent), final, tt.wantFinal)
+			}
+		})
+	}
+}
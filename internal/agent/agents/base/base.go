@@ -2,36 +2,130 @@ package base
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
+	"video_agent/internal/agent/progress"
 	"video_agent/internal/agent/state"
 	"video_agent/internal/agent/types"
+	"video_agent/internal/mcp"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 )
 
+// defaultMaxToolRound 单次Agent执行默认允许的工具调用轮数
+const defaultMaxToolRound = 5
+
+// defaultMaxToolCalls 单轮默认最多执行的工具调用数
+const defaultMaxToolCalls = 5
+
+// ErrStepLimitReached 达到最大工具调用轮数时模型仍在请求工具，分析未完成
+var ErrStepLimitReached = errors.New("tool step limit reached")
+
 type ToolExecutor struct {
-	tools []tool.BaseTool
-	llm   model.ChatModel
+	tools         []tool.BaseTool
+	llm           model.ChatModel
+	maxToolRounds int
+	maxToolCalls  int
+	// metrics 工具调用指标，为 nil 时不记录
+	metrics *mcp.ToolMetrics
 }
 
 func NewToolExecutor(tools []tool.BaseTool, llm model.ChatModel) *ToolExecutor {
 	return &ToolExecutor{
-		tools: tools,
-		llm:   llm,
+		tools:         tools,
+		llm:           llm,
+		maxToolRounds: defaultMaxToolRound,
+		maxToolCalls:  defaultMaxToolCalls,
+	}
+}
+
+// SetMetrics 设置工具调用指标，按工具记录调用次数、成败与耗时；为 nil 时不记录
+func (te *ToolExecutor) SetMetrics(metrics *mcp.ToolMetrics) {
+	te.metrics = metrics
+}
+
+// SetMaxToolCalls 设置单轮最多执行的工具调用数，<=0 时使用默认值
+func (te *ToolExecutor) SetMaxToolCalls(calls int) {
+	if calls <= 0 {
+		calls = defaultMaxToolCalls
+	}
+	te.maxToolCalls = calls
+}
+
+// sanitizeToolCalls 丢弃重复的调用（同名同参数），可用工具的调用超过上限时按模型给出的顺序保留前 maxToolCalls 个，
+// 避免异常输出触发大量 Gateway 请求。模型的工具调用不带置信度，排在前面的视为优先级更高。
+// 模型编造的（未绑定的）工具调用不会执行，但仍保留在响应中，由 runToolCall 回填“工具不可用”，
+// 让模型在下一轮改用可用工具或直接回答，而不是得到一个既没有工具调用也没有内容的空回复
+func (te *ToolExecutor) sanitizeToolCalls(ctx context.Context, resp *schema.Message) {
+	known := te.toolNames(ctx)
+
+	seen := make(map[string]bool, len(resp.ToolCalls))
+	calls := make([]schema.ToolCall, 0, len(resp.ToolCalls))
+	executable := 0
+	for _, tc := range resp.ToolCalls {
+		key := tc.Function.Name + "\x00" + tc.Function.Arguments
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !known[tc.Function.Name] {
+			log.Printf("[ToolExecutor] tool call %s refused: tool not available", tc.Function.Name)
+			calls = append(calls, tc)
+			continue
+		}
+		if executable == te.maxToolCalls {
+			log.Printf("[ToolExecutor] tool call limit reached, dropped %s beyond first %d calls", tc.Function.Name, te.maxToolCalls)
+			continue
+		}
+		executable++
+		calls = append(calls, tc)
+	}
+	resp.ToolCalls = calls
+}
+
+// toolNames 已绑定工具的名称集合
+func (te *ToolExecutor) toolNames(ctx context.Context) map[string]bool {
+	known := make(map[string]bool, len(te.tools))
+	for _, t := range te.tools {
+		if info, err := t.Info(ctx); err == nil {
+			known[info.Name] = true
+		}
+	}
+	return known
+}
+
+// SetMaxToolRounds 设置最大工具调用轮数，<=0 时使用默认值
+func (te *ToolExecutor) SetMaxToolRounds(rounds int) {
+	if rounds <= 0 {
+		rounds = defaultMaxToolRound
 	}
+	te.maxToolRounds = rounds
 }
 
+// ToolRun 一次 ExecuteWithTools 中的工具调用记录
+type ToolRun struct {
+	// ToolsUsed 调用过的已注册工具
+	ToolsUsed []string
+	// Errors 失败的工具调用
+	Errors []types.ToolError
+	// Outputs 成功调用的工具输出，供调用方直接读取工具数据而不必从模型回答中解析
+	Outputs []types.ToolOutput
+}
+
+// ExecuteWithTools 执行 LLM 与工具的多轮交互：模型请求工具时执行并回填结果，直到模型给出最终回答。
+// 返回最终响应及工具调用记录（不为 nil）；超过最大轮数时返回最后一次响应及 ErrStepLimitReached
 func (te *ToolExecutor) ExecuteWithTools(
 	ctx context.Context,
 	messages []*schema.Message,
-) (*schema.Message, []string, error) {
-	var toolsUsed []string
+) (*schema.Message, *ToolRun, error) {
+	run := &ToolRun{}
 
 	if len(te.tools) > 0 {
 		toolInfos := make([]*schema.ToolInfo, len(te.tools))
@@ -55,76 +149,151 @@ func (te *ToolExecutor) ExecuteWithTools(
 
 	resp, err := te.llm.Generate(ctx, messages)
 	if err != nil {
-		return nil, nil, fmt.Errorf("LLM generate failed: %w", err)
+		return nil, run, fmt.Errorf("LLM generate failed: %w", err)
 	}
 
 	log.Printf("[ToolExecutor] LLM response: content=%q, tool_calls=%d", resp.Content, len(resp.ToolCalls))
 
-	if len(resp.ToolCalls) > 0 && len(te.tools) > 0 {
-		log.Printf("[ToolExecutor] executing tool calls...")
-		toolResultMsgs := make([]*schema.Message, 0, len(resp.ToolCalls)+1)
-		toolResultMsgs = append(toolResultMsgs, resp)
+	if len(te.tools) == 0 {
+		return resp, run, nil
+	}
+
+	conversation := append([]*schema.Message{}, messages...)
+	for round := 1; len(resp.ToolCalls) > 0; round++ {
+		if round > te.maxToolRounds {
+			log.Printf("[ToolExecutor] step limit reached after %d rounds", te.maxToolRounds)
+			return resp, run, fmt.Errorf("%w: %d rounds", ErrStepLimitReached, te.maxToolRounds)
+		}
 
+		te.sanitizeToolCalls(ctx, resp)
+		if len(resp.ToolCalls) == 0 {
+			break
+		}
+		log.Printf("[ToolExecutor] executing %d tool calls, round %d/%d", len(resp.ToolCalls), round, te.maxToolRounds)
+		toolResultMsgs := make([]*schema.Message, 0, len(resp.ToolCalls))
+		known := te.toolNames(ctx)
 		for _, tc := range resp.ToolCalls {
-			toolsUsed = append(toolsUsed, tc.Function.Name)
+			if known[tc.Function.Name] {
+				run.ToolsUsed = append(run.ToolsUsed, tc.Function.Name)
+			}
 
-			var result string
-			var args map[string]interface{}
-			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-				log.Printf("[ToolExecutor] unmarshal args error: %v", err)
-				result = fmt.Sprintf("参数解析失败: %v", err)
+			result, toolErr := te.runToolCall(ctx, tc)
+			if toolErr != nil {
+				run.Errors = append(run.Errors, *toolErr)
 			} else {
-				for _, t := range te.tools {
-					info, _ := t.Info(ctx)
-					if info.Name == tc.Function.Name {
-						invokable, ok := t.(tool.InvokableTool)
-						if !ok {
-							result = fmt.Sprintf("tool %s is not invokable", tc.Function.Name)
-							break
-						}
-						argsJSON, _ := json.Marshal(args)
-						log.Println("调用工具之前+v%", argsJSON)
-						output, err := invokable.InvokableRun(ctx, string(argsJSON))
-						log.Printf("[ToolExecutor] 工具调用返回 %+v", output)
-
-						if err != nil {
-							result = fmt.Sprintf("tool execution failed: %v", err)
-						} else {
-							result = extractMCPToolResult(fmt.Sprintf("%v", output))
-							log.Printf("工具格式转换后返回: %s", result)
-						}
-						break
-					}
-				}
+				run.Outputs = append(run.Outputs, types.ToolOutput{Tool: tc.Function.Name, Output: result})
 			}
-
-			toolMsg := &schema.Message{
+			toolResultMsgs = append(toolResultMsgs, &schema.Message{
 				Role:       schema.Tool,
 				Content:    result,
 				ToolCallID: tc.ID,
-			}
-			toolResultMsgs = append(toolResultMsgs, toolMsg)
+			})
 			log.Printf("[ToolExecutor] tool %s result: %s", tc.Function.Name, result)
 		}
+		conversation = append(conversation, resp)
+		conversation = append(conversation, toolResultMsgs...)
+
 		//到这里工具调用完成 拼接工具返回和agent的系统提示词
-		log.Printf("[ToolExecutor] sending %d messages to LLM for final generation", len(toolResultMsgs))
-		resp, err = te.llm.Generate(ctx, toolResultMsgs)
+		log.Printf("[ToolExecutor] sending %d messages to LLM for next generation", len(conversation))
+		next, err := te.llm.Generate(ctx, conversation)
 		//到这里会调用agent 综合工具数据返回给出了分析
 		if err != nil {
 			log.Printf("[ToolExecutor] LLM generate after tool warning: %v, returning tool result directly", err)
 			toolResultContent := ""
-			for i := 1; i < len(toolResultMsgs); i++ {
-				toolResultContent += toolResultMsgs[i].Content + "\n"
+			for _, msg := range toolResultMsgs {
+				toolResultContent += msg.Content + "\n"
 			}
 			return &schema.Message{
 				Role:    schema.Assistant,
 				Content: toolResultContent,
-			}, toolsUsed, nil
+			}, run, nil
+		}
+		resp = next
+		log.Printf("[ToolExecutor] round %d response: content=%q, tool_calls=%d", round, resp.Content, len(resp.ToolCalls))
+	}
+
+	return resp, run, nil
+}
+
+// runToolCall 执行单个工具调用，返回写回模型的结果文本；失败时同时返回带错误码的 ToolError
+func (te *ToolExecutor) runToolCall(ctx context.Context, tc schema.ToolCall) (string, *types.ToolError) {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+		log.Printf("[ToolExecutor] unmarshal args error: %v", err)
+		return fmt.Sprintf("参数解析失败: %v", err), &types.ToolError{
+			Tool:    tc.Function.Name,
+			Code:    types.ToolErrorInvalidArgument,
+			Message: err.Error(),
+		}
+	}
+
+	for _, t := range te.tools {
+		info, _ := t.Info(ctx)
+		if info.Name != tc.Function.Name {
+			continue
+		}
+
+		invokable, ok := t.(tool.InvokableTool)
+		if !ok {
+			return fmt.Sprintf("tool %s is not invokable", tc.Function.Name), &types.ToolError{
+				Tool:    tc.Function.Name,
+				Code:    types.ToolErrorInternal,
+				Message: "tool is not invokable",
+			}
 		}
-		log.Printf("[ToolExecutor] final response: %s", resp.Content)
+		// 按工具声明的参数结构对齐类型（模型常把数字、布尔值写成字符串），缺少必填参数时不调用
+		validated, err := mcp.ValidateParams(ctx, t, args)
+		if err != nil {
+			log.Printf("[ToolExecutor] validate args error: %v", err)
+			return fmt.Sprintf("参数校验失败: %v", err), &types.ToolError{
+				Tool:    tc.Function.Name,
+				Code:    types.ToolErrorInvalidArgument,
+				Message: err.Error(),
+			}
+		}
+		if dropped := droppedParams(args, validated); len(dropped) > 0 {
+			log.Printf("[ToolExecutor] tool %s: dropped undeclared args %v", tc.Function.Name, dropped)
+		}
+		args = validated
+		argsJSON, _ := json.Marshal(args)
+		log.Printf("[ToolExecutor] 调用工具 %s 参数: %s", tc.Function.Name, argsJSON)
+		progress.Report(ctx, progress.PhaseTool, "调用工具 "+tc.Function.Name)
+		start := time.Now()
+		output, err := invokable.InvokableRun(ctx, string(argsJSON))
+		elapsed := time.Since(start)
+		te.metrics.Observe(tc.Function.Name, elapsed, err)
+		if elapsed > mcp.DefaultSlowToolThreshold {
+			log.Printf("[ToolExecutor] slow tool call %s: %s", tc.Function.Name, elapsed)
+		}
+		log.Printf("[ToolExecutor] 工具调用返回 %+v", output)
+		if err != nil {
+			toolErr := types.ToolErrorFromErr(tc.Function.Name, err)
+			return fmt.Sprintf("tool execution failed (%s): %s", toolErr.Code, toolErr.Message), toolErr
+		}
+
+		result := extractMCPToolResult(fmt.Sprintf("%v", output))
+		log.Printf("工具格式转换后返回: %s", result)
+		return result, nil
 	}
 
-	return resp, toolsUsed, nil
+	log.Printf("[ToolExecutor] refused tool call %s: tool not available", tc.Function.Name)
+	return fmt.Sprintf("工具 %s 不可用，请仅使用已提供的工具", tc.Function.Name), &types.ToolError{
+		Tool:    tc.Function.Name,
+		Code:    types.ToolErrorInvalidArgument,
+		Message: "tool not available",
+	}
+}
+
+// droppedParams 校验后被丢弃的参数名（未在工具参数结构中声明），按名称排序
+func droppedParams(before, after map[string]interface{}) []string {
+	var dropped []string
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			dropped = append(dropped, name)
+		}
+	}
+	sort.Strings(dropped)
+	return dropped
 }
 
 type BaseAgent struct {
@@ -132,7 +301,6 @@ type BaseAgent struct {
 	llm          model.ChatModel
 	toolExecutor *ToolExecutor
 	systemPrompt string
-	maxToolRound int
 }
 
 func NewBaseAgent(name types.AgentType, llm model.ChatModel, te *ToolExecutor, systemPrompt string) *BaseAgent {
@@ -141,7 +309,13 @@ func NewBaseAgent(name types.AgentType, llm model.ChatModel, te *ToolExecutor, s
 		llm:          llm,
 		toolExecutor: te,
 		systemPrompt: systemPrompt,
-		maxToolRound: 5,
+	}
+}
+
+// SetSystemPrompt 替换 Agent 的系统提示词，为空时保持不变
+func (b *BaseAgent) SetSystemPrompt(systemPrompt string) {
+	if systemPrompt != "" {
+		b.systemPrompt = systemPrompt
 	}
 }
 
@@ -155,15 +329,26 @@ func (b *BaseAgent) ExecuteWithToolLoop(ctx context.Context, state *state.GraphS
 	messages := state.BuildMessagesForAgent(b.systemPrompt, b.name)
 
 	var resp *schema.Message
-	var toolsUsed []string
+	run := &ToolRun{}
 	var err error
 
 	if b.toolExecutor != nil {
-		resp, toolsUsed, err = b.toolExecutor.ExecuteWithTools(ctx, messages)
+		resp, run, err = b.toolExecutor.ExecuteWithTools(ctx, messages)
 	} else {
 		resp, err = b.llm.Generate(ctx, messages)
 	}
 
+	if errors.Is(err, ErrStepLimitReached) {
+		// 步数耗尽时保留已使用的工具，明确告知分析未完成而不是返回残缺结果
+		return &types.AgentResult{
+			AgentType:   b.name,
+			Content:     fmt.Sprintf("分析未完成：已达到最大工具调用轮数（%d），请提高步数上限后重试", b.toolExecutor.maxToolRounds),
+			ToolsUsed:   run.ToolsUsed,
+			Error:       err.Error(),
+			ToolErrors:  run.Errors,
+			ToolOutputs: run.Outputs,
+		}, err
+	}
 	if err != nil {
 		return &types.AgentResult{
 			AgentType: b.name,
@@ -181,86 +366,33 @@ func (b *BaseAgent) ExecuteWithToolLoop(ctx context.Context, state *state.GraphS
 	}
 
 	return &types.AgentResult{
-		AgentType: b.name,
-		Content:   decodedContent,
-		ToolsUsed: toolsUsed,
-		ToolCalls: toolCalls,
+		AgentType:   b.name,
+		Content:     decodedContent,
+		ToolsUsed:   run.ToolsUsed,
+		ToolCalls:   toolCalls,
+		ToolErrors:  run.Errors,
+		ToolOutputs: run.Outputs,
 	}, nil
 }
 
+// decodeToolResult 模型回复为 MCP 工具结果（或其 JSON 字符串形式）时解码为格式化的 JSON，
+// 载荷不是 JSON 对象时返回其中的文本，见 mcp.ToolResultText
 func decodeToolResult(result string) string {
-	log.Printf("mcp解码[decodeToolResult] input length: %d", len(result))
-
 	result = strings.TrimSpace(result)
-
-	var outer string
-	if err := json.Unmarshal([]byte(result), &outer); err == nil {
-		log.Printf("[decodeToolResult] step0: result is a JSON string, unwrapping")
-		result = outer
-	}
-
-	var wrapper map[string]interface{}
-	if err := json.Unmarshal([]byte(result), &wrapper); err != nil {
-		log.Printf("[decodeToolResult] step1: failed to unmarshal wrapper: %v", err)
-		return result
-	}
-
-	if content, ok := wrapper["content"].([]interface{}); ok && len(content) > 0 {
-		if item, ok := content[0].(map[string]interface{}); ok {
-			if text, ok := item["text"].(string); ok {
-				log.Printf("[decodeToolResult] step1: found text field, trying to decode inner JSON")
-
-				cleaned := strings.Trim(text, "\"")
-
-				var inner map[string]interface{}
-				if err := json.Unmarshal([]byte(cleaned), &inner); err != nil {
-					log.Printf("[decodeToolResult] step2: failed to parse inner JSON: %v", err)
-					return cleaned
-				}
-
-				formatted, _ := json.MarshalIndent(inner, "", "  ")
-				log.Printf("[decodeToolResult] step2: decoded successfully, length: %d", len(formatted))
-				return string(formatted)
-			}
-		}
+	payload, err := mcp.DecodeToolResult(result)
+	if err != nil {
+		return mcp.ToolResultText(result)
 	}
-
-	formatted, _ := json.MarshalIndent(wrapper, "", "  ")
+	formatted, _ := json.MarshalIndent(payload, "", "  ")
+	log.Printf("[decodeToolResult] decoded tool result, length: %d", len(formatted))
 	return string(formatted)
 }
 
+// extractMCPToolResult 解码工具输出供模型阅读，纯文本、JSON 数组等非对象结果返回其中的文本，见 mcp.ToolResultText
 func extractMCPToolResult(raw string) string {
-	var wrapper map[string]interface{}
-	if err := json.Unmarshal([]byte(raw), &wrapper); err != nil {
-		log.Printf("[extractMCPToolResult] not JSON, returning raw")
-		return raw
-	}
-
-	if structured, ok := wrapper["structuredContent"].(string); ok && structured != "" {
-		decoded, err := base64.StdEncoding.DecodeString(structured)
-		if err == nil {
-			log.Printf("[extractMCPToolResult] decoded from structuredContent, length: %d", len(decoded))
-			return string(decoded)
-		}
-		log.Printf("[extractMCPToolResult] base64 decode failed: %v", err)
-	}
-
-	if content, ok := wrapper["content"].([]interface{}); ok && len(content) > 0 {
-		if item, ok := content[0].(map[string]interface{}); ok {
-			if text, ok := item["text"].(string); ok {
-				cleaned := strings.Trim(text, "\"")
-				decoded, err := base64.StdEncoding.DecodeString(cleaned)
-				if err == nil {
-					log.Printf("[extractMCPToolResult] decoded from content.text, length: %d", len(decoded))
-					return string(decoded)
-				}
-				log.Printf("[extractMCPToolResult] text is not base64, returning as-is")
-				return text
-			}
-		}
-	}
-
-	return raw
+	result := mcp.ToolResultText(raw)
+	log.Printf("[extractMCPToolResult] decoded tool result, length: %d", len(result))
+	return result
 }
 
 func (b *BaseAgent) DefaultRoute(ctx context.Context, state *state.GraphState, result *types.AgentResult) (types.AgentType, error) {
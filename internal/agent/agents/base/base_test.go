@@ -0,0 +1,357 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"video_agent/internal/agent/state"
+	"video_agent/internal/agent/types"
+	"video_agent/internal/mcp"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// scriptedModel 按顺序返回预设响应，并记录每次收到的消息
+type scriptedModel struct {
+	responses []*schema.Message
+	calls     [][]*schema.Message
+}
+
+func (m *scriptedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls = append(m.calls, input)
+	resp := m.responses[0]
+	if len(m.responses) > 1 {
+		m.responses = m.responses[1:]
+	}
+	return resp, nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	resp, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{resp}), nil
+}
+
+func (m *scriptedModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// echoTool 返回固定结果并记录调用参数
+type echoTool struct {
+	name   string
+	result string
+	args   []string
+}
+
+func (t *echoTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name}, nil
+}
+
+func (t *echoTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	t.args = append(t.args, args)
+	return t.result, nil
+}
+
+func toolCall(id, name, args string) schema.ToolCall {
+	return schema.ToolCall{ID: id, Function: schema.FunctionCall{Name: name, Arguments: args}}
+}
+
+func TestExecuteWithToolsUnknownTools(t *testing.T) {
+	stats := &echoTool{name: "get_video_stats", result: `{"views":10}`}
+
+	tests := []struct {
+		name          string
+		calls         []schema.ToolCall
+		wantToolsUsed int
+		wantErrors    int
+		wantRefused   string
+	}{
+		{
+			name:        "all calls hallucinated",
+			calls:       []schema.ToolCall{toolCall("1", "get_weather", `{}`)},
+			wantErrors:  1,
+			wantRefused: "get_weather",
+		},
+		{
+			name:          "hallucinated call next to a real one",
+			calls:         []schema.ToolCall{toolCall("1", "get_weather", `{}`), toolCall("2", "get_video_stats", `{"video_id":"BV1"}`)},
+			wantToolsUsed: 1,
+			wantErrors:    1,
+			wantRefused:   "get_weather",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &scriptedModel{responses: []*schema.Message{
+				schema.AssistantMessage("", tt.calls),
+				schema.AssistantMessage("无法查询天气，这里是视频数据", nil),
+			}}
+			te := NewToolExecutor([]tool.BaseTool{stats}, llm)
+
+			resp, run, err := te.ExecuteWithTools(context.Background(), []*schema.Message{schema.UserMessage("q")})
+			if err != nil {
+				t.Fatalf("ExecuteWithTools: %v", err)
+			}
+			if resp.Content == "" {
+				t.Fatal("final reply is empty")
+			}
+			if len(run.ToolsUsed) != tt.wantToolsUsed {
+				t.Errorf("ToolsUsed = %v, want %d entries", run.ToolsUsed, tt.wantToolsUsed)
+			}
+			if len(run.Errors) != tt.wantErrors {
+				t.Errorf("Errors = %v, want %d entries", run.Errors, tt.wantErrors)
+			}
+			if len(run.Outputs) != tt.wantToolsUsed {
+				t.Errorf("Outputs = %v, want one per successful call", run.Outputs)
+			}
+			for _, out := range run.Outputs {
+				if out.Tool != stats.name || out.Output != stats.result {
+					t.Errorf("output = %+v, want %s result %s", out, stats.name, stats.result)
+				}
+			}
+			if len(llm.calls) != 2 {
+				t.Fatalf("model called %d times, want the refusal sent back for a second round", len(llm.calls))
+			}
+			var refused bool
+			for _, msg := range llm.calls[1] {
+				if msg.Role == schema.Tool && strings.Contains(msg.Content, tt.wantRefused) && strings.Contains(msg.Content, "不可用") {
+					refused = true
+				}
+			}
+			if !refused {
+				t.Errorf("second round has no refusal for %s", tt.wantRefused)
+			}
+		})
+	}
+}
+
+func TestSanitizeToolCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxCalls int
+		calls    []schema.ToolCall
+		want     []string
+	}{
+		{
+			name:     "duplicates dropped",
+			maxCalls: 5,
+			calls:    []schema.ToolCall{toolCall("1", "a", `{"x":1}`), toolCall("2", "a", `{"x":1}`), toolCall("3", "a", `{"x":2}`)},
+			want:     []string{"1", "3"},
+		},
+		{
+			name:     "limit applies to available tools only",
+			maxCalls: 1,
+			calls:    []schema.ToolCall{toolCall("1", "ghost", `{}`), toolCall("2", "a", `{}`), toolCall("3", "b", `{}`)},
+			want:     []string{"1", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := NewToolExecutor([]tool.BaseTool{&echoTool{name: "a"}, &echoTool{name: "b"}}, &scriptedModel{})
+			te.SetMaxToolCalls(tt.maxCalls)
+			resp := schema.AssistantMessage("", tt.calls)
+
+			te.sanitizeToolCalls(context.Background(), resp)
+
+			var got []string
+			for _, tc := range resp.ToolCalls {
+				got = append(got, tc.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("kept calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteWithToolsCapsToolCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxCalls int
+		want     int
+	}{
+		{name: "default cap", want: defaultMaxToolCalls},
+		{name: "configured cap", maxCalls: 3, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tools []tool.BaseTool
+			var echoes []*echoTool
+			var calls []schema.ToolCall
+			for i := 0; i < 10; i++ {
+				echo := &echoTool{name: fmt.Sprintf("tool_%d", i), result: "{}"}
+				echoes = append(echoes, echo)
+				tools = append(tools, echo)
+				calls = append(calls, toolCall(fmt.Sprint(i), echo.name, `{}`))
+			}
+			llm := &scriptedModel{responses: []*schema.Message{
+				schema.AssistantMessage("", calls),
+				schema.AssistantMessage("分析完成", nil),
+			}}
+			te := NewToolExecutor(tools, llm)
+			te.SetMaxToolCalls(tt.maxCalls)
+
+			_, run, err := te.ExecuteWithTools(context.Background(), []*schema.Message{schema.UserMessage("q")})
+			if err != nil {
+				t.Fatalf("ExecuteWithTools: %v", err)
+			}
+			if len(run.ToolsUsed) != tt.want {
+				t.Errorf("ToolsUsed = %v, want %d", run.ToolsUsed, tt.want)
+			}
+			for i, echo := range echoes {
+				if executed := len(echo.args) > 0; executed != (i < tt.want) {
+					t.Errorf("%s executed = %v, want only the first %d calls to run", echo.name, executed, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// paramTool 声明参数结构的 echoTool
+type paramTool struct {
+	echoTool
+}
+
+func (t *paramTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: t.name,
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"video_id": {Type: schema.String, Required: true},
+			"limit":    {Type: schema.Integer},
+		}),
+	}, nil
+}
+
+func TestRunToolCallValidatesArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantArgs string
+		wantErr  bool
+	}{
+		{name: "declared args pass through", args: `{"video_id":"BV1","limit":3}`, wantArgs: `{"limit":3,"video_id":"BV1"}`},
+		{name: "string number coerced", args: `{"video_id":"BV1","limit":"3"}`, wantArgs: `{"limit":3,"video_id":"BV1"}`},
+		{name: "undeclared args dropped", args: `{"video_id":"BV1","debug":true}`, wantArgs: `{"video_id":"BV1"}`},
+		{name: "missing required arg", args: `{"limit":3}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &paramTool{echoTool{name: "get_video_stats", result: "ok"}}
+			te := NewToolExecutor([]tool.BaseTool{stats}, &scriptedModel{})
+
+			result, toolErr := te.runToolCall(context.Background(), toolCall("1", "get_video_stats", tt.args))
+			if (toolErr != nil) != tt.wantErr {
+				t.Fatalf("toolErr = %+v, wantErr %v", toolErr, tt.wantErr)
+			}
+			if tt.wantErr {
+				// 校验错误写回模型，指出缺失的参数以便模型重新调用
+				if toolErr.Code != types.ToolErrorInvalidArgument || !strings.Contains(result, "video_id") {
+					t.Errorf("result = %q with code %s, want an invalid-argument error naming video_id", result, toolErr.Code)
+				}
+				if len(stats.args) != 0 {
+					t.Errorf("tool invoked with invalid args: %v", stats.args)
+				}
+				return
+			}
+			if len(stats.args) != 1 || stats.args[0] != tt.wantArgs {
+				t.Errorf("tool args = %v, want %s", stats.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDroppedParams(t *testing.T) {
+	before := map[string]interface{}{"video_id": "BV1", "debug": true, "aaa": 1}
+	after := map[string]interface{}{"video_id": "BV1"}
+	if got := strings.Join(droppedParams(before, after), ","); got != "aaa,debug" {
+		t.Errorf("droppedParams = %s, want aaa,debug", got)
+	}
+}
+
+func TestRunToolCallRecordsMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       schema.ToolCall
+		wantSeries int
+	}{
+		{name: "executed call is recorded", call: toolCall("1", "get_video_stats", `{}`), wantSeries: 1},
+		{name: "refused call is not recorded", call: toolCall("1", "get_weather", `{}`), wantSeries: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			te := NewToolExecutor([]tool.BaseTool{&echoTool{name: "get_video_stats", result: "ok"}}, &scriptedModel{})
+			te.SetMetrics(mcp.NewToolMetrics(registry))
+
+			te.runToolCall(context.Background(), tt.call)
+
+			for _, name := range []string{"mcp_tool_calls_total", "mcp_tool_duration_seconds"} {
+				got, err := testutil.GatherAndCount(registry, name)
+				if err != nil {
+					t.Fatalf("GatherAndCount(%s): %v", name, err)
+				}
+				if got != tt.wantSeries {
+					t.Errorf("%s series = %d, want %d", name, got, tt.wantSeries)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteWithToolLoopStepLimit(t *testing.T) {
+	loop := schema.AssistantMessage("", []schema.ToolCall{toolCall("1", "get_video_stats", `{"video_id":"BV1"}`)})
+
+	tests := []struct {
+		name string
+		// responses 依次返回，最后一个重复返回
+		responses  []*schema.Message
+		rounds     int
+		wantRounds int
+		wantLimit  bool
+	}{
+		{name: "looping model hits the configured limit", responses: []*schema.Message{loop}, rounds: 2, wantRounds: 2, wantLimit: true},
+		{name: "looping model hits the default limit", responses: []*schema.Message{loop}, wantRounds: defaultMaxToolRound, wantLimit: true},
+		{
+			name:       "answer within the limit",
+			responses:  []*schema.Message{loop, loop, schema.AssistantMessage("播放量稳定增长", nil)},
+			rounds:     3,
+			wantRounds: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &echoTool{name: "get_video_stats", result: `{"views":10}`}
+			llm := &scriptedModel{responses: tt.responses}
+			te := NewToolExecutor([]tool.BaseTool{stats}, llm)
+			te.SetMaxToolRounds(tt.rounds)
+			agent := NewBaseAgent(types.AgentTypeAnalysis, llm, te, "system")
+
+			result, err := agent.ExecuteWithToolLoop(context.Background(), state.NewGraphState("分析BV1", "s1", "u1"))
+			if errors.Is(err, ErrStepLimitReached) != tt.wantLimit {
+				t.Fatalf("err = %v, want step limit %v", err, tt.wantLimit)
+			}
+			if len(stats.args) != tt.wantRounds {
+				t.Errorf("tool executed %d times, want %d", len(stats.args), tt.wantRounds)
+			}
+			if !tt.wantLimit {
+				if err != nil || result.Content != "播放量稳定增长" {
+					t.Errorf("result = %+v, err = %v; want the final answer", result, err)
+				}
+				return
+			}
+			if !strings.Contains(result.Content, "分析未完成") || result.Error == "" {
+				t.Errorf("result = %+v, want an explicit incomplete-analysis message", result)
+			}
+			if len(result.ToolsUsed) != tt.wantRounds {
+				t.Errorf("ToolsUsed = %v, want the %d calls made before the limit", result.ToolsUsed, tt.wantRounds)
+			}
+		})
+	}
+}
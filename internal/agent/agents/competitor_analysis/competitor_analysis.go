@@ -0,0 +1,113 @@
+package competitor_analysis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	base "video_agent/internal/agent/agents/base"
+	prompt "video_agent/internal/agent/prompt"
+	"video_agent/internal/agent/state"
+	"video_agent/internal/agent/types"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+// minCompareTargets 对比分析至少需要的对象数量
+const minCompareTargets = 2
+
+// idPrefix 数字ID前必须出现的上下文（UID、账号、视频等），避免把年份、粉丝数当作ID
+const idPrefix = `(?:\buid|\bmid|\bav|\bid|账号|用户|创作者|up主|视频)\s*(?:是|为)?\s*[:：=]?\s*`
+
+var (
+	// bvPattern B站 BV 号，本身即可识别为视频ID
+	bvPattern = regexp.MustCompile(`BV[0-9A-Za-z]{10}`)
+	// idGroupPattern 带前缀的数字ID，以及用“和、与、vs”等连接词紧接其后的数字ID（如“账号10086和10010”）
+	idGroupPattern = regexp.MustCompile(`(?i)` + idPrefix + `\d{3,}(?:\s*(?:和|与|跟|、|,|，|/|vs\.?|and)\s*(?:` + idPrefix + `)?\d{3,})*`)
+	digitsPattern  = regexp.MustCompile(`\d{3,}`)
+	// unitSuffix 数字后紧跟这些单位时是数量或日期而不是ID
+	unitSuffix = regexp.MustCompile(`^\s*(?:粉丝|播放|点赞|评论|收藏|万|千|年|月|日|天|次|个|条|岁|%)`)
+)
+
+type CompetitorAnalysisAgentNode struct {
+	*base.BaseAgent
+	llm model.ChatModel
+	te  *base.ToolExecutor
+}
+
+func NewCompetitorAnalysisAgentNode(llm model.ChatModel, te *base.ToolExecutor) *CompetitorAnalysisAgentNode {
+	return &CompetitorAnalysisAgentNode{
+		BaseAgent: base.NewBaseAgent(types.AgentTypeCompetitorAnalysis, llm, te, prompt.CompetitorAnalysisAgentPrompt),
+		llm:       llm,
+		te:        te,
+	}
+}
+
+func (a *CompetitorAnalysisAgentNode) Execute(ctx context.Context, state *state.GraphState) (*types.AgentResult, error) {
+	log.Printf("[CompetitorAnalysisAgent] executing for query: %s", state.OriginalQuery)
+
+	ids := ExtractCompareIDs(state.OriginalQuery)
+	if len(ids) < minCompareTargets {
+		return &types.AgentResult{
+			AgentType: types.AgentTypeCompetitorAnalysis,
+			Content:   "请提供至少两个需要对比的创作者ID或视频ID，例如：对比一下账号10086和10010",
+		}, nil
+	}
+
+	// 将识别出的对比对象写入系统提示词，要求模型逐个获取数据后再横向对比
+	agent := base.NewBaseAgent(types.AgentTypeCompetitorAnalysis, a.llm, a.te, BuildComparePrompt(ids))
+	return agent.ExecuteWithToolLoop(ctx, state)
+}
+
+func (a *CompetitorAnalysisAgentNode) Route(ctx context.Context, state *state.GraphState, result *types.AgentResult) (types.AgentType, error) {
+	return a.DefaultRoute(ctx, state, result)
+}
+
+// ExtractCompareIDs 从查询中提取待对比的创作者/视频ID（保持出现顺序并去重）：BV 号直接识别，
+// 数字ID需带 UID/账号/视频 等前缀或以连接词接在这类ID之后，后接“粉丝”“年”等单位的数字不视为ID
+func ExtractCompareIDs(query string) []string {
+	type match struct {
+		start int
+		id    string
+	}
+	var matches []match
+	for _, loc := range bvPattern.FindAllStringIndex(query, -1) {
+		matches = append(matches, match{start: loc[0], id: query[loc[0]:loc[1]]})
+	}
+	for _, group := range idGroupPattern.FindAllStringIndex(query, -1) {
+		for _, loc := range digitsPattern.FindAllStringIndex(query[group[0]:group[1]], -1) {
+			start, end := group[0]+loc[0], group[0]+loc[1]
+			if unitSuffix.MatchString(query[end:]) {
+				continue
+			}
+			matches = append(matches, match{start: start, id: query[start:end]})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	seen := make(map[string]bool, len(matches))
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if seen[m.id] {
+			continue
+		}
+		seen[m.id] = true
+		ids = append(ids, m.id)
+	}
+	return ids
+}
+
+// BuildComparePrompt 构建包含全部对比对象的系统提示词
+func BuildComparePrompt(ids []string) string {
+	var sb strings.Builder
+	sb.WriteString(prompt.CompetitorAnalysisAgentPrompt)
+	sb.WriteString("\n\n## 待对比对象\n")
+	for i, id := range ids {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, id))
+	}
+	sb.WriteString("\n请对以上每个对象分别调用工具获取数据，获取完整后再进行对比。")
+	return sb.String()
+}
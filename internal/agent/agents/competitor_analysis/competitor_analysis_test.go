@@ -0,0 +1,100 @@
+package competitor_analysis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	base "video_agent/internal/agent/agents/base"
+	"video_agent/internal/agent/state"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestExtractCompareIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "two accounts joined by 和", query: "对比一下账号10086和10010", want: []string{"10086", "10010"}},
+		{name: "uid prefixes", query: "UID: 123456 vs uid 654321 谁涨粉更快", want: []string{"123456", "654321"}},
+		{name: "bv ids", query: "对比BV1xx411c7mD和BV1GJ411x7h7的完播率", want: []string{"BV1xx411c7mD", "BV1GJ411x7h7"}},
+		{name: "mixed bv and av ids keep order", query: "视频av170001和BV1xx411c7mD哪个更好", want: []string{"170001", "BV1xx411c7mD"}},
+		{name: "duplicates removed", query: "账号10086和账号10086对比", want: []string{"10086"}},
+		{name: "year is not an id", query: "对比账号10086和10010在2024年的数据", want: []string{"10086", "10010"}},
+		{name: "follower counts are not ids", query: "账号10086有1000粉丝，账号10010有2000粉丝", want: []string{"10086", "10010"}},
+		{name: "unit right after a prefixed number", query: "创作者 3000粉丝 和 5000粉丝 的账号怎么比", want: []string{}},
+		{name: "bare numbers need a prefix", query: "对比10086和10010", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractCompareIDs(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCompareIDs(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// promptModel 记录系统提示词并直接给出最终回答
+type promptModel struct {
+	system string
+	calls  int
+}
+
+func (m *promptModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	if len(input) > 0 && input[0].Role == schema.System {
+		m.system = input[0].Content
+	}
+	return schema.AssistantMessage("对比结果", nil), nil
+}
+
+func (m *promptModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *promptModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestCompetitorAnalysisPrompt(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []string
+		wantCalls int
+	}{
+		{name: "two creators are both in the prompt", query: "对比一下账号10086和10010的粉丝增长", wantIDs: []string{"1. 10086", "2. 10010"}, wantCalls: 1},
+		{name: "one creator asks for another id", query: "分析账号10086在2024年的表现"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &promptModel{}
+			node := NewCompetitorAnalysisAgentNode(llm, base.NewToolExecutor(nil, llm))
+
+			result, err := node.Execute(context.Background(), state.NewGraphState(tt.query, "s1", "u1"))
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if llm.calls != tt.wantCalls {
+				t.Fatalf("model called %d times, want %d", llm.calls, tt.wantCalls)
+			}
+			if tt.wantCalls == 0 {
+				if !strings.Contains(result.Content, "至少两个") {
+					t.Errorf("result = %q, want a request for more ids", result.Content)
+				}
+				return
+			}
+			for _, id := range tt.wantIDs {
+				if !strings.Contains(llm.system, id) {
+					t.Errorf("comparison prompt missing %q:\n%s", id, llm.system)
+				}
+			}
+		})
+	}
+}
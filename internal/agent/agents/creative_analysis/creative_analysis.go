@@ -18,12 +18,16 @@ import (
 // CreativeAnalysisAgentNode 创作分析Agent节点
 type CreativeAnalysisAgentNode struct {
 	*base.BaseAgent
+	llm model.ChatModel
+	te  *base.ToolExecutor
 }
 
 // NewCreativeAnalysisAgentNode 创建创作分析Agent节点
 func NewCreativeAnalysisAgentNode(llm model.ChatModel, te *base.ToolExecutor) *CreativeAnalysisAgentNode {
 	return &CreativeAnalysisAgentNode{
 		BaseAgent: base.NewBaseAgent(types.AgentTypeCreativeAnalysis, llm, te, prompt.CreativeAnalysisAgentPrompt),
+		llm:       llm,
+		te:        te,
 	}
 }
 
@@ -40,7 +44,9 @@ func (a *CreativeAnalysisAgentNode) Execute(ctx context.Context, state *state.Gr
 		log.Printf("[CreativeAnalysisAgent] using analysis data, length: %d", len(analysisResult.Content))
 	}
 
-	result, err := a.ExecuteWithToolLoop(ctx, state)
+	// 将识别出的领域作为 get_trending_topics 的 category 参数提示给模型
+	agent := base.NewBaseAgent(types.AgentTypeCreativeAnalysis, a.llm, a.te, buildTrendPrompt(field))
+	result, err := agent.ExecuteWithToolLoop(ctx, state)
 	if err != nil {
 		return result, err
 	}
@@ -88,6 +94,14 @@ func (a *CreativeAnalysisAgentNode) extractField(query string) string {
 	return "综合"
 }
 
+// buildTrendPrompt 在系统提示词中加入目标领域，"综合" 表示不限定分类
+func buildTrendPrompt(field string) string {
+	if field == "" || field == "综合" {
+		return prompt.CreativeAnalysisAgentPrompt + "\n\n## 目标领域\n未指定领域，调用 get_trending_topics 时不传 category，分析全站趋势。"
+	}
+	return prompt.CreativeAnalysisAgentPrompt + fmt.Sprintf("\n\n## 目标领域\n%s（调用 get_trending_topics 时 category 参数使用该值）", field)
+}
+
 // postProcess 后处理结果
 func (a *CreativeAnalysisAgentNode) postProcess(result *types.AgentResult, field string) *types.AgentResult {
 	if result == nil {
@@ -158,6 +172,9 @@ func ParseCreativeResult(content string) (*CreativeAnalysisResult, error) {
 	// 尝试从JSON解析
 	var result CreativeAnalysisResult
 	if err := json.Unmarshal([]byte(content), &result); err == nil {
+		for i := range result.HotTopics {
+			result.HotTopics[i].Tags = normalizeTags(result.HotTopics[i].Tags)
+		}
 		return &result, nil
 	}
 
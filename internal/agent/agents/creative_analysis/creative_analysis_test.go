@@ -0,0 +1,93 @@
+package creative_analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	base "video_agent/internal/agent/agents/base"
+	"video_agent/internal/agent/state"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+const trendsJSON = `{"topics":[{"name":"AI 绘画","momentum":0.8},{"name":"折叠屏评测","momentum":0.3}]}`
+
+// trendModel 首次调用请求 get_trending_topics，拿到工具结果后据此回答，并记录系统提示词
+type trendModel struct {
+	system string
+}
+
+func (m *trendModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if len(input) > 0 && input[0].Role == schema.System {
+		m.system = input[0].Content
+	}
+	last := input[len(input)-1]
+	if last.Role == schema.Tool {
+		return schema.AssistantMessage("当前上升最快的话题：\n"+last.Content, nil), nil
+	}
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call-1",
+		Function: schema.FunctionCall{Name: "get_trending_topics", Arguments: `{"limit":5}`},
+	}}), nil
+}
+
+func (m *trendModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *trendModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// trendsTool 返回固定的趋势话题
+type trendsTool struct {
+	calls int
+}
+
+func (t *trendsTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "get_trending_topics"}, nil
+}
+
+func (t *trendsTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	t.calls++
+	return trendsJSON, nil
+}
+
+func TestCreativeAnalysisUsesTrends(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantPrompt string
+	}{
+		{name: "detected field becomes the category", query: "科技区最近有什么选题", wantPrompt: "科技（调用 get_trending_topics 时 category 参数使用该值）"},
+		{name: "no field analyzes site-wide trends", query: "最近有什么选题", wantPrompt: "调用 get_trending_topics 时不传 category"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &trendModel{}
+			trends := &trendsTool{}
+			node := NewCreativeAnalysisAgentNode(llm, base.NewToolExecutor([]tool.BaseTool{trends}, llm))
+
+			result, err := node.Execute(context.Background(), state.NewGraphState(tt.query, "s1", "u1"))
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if !strings.Contains(llm.system, tt.wantPrompt) {
+				t.Errorf("system prompt missing %q:\n%s", tt.wantPrompt, llm.system)
+			}
+			if trends.calls != 1 {
+				t.Errorf("get_trending_topics called %d times, want 1", trends.calls)
+			}
+			for _, topic := range []string{"AI 绘画", "折叠屏评测"} {
+				if !strings.Contains(result.Content, topic) {
+					t.Errorf("analysis missing topic %q:\n%s", topic, result.Content)
+				}
+			}
+		})
+	}
+}
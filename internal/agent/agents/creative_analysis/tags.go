@@ -0,0 +1,29 @@
+package creative_analysis
+
+import "strings"
+
+// maxTopicTags 每个选题保留的最大标签数
+const maxTopicTags = 10
+
+// normalizeTags 按顺序合并多组标签：去除首尾空白、忽略大小写去重（保留首次出现的写法），
+// 再截断到 maxTopicTags 个；靠前的组优先，如原始标签优先于模型推荐的标签。
+// 没有标签时返回空切片而非 nil，序列化为 [] 与模型原始输出一致
+func normalizeTags(groups ...[]string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, group := range groups {
+		for _, tag := range group {
+			tag = strings.TrimSpace(tag)
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			tags = append(tags, tag)
+			if len(tags) == maxTopicTags {
+				return tags
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,57 @@
+package creative_analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	many := make([]string, 0, maxTopicTags+5)
+	for i := 0; i < maxTopicTags+5; i++ {
+		many = append(many, fmt.Sprintf("tag%d", i))
+	}
+
+	tests := []struct {
+		name   string
+		groups [][]string
+		want   []string
+	}{
+		{name: "no tags", want: []string{}},
+		{name: "trims and drops blanks", groups: [][]string{{" AI ", "", "  ", "绘画"}}, want: []string{"AI", "绘画"}},
+		{name: "case-insensitive duplicates keep the first casing", groups: [][]string{{"AI", "ai ", "Ai", "科技"}}, want: []string{"AI", "科技"}},
+		{
+			name:   "original tags win over suggested",
+			groups: [][]string{{"ChatGPT", "教程"}, {"chatgpt", "AI绘画", "教程 "}},
+			want:   []string{"ChatGPT", "教程", "AI绘画"},
+		},
+		{name: "dedupe before truncation", groups: [][]string{append([]string{"tag0", "TAG0", "Tag0"}, many[1:]...)}, want: many[:maxTopicTags]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTags(tt.groups...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeTags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCreativeResultNormalizesTags(t *testing.T) {
+	content := `{"field":"科技","hot_topics":[{"title":"AI 绘画入门","tags":["AI"," ai","AI绘画","教程","Ai绘画 "]},{"title":"折叠屏评测","tags":[]}]}`
+	result, err := ParseCreativeResult(content)
+	if err != nil {
+		t.Fatalf("ParseCreativeResult: %v", err)
+	}
+	if len(result.HotTopics) != 2 {
+		t.Fatalf("topics = %+v, want 2", result.HotTopics)
+	}
+	if want := []string{"AI", "AI绘画", "教程"}; !reflect.DeepEqual(result.HotTopics[0].Tags, want) {
+		t.Errorf("tags = %q, want %q", result.HotTopics[0].Tags, want)
+	}
+	data, _ := json.Marshal(result.HotTopics[1])
+	if !strings.Contains(string(data), `"tags":[]`) {
+		t.Errorf("topic without tags = %s, want tags serialized as []", data)
+	}
+}
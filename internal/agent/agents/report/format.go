@@ -0,0 +1,42 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	emphasisRe = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	// dividerRe 表格分隔行与分割线
+	dividerRe    = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	listMarkerRe = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+)
+
+// StripMarkdown 去除模型在纯文本格式下仍输出的 Markdown 语法：标题符号、加粗、代码块标记、
+// 表格分隔行，表格行转换为以“：”分隔的文字，列表符号替换为“·”
+func StripMarkdown(content string) string {
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || dividerRe.MatchString(line) {
+			continue
+		}
+
+		line = headingRe.ReplaceAllString(line, "")
+		line = emphasisRe.ReplaceAllString(line, "$1$2")
+		line = listMarkerRe.ReplaceAllString(line, "$1· ")
+		if strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|") {
+			var cells []string
+			for _, cell := range strings.Split(strings.Trim(trimmed, "|"), "|") {
+				if cell = strings.TrimSpace(cell); cell != "" {
+					cells = append(cells, cell)
+				}
+			}
+			line = strings.Join(cells, "：")
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
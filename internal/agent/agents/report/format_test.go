@@ -0,0 +1,23 @@
+package report
+
+import "testing"
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "headings and emphasis", content: "## 概览摘要\n播放量**稳步增长**", want: "概览摘要\n播放量稳步增长"},
+		{name: "table rows", content: "| 指标 | 数值 |\n|---|---|\n| 播放量 | 879 |", want: "指标：数值\n播放量：879"},
+		{name: "lists and code fences", content: "```\n- 保持更新\n* 优化封面\n```", want: "· 保持更新\n· 优化封面"},
+		{name: "plain text unchanged", content: "概览摘要：播放量879", want: "概览摘要：播放量879"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMarkdown(tt.content); got != tt.want {
+				t.Errorf("StripMarkdown = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"video_agent/internal/agent/stats"
+	"video_agent/internal/agent/types"
+)
+
+// ErrInvalidStructuredOutput 模型输出无法解析为合法的结构化分析结果
+var ErrInvalidStructuredOutput = errors.New("invalid structured analysis output")
+
+// 情感倾向取值
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// StructuredAnalysis 视频分析的结构化结果，字段与 prompt.StructuredAnalysisPrompt 中的 Schema 一致
+type StructuredAnalysis struct {
+	Summary     string             `json:"summary"`
+	Metrics     map[string]float64 `json:"metrics"`
+	Sentiment   string             `json:"sentiment"`
+	KeyPoints   []string           `json:"key_points"`
+	Suggestions []string           `json:"suggestions"`
+}
+
+var trailingCommaRe = regexp.MustCompile(`,\s*([}\]])`)
+
+// ParseStructuredAnalysis 解析并修复模型输出：去除代码块与前后说明文字、删除多余逗号，
+// 再按字段容错转换（数字字符串、"万"单位、单个字符串代替数组等），最后校验必填字段
+func ParseStructuredAnalysis(content string) (*StructuredAnalysis, error) {
+	raw := extractJSONObject(content)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: no JSON object found", ErrInvalidStructuredOutput)
+	}
+	raw = trailingCommaRe.ReplaceAllString(raw, "$1")
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredOutput, err)
+	}
+
+	result := &StructuredAnalysis{
+		Summary:     strings.TrimSpace(toString(fields["summary"])),
+		Metrics:     toMetrics(fields["metrics"]),
+		Sentiment:   normalizeSentiment(toString(fields["sentiment"])),
+		KeyPoints:   toStringSlice(fields["key_points"]),
+		Suggestions: toStringSlice(fields["suggestions"]),
+	}
+
+	if result.Summary == "" {
+		return nil, fmt.Errorf("%w: summary is required", ErrInvalidStructuredOutput)
+	}
+	if result.Sentiment == "" {
+		return nil, fmt.Errorf("%w: sentiment must be one of positive/neutral/negative", ErrInvalidStructuredOutput)
+	}
+	return result, nil
+}
+
+// extractJSONObject 截取第一个 '{' 到最后一个 '}' 之间的内容
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return content[start : end+1]
+}
+
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func toStringSlice(v interface{}) []string {
+	switch val := v.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			if s := strings.TrimSpace(toString(item)); s != "" {
+				items = append(items, s)
+			}
+		}
+		return items
+	case string:
+		if s := strings.TrimSpace(val); s != "" {
+			return []string{s}
+		}
+	}
+	return nil
+}
+
+// ApplyToolStats 用工具返回的视频数据覆盖 Metrics 中的 views/likes/comments，计数以工具数据为准，
+// 不采用模型转述的数字；取最后一个可解析的快照（历史数据取最新一期），没有可解析的视频数据时不修改并返回 false
+func ApplyToolStats(analysis *StructuredAnalysis, outputs []types.ToolOutput) bool {
+	var latest *stats.Point
+	for _, out := range outputs {
+		if points := stats.ParseHistory(out.Output); len(points) > 0 {
+			latest = &points[len(points)-1]
+		}
+	}
+	if latest == nil {
+		return false
+	}
+
+	if analysis.Metrics == nil {
+		analysis.Metrics = make(map[string]float64, 3)
+	}
+	analysis.Metrics["views"] = latest.ViewCount
+	analysis.Metrics["likes"] = latest.LikeCount
+	analysis.Metrics["comments"] = latest.CommentCount
+	return true
+}
+
+func toMetrics(v interface{}) map[string]float64 {
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	metrics := make(map[string]float64, len(fields))
+	for name, value := range fields {
+		switch val := value.(type) {
+		case float64:
+			metrics[name] = val
+		case string:
+			if num, ok := parseNumber(val); ok {
+				metrics[name] = num
+			}
+		}
+	}
+	return metrics
+}
+
+// parseNumber 解析 "12,000"、"1.2万"、"7.5%" 等常见写法
+func parseNumber(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "万"):
+		multiplier, s = 10000, strings.TrimSuffix(s, "万")
+	case strings.HasSuffix(s, "亿"):
+		multiplier, s = 100000000, strings.TrimSuffix(s, "亿")
+	case strings.HasSuffix(s, "%"):
+		multiplier, s = 0.01, strings.TrimSuffix(s, "%")
+	}
+
+	num, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return num * multiplier, true
+}
+
+func normalizeSentiment(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case SentimentPositive, "正面", "积极":
+		return SentimentPositive
+	case SentimentNeutral, "中性", "中立":
+		return SentimentNeutral
+	case SentimentNegative, "负面", "消极":
+		return SentimentNegative
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"video_agent/internal/agent/types"
+)
+
+func TestApplyToolStats(t *testing.T) {
+	videoInfo := `{"video_id":"BV1","title":"测试视频","view_count":12000,"like_count":800,"comment_count":120}`
+	mcpWrapped := `{"content":[{"type":"text","text":"{\"view_count\":500,\"like_count\":50,\"comment_count\":5}"}]}`
+	history := `{"data":{"points":[{"date":"2026-01-01","view_count":100,"like_count":10,"comment_count":1},{"date":"2026-01-08","view_count":300,"like_count":30,"comment_count":3}]}}`
+
+	tests := []struct {
+		name    string
+		metrics map[string]float64
+		outputs []types.ToolOutput
+		wantOK  bool
+		want    map[string]float64
+	}{
+		{
+			name:    "工具数据覆盖模型转述的数字",
+			metrics: map[string]float64{"views": 1.2, "likes": 8, "engagement_rate": 0.077},
+			outputs: []types.ToolOutput{{Tool: "get_video_info", Output: videoInfo}},
+			wantOK:  true,
+			want:    map[string]float64{"views": 12000, "likes": 800, "comments": 120, "engagement_rate": 0.077},
+		},
+		{
+			name:    "MCP 包装的结果",
+			outputs: []types.ToolOutput{{Tool: "get_video_info", Output: mcpWrapped}},
+			wantOK:  true,
+			want:    map[string]float64{"views": 500, "likes": 50, "comments": 5},
+		},
+		{
+			name:    "历史数据取最新一期",
+			outputs: []types.ToolOutput{{Tool: "get_video_stats_history", Output: history}},
+			wantOK:  true,
+			want:    map[string]float64{"views": 300, "likes": 30, "comments": 3},
+		},
+		{
+			name:    "没有视频数据时不修改",
+			metrics: map[string]float64{"views": 10},
+			outputs: []types.ToolOutput{{Tool: "get_trending_topics", Output: `{"topics":["a"]}`}},
+			wantOK:  false,
+			want:    map[string]float64{"views": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := &StructuredAnalysis{Metrics: tt.metrics}
+			if ok := ApplyToolStats(analysis, tt.outputs); ok != tt.wantOK {
+				t.Fatalf("ApplyToolStats() = %v, want %v", ok, tt.wantOK)
+			}
+			if len(analysis.Metrics) != len(tt.want) {
+				t.Fatalf("metrics = %v, want %v", analysis.Metrics, tt.want)
+			}
+			for k, v := range tt.want {
+				if analysis.Metrics[k] != v {
+					t.Errorf("metrics[%s] = %v, want %v", k, analysis.Metrics[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStructuredAnalysis(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *StructuredAnalysis
+		wantErr bool
+	}{
+		{
+			name:    "strict JSON",
+			content: `{"summary":"播放量稳步增长","metrics":{"views":1000},"sentiment":"positive","key_points":["完播率高"],"suggestions":["保持更新"]}`,
+			want:    &StructuredAnalysis{Summary: "播放量稳步增长", Metrics: map[string]float64{"views": 1000}, Sentiment: SentimentPositive, KeyPoints: []string{"完播率高"}, Suggestions: []string{"保持更新"}},
+		},
+		{
+			name:    "code fence, prose and trailing commas",
+			content: "分析结果如下：\n```json\n{\"summary\":\"互动率偏低\",\"metrics\":{\"views\":200,},\"sentiment\":\"neutral\",\"key_points\":[\"评论少\",],}\n```\n以上。",
+			want:    &StructuredAnalysis{Summary: "互动率偏低", Metrics: map[string]float64{"views": 200}, Sentiment: SentimentNeutral, KeyPoints: []string{"评论少"}},
+		},
+		{
+			name:    "loose field types are coerced",
+			content: `{"summary":" 涨粉明显 ","metrics":{"views":"1.2万","engagement_rate":"7.5%","likes":"1,200","note":"无"},"sentiment":"正面","key_points":"封面吸引人","suggestions":["", "多发动态"]}`,
+			want: &StructuredAnalysis{
+				Summary: "涨粉明显", Metrics: map[string]float64{"views": 12000, "engagement_rate": 0.075, "likes": 1200},
+				Sentiment: SentimentPositive, KeyPoints: []string{"封面吸引人"}, Suggestions: []string{"多发动态"},
+			},
+		},
+		{name: "no JSON object", content: "视频表现良好", wantErr: true},
+		{name: "broken JSON", content: `{"summary":"播放量", "sentiment":}`, wantErr: true},
+		{name: "missing summary", content: `{"sentiment":"positive"}`, wantErr: true},
+		{name: "unknown sentiment", content: `{"summary":"播放量稳步增长","sentiment":"great"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStructuredAnalysis(tt.content)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStructuredOutput) {
+					t.Fatalf("err = %v, want ErrInvalidStructuredOutput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStructuredAnalysis: %v", err)
+			}
+			if got.Summary != tt.want.Summary || got.Sentiment != tt.want.Sentiment {
+				t.Errorf("summary/sentiment = %q/%q, want %q/%q", got.Summary, got.Sentiment, tt.want.Summary, tt.want.Sentiment)
+			}
+			if !reflect.DeepEqual(got.Metrics, tt.want.Metrics) {
+				t.Errorf("metrics = %v, want %v", got.Metrics, tt.want.Metrics)
+			}
+			if !reflect.DeepEqual(got.KeyPoints, tt.want.KeyPoints) || !reflect.DeepEqual(got.Suggestions, tt.want.Suggestions) {
+				t.Errorf("key_points/suggestions = %v/%v, want %v/%v", got.KeyPoints, got.Suggestions, tt.want.KeyPoints, tt.want.Suggestions)
+			}
+		})
+	}
+}
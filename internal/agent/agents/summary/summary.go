@@ -8,17 +8,70 @@ import (
 
 	"video_agent/internal/agent/prompt"
 	states "video_agent/internal/agent/state"
+	"video_agent/internal/agent/types"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 )
 
+// DefaultFallbackMessage 各 Agent 均执行失败且没有可告知的工具错误时的降级回复
+const DefaultFallbackMessage = "抱歉，处理过程中出现了问题，请重试。"
+
+// DefaultFallbackMessages 按意图的默认降级回复，{video_id} 替换为查询中的视频 ID，
+// 查询中没有视频 ID 时使用 DefaultFallbackMessage
+var DefaultFallbackMessages = map[string]string{
+	"Report":         "抱歉，视频 {video_id} 的数据分析没有完成，请确认视频ID是否正确，稍后再试。",
+	"VideoRecommend": "抱歉，暂时没能为你找到推荐视频，可以换个感兴趣的领域或关键词再试试。",
+}
+
 type SummaryNode struct {
-	llm model.ChatModel
+	llm       model.ChatModel
+	persona   string
+	fallbacks map[string]string
 }
 
 func NewSummaryNode(llm model.ChatModel) *SummaryNode {
-	return &SummaryNode{llm: llm}
+	return &SummaryNode{llm: llm, persona: prompt.DefaultPersonaPrompt, fallbacks: DefaultFallbackMessages}
+}
+
+// SetPersona 设置通用对话的系统人设，为空时恢复默认人设
+func (s *SummaryNode) SetPersona(persona string) {
+	if strings.TrimSpace(persona) == "" {
+		persona = prompt.DefaultPersonaPrompt
+	}
+	s.persona = persona
+}
+
+// SetFallbackMessages 按意图覆盖默认降级回复（键为 Report、VideoRecommend 等意图类型），
+// 值为空时该意图使用 DefaultFallbackMessage；未覆盖的意图保留 DefaultFallbackMessages 中的回复
+func (s *SummaryNode) SetFallbackMessages(messages map[string]string) {
+	fallbacks := make(map[string]string, len(DefaultFallbackMessages)+len(messages))
+	for intent, msg := range DefaultFallbackMessages {
+		fallbacks[intent] = msg
+	}
+	for intent, msg := range messages {
+		if strings.TrimSpace(msg) == "" {
+			delete(fallbacks, intent)
+			continue
+		}
+		fallbacks[intent] = msg
+	}
+	s.fallbacks = fallbacks
+}
+
+// fallbackMessage 当前意图的降级回复
+func (s *SummaryNode) fallbackMessage(state *states.GraphState) string {
+	msg, ok := s.fallbacks[state.Intent]
+	if !ok {
+		return DefaultFallbackMessage
+	}
+	if strings.Contains(msg, "{video_id}") {
+		if state.VideoID == "" {
+			return DefaultFallbackMessage
+		}
+		msg = strings.ReplaceAll(msg, "{video_id}", state.VideoID)
+	}
+	return msg
 }
 
 func (s *SummaryNode) Execute(ctx context.Context, state *states.GraphState) (string, error) {
@@ -41,7 +94,7 @@ func (s *SummaryNode) Execute(ctx context.Context, state *states.GraphState) (st
 
 func (s *SummaryNode) directAnswer(ctx context.Context, state *states.GraphState) (string, error) {
 	messages := []*schema.Message{
-		schema.SystemMessage("你是一个专业的视频助手。请直接回答用户的问题。"),
+		schema.SystemMessage(s.persona),
 	}
 
 	if rag := state.GetRAGContext(); rag != "" {
@@ -49,6 +102,11 @@ func (s *SummaryNode) directAnswer(ctx context.Context, state *states.GraphState
 			schema.SystemMessage("参考知识：\n"+rag))
 	}
 
+	if instruction := state.LanguageInstruction(); instruction != "" {
+		messages = append(messages, schema.SystemMessage(instruction))
+	}
+
+	messages = append(messages, state.History()...)
 	messages = append(messages, schema.UserMessage(state.OriginalQuery))
 
 	resp, err := s.llm.Generate(ctx, messages)
@@ -76,6 +134,7 @@ func (s *SummaryNode) integrateResults(ctx context.Context, state *states.GraphS
 						sb.WriteString(fmt.Sprintf("\n(使用的工具: %s)", strings.Join(result.ToolsUsed, ", ")))
 					}
 				}
+				writeToolErrors(&sb, result.ToolErrors)
 				sb.WriteString("\n\n")
 			}
 		}
@@ -87,14 +146,18 @@ func (s *SummaryNode) integrateResults(ctx context.Context, state *states.GraphS
 			} else {
 				sb.WriteString(result.Content)
 			}
+			writeToolErrors(&sb, result.ToolErrors)
 			sb.WriteString("\n\n")
 		}
 	}
 
 	messages := []*schema.Message{
 		schema.SystemMessage(prompt.SummaryPrompt),
-		schema.UserMessage(sb.String()),
 	}
+	if instruction := state.LanguageInstruction(); instruction != "" {
+		messages = append(messages, schema.SystemMessage(instruction))
+	}
+	messages = append(messages, schema.UserMessage(sb.String()))
 
 	resp, err := s.llm.Generate(ctx, messages)
 	if err != nil {
@@ -108,10 +171,16 @@ func (s *SummaryNode) integrateResults(ctx context.Context, state *states.GraphS
 
 func (s *SummaryNode) fallbackIntegration(state *states.GraphState) string {
 	var sb strings.Builder
+	var toolErrors []types.ToolError
 
 	if state.Plan != nil {
 		for _, agentType := range state.Plan.ExecutionOrder {
-			if result, ok := state.AgentResults[agentType]; ok && result.Error == "" {
+			result, ok := state.AgentResults[agentType]
+			if !ok {
+				continue
+			}
+			toolErrors = append(toolErrors, result.ToolErrors...)
+			if result.Error == "" {
 				sb.WriteString(result.Content)
 				sb.WriteString("\n\n")
 			}
@@ -119,8 +188,19 @@ func (s *SummaryNode) fallbackIntegration(state *states.GraphState) string {
 	}
 
 	if sb.Len() == 0 {
-		return "抱歉，处理过程中出现了问题，请重试。"
+		// 有明确的工具错误时告知具体原因（如视频不存在、超时），而不是笼统的失败提示
+		if len(toolErrors) > 0 {
+			return "抱歉，" + toolErrors[0].UserMessage() + "。"
+		}
+		return s.fallbackMessage(state)
 	}
 
 	return sb.String()
 }
+
+// writeToolErrors 写入失败的工具调用及面向用户的原因，供总结模型据此向用户说明
+func writeToolErrors(sb *strings.Builder, toolErrors []types.ToolError) {
+	for _, toolErr := range toolErrors {
+		sb.WriteString(fmt.Sprintf("\n(工具 %s 调用失败[%s]: %s)", toolErr.Tool, toolErr.Code, toolErr.UserMessage()))
+	}
+}
@@ -0,0 +1,157 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"video_agent/internal/agent/prompt"
+	states "video_agent/internal/agent/state"
+	"video_agent/internal/agent/types"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// recordingModel 返回固定回复（err 非空时返回错误），并记录最后一次调用的输入
+type recordingModel struct {
+	reply string
+	err   error
+	input []*schema.Message
+}
+
+func (m *recordingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.input = input
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(m.reply, nil), nil
+}
+
+func (m *recordingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *recordingModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestSummaryPersona(t *testing.T) {
+	tests := []struct {
+		name    string
+		persona string
+		want    string
+	}{
+		{name: "custom persona", persona: "你是B站UP主的运营小助手，说话活泼，称呼用户为“UP主”。", want: "你是B站UP主的运营小助手，说话活泼，称呼用户为“UP主”。"},
+		{name: "blank persona keeps the default", persona: "  ", want: prompt.DefaultPersonaPrompt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &recordingModel{reply: "你好"}
+			node := NewSummaryNode(llm)
+			node.SetPersona(tt.persona)
+
+			state := states.NewGraphState("你好", "s1", "u1")
+			if _, err := node.Execute(context.Background(), state); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if len(llm.input) < 2 {
+				t.Fatalf("model input = %v, want a system and a user message", llm.input)
+			}
+			if first := llm.input[0]; first.Role != schema.System || first.Content != tt.want {
+				t.Errorf("first message = %s %q, want system %q", first.Role, first.Content, tt.want)
+			}
+			if last := llm.input[len(llm.input)-1]; last.Role != schema.User || last.Content != "你好" {
+				t.Errorf("last message = %s %q, want the user query without the persona", last.Role, last.Content)
+			}
+		})
+	}
+}
+
+func TestFallbackReportsToolError(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *types.AgentResult
+		want   string
+	}{
+		{
+			name: "tool error explains the failure",
+			result: &types.AgentResult{
+				AgentType:  types.AgentTypeVideo,
+				Error:      "tool failed",
+				ToolErrors: []types.ToolError{{Tool: "get_video_stats", Code: types.ToolErrorNotFound}},
+			},
+			want: "抱歉，未找到相关数据，请确认视频或用户ID是否正确。",
+		},
+		{
+			name:   "no tool error keeps the generic message",
+			result: &types.AgentResult{AgentType: types.AgentTypeVideo, Error: "LLM generate failed"},
+			want:   "抱歉，处理过程中出现了问题，请重试。",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := states.NewGraphState("BV1xx 的数据怎么样", "s1", "u1")
+			state.SetPlan(&states.SupervisorPlan{ExecutionOrder: []types.AgentType{types.AgentTypeVideo}})
+			state.SetAgentResult(types.AgentTypeVideo, tt.result)
+
+			got, err := NewSummaryNode(&recordingModel{err: errors.New("model unavailable")}).Execute(context.Background(), state)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("summary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackPerIntent(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		intent    string
+		videoID   string
+		want      string
+	}{
+		{name: "video analysis names the video", intent: "Report", videoID: "BV1abc", want: "抱歉，视频 BV1abc 的数据分析没有完成，请确认视频ID是否正确，稍后再试。"},
+		{name: "video analysis without a video id", intent: "Report", want: DefaultFallbackMessage},
+		{name: "recommendation guidance", intent: "VideoRecommend", want: DefaultFallbackMessages["VideoRecommend"]},
+		{name: "intent without a fallback", intent: "HotVideo", want: DefaultFallbackMessage},
+		{
+			name:      "override",
+			overrides: map[string]string{"Report": "视频 {video_id} 分析失败"},
+			intent:    "Report",
+			videoID:   "BV1abc",
+			want:      "视频 BV1abc 分析失败",
+		},
+		{
+			name:      "override keeps other defaults",
+			overrides: map[string]string{"Report": "视频 {video_id} 分析失败"},
+			intent:    "VideoRecommend",
+			want:      DefaultFallbackMessages["VideoRecommend"],
+		},
+		{name: "blank override restores the generic message", overrides: map[string]string{"VideoRecommend": " "}, intent: "VideoRecommend", want: DefaultFallbackMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := states.NewGraphState("帮我看看", "s1", "u1")
+			state.SetIntent(tt.intent, tt.videoID)
+			state.SetPlan(&states.SupervisorPlan{ExecutionOrder: []types.AgentType{types.AgentTypeVideo}})
+			state.SetAgentResult(types.AgentTypeVideo, &types.AgentResult{AgentType: types.AgentTypeVideo, Error: "LLM generate failed"})
+
+			node := NewSummaryNode(&recordingModel{err: errors.New("model unavailable")})
+			if tt.overrides != nil {
+				node.SetFallbackMessages(tt.overrides)
+			}
+			got, err := node.Execute(context.Background(), state)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("summary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if DefaultFallbackMessages["Report"] != "抱歉，视频 {video_id} 的数据分析没有完成，请确认视频ID是否正确，稍后再试。" {
+		t.Error("SetFallbackMessages modified DefaultFallbackMessages")
+	}
+}
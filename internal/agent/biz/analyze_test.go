@@ -0,0 +1,66 @@
+package agent_biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"video_agent/internal/memory"
+)
+
+func TestAnalyzeRequiresVideoID(t *testing.T) {
+	analyze := map[string]func(uc *VideoAssistantUsecase, videoID string) error{
+		"AnalyzeVideo": func(uc *VideoAssistantUsecase, videoID string) error {
+			_, err := uc.AnalyzeVideo(context.Background(), "s1", "u1", videoID, "分析播放数据")
+			return err
+		},
+		"AnalyzeStructured": func(uc *VideoAssistantUsecase, videoID string) error {
+			_, err := uc.AnalyzeStructured(context.Background(), "s1", "u1", videoID, "分析播放数据")
+			return err
+		},
+		"StreamAnalyzeVideo": func(uc *VideoAssistantUsecase, videoID string) error {
+			stream, err := uc.StreamAnalyzeVideo(context.Background(), "s1", "u1", videoID, "分析播放数据")
+			if err == nil {
+				drainStream(t, stream)
+			}
+			return err
+		},
+	}
+	tests := []struct {
+		name    string
+		videoID string
+		wantErr error
+	}{
+		{name: "empty", videoID: "", wantErr: ErrVideoIDRequired},
+		{name: "blank", videoID: "  ", wantErr: ErrVideoIDRequired},
+		{name: "padded id is trimmed", videoID: " BV1abc "},
+	}
+	for method, run := range analyze {
+		for _, tt := range tests {
+			t.Run(method+"/"+tt.name, func(t *testing.T) {
+				uc := newMemoryUsecase(t, structuredAnswer)
+				if err := run(uc, tt.videoID); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+
+				// 校验失败时不执行分析，也不写入分析记录
+				memories, err := uc.memory.Retrieve(context.Background(), "", "s1", 10)
+				if err != nil {
+					t.Fatalf("Retrieve: %v", err)
+				}
+				var videoIDs []interface{}
+				for _, mem := range memories {
+					if mem.Type == memory.MemoryTypeEpisodic {
+						videoIDs = append(videoIDs, mem.Metadata[EpisodeMetadataVideoID])
+					}
+				}
+				if tt.wantErr != nil && len(videoIDs) != 0 {
+					t.Errorf("episodes for %v stored after a rejected request", videoIDs)
+				}
+				if tt.wantErr == nil && (len(videoIDs) != 1 || videoIDs[0] != "BV1abc") {
+					t.Errorf("episode video ids = %v, want the trimmed BV1abc", videoIDs)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package agent_biz
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// videoFailModel 输入中出现 failVideo 时返回错误，其余调用返回固定回答
+type videoFailModel struct {
+	answer    string
+	failVideo string
+}
+
+func (m videoFailModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	for _, msg := range input {
+		if strings.Contains(msg.Content, m.failVideo) {
+			return nil, errors.New("gateway 503")
+		}
+	}
+	return schema.AssistantMessage(m.answer, nil), nil
+}
+
+func (m videoFailModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (videoFailModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestBatchAnalyze(t *testing.T) {
+	const answer = "播放量稳定增长"
+
+	tests := []struct {
+		name     string
+		videoIDs []string
+		// wantFailed 期望分析失败的视频
+		wantFailed map[string]bool
+	}{
+		{name: "all succeed", videoIDs: []string{"BV1a", "BV1b", "BV1c"}},
+		{name: "one failure does not abort the others", videoIDs: []string{"BV1a", "BV1bad", "BV1c"}, wantFailed: map[string]bool{"BV1bad": true}},
+		{name: "blank id fails alone", videoIDs: []string{"BV1a", " ", "BV1c"}, wantFailed: map[string]bool{" ": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, err := NewVideoAssistantUsecase(nil, videoFailModel{answer: answer, failVideo: "BV1bad"}, nil, nil)
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecase: %v", err)
+			}
+
+			results, err := uc.BatchAnalyze(context.Background(), "s1", "u1", tt.videoIDs, "分析数据表现")
+			if err != nil {
+				t.Fatalf("BatchAnalyze: %v", err)
+			}
+			if len(results) != len(tt.videoIDs) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.videoIDs))
+			}
+			for i, r := range results {
+				if r.VideoID != tt.videoIDs[i] {
+					t.Errorf("results[%d].VideoID = %q, want %q (order must match the request)", i, r.VideoID, tt.videoIDs[i])
+				}
+				if tt.wantFailed[r.VideoID] {
+					if r.Error == "" || r.Result != nil {
+						t.Errorf("%s: got result %+v, want an error", r.VideoID, r.Result)
+					}
+					continue
+				}
+				if r.Error != "" {
+					t.Errorf("%s: unexpected error %q", r.VideoID, r.Error)
+					continue
+				}
+				if r.Result == nil || r.Result.Content != answer {
+					t.Errorf("%s: result = %+v, want content %q", r.VideoID, r.Result, answer)
+				}
+			}
+		})
+	}
+}
@@ -4,24 +4,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"video_agent/internal/agent/agents/report"
 	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/progress"
 	"video_agent/internal/agent/types"
+	"video_agent/internal/logger"
+	"video_agent/internal/memory"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrGraphNotInitialized = errors.New("graph not initialized")
+	// ErrMessageTooLong 用户消息超过长度上限且未开启截断
+	ErrMessageTooLong = errors.New("message too long")
+	// ErrMemoryNotConfigured 未设置会话记忆
+	ErrMemoryNotConfigured = errors.New("memory manager not configured")
+	// ErrVideoIDRequired 视频分析请求缺少视频ID
+	ErrVideoIDRequired = errors.New("video id is required")
+)
+
+const (
+	// historyLimit 每轮对话最多带入的历史消息条数
+	historyLimit = 20
+	// historyMaxTokens 历史消息的 token 预算，超出时由 ContextBuilder 压缩旧消息
+	historyMaxTokens = 2000
+	// defaultMaxMessageRunes 单条用户消息默认允许的最大字符数
+	defaultMaxMessageRunes = 4000
 )
 
 type VideoAssistantUsecase struct {
-	repo         types.VideoAssistantRepo
-	llm          model.ChatModel
+	repo types.VideoAssistantRepo
+	llm  model.ChatModel
+	// mcpMu 保护 mcpServers，rebuildMu 串行化图的重建（MCP 重连、刷新工具）
+	mcpMu        sync.RWMutex
 	mcpServers   []types.MCPServer
+	rebuildMu    sync.Mutex
 	graph        *graph.VideoGraph
+	graphMu      sync.RWMutex
 	ragRetriever types.RAGDocsRetriever
+	memory       *memory.MemoryManager
+
+	maxMessageRunes  int
+	truncateMessages bool
+
+	// userMemoryTopK 每轮对话带入的用户跨会话长期记忆条数，<=0 不带入
+	userMemoryTopK int
+
+	// retryMu 保护 stopMCPRetry，stopMCPRetry 停止后台 MCP 重连
+	retryMu      sync.Mutex
+	stopMCPRetry context.CancelFunc
+
+	// graphOpts 构建（及 MCP 恢复后重建）图时使用的选项
+	graphOpts []graph.GraphOption
 }
 
 func NewVideoAssistantUsecase(
@@ -29,16 +72,29 @@ func NewVideoAssistantUsecase(
 	llm model.ChatModel,
 	ragRetriever types.RAGDocsRetriever,
 	mcpServers []types.MCPServer,
+) (*VideoAssistantUsecase, error) {
+	return NewVideoAssistantUsecaseWithGraphOptions(repo, llm, ragRetriever, mcpServers)
+}
+
+// NewVideoAssistantUsecaseWithGraphOptions 与 NewVideoAssistantUsecase 相同，额外指定图选项（节点模型、内容审核等）
+func NewVideoAssistantUsecaseWithGraphOptions(
+	repo types.VideoAssistantRepo,
+	llm model.ChatModel,
+	ragRetriever types.RAGDocsRetriever,
+	mcpServers []types.MCPServer,
+	graphOpts ...graph.GraphOption,
 ) (*VideoAssistantUsecase, error) {
 	if llm == nil {
 		return nil, errors.New("llm is required")
 	}
 
 	usecase := &VideoAssistantUsecase{
-		repo:         repo,
-		llm:          llm,
-		mcpServers:   mcpServers,
-		ragRetriever: ragRetriever,
+		repo:            repo,
+		llm:             llm,
+		mcpServers:      mcpServers,
+		ragRetriever:    ragRetriever,
+		maxMessageRunes: defaultMaxMessageRunes,
+		graphOpts:       graphOpts,
 	}
 
 	if err := usecase.initGraph(); err != nil {
@@ -49,25 +105,139 @@ func NewVideoAssistantUsecase(
 }
 
 func (uc *VideoAssistantUsecase) initGraph() error {
-	graph, err := graph.NewVideoGraph(uc.llm, uc.mcpServers)
+	uc.rebuildMu.Lock()
+	defer uc.rebuildMu.Unlock()
+	return uc.buildGraph(uc.MCPServers())
+}
+
+// buildGraph 按 MCP 服务重建图并关闭旧图的 MCP 连接（旧图上进行中的工具调用会失败），调用方需持有 rebuildMu
+func (uc *VideoAssistantUsecase) buildGraph(mcpServers []types.MCPServer) error {
+	graph, err := graph.NewVideoGraph(uc.llm, mcpServers, uc.graphOpts...)
 	if err != nil {
 		return fmt.Errorf("create video graph: %w", err)
 	}
+	uc.graphMu.Lock()
+	old := uc.graph
 	uc.graph = graph
-	log.Printf("[Usecase] graph initialized successfully")
+	uc.graphMu.Unlock()
+	if old != nil {
+		if err := old.Close(); err != nil {
+			log.Printf("[Usecase] close previous graph warning: %v", err)
+		}
+	}
+
+	if graph.MCPAvailable() {
+		log.Printf("[Usecase] graph initialized successfully")
+	} else {
+		log.Printf("[Usecase] graph initialized in degraded mode: MCP unavailable, tools disabled")
+	}
 	return nil
 }
 
+func (uc *VideoAssistantUsecase) currentGraph() *graph.VideoGraph {
+	uc.graphMu.RLock()
+	defer uc.graphMu.RUnlock()
+	return uc.graph
+}
+
+// StartMCPRetry 图处于降级模式（MCP 不可用）时在后台定期重建图，MCP 恢复后自动启用工具；
+// 重复调用时先停止上一次启动的重连
+func (uc *VideoAssistantUsecase) StartMCPRetry(interval time.Duration) {
+	g := uc.currentGraph()
+	if g != nil && g.MCPAvailable() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	uc.retryMu.Lock()
+	if uc.stopMCPRetry != nil {
+		uc.stopMCPRetry()
+	}
+	uc.stopMCPRetry = cancel
+	uc.retryMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			log.Printf("[Usecase] retrying MCP connection...")
+			if err := uc.initGraph(); err != nil {
+				log.Printf("[Usecase] rebuild graph warning: %v", err)
+				continue
+			}
+			if uc.currentGraph().MCPAvailable() {
+				log.Printf("[Usecase] MCP connection restored, leaving degraded mode")
+				return
+			}
+		}
+	}()
+}
+
+// SetMemoryManager 设置会话记忆，设置后每轮对话会带入历史消息并写回本轮对话
+func (uc *VideoAssistantUsecase) SetMemoryManager(mm *memory.MemoryManager) {
+	uc.memory = mm
+}
+
+// withScratchpad 为本次执行挂载会话的工作记忆，供工具与图节点通过 memory.ScratchpadFrom 共享中间状态，
+// 返回的 release 在执行结束时保存 Persist 过的键、丢弃其余；未配置记忆或外层已挂载时为空操作
+func (uc *VideoAssistantUsecase) withScratchpad(ctx context.Context, sessionID string) (context.Context, func()) {
+	if uc.memory == nil || memory.ScratchpadFrom(ctx) != nil {
+		return ctx, func() {}
+	}
+	ctx, pad := uc.memory.WithScratchpad(ctx, sessionID)
+	return ctx, pad.Release
+}
+
+// SetUserMemoryTopK 设置每轮对话带入的用户跨会话长期记忆条数（个性化），<=0 关闭
+func (uc *VideoAssistantUsecase) SetUserMemoryTopK(topK int) {
+	uc.userMemoryTopK = topK
+}
+
+// SetMessageLimit 设置单条用户消息的最大字符数（按 rune 计），<=0 表示不限制；
+// truncate 为 true 时超长消息截断后继续处理，否则返回 ErrMessageTooLong
+func (uc *VideoAssistantUsecase) SetMessageLimit(maxRunes int, truncate bool) {
+	uc.maxMessageRunes = maxRunes
+	uc.truncateMessages = truncate
+}
+
+// CheckMessage 按长度上限校验用户消息，返回实际用于处理的消息
+func (uc *VideoAssistantUsecase) CheckMessage(message string) (string, error) {
+	if uc.maxMessageRunes <= 0 || utf8.RuneCountInString(message) <= uc.maxMessageRunes {
+		return message, nil
+	}
+	if !uc.truncateMessages {
+		return "", fmt.Errorf("%w: limit is %d characters", ErrMessageTooLong, uc.maxMessageRunes)
+	}
+	return string([]rune(message)[:uc.maxMessageRunes]), nil
+}
+
+// Chat 执行一轮对话；ctx 中没有追踪 ID 时会生成一个，本轮的图节点日志均带上该 ID
 func (uc *VideoAssistantUsecase) Chat(ctx context.Context, sessionID, userID, message string) (string, error) {
-	if uc.graph == nil {
+	ctx, _ = logger.EnsureTraceID(ctx)
+
+	g := uc.currentGraph()
+	if g == nil {
 		return "", ErrGraphNotInitialized
 	}
 
-	messages := []*schema.Message{
-		schema.UserMessage(message),
+	message, err := uc.CheckMessage(message)
+	if err != nil {
+		return "", err
 	}
 
-	result, err := uc.graph.Run(ctx, messages)
+	ctx, release := uc.withScratchpad(ctx, sessionID)
+	defer release()
+
+	messages := uc.buildMessages(ctx, sessionID, userID, message)
+
+	result, err := g.Run(ctx, messages)
 	if err != nil {
 		return "", fmt.Errorf("graph chat: %w", err)
 	}
@@ -79,34 +249,623 @@ func (uc *VideoAssistantUsecase) Chat(ctx context.Context, sessionID, userID, me
 
 	if uc.repo != nil {
 		if saveErr := uc.repo.SaveConversation(ctx, sessionID, userID, message, content); saveErr != nil {
-			log.Printf("[Usecase] save conversation warning: %v", saveErr)
+			logger.WithTrace(ctx, nil).Warnf("[Usecase] save conversation failed: %v", saveErr)
 		}
 	}
+	uc.rememberTurn(ctx, sessionID, userID, message, content)
 
 	return content, nil
 }
 
+// ChatDetail 对话回复及执行详情
+type ChatDetail struct {
+	Reply string
+	// Intent 识别的意图类型，未识别时为空
+	Intent string
+	// Analysis 视频分析意图下的结构化结果，其他意图或结构化失败时为 nil
+	Analysis *report.StructuredAnalysis
+	// Sources 支撑回答的知识库文档，回答中的 [n] 引用对应第 n 篇；未检索知识库时为空
+	Sources []types.RAGDocument
+}
+
+// ChatWithDetail 与 Chat 相同，额外返回识别的意图；意图为视频分析（Report）时将报告转换为结构化结果，
+// 结构化失败不影响回复
+func (uc *VideoAssistantUsecase) ChatWithDetail(ctx context.Context, sessionID, userID, message string) (*ChatDetail, error) {
+	ctx, _ = logger.EnsureTraceID(ctx)
+	ctx, info := graph.WithRunInfo(ctx)
+
+	reply, err := uc.Chat(ctx, sessionID, userID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &ChatDetail{Reply: reply, Intent: info.Intent(), Sources: info.Sources()}
+	if detail.Intent != "Report" {
+		return detail, nil
+	}
+	result, ok := info.Result(types.AgentTypeReport)
+	if !ok || result.Content == "" {
+		return detail, nil
+	}
+
+	episode := Episode{Intent: detail.Intent, Query: message, ToolsUsed: result.ToolsUsed, Findings: result.Content}
+	defer func() { uc.rememberEpisode(ctx, sessionID, userID, episode) }()
+
+	g := uc.currentGraph()
+	if g == nil {
+		return detail, nil
+	}
+	// 计数取自工具数据；文本字段（总结、要点、建议）由模型从报告中整理，失败时仍返回工具计数
+	analysis, err := g.StructureReport(ctx, result.Content)
+	if err != nil {
+		logger.WithTrace(ctx, nil).Warnf("[Usecase] structure report failed: %v", err)
+		counts := &report.StructuredAnalysis{}
+		if report.ApplyToolStats(counts, result.ToolOutputs) {
+			detail.Analysis = counts
+		}
+		return detail, nil
+	}
+	report.ApplyToolStats(analysis, result.ToolOutputs)
+	detail.Analysis = analysis
+	episode.Findings = structuredFindings(analysis)
+	return detail, nil
+}
+
+// SessionHistory 会话历史与标题
+type SessionHistory struct {
+	Title    string
+	Messages []memory.Memory
+}
+
+// GetSessionHistory 获取会话最近 limit 条消息及会话标题；标题在写入对话时后台生成，读取时不调用模型
+func (uc *VideoAssistantUsecase) GetSessionHistory(ctx context.Context, sessionID string, limit int) (*SessionHistory, error) {
+	if uc.memory == nil {
+		return nil, ErrMemoryNotConfigured
+	}
+
+	messages, err := uc.memory.GetSessionHistory(ctx, sessionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get session history: %w", err)
+	}
+
+	return &SessionHistory{Title: uc.memory.SessionTitle(ctx, sessionID), Messages: messages}, nil
+}
+
+// ClearSession 清除会话的短期与工作记忆，用户跨会话的长期记忆保留
+func (uc *VideoAssistantUsecase) ClearSession(ctx context.Context, sessionID string) error {
+	if uc.memory == nil {
+		return ErrMemoryNotConfigured
+	}
+	if err := uc.memory.ClearSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("clear session: %w", err)
+	}
+	return nil
+}
+
+// buildMessages 组装本轮输入：用户跨会话长期记忆（已开启时）+ 按 token 预算裁剪后的会话历史 + 当前用户消息
+func (uc *VideoAssistantUsecase) buildMessages(ctx context.Context, sessionID, userID, message string) []*schema.Message {
+	if uc.memory == nil {
+		return []*schema.Message{schema.UserMessage(message)}
+	}
+
+	var messages []*schema.Message
+	if userMemory := uc.userMemoryContext(ctx, userID, message); userMemory != "" {
+		messages = append(messages, schema.SystemMessage(userMemory))
+	}
+
+	history, err := uc.memory.GetSessionHistory(ctx, sessionID, historyLimit)
+	if err != nil {
+		log.Printf("[Usecase] load session history warning: %v", err)
+	}
+
+	builder := memory.NewContextBuilder(historyMaxTokens)
+	for _, mem := range history {
+		role := string(schema.User)
+		if mem.Type == memory.MemoryTypeAssistant {
+			role = string(schema.Assistant)
+		}
+		builder.AddMessage(role, mem.Content)
+	}
+
+	for _, msg := range builder.Build() {
+		messages = append(messages, &schema.Message{
+			Role:    schema.RoleType(msg.Role),
+			Content: msg.Content,
+		})
+	}
+	return append(messages, schema.UserMessage(message))
+}
+
+// userMemoryContext 检索与本轮消息相关的用户长期记忆并格式化为系统消息，未开启或无结果时返回空
+func (uc *VideoAssistantUsecase) userMemoryContext(ctx context.Context, userID, message string) string {
+	if uc.userMemoryTopK <= 0 || userID == "" {
+		return ""
+	}
+
+	memories, err := uc.memory.RetrieveUserMemories(ctx, message, userID, uc.userMemoryTopK)
+	if err != nil {
+		logger.WithTrace(ctx, nil).Warnf("[Usecase] retrieve user memories failed: %v", err)
+		return ""
+	}
+	if len(memories) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("用户在以往会话中的相关信息（用于个性化回答，与当前问题无关时忽略）：")
+	for _, mem := range memories {
+		sb.WriteString("\n- " + mem.Content)
+	}
+	return sb.String()
+}
+
+// rememberTurn 将本轮用户消息与助手回复写入会话记忆，记录 user_id 以便跨会话检索；
+// 重要性由 MemoryManager 的打分器决定
+func (uc *VideoAssistantUsecase) rememberTurn(ctx context.Context, sessionID, userID, message, reply string) {
+	if uc.memory == nil {
+		return
+	}
+
+	var metadata map[string]interface{}
+	if userID != "" {
+		metadata = map[string]interface{}{memory.MetadataUserID: userID}
+	}
+
+	// 同一轮两条消息使用相同时间，先后顺序由写入顺序保证；人为错开时间会与紧接着的下一轮交错
+	now := time.Now()
+	turn := []memory.Memory{
+		{SessionID: sessionID, Type: memory.MemoryTypeUser, Content: message, Metadata: metadata, CreatedAt: now},
+		{SessionID: sessionID, Type: memory.MemoryTypeAssistant, Content: reply, Metadata: metadata, CreatedAt: now},
+	}
+	for _, mem := range turn {
+		if err := uc.memory.Store(ctx, mem); err != nil {
+			logger.WithTrace(ctx, nil).Warnf("[Usecase] store memory failed: %v", err)
+		}
+	}
+	uc.memory.RefreshSessionTitle(ctx, sessionID)
+}
+
+// VideoAnalysisResult 视频分析结果
+type VideoAnalysisResult struct {
+	Content        string
+	ToolsUsed      []string
+	ProcessingTime time.Duration
+}
+
+// AnalyzeVideo 直接分析指定视频，不经过意图识别
+func (uc *VideoAssistantUsecase) AnalyzeVideo(ctx context.Context, sessionID, userID, videoID, query string) (*VideoAnalysisResult, error) {
+	videoID = strings.TrimSpace(videoID)
+	if videoID == "" {
+		return nil, ErrVideoIDRequired
+	}
+
+	g := uc.currentGraph()
+	if g == nil {
+		return nil, ErrGraphNotInitialized
+	}
+
+	ctx, release := uc.withScratchpad(ctx, sessionID)
+	defer release()
+
+	start := time.Now()
+	result, err := g.AnalyzeVideo(ctx, sessionID, userID, videoID, query)
+	if err != nil {
+		uc.rememberEpisode(ctx, sessionID, userID, Episode{
+			Intent: "Report", VideoID: videoID, Query: query, Outcome: EpisodeOutcomeFailed, Error: err.Error(),
+		})
+		return nil, fmt.Errorf("analyze video: %w", err)
+	}
+	// 分析报告不经过总结节点，在返回前审核（BatchAnalyze、StreamAnalyzeVideo 同样经过这里）
+	result.Content = g.Moderate(ctx, result.Content)
+	uc.rememberEpisode(ctx, sessionID, userID, Episode{
+		Intent: "Report", VideoID: videoID, Query: query, ToolsUsed: result.ToolsUsed, Findings: result.Content,
+	})
+
+	if uc.repo != nil {
+		question := fmt.Sprintf("[video:%s] %s", videoID, query)
+		if saveErr := uc.repo.SaveConversation(ctx, sessionID, userID, question, result.Content); saveErr != nil {
+			log.Printf("[Usecase] save conversation warning: %v", saveErr)
+		}
+	}
+
+	return &VideoAnalysisResult{
+		Content:        result.Content,
+		ToolsUsed:      result.ToolsUsed,
+		ProcessingTime: time.Since(start),
+	}, nil
+}
+
+// StructuredAnalysisResult 结构化视频分析结果，Report 为生成结构化结果所依据的原始报告
+type StructuredAnalysisResult struct {
+	Analysis *report.StructuredAnalysis
+	Report   *VideoAnalysisResult
+}
+
+// Plan 预演对话：返回意图、执行 Agent、候选工具与视频 ID，不调用工具、不执行分析，也不写入会话记忆
+func (uc *VideoAssistantUsecase) Plan(ctx context.Context, message string) (*graph.ExecutionPlan, error) {
+	g := uc.currentGraph()
+	if g == nil {
+		return nil, ErrGraphNotInitialized
+	}
+
+	message, err := uc.CheckMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := g.Plan(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+	return plan, nil
+}
+
+// AnalyzeStructured 分析指定视频并返回结构化结果，模型输出修复后仍不合法时返回 report.ErrInvalidStructuredOutput
+func (uc *VideoAssistantUsecase) AnalyzeStructured(ctx context.Context, sessionID, userID, videoID, query string) (*StructuredAnalysisResult, error) {
+	videoID = strings.TrimSpace(videoID)
+	if videoID == "" {
+		return nil, ErrVideoIDRequired
+	}
+
+	g := uc.currentGraph()
+	if g == nil {
+		return nil, ErrGraphNotInitialized
+	}
+
+	ctx, release := uc.withScratchpad(ctx, sessionID)
+	defer release()
+
+	start := time.Now()
+	analysis, result, err := g.AnalyzeStructured(ctx, sessionID, userID, videoID, query)
+	if err != nil {
+		uc.rememberEpisode(ctx, sessionID, userID, Episode{
+			Intent: "Report", VideoID: videoID, Query: query, Outcome: EpisodeOutcomeFailed, Error: err.Error(),
+		})
+		return nil, fmt.Errorf("analyze structured: %w", err)
+	}
+	result.Content = g.Moderate(ctx, result.Content)
+	moderateStructured(ctx, g, analysis)
+	uc.rememberEpisode(ctx, sessionID, userID, Episode{
+		Intent: "Report", VideoID: videoID, Query: query, ToolsUsed: result.ToolsUsed, Findings: structuredFindings(analysis),
+	})
+
+	if uc.repo != nil {
+		question := fmt.Sprintf("[video:%s] %s", videoID, query)
+		if saveErr := uc.repo.SaveConversation(ctx, sessionID, userID, question, result.Content); saveErr != nil {
+			log.Printf("[Usecase] save conversation warning: %v", saveErr)
+		}
+	}
+
+	return &StructuredAnalysisResult{
+		Analysis: analysis,
+		Report: &VideoAnalysisResult{
+			Content:        result.Content,
+			ToolsUsed:      result.ToolsUsed,
+			ProcessingTime: time.Since(start),
+		},
+	}, nil
+}
+
+// moderateStructured 逐项审核结构化结果中的文本字段，命中的字段单独脱敏或替换，不影响其他字段与指标
+func moderateStructured(ctx context.Context, g *graph.VideoGraph, analysis *report.StructuredAnalysis) {
+	analysis.Summary = g.Moderate(ctx, analysis.Summary)
+	for i, point := range analysis.KeyPoints {
+		analysis.KeyPoints[i] = g.Moderate(ctx, point)
+	}
+	for i, suggestion := range analysis.Suggestions {
+		analysis.Suggestions[i] = g.Moderate(ctx, suggestion)
+	}
+}
+
+// StreamChunk 流式输出片段：Phase 为 progress.PhaseContent 时 Content 为回复内容，
+// 其余阶段（识别意图、调用工具、生成分析）为进度提示，不计入续传分片序号
+type StreamChunk struct {
+	Phase   string
+	Content string
+}
+
+// IsProgress 是否为进度事件
+func (c *StreamChunk) IsProgress() bool {
+	return c.Phase != progress.PhaseContent
+}
+
+// ChatStreamReader 流式对话结果读取器，读取完毕返回 io.EOF（调用方应使用 errors.Is 判断）
+type ChatStreamReader interface {
+	Recv() (*StreamChunk, error)
+	// StreamID 本次流式回复的ID，续传时与分片序号一起传给 ResumeStreamChat；不支持续传时为空
+	StreamID() string
+	// Done 后台生成（含写入会话记录）结束时关闭；调用方提前停止读取时，生成可能在 ctx 取消后仍在收尾
+	Done() <-chan struct{}
+}
+
+// batchAnalyzeConcurrency 批量分析时同时执行的视频数量上限
+const batchAnalyzeConcurrency = 3
+
+// BatchVideoResult 批量分析中单个视频的结果，Error 非空表示该视频分析失败
+type BatchVideoResult struct {
+	VideoID string
+	Result  *VideoAnalysisResult
+	Error   string
+}
+
+// BatchAnalyze 并发分析多个视频，单个视频失败不影响其他视频，结果顺序与 videoIDs 一致
+func (uc *VideoAssistantUsecase) BatchAnalyze(ctx context.Context, sessionID, userID string, videoIDs []string, query string) ([]*BatchVideoResult, error) {
+	if uc.currentGraph() == nil {
+		return nil, ErrGraphNotInitialized
+	}
+
+	results := make([]*BatchVideoResult, len(videoIDs))
+	sem := make(chan struct{}, batchAnalyzeConcurrency)
+	var wg sync.WaitGroup
+
+	for i, videoID := range videoIDs {
+		wg.Add(1)
+		go func(i int, videoID string) {
+			defer wg.Done()
+
+			item := &BatchVideoResult{VideoID: videoID}
+			results[i] = item
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				item.Error = ctx.Err().Error()
+				return
+			}
+
+			result, err := uc.AnalyzeVideo(ctx, sessionID, userID, videoID, query)
+			if err != nil {
+				log.Printf("[Usecase] batch analyze video %s failed: %v", videoID, err)
+				item.Error = err.Error()
+				return
+			}
+			item.Result = result
+		}(i, videoID)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// streamChunkRunes 流式回复每个分片的字符数，分片序号即 SSE 事件 ID
+const streamChunkRunes = 64
+
+// ErrStreamNotFound 续传时找不到会话最近一次流式回复
+var ErrStreamNotFound = errors.New("stream reply not found")
+
 type streamResult struct {
-	content string
-	done    bool
+	id     string
+	chunks []string
+	next   int
+}
+
+// newStreamResult 按 streamChunkRunes 切分回复，从第 offset 个分片之后开始读取
+func newStreamResult(id, content string, offset int) *streamResult {
+	runes := []rune(content)
+	var chunks []string
+	for start := 0; start < len(runes); start += streamChunkRunes {
+		end := start + streamChunkRunes
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return &streamResult{id: id, chunks: chunks, next: offset}
+}
+
+func (s *streamResult) StreamID() string {
+	return s.id
+}
+
+// closedDone 没有后台生成的读取器的 Done
+var closedDone = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (s *streamResult) Done() <-chan struct{} {
+	return closedDone
+}
+
+func (s *streamResult) Recv() (*StreamChunk, error) {
+	if s.next >= len(s.chunks) {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[s.next]
+	s.next++
+	return &StreamChunk{Phase: progress.PhaseContent, Content: chunk}, nil
+}
+
+// progressBuffer 未读取的进度事件缓冲数，读取方跟不上时丢弃多余的进度事件而不阻塞图执行
+const progressBuffer = 16
+
+// progressStream 先推送图执行中的进度事件，完成后推送回复分片
+type progressStream struct {
+	id     string
+	chunks chan *StreamChunk
+	// done 后台 goroutine 退出时关闭
+	done chan struct{}
+	// err 在关闭 chunks 前写入，读取方在 chunks 关闭后读取
+	err error
+}
+
+func (s *progressStream) StreamID() string {
+	return s.id
+}
+
+func (s *progressStream) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *progressStream) Recv() (*StreamChunk, error) {
+	chunk, ok := <-s.chunks
+	if !ok {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	return chunk, nil
+}
+
+// StreamChat 流式对话，图执行期间先推送进度事件，完成后按分片推送回复，
+// 生成失败时 Recv 返回错误；ctx 取消后停止推送。回复分片通过 Chat 写入会话记录；
+// 配置了记忆时同时按 StreamID 缓存完整回复，供断线重连后通过 ResumeStreamChat 续传
+func (uc *VideoAssistantUsecase) StreamChat(ctx context.Context, sessionID, userID, message string) (ChatStreamReader, error) {
+	streamID := uuid.New().String()
+	stream := streamWithProgress(ctx, func(ctx context.Context) (string, error) {
+		content, err := uc.Chat(ctx, sessionID, userID, message)
+		if err == nil && uc.memory != nil {
+			uc.memory.SaveStreamReply(sessionID, streamID, content)
+		}
+		return content, err
+	})
+	stream.id = streamID
+	return stream, nil
+}
+
+// VideoAnalysisStream 流式视频分析结果读取器，Recv 返回 io.EOF 后可通过 Result 获取完整结果
+type VideoAnalysisStream struct {
+	*progressStream
+	result *VideoAnalysisResult
+}
+
+// Result 完整的分析结果，仅在 Recv 返回 io.EOF 后有效
+func (s *VideoAnalysisStream) Result() *VideoAnalysisResult {
+	return s.result
+}
+
+// StreamAnalyzeVideo 流式分析指定视频：执行期间实时推送进度事件（调用的工具等），报告生成后按分片推送。
+// Report Agent 需要先完成工具调用并生成整份报告才能做格式转换，报告内容不是逐 token 推送的
+func (uc *VideoAssistantUsecase) StreamAnalyzeVideo(ctx context.Context, sessionID, userID, videoID, query string) (*VideoAnalysisStream, error) {
+	if strings.TrimSpace(videoID) == "" {
+		return nil, ErrVideoIDRequired
+	}
+	if uc.currentGraph() == nil {
+		return nil, ErrGraphNotInitialized
+	}
+
+	stream := &VideoAnalysisStream{}
+	stream.progressStream = streamWithProgress(ctx, func(ctx context.Context) (string, error) {
+		result, err := uc.AnalyzeVideo(ctx, sessionID, userID, videoID, query)
+		if err != nil {
+			return "", err
+		}
+		stream.result = result
+		return result.Content, nil
+	})
+	return stream, nil
 }
 
-func (s *streamResult) Recv() (string, error) {
-	if s.done {
-		return "", fmt.Errorf("EOF")
+// streamWithProgress 在后台执行 run：执行期间推送进度事件，完成后按分片推送 run 返回的内容，
+// run 失败时 Recv 返回该错误；ctx 取消后停止推送
+func streamWithProgress(ctx context.Context, run func(ctx context.Context) (string, error)) *progressStream {
+	stream := &progressStream{chunks: make(chan *StreamChunk, progressBuffer), done: make(chan struct{})}
+	// runDone 在 run 返回后置位，之后到达的进度事件直接丢弃
+	var mu sync.Mutex
+	runDone := false
+
+	reportCtx := progress.WithReporter(ctx, func(e progress.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if runDone {
+			return
+		}
+		select {
+		case stream.chunks <- &StreamChunk{Phase: e.Phase, Content: e.Message}:
+		default:
+		}
+	})
+
+	go func() {
+		defer close(stream.done)
+		defer close(stream.chunks)
+		content, err := run(reportCtx)
+		mu.Lock()
+		runDone = true
+		mu.Unlock()
+		if err != nil {
+			stream.err = err
+			return
+		}
+
+		reply := newStreamResult("", content, 0)
+		for {
+			chunk, err := reply.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case stream.chunks <- chunk:
+			case <-ctx.Done():
+				stream.err = ctx.Err()
+				return
+			}
+		}
+	}()
+	return stream
+}
+
+// ResumeStreamChat 从流式回复 streamID 的第 lastChunk 个分片之后继续读取，不重新生成；
+// 只缓存会话最近一次流式回复，streamID 不是该回复时返回 ErrStreamNotFound
+func (uc *VideoAssistantUsecase) ResumeStreamChat(sessionID, streamID string, lastChunk int) (ChatStreamReader, error) {
+	if uc.memory == nil {
+		return nil, ErrMemoryNotConfigured
 	}
-	s.done = true
-	return s.content, nil
+	content, ok := uc.memory.StreamReply(sessionID, streamID)
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	return newStreamResult(streamID, content, lastChunk), nil
+}
+
+// MCPToolsInfo 当前加载的 MCP 工具及其来源服务
+type MCPToolsInfo struct {
+	// Available 为 false 表示 MCP 不可用，图以降级模式运行
+	Available bool
+	Servers   []types.MCPServer
+	Tools     []*schema.ToolInfo
 }
 
-func (uc *VideoAssistantUsecase) StreamChat(ctx context.Context, sessionID, userID, message string) (string, error) {
-	return uc.Chat(ctx, sessionID, userID, message)
+// MCPServers 返回当前配置的 MCP 服务
+func (uc *VideoAssistantUsecase) MCPServers() []types.MCPServer {
+	uc.mcpMu.RLock()
+	defer uc.mcpMu.RUnlock()
+	return append([]types.MCPServer(nil), uc.mcpServers...)
 }
 
+// MCPTools 返回当前图加载的 MCP 工具
+func (uc *VideoAssistantUsecase) MCPTools(ctx context.Context) (*MCPToolsInfo, error) {
+	g := uc.currentGraph()
+	if g == nil {
+		return nil, ErrGraphNotInitialized
+	}
+	tools, err := g.ToolInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MCPToolsInfo{
+		Available: g.MCPAvailable(),
+		Servers:   uc.MCPServers(),
+		Tools:     tools,
+	}, nil
+}
+
+// RefreshMCPTools 替换 MCP 服务配置并重建图，与后台重连串行执行
 func (uc *VideoAssistantUsecase) RefreshMCPTools(ctx context.Context, mcpServers []types.MCPServer) error {
+	uc.rebuildMu.Lock()
+	defer uc.rebuildMu.Unlock()
+
+	uc.mcpMu.Lock()
 	uc.mcpServers = mcpServers
+	uc.mcpMu.Unlock()
 
-	if err := uc.initGraph(); err != nil {
+	if err := uc.buildGraph(mcpServers); err != nil {
 		return fmt.Errorf("reinit graph: %w", err)
 	}
 
@@ -115,6 +874,34 @@ func (uc *VideoAssistantUsecase) RefreshMCPTools(ctx context.Context, mcpServers
 	return nil
 }
 
+// ReloadMCPTools 按当前 MCP 服务配置重建图，重新连接并加载工具。已编译的图持有的工具绑定在建图时的连接上，
+// MCP 客户端重连后（如 stdio 子进程重启，见 mcp_client.ServerConfig.OnRestart）调用以换用新连接
+func (uc *VideoAssistantUsecase) ReloadMCPTools(ctx context.Context) error {
+	uc.rebuildMu.Lock()
+	defer uc.rebuildMu.Unlock()
+
+	if err := uc.buildGraph(uc.MCPServers()); err != nil {
+		return fmt.Errorf("reload graph: %w", err)
+	}
+	log.Printf("[Usecase] MCP tools reloaded")
+	return nil
+}
+
 func (uc *VideoAssistantUsecase) Close() {
+	uc.retryMu.Lock()
+	if uc.stopMCPRetry != nil {
+		uc.stopMCPRetry()
+		uc.stopMCPRetry = nil
+	}
+	uc.retryMu.Unlock()
+
+	// 等待进行中的重建完成，确保关闭的是最终使用的图
+	uc.rebuildMu.Lock()
+	defer uc.rebuildMu.Unlock()
+	if g := uc.currentGraph(); g != nil {
+		if err := g.Close(); err != nil {
+			log.Printf("[Usecase] close graph warning: %v", err)
+		}
+	}
 	log.Printf("[Usecase] resources closed")
 }
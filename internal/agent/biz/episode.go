@@ -0,0 +1,127 @@
+package agent_biz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"video_agent/internal/agent/agents/report"
+	"video_agent/internal/logger"
+	"video_agent/internal/memory"
+)
+
+const (
+	// episodeImportance 分析记录的重要性，高于长期记忆阈值，长期记忆可用时总会写入
+	episodeImportance = 0.9
+	// maxEpisodeFindingsRunes 分析记录中结论摘要的最大字符数
+	maxEpisodeFindingsRunes = 300
+)
+
+// 分析记录的结果
+const (
+	EpisodeOutcomeSuccess = "success"
+	EpisodeOutcomeFailed  = "failed"
+)
+
+// 分析记录写入记忆元数据的字段
+const (
+	EpisodeMetadataIntent    = "intent"
+	EpisodeMetadataVideoID   = "video_id"
+	EpisodeMetadataToolsUsed = "tools_used"
+	EpisodeMetadataOutcome   = "outcome"
+)
+
+// Episode 一次完整的分析过程，作为情景记忆保存，便于回答“上次分析的那个视频”之类的追问
+type Episode struct {
+	Intent    string
+	VideoID   string
+	Query     string
+	ToolsUsed []string
+	// Findings 关键结论，过长时截断
+	Findings string
+	Outcome  string
+	// Error 分析失败的原因
+	Error string
+}
+
+// Summary 情景记忆的可检索文本
+func (e Episode) Summary(at time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[分析记录 %s] ", at.Format("2006-01-02 15:04")))
+	if e.VideoID != "" {
+		sb.WriteString(fmt.Sprintf("分析视频 %s", e.VideoID))
+	} else {
+		sb.WriteString("分析")
+	}
+	if e.Intent != "" {
+		sb.WriteString(fmt.Sprintf("（%s）", e.Intent))
+	}
+	if e.Query != "" {
+		sb.WriteString("，问题：" + e.Query)
+	}
+	if e.Outcome == EpisodeOutcomeFailed {
+		sb.WriteString("。分析失败：" + e.Error)
+	} else if e.Findings != "" {
+		sb.WriteString("。结论：" + truncateRunes(e.Findings, maxEpisodeFindingsRunes))
+	}
+	if len(e.ToolsUsed) > 0 {
+		sb.WriteString("。使用工具：" + strings.Join(e.ToolsUsed, ", "))
+	}
+	return sb.String()
+}
+
+// structuredFindings 结构化分析的摘要与要点
+func structuredFindings(analysis *report.StructuredAnalysis) string {
+	if analysis == nil {
+		return ""
+	}
+	findings := analysis.Summary
+	if len(analysis.KeyPoints) > 0 {
+		findings += "；要点：" + strings.Join(analysis.KeyPoints, "；")
+	}
+	return findings
+}
+
+// rememberEpisode 将一次分析写入情景记忆（短期记忆，长期记忆可用时同时写入长期记忆），失败只记录日志
+func (uc *VideoAssistantUsecase) rememberEpisode(ctx context.Context, sessionID, userID string, episode Episode) {
+	if uc.memory == nil {
+		return
+	}
+	if episode.Outcome == "" {
+		episode.Outcome = EpisodeOutcomeSuccess
+	}
+
+	metadata := map[string]interface{}{
+		EpisodeMetadataIntent:    episode.Intent,
+		EpisodeMetadataOutcome:   episode.Outcome,
+		EpisodeMetadataToolsUsed: episode.ToolsUsed,
+	}
+	if episode.VideoID != "" {
+		metadata[EpisodeMetadataVideoID] = episode.VideoID
+	}
+	if userID != "" {
+		metadata[memory.MetadataUserID] = userID
+	}
+
+	now := time.Now()
+	err := uc.memory.Store(ctx, memory.Memory{
+		SessionID:  sessionID,
+		Type:       memory.MemoryTypeEpisodic,
+		Content:    episode.Summary(now),
+		Metadata:   metadata,
+		Importance: episodeImportance,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		logger.WithTrace(ctx, nil).Warnf("[Usecase] store episode failed: %v", err)
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "..."
+}
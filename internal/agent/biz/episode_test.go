@@ -0,0 +1,147 @@
+package agent_biz
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"video_agent/internal/memory"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestEpisodeSummary(t *testing.T) {
+	at := time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		episode Episode
+		want    []string
+		drop    []string
+	}{
+		{
+			name:    "successful analysis",
+			episode: Episode{Intent: "Report", VideoID: "BV1abc", Query: "分析播放数据", ToolsUsed: []string{"get_video_stats"}, Findings: "完播率高"},
+			want:    []string{"[分析记录 2026-10-01 09:30]", "分析视频 BV1abc", "（Report）", "问题：分析播放数据", "结论：完播率高", "使用工具：get_video_stats"},
+		},
+		{
+			name:    "failed analysis reports the error instead of findings",
+			episode: Episode{VideoID: "BV1abc", Outcome: EpisodeOutcomeFailed, Error: "timeout", Findings: "不应出现"},
+			want:    []string{"分析视频 BV1abc", "分析失败：timeout"},
+			drop:    []string{"不应出现"},
+		},
+		{
+			name:    "long findings are truncated",
+			episode: Episode{Findings: strings.Repeat("长", maxEpisodeFindingsRunes+10)},
+			want:    []string{strings.Repeat("长", maxEpisodeFindingsRunes) + "..."},
+			drop:    []string{strings.Repeat("长", maxEpisodeFindingsRunes+1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.episode.Summary(at)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("summary %q missing %q", got, want)
+				}
+			}
+			for _, drop := range tt.drop {
+				if strings.Contains(got, drop) {
+					t.Errorf("summary %q should not contain %q", got, drop)
+				}
+			}
+		})
+	}
+}
+
+// failingModel 所有调用都返回错误
+type failingModel struct{}
+
+func (failingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return nil, errors.New("model unavailable")
+}
+
+func (failingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("model unavailable")
+}
+
+func (failingModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// newLongTermMemoryUsecase 配置了短期与本地长期记忆的用例，所有文本的向量相同
+func newLongTermMemoryUsecase(t *testing.T, llm model.ChatModel) *VideoAssistantUsecase {
+	t.Helper()
+	uc, err := NewVideoAssistantUsecase(nil, llm, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	dir := t.TempDir()
+	vectors, err := memory.NewLocalVectorStore(filepath.Join(dir, "vectors.json"))
+	if err != nil {
+		t.Fatalf("NewLocalVectorStore: %v", err)
+	}
+	metadata, err := memory.NewLocalMetadataStore(filepath.Join(dir, "memories.json"))
+	if err != nil {
+		t.Fatalf("NewLocalMetadataStore: %v", err)
+	}
+	embed := func(ctx context.Context, text string) ([]float64, error) { return []float64{1, 0}, nil }
+	uc.SetMemoryManager(memory.NewMemoryManager(
+		memory.NewShortTermMemory(100, time.Hour),
+		memory.NewLongTermMemory(vectors, metadata, embed),
+		memory.NewWorkingMemory(100),
+	))
+	return uc
+}
+
+func TestAnalyzeVideoStoresEpisode(t *testing.T) {
+	tests := []struct {
+		name        string
+		llm         model.ChatModel
+		wantErr     bool
+		wantOutcome string
+		wantContent string
+	}{
+		{name: "completed analysis", llm: answerModel{answer: "完播率高于同类视频"}, wantOutcome: EpisodeOutcomeSuccess, wantContent: "结论：完播率高于同类视频"},
+		{name: "failed analysis", llm: failingModel{}, wantErr: true, wantOutcome: EpisodeOutcomeFailed, wantContent: "分析失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newLongTermMemoryUsecase(t, tt.llm)
+			ctx := context.Background()
+
+			if _, err := uc.AnalyzeVideo(ctx, "s1", "u1", "BV1abc", "分析播放数据"); (err != nil) != tt.wantErr {
+				t.Fatalf("AnalyzeVideo err = %v, want error %v", err, tt.wantErr)
+			}
+
+			memories, err := uc.memory.Retrieve(ctx, "上次我们分析的那个视频", "s1", 10)
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			var episode *memory.Memory
+			for i := range memories {
+				if memories[i].Type == memory.MemoryTypeEpisodic {
+					episode = &memories[i]
+					break
+				}
+			}
+			if episode == nil {
+				t.Fatalf("memories = %+v, want an episodic memory", memories)
+			}
+			if episode.Metadata[EpisodeMetadataVideoID] != "BV1abc" || episode.Metadata[EpisodeMetadataOutcome] != tt.wantOutcome {
+				t.Errorf("episode metadata = %v, want video BV1abc with outcome %s", episode.Metadata, tt.wantOutcome)
+			}
+			if !strings.Contains(episode.Content, "BV1abc") || !strings.Contains(episode.Content, tt.wantContent) {
+				t.Errorf("episode content = %q, want the video and %q", episode.Content, tt.wantContent)
+			}
+
+			// 新会话中的追问通过长期记忆取回该分析记录
+			uc.SetUserMemoryTopK(3)
+			messages := uc.buildMessages(ctx, "s2", "u1", "上次我们分析的那个视频")
+			if len(messages) == 0 || messages[0].Role != schema.System || !strings.Contains(messages[0].Content, "分析视频 BV1abc") {
+				t.Errorf("follow-up prompt = %+v, want the episode as user memory", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package agent_biz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"video_agent/internal/memory"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestBuildMessagesIncludesHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		// turns 预先写入的历史轮数，每轮回复长度为 replyLen
+		turns    int
+		replyLen int
+		check    func(t *testing.T, history []*schema.Message)
+	}{
+		{
+			name: "no history",
+			check: func(t *testing.T, history []*schema.Message) {
+				if len(history) != 0 {
+					t.Errorf("history = %v, want none", history)
+				}
+			},
+		},
+		{
+			name:     "prior turns in order",
+			turns:    2,
+			replyLen: 10,
+			check: func(t *testing.T, history []*schema.Message) {
+				want := []schema.RoleType{schema.User, schema.Assistant, schema.User, schema.Assistant}
+				if len(history) != len(want) {
+					t.Fatalf("history has %d messages, want %d", len(history), len(want))
+				}
+				for i, msg := range history {
+					if msg.Role != want[i] {
+						t.Errorf("history[%d].Role = %s, want %s", i, msg.Role, want[i])
+					}
+				}
+				if history[0].Content != "问题 0" || history[2].Content != "问题 1" {
+					t.Errorf("history = %v, want the stored questions in order", history)
+				}
+			},
+		},
+		{
+			name:     "overflow compresses older turns",
+			turns:    8,
+			replyLen: 400,
+			check: func(t *testing.T, history []*schema.Message) {
+				if len(history) == 0 || history[0].Role != schema.System || !strings.HasPrefix(history[0].Content, "历史对话摘要") {
+					t.Fatalf("history = %v, want a leading summary of the older turns", history)
+				}
+				tokens := 0
+				for _, msg := range history {
+					tokens += memory.EstimateTokens(msg.Content)
+				}
+				if tokens > historyMaxTokens {
+					t.Errorf("history uses %d tokens, want at most %d", tokens, historyMaxTokens)
+				}
+				if last := history[len(history)-1]; last.Role != schema.Assistant || len(last.Content) != 400 {
+					t.Errorf("last history message = %s (%d bytes), want the latest reply kept", last.Role, len(last.Content))
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newMemoryUsecase(t, "unused")
+			ctx := context.Background()
+			for i := 0; i < tt.turns; i++ {
+				uc.rememberTurn(ctx, "s1", "u1", fmt.Sprintf("问题 %d", i), strings.Repeat("a", tt.replyLen))
+			}
+
+			messages := uc.buildMessages(ctx, "s1", "u1", "本轮问题")
+			last := messages[len(messages)-1]
+			if last.Role != schema.User || last.Content != "本轮问题" {
+				t.Fatalf("last message = %s %q, want the current user message", last.Role, last.Content)
+			}
+			tt.check(t, messages[:len(messages)-1])
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package agent_biz
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/types"
+	"video_agent/mcp_client"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestMCPServersConcurrentRefresh(t *testing.T) {
+	uc, err := NewVideoAssistantUsecase(nil, answerModel{answer: "ok"}, nil, []types.MCPServer{{Name: "a"}})
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := uc.RefreshMCPTools(context.Background(), []types.MCPServer{{Name: "b"}}); err != nil {
+				t.Errorf("RefreshMCPTools: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = uc.MCPServers()
+		}()
+	}
+	wg.Wait()
+
+	servers := uc.MCPServers()
+	if len(servers) != 1 || servers[0].Name != "b" {
+		t.Fatalf("MCPServers() = %+v, want [b]", servers)
+	}
+
+	// 返回的是副本，修改不影响用例内部状态
+	servers[0].Name = "changed"
+	if got := uc.MCPServers()[0].Name; got != "b" {
+		t.Fatalf("MCPServers() leaked internal slice, got %q", got)
+	}
+}
+
+func TestChatInDegradedMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []types.MCPServer
+	}{
+		{name: "no MCP servers", servers: nil},
+		{name: "unreachable MCP server", servers: []types.MCPServer{{Name: "video", URL: "http://127.0.0.1:1/mcp/sse"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, err := NewVideoAssistantUsecase(nil, answerModel{answer: "你好，我是小V"}, nil, tt.servers)
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecase should start without MCP: %v", err)
+			}
+			defer uc.Close()
+			if uc.currentGraph().MCPAvailable() {
+				t.Fatal("graph should be in degraded mode without MCP")
+			}
+
+			// 后台重连期间图会被重建，对话不受影响
+			uc.StartMCPRetry(5 * time.Millisecond)
+			for i := 0; i < 3; i++ {
+				reply, err := uc.Chat(context.Background(), "s1", "u1", "你好")
+				if err != nil {
+					t.Fatalf("Chat in degraded mode: %v", err)
+				}
+				if reply != "你好，我是小V" {
+					t.Errorf("reply = %q, want the general chat answer", reply)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestStartMCPRetryConcurrentClose(t *testing.T) {
+	uc, err := NewVideoAssistantUsecase(nil, answerModel{answer: "ok"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+
+	// 重连的启动与停止可以并发调用，-race 下不应报告数据竞争
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			uc.StartMCPRetry(time.Millisecond)
+		}()
+		go func() {
+			defer wg.Done()
+			uc.Close()
+		}()
+	}
+	wg.Wait()
+	uc.Close()
+
+	uc.retryMu.Lock()
+	defer uc.retryMu.Unlock()
+	if uc.stopMCPRetry != nil {
+		t.Error("Close left a background retry registered")
+	}
+}
+
+// stubMCPClient 返回固定工具的 MCP 客户端
+type stubMCPClient struct {
+	tools  []tool.BaseTool
+	closed atomic.Bool
+}
+
+func (c *stubMCPClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) { return c.tools, nil }
+
+func (c *stubMCPClient) GetTool(ctx context.Context, name string) (tool.BaseTool, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *stubMCPClient) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// stubTool 只有名称的工具
+type stubTool string
+
+func (t stubTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: string(t)}, nil
+}
+
+func (t stubTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	return "{}", nil
+}
+
+func TestRefreshMCPToolsUsesServerList(t *testing.T) {
+	clients := map[string]*stubMCPClient{
+		"video": {tools: []tool.BaseTool{stubTool("get_video_stats")}},
+		"trend": {tools: []tool.BaseTool{stubTool("get_trending_topics")}},
+	}
+	factory := func(ctx context.Context, server types.MCPServer) (mcp_client.Client, error) {
+		if cli, ok := clients[server.Name]; ok {
+			return cli, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+	uc, err := NewVideoAssistantUsecaseWithGraphOptions(nil, answerModel{answer: "ok"}, nil,
+		[]types.MCPServer{{Name: "video"}}, graph.WithMCPClientFactory(factory))
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	defer uc.Close()
+
+	toolNames := func() []string {
+		t.Helper()
+		info, err := uc.MCPTools(context.Background())
+		if err != nil {
+			t.Fatalf("MCPTools: %v", err)
+		}
+		var names []string
+		for _, tl := range info.Tools {
+			names = append(names, tl.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name    string
+		servers []types.MCPServer
+		want    []string
+	}{
+		{name: "server replaced", servers: []types.MCPServer{{Name: "trend"}}, want: []string{"get_trending_topics"}},
+		{name: "server added", servers: []types.MCPServer{{Name: "video"}, {Name: "trend"}}, want: []string{"get_video_stats", "get_trending_topics"}},
+		{name: "servers removed", servers: nil},
+	}
+	if got := toolNames(); !reflect.DeepEqual(got, []string{"get_video_stats"}) {
+		t.Fatalf("initial tools = %v, want [get_video_stats]", got)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := uc.RefreshMCPTools(context.Background(), tt.servers); err != nil {
+				t.Fatalf("RefreshMCPTools: %v", err)
+			}
+			if got := toolNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !clients["video"].closed.Load() || !clients["trend"].closed.Load() {
+		t.Error("clients of replaced graphs were not closed")
+	}
+}
+
+func TestReloadMCPToolsReconnects(t *testing.T) {
+	// 每次连接返回新的客户端，模拟子进程重启后工具绑定在新连接上
+	var connected []*stubMCPClient
+	var mu sync.Mutex
+	factory := func(ctx context.Context, server types.MCPServer) (mcp_client.Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		cli := &stubMCPClient{tools: []tool.BaseTool{stubTool("get_video_stats")}}
+		connected = append(connected, cli)
+		return cli, nil
+	}
+	uc, err := NewVideoAssistantUsecaseWithGraphOptions(nil, answerModel{answer: "ok"}, nil,
+		[]types.MCPServer{{Name: "video"}}, graph.WithMCPClientFactory(factory))
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	defer uc.Close()
+
+	if err := uc.ReloadMCPTools(context.Background()); err != nil {
+		t.Fatalf("ReloadMCPTools: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(connected) != 2 {
+		t.Fatalf("connected %d times, want a new connection on reload", len(connected))
+	}
+	if !connected[0].closed.Load() || connected[1].closed.Load() {
+		t.Errorf("closed = [%v %v], want only the stale connection closed", connected[0].closed.Load(), connected[1].closed.Load())
+	}
+	if servers := uc.MCPServers(); len(servers) != 1 || servers[0].Name != "video" {
+		t.Errorf("MCPServers = %+v, want the configuration kept", servers)
+	}
+}
@@ -0,0 +1,102 @@
+package agent_biz
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/moderation"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// answerModel 所有调用都返回固定回答
+type answerModel struct {
+	answer string
+}
+
+func (m answerModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return schema.AssistantMessage(m.answer, nil), nil
+}
+
+func (m answerModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(m.answer, nil)}), nil
+}
+
+func (answerModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+const structuredAnswer = `{"summary":"弹幕出现违禁词","metrics":{"views":100},"sentiment":"neutral","key_points":["违禁词刷屏","互动率高"],"suggestions":["开启弹幕过滤"]}`
+
+func TestAnalysisOutputsAreModerated(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact bool
+		check  func(t *testing.T, got string)
+	}{
+		{
+			name:   "脱敏",
+			redact: true,
+			check: func(t *testing.T, got string) {
+				if strings.Contains(got, "违禁词") || !strings.Contains(got, "***") {
+					t.Errorf("content = %q, want the keyword redacted", got)
+				}
+			},
+		},
+		{
+			name:   "拦截",
+			redact: false,
+			check: func(t *testing.T, got string) {
+				if got != moderation.BlockedReply {
+					t.Errorf("content = %q, want BlockedReply", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderator := moderation.NewKeywordModerator([]string{"违禁词"}, tt.redact)
+			uc, err := NewVideoAssistantUsecaseWithGraphOptions(nil, answerModel{answer: structuredAnswer}, nil, nil, graph.WithModerator(moderator))
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecaseWithGraphOptions: %v", err)
+			}
+			ctx := context.Background()
+
+			reply, err := uc.Chat(ctx, "s1", "u1", "你好")
+			if err != nil {
+				t.Fatalf("Chat: %v", err)
+			}
+			tt.check(t, reply)
+
+			result, err := uc.AnalyzeVideo(ctx, "s1", "u1", "BV1", "分析")
+			if err != nil {
+				t.Fatalf("AnalyzeVideo: %v", err)
+			}
+			tt.check(t, result.Content)
+
+			batch, err := uc.BatchAnalyze(ctx, "s1", "u1", []string{"BV1", "BV2"}, "分析")
+			if err != nil {
+				t.Fatalf("BatchAnalyze: %v", err)
+			}
+			for _, item := range batch {
+				if item.Result == nil {
+					t.Fatalf("batch %s failed: %s", item.VideoID, item.Error)
+				}
+				tt.check(t, item.Result.Content)
+			}
+
+			structured, err := uc.AnalyzeStructured(ctx, "s1", "u1", "BV1", "分析")
+			if err != nil {
+				t.Fatalf("AnalyzeStructured: %v", err)
+			}
+			tt.check(t, structured.Report.Content)
+			tt.check(t, structured.Analysis.Summary)
+			tt.check(t, structured.Analysis.KeyPoints[0])
+			if got := structured.Analysis.KeyPoints[1]; got != "互动率高" {
+				t.Errorf("unflagged key point = %q, want it unchanged", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package agent_biz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"video_agent/internal/agent/graph"
+	"video_agent/internal/agent/progress"
+	"video_agent/internal/memory"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// newMemoryUsecase 配置了记忆、所有模型调用都返回 answer 的用例
+func newMemoryUsecase(t *testing.T, answer string) *VideoAssistantUsecase {
+	t.Helper()
+	uc, err := NewVideoAssistantUsecase(nil, answerModel{answer: answer}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	uc.SetMemoryManager(memory.NewMemoryManager(
+		memory.NewShortTermMemory(100, time.Hour),
+		memory.NewLongTermMemory(nil, nil, nil),
+		memory.NewWorkingMemory(100),
+	))
+	return uc
+}
+
+// drainStream 读完流式回复并等待后台生成结束
+func drainStream(t *testing.T, reader ChatStreamReader) {
+	t.Helper()
+	for {
+		_, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+	}
+	<-reader.Done()
+}
+
+func TestStreamChatStoresTurnOnce(t *testing.T) {
+	const answer = "播放量稳定增长"
+
+	tests := []struct {
+		name  string
+		turns int
+	}{
+		{name: "one turn", turns: 1},
+		{name: "two turns", turns: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newMemoryUsecase(t, answer)
+			ctx := context.Background()
+
+			for i := 0; i < tt.turns; i++ {
+				reader, err := uc.StreamChat(ctx, "s1", "u1", "我的视频数据怎么样")
+				if err != nil {
+					t.Fatalf("StreamChat: %v", err)
+				}
+				drainStream(t, reader)
+			}
+
+			history, err := uc.GetSessionHistory(ctx, "s1", 100)
+			if err != nil {
+				t.Fatalf("GetSessionHistory: %v", err)
+			}
+			if got, want := len(history.Messages), 2*tt.turns; got != want {
+				t.Fatalf("history has %d messages, want %d: %+v", got, want, history.Messages)
+			}
+			for i, mem := range history.Messages {
+				wantType, wantContent := memory.MemoryTypeUser, "我的视频数据怎么样"
+				if i%2 == 1 {
+					wantType, wantContent = memory.MemoryTypeAssistant, answer
+				}
+				if mem.Type != wantType || mem.Content != wantContent {
+					t.Errorf("message %d = (%s, %q), want (%s, %q)", i, mem.Type, mem.Content, wantType, wantContent)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamChatProgressPrecedesContent(t *testing.T) {
+	const answer = "播放量稳定增长"
+	uc := newMemoryUsecase(t, answer)
+
+	reader, err := uc.StreamChat(context.Background(), "s1", "u1", "我的视频数据怎么样")
+	if err != nil {
+		t.Fatalf("StreamChat: %v", err)
+	}
+
+	var phases []string
+	var content strings.Builder
+	firstContent := -1
+	for {
+		chunk, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if !chunk.IsProgress() {
+			if firstContent < 0 {
+				firstContent = len(phases)
+			}
+			content.WriteString(chunk.Content)
+		}
+		phases = append(phases, chunk.Phase)
+	}
+	<-reader.Done()
+
+	if firstContent <= 0 {
+		t.Fatalf("phases = %v, want a progress event before the first content chunk", phases)
+	}
+	if phases[0] != progress.PhaseIntent {
+		t.Errorf("first event phase = %s, want %s", phases[0], progress.PhaseIntent)
+	}
+	for _, phase := range phases[firstContent:] {
+		if phase != progress.PhaseContent {
+			t.Errorf("phases = %v, want no progress after content starts", phases)
+			break
+		}
+	}
+	if content.String() != answer {
+		t.Errorf("streamed content = %q, want %q", content.String(), answer)
+	}
+}
+
+// countingIntentModel 意图识别模型，总是识别为 Chat 并记录调用次数
+type countingIntentModel struct {
+	calls atomic.Int32
+}
+
+func (m *countingIntentModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls.Add(1)
+	return schema.AssistantMessage("Chat", nil), nil
+}
+
+func (m *countingIntentModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *countingIntentModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestIntentRecognizedOncePerRequest(t *testing.T) {
+	const answer = "你好，有什么可以帮你"
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "Chat"},
+		{name: "StreamChat", stream: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intent := &countingIntentModel{}
+			uc, err := NewVideoAssistantUsecaseWithGraphOptions(nil, answerModel{answer: answer}, nil, nil,
+				graph.WithNodeModels(map[string]model.ChatModel{graph.NodeIntentModel: intent}))
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecase: %v", err)
+			}
+			ctx := context.Background()
+
+			for i := 1; i <= 2; i++ {
+				if tt.stream {
+					reader, err := uc.StreamChat(ctx, "s1", "u1", "你好")
+					if err != nil {
+						t.Fatalf("StreamChat: %v", err)
+					}
+					drainStream(t, reader)
+				} else if _, err := uc.Chat(ctx, "s1", "u1", "你好"); err != nil {
+					t.Fatalf("Chat: %v", err)
+				}
+				if got := intent.calls.Load(); got != int32(i) {
+					t.Fatalf("after %d requests intent recognized %d times, want once per request", i, got)
+				}
+			}
+		})
+	}
+}
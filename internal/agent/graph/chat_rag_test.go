@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"video_agent/rag"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const kbDoc = "B站创作激励计划要求粉丝数达到1000且近30天无违规"
+
+// fakeChatRetriever 与 rag.RetrieverRAGTop1 相同地按阈值过滤固定文档，记录调用次数
+type fakeChatRetriever struct {
+	score float64
+	calls int
+}
+
+func (r *fakeChatRetriever) retrieve(query string, threshold float64) *rag.RAGResult {
+	r.calls++
+	if r.score < threshold {
+		return &rag.RAGResult{Query: query}
+	}
+	doc := &rag.DocumentWithScore{Document: &schema.Document{ID: "kb-1", Content: kbDoc}, Score: r.score}
+	return &rag.RAGResult{Query: query, Documents: []*rag.DocumentWithScore{doc}, TopDocument: doc, TotalFound: 1, HasResult: true}
+}
+
+func TestChatRAG(t *testing.T) {
+	tests := []struct {
+		name          string
+		threshold     float64
+		score         float64
+		wantRetrieved bool
+		wantGrounded  bool
+	}{
+		{name: "disabled", score: 0.9},
+		{name: "matching document grounds the answer", threshold: 0.4, score: 0.8, wantRetrieved: true, wantGrounded: true},
+		{name: "no document above threshold stays pure chat", threshold: 0.4, score: 0.2, wantRetrieved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaryModel := newRecordingModel("需要粉丝数达到1000")
+			var opts []GraphOption
+			if tt.threshold > 0 {
+				opts = append(opts, WithChatRAG(tt.threshold))
+			}
+			opts = append(opts, WithNodeModels(map[string]model.ChatModel{NodeIntentModel: newRecordingModel("Chat")}))
+			vg, err := NewVideoGraph(summaryModel, nil, opts...)
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			retriever := &fakeChatRetriever{score: tt.score}
+			vg.chatRetriever = retriever.retrieve
+
+			out, err := vg.Run(context.Background(), []*schema.Message{schema.UserMessage("创作激励计划有什么要求")})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if len(out) == 0 || !strings.Contains(out[len(out)-1].Content, "1000") {
+				t.Fatalf("Run = %+v, want the summary answer", out)
+			}
+			if retrieved := retriever.calls > 0; retrieved != tt.wantRetrieved {
+				t.Errorf("knowledge base searched = %v, want %v", retrieved, tt.wantRetrieved)
+			}
+
+			var grounded bool
+			for _, input := range summaryModel.Inputs() {
+				for _, msg := range input {
+					if msg.Role == schema.System && strings.Contains(msg.Content, "参考知识") && strings.Contains(msg.Content, kbDoc) {
+						grounded = true
+					}
+				}
+			}
+			if grounded != tt.wantGrounded {
+				t.Errorf("answer prompt grounded with the document = %v, want %v", grounded, tt.wantGrounded)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"video_agent/internal/agent/agents/report"
+	agentprompt "video_agent/internal/agent/prompt"
+)
+
+type outputFormatKey struct{}
+
+// WithOutputFormat 指定本次视频分析报告的输出格式，未指定时输出 Markdown
+func WithOutputFormat(ctx context.Context, format agentprompt.OutputFormat) context.Context {
+	return context.WithValue(ctx, outputFormatKey{}, format)
+}
+
+// outputFormatFor 返回 context 中指定的输出格式，没有指定时为 Markdown
+func outputFormatFor(ctx context.Context) agentprompt.OutputFormat {
+	if format, _ := ctx.Value(outputFormatKey{}).(agentprompt.OutputFormat); format != "" {
+		return format
+	}
+	return agentprompt.OutputFormatMarkdown
+}
+
+// formatAnalysis 按输出格式后处理分析报告：纯文本去除残留的 Markdown 语法；
+// JSON 解析并规范化为合法 JSON，模型输出不合法时经 StructureReport 转换，仍失败时返回 report.ErrInvalidStructuredOutput
+func (vg *VideoGraph) formatAnalysis(ctx context.Context, content string, format agentprompt.OutputFormat) (string, error) {
+	switch format {
+	case agentprompt.OutputFormatPlain:
+		return report.StripMarkdown(content), nil
+	case agentprompt.OutputFormatJSON:
+		structured, err := report.ParseStructuredAnalysis(content)
+		if err != nil {
+			vg.tracedLog(ctx).Warnf("[Graph] json analysis output invalid, converting: %v", err)
+			if structured, err = vg.StructureReport(ctx, content); err != nil {
+				return "", err
+			}
+		}
+		data, err := json.Marshal(structured)
+		if err != nil {
+			return "", fmt.Errorf("marshal analysis: %w", err)
+		}
+		return string(data), nil
+	default:
+		return content, nil
+	}
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"video_agent/internal/agent/agents/report"
+	agentprompt "video_agent/internal/agent/prompt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	markdownReport = "## 概览摘要\n播放量**稳步增长**\n\n| 指标 | 数值 |\n|---|---|\n| 播放量 | 879 |"
+	jsonReport     = "```json\n{\"summary\":\"播放量稳步增长\",\"metrics\":{\"views\":879},\"sentiment\":\"positive\",\"key_points\":[\"完播率高\"],\"suggestions\":[]}\n```"
+)
+
+func TestAnalyzeVideoOutputFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      agentprompt.OutputFormat
+		reportReply string
+		// structureReply StructureReport 转换时默认模型的回复
+		structureReply  string
+		wantInstruction string
+		wantErr         error
+		check           func(t *testing.T, content string)
+	}{
+		{
+			name:        "markdown keeps headings",
+			reportReply: markdownReport,
+			check: func(t *testing.T, content string) {
+				if content != markdownReport {
+					t.Errorf("content = %q, want the markdown report unchanged", content)
+				}
+			},
+		},
+		{
+			name:            "plain strips markdown",
+			format:          agentprompt.OutputFormatPlain,
+			reportReply:     markdownReport,
+			wantInstruction: "输出格式：纯文本",
+			check: func(t *testing.T, content string) {
+				if strings.ContainsAny(content, "#|*") || !strings.Contains(content, "播放量：879") {
+					t.Errorf("content = %q, want plain text", content)
+				}
+			},
+		},
+		{
+			name:            "json is parseable",
+			format:          agentprompt.OutputFormatJSON,
+			reportReply:     jsonReport,
+			wantInstruction: "输出格式：JSON",
+			check:           checkJSONAnalysis,
+		},
+		{
+			name:            "invalid json is converted",
+			format:          agentprompt.OutputFormatJSON,
+			reportReply:     markdownReport,
+			structureReply:  jsonReport,
+			wantInstruction: "输出格式：JSON",
+			check:           checkJSONAnalysis,
+		},
+		{
+			name:            "unconvertible json fails",
+			format:          agentprompt.OutputFormatJSON,
+			reportReply:     markdownReport,
+			structureReply:  "无法转换",
+			wantInstruction: "输出格式：JSON",
+			wantErr:         report.ErrInvalidStructuredOutput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportModel := newRecordingModel(tt.reportReply)
+			vg, err := NewVideoGraph(newRecordingModel(tt.structureReply), nil,
+				WithNodeModels(map[string]model.ChatModel{NodeReportAgent: reportModel}))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			ctx := context.Background()
+			if tt.format != "" {
+				ctx = WithOutputFormat(ctx, tt.format)
+			}
+
+			result, err := vg.AnalyzeVideo(ctx, "s1", "u1", "BV1abc", "分析播放数据")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AnalyzeVideo err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				tt.check(t, result.Content)
+			}
+
+			inputs := reportModel.Inputs()
+			if len(inputs) == 0 {
+				t.Fatal("report agent was not called")
+			}
+			var system string
+			for _, msg := range inputs[0] {
+				if msg.Role == schema.System {
+					system += msg.Content
+				}
+			}
+			for _, instruction := range []string{"输出格式：纯文本", "输出格式：JSON"} {
+				if got := strings.Contains(system, instruction); got != (instruction == tt.wantInstruction) {
+					t.Errorf("report prompt contains %q = %v, want %v", instruction, got, !got)
+				}
+			}
+		})
+	}
+}
+
+func checkJSONAnalysis(t *testing.T, content string) {
+	t.Helper()
+	var analysis report.StructuredAnalysis
+	if err := json.Unmarshal([]byte(content), &analysis); err != nil {
+		t.Fatalf("content is not JSON: %v\n%s", err, content)
+	}
+	if analysis.Summary != "播放量稳步增长" || analysis.Metrics["views"] != 879 {
+		t.Errorf("analysis = %+v, want the report's summary and metrics", analysis)
+	}
+}
@@ -2,12 +2,16 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"strings"
+	"sync"
+	"time"
 
 	"video_agent/internal/agent/agents/base"
 	"video_agent/internal/agent/agents/comment_analysis"
+	"video_agent/internal/agent/agents/competitor_analysis"
 	"video_agent/internal/agent/agents/creative_analysis"
 	"video_agent/internal/agent/agents/hot_live"
 	"video_agent/internal/agent/agents/hot_video"
@@ -17,14 +21,21 @@ import (
 	"video_agent/internal/agent/agents/user_liked_videos"
 	"video_agent/internal/agent/agents/video_recommend"
 	"video_agent/internal/agent/agents/video_summary"
+	"video_agent/internal/agent/moderation"
+	"video_agent/internal/agent/progress"
+	agentprompt "video_agent/internal/agent/prompt"
 	"video_agent/internal/agent/state"
 	states "video_agent/internal/agent/state"
+	"video_agent/internal/agent/stats"
 	"video_agent/internal/agent/types"
+	"video_agent/internal/agent/vision"
+	"video_agent/internal/logger"
+	mcptools "video_agent/internal/mcp"
 	"video_agent/mcp"
+	"video_agent/mcp_client"
 	"video_agent/rag"
 
 	"github.com/cloudwego/eino/components/model"
-	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
@@ -49,11 +60,217 @@ const (
 	NodeHotVideoAgent         = "hot_video_agent"
 	NodeHotLiveAgent          = "hot_live_agent"
 	NodeVideoSummaryAgent     = "video_summary_agent"
+	NodeCompetitorAgent       = "competitor_analysis_agent"
+
+	// NodeToolSelection 各 Agent 的 ToolExecutor 选择工具时使用的模型键（非图节点）。
+	// 配置后由它完成有工具 Agent 的工具调用循环（含最终回答），未配置时使用 Agent 自身的模型
+	NodeToolSelection = "tool_selection"
 )
 
+// GraphOption 构建 VideoGraph 的可选配置
+type GraphOption func(*graphOptions)
+
+type graphOptions struct {
+	nodeModels    map[string]model.ChatModel
+	persona       string
+	maxToolRounds int
+	maxToolCalls  int
+	allowedTools  map[string]bool
+	deniedTools   map[string]bool
+	captioner     vision.Captioner
+	intentRetries int
+	logger        logger.Logger
+	moderator     moderation.Moderator
+	prompts       *agentprompt.Registry
+	// chatRAGThreshold 通用对话检索知识库的相似度阈值，<=0 不检索
+	chatRAGThreshold float64
+	// fallbackMessages 按意图覆盖的降级回复
+	fallbackMessages map[string]string
+	// toolMetrics 各 Agent 工具调用的指标
+	toolMetrics *mcptools.ToolMetrics
+	// mcpClientFactory 连接 MCP 服务，为 nil 时使用 newSSEClient
+	mcpClientFactory MCPClientFactory
+}
+
+// WithNodeModels 按节点名（NodeIntentModel、NodeReportAgent 等）指定模型，
+// 未配置或为 nil 的节点使用默认模型。例如意图识别用小模型、分析类 Agent 用大模型
+func WithNodeModels(models map[string]model.ChatModel) GraphOption {
+	return func(o *graphOptions) {
+		if o.nodeModels == nil {
+			o.nodeModels = make(map[string]model.ChatModel, len(models))
+		}
+		for node, m := range models {
+			o.nodeModels[node] = m
+		}
+	}
+}
+
+// WithPersona 自定义通用对话的系统人设（语气、品牌等），为空时使用 prompt.DefaultPersonaPrompt
+func WithPersona(persona string) GraphOption {
+	return func(o *graphOptions) {
+		o.persona = persona
+	}
+}
+
+// WithFallbackMessages 按意图（Report、VideoRecommend 等）自定义各 Agent 均失败时的降级回复，
+// {video_id} 替换为查询中的视频 ID；未配置的意图使用 summary.DefaultFallbackMessages
+func WithFallbackMessages(messages map[string]string) GraphOption {
+	return func(o *graphOptions) {
+		if o.fallbackMessages == nil {
+			o.fallbackMessages = make(map[string]string, len(messages))
+		}
+		for intent, msg := range messages {
+			o.fallbackMessages[intent] = msg
+		}
+	}
+}
+
+// WithMaxToolRounds 设置各 Agent 单次执行允许的最大工具调用轮数，<=0 使用默认值
+func WithMaxToolRounds(rounds int) GraphOption {
+	return func(o *graphOptions) {
+		o.maxToolRounds = rounds
+	}
+}
+
+// WithMaxToolCalls 设置各 Agent 单轮最多执行的工具调用数（默认 5），超出部分被丢弃，<=0 使用默认值
+func WithMaxToolCalls(calls int) GraphOption {
+	return func(o *graphOptions) {
+		o.maxToolCalls = calls
+	}
+}
+
+// WithToolMetrics 记录各 Agent 工具调用的次数、成败与耗时，为 nil 时不记录
+func WithToolMetrics(metrics *mcptools.ToolMetrics) GraphOption {
+	return func(o *graphOptions) {
+		o.toolMetrics = metrics
+	}
+}
+
+// WithToolAllowlist 仅向模型开放列表中的 MCP 工具，为空表示不限制
+func WithToolAllowlist(names ...string) GraphOption {
+	return func(o *graphOptions) {
+		if o.allowedTools == nil {
+			o.allowedTools = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			o.allowedTools[name] = true
+		}
+	}
+}
+
+// WithToolDenylist 禁用列表中的 MCP 工具（如删除类高危操作），优先级高于 allowlist
+func WithToolDenylist(names ...string) GraphOption {
+	return func(o *graphOptions) {
+		if o.deniedTools == nil {
+			o.deniedTools = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			o.deniedTools[name] = true
+		}
+	}
+}
+
+// filterTools 按 allow/deny 列表过滤工具，被过滤的工具不会出现在绑定给模型的工具列表中，
+// ToolExecutor 也会拒绝执行模型自行编造的不在列表中的工具
+func (o *graphOptions) filterTools(ctx context.Context, tools []tool.BaseTool) []tool.BaseTool {
+	if len(o.allowedTools) == 0 && len(o.deniedTools) == 0 {
+		return tools
+	}
+
+	filtered := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			continue
+		}
+		if o.deniedTools[info.Name] || (len(o.allowedTools) > 0 && !o.allowedTools[info.Name]) {
+			o.log().Debugf("[Graph] tool %s disabled by tool filter", info.Name)
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+// WithModerator 设置最终回复的内容安全审核，未设置时不审核；命中拦截时返回 moderation.BlockedReply
+func WithModerator(m moderation.Moderator) GraphOption {
+	return func(o *graphOptions) {
+		o.moderator = m
+	}
+}
+
+// WithPromptRegistry 设置提示词模板注册表（意图识别、报表、结构化输出等），未设置时使用内置默认模板
+func WithPromptRegistry(r *agentprompt.Registry) GraphOption {
+	return func(o *graphOptions) {
+		o.prompts = r
+	}
+}
+
+// WithLogger 设置分级日志实现，未设置时使用 logger.Default()
+func WithLogger(l logger.Logger) GraphOption {
+	return func(o *graphOptions) {
+		o.logger = l
+	}
+}
+
+func (o *graphOptions) log() logger.Logger {
+	return logger.OrDefault(o.logger)
+}
+
+func (o *graphOptions) promptRegistry() *agentprompt.Registry {
+	if o.prompts == nil {
+		return agentprompt.NewRegistry()
+	}
+	return o.prompts
+}
+
+// WithVisionModel 设置支持图片输入的视觉模型，AnalyzeVideo 时会描述视频关键帧并加入分析上下文；
+// 未设置时仅使用文本数据分析
+func WithVisionModel(m model.ChatModel) GraphOption {
+	return func(o *graphOptions) {
+		if m != nil {
+			o.captioner = vision.NewModelCaptioner(m)
+		}
+	}
+}
+
+// WithFrameCaptioner 自定义关键帧描述实现，优先级高于 WithVisionModel
+func WithFrameCaptioner(c vision.Captioner) GraphOption {
+	return func(o *graphOptions) {
+		o.captioner = c
+	}
+}
+
+// WithIntentRetries 设置意图输出无法识别时重新提示模型的次数，默认 1 次，0 表示不重试直接回退为通用对话
+func WithIntentRetries(retries int) GraphOption {
+	return func(o *graphOptions) {
+		if retries >= 0 {
+			o.intentRetries = retries
+		}
+	}
+}
+
+// WithChatRAG 通用对话（Chat 意图）回答前先检索知识库，相似度不低于 threshold 的文档作为参考知识注入；
+// 没有达到阈值的文档时按纯对话回答。threshold 可参考 rag.ScoreRelevant，<=0 关闭
+func WithChatRAG(threshold float64) GraphOption {
+	return func(o *graphOptions) {
+		o.chatRAGThreshold = threshold
+	}
+}
+
+// modelFor 返回节点对应的模型，未配置时回退到默认模型
+func (o *graphOptions) modelFor(node string, fallback model.ChatModel) model.ChatModel {
+	if m, ok := o.nodeModels[node]; ok && m != nil {
+		return m
+	}
+	return fallback
+}
+
 type VideoGraph struct {
 	runner                compose.Runnable[[]*schema.Message, []*schema.Message]
 	llm                   model.ChatModel
+	intentLLM             model.ChatModel
+	ragLLM                model.ChatModel
 	mcpTools              []tool.BaseTool
 	reportAgent           *report.ReportAgentNode
 	creativeAnalysisAgent *creative_analysis.CreativeAnalysisAgentNode
@@ -65,6 +282,18 @@ type VideoGraph struct {
 	hotVideoAgent         *hot_video.HotVideoAgentNode
 	hotLiveAgent          *hot_live.HotLiveAgentNode
 	videoSummaryAgent     *video_summary.VideoSummaryAgentNode
+	competitorAgent       *competitor_analysis.CompetitorAnalysisAgentNode
+	captioner             vision.Captioner
+	intentRetries         int
+	log                   logger.Logger
+	moderator             moderation.Moderator
+	prompts               *agentprompt.Registry
+	chatRAGThreshold      float64
+	// chatRetriever 通用对话检索知识库 Top1 文档，默认为 rag.RetrieverRAGTop1
+	chatRetriever func(query string, threshold float64) *rag.RAGResult
+	// mcpClients 加载 mcpTools 时建立的连接，closeMu 保护，由 Close 关闭
+	closeMu    sync.Mutex
+	mcpClients []mcp_client.Client
 }
 
 // AgentNode 定义 Agent 节点的通用接口
@@ -73,59 +302,97 @@ type AgentNode interface {
 	Route(ctx context.Context, state *states.GraphState, result *types.AgentResult) (types.AgentType, error)
 }
 
-func NewVideoGraph(llm model.ChatModel, mcpServers []types.MCPServer) (*VideoGraph, error) {
+// NewVideoGraph 创建视频助手图，工具从 mcpServers 逐个加载（见 WithMCPClientFactory）；
+// 没有可用的 MCP 服务时图以降级模式运行。不再使用时调用 Close 释放 MCP 连接
+func NewVideoGraph(llm model.ChatModel, mcpServers []types.MCPServer, opts ...GraphOption) (*VideoGraph, error) {
 	ctx := context.Background()
 
-	var mcpTools []tool.BaseTool
-	mcpTools, err := mcp.GetMCPTool(ctx)
-	if err != nil {
-		log.Printf("[Graph] warning: get MCP tools failed: %v (continuing without MCP)", err)
-		mcpTools = nil
+	options := &graphOptions{intentRetries: defaultIntentRetries}
+	for _, opt := range opts {
+		opt(options)
 	}
 
 	if llm == nil {
 		return nil, fmt.Errorf("llm is required")
 	}
 
+	mcpTools, mcpClients := options.loadMCPTools(ctx, mcpServers)
+	mcpTools = options.filterTools(ctx, mcpTools)
+
+	// newToolExecutor 创建 Agent 的工具执行器，没有工具时直接使用 Agent 的模型回答
+	newToolExecutor := func(tools []tool.BaseTool, agentLLM model.ChatModel) *base.ToolExecutor {
+		toolLLM := agentLLM
+		if len(tools) > 0 {
+			toolLLM = options.modelFor(NodeToolSelection, agentLLM)
+		}
+		te := base.NewToolExecutor(tools, toolLLM)
+		te.SetMaxToolRounds(options.maxToolRounds)
+		te.SetMaxToolCalls(options.maxToolCalls)
+		te.SetMetrics(options.toolMetrics)
+		return te
+	}
+
 	reportTools := selectToolsForAgent(mcpTools, types.AgentTypeReport)
-	te := base.NewToolExecutor(reportTools, llm)
-	reportAgent := report.NewReportAgentNode(llm, te)
+	reportLLM := options.modelFor(NodeReportAgent, llm)
+	te := newToolExecutor(reportTools, reportLLM)
+	prompts := options.promptRegistry()
+	reportAgent := report.NewReportAgentNode(reportLLM, te)
+	reportAgent.SetSystemPrompt(prompts.Text(agentprompt.TemplateReport))
 
 	creativeAnalysisTools := selectToolsForAgent(mcpTools, types.AgentTypeCreativeAnalysis)
-	creativeAnalysisTE := base.NewToolExecutor(creativeAnalysisTools, llm)
-	creativeAnalysisAgent := creative_analysis.NewCreativeAnalysisAgentNode(llm, creativeAnalysisTE)
+	creativeAnalysisLLM := options.modelFor(NodeCreativeAnalysisAgent, llm)
+	creativeAnalysisTE := newToolExecutor(creativeAnalysisTools, creativeAnalysisLLM)
+	creativeAnalysisAgent := creative_analysis.NewCreativeAnalysisAgentNode(creativeAnalysisLLM, creativeAnalysisTE)
 
-	ragSelectorAgent := rag_selector.NewRAGSelectorAgentNode(llm, nil, nil)
+	ragSelectorAgent := rag_selector.NewRAGSelectorAgentNode(options.modelFor(NodeRAGSelectorAgent, llm), nil, nil)
 
-	summaryNode := summary.NewSummaryNode(llm)
+	summaryNode := summary.NewSummaryNode(options.modelFor(NodeSummary, llm))
+	summaryNode.SetPersona(options.persona)
+	if len(options.fallbackMessages) > 0 {
+		summaryNode.SetFallbackMessages(options.fallbackMessages)
+	}
 
 	commentAnalysisTools := selectToolsForAgent(mcpTools, types.AgentTypeCommentAnalysis)
-	commentAnalysisTE := base.NewToolExecutor(commentAnalysisTools, llm)
-	commentAnalysisAgent := comment_analysis.NewCommentAnalysisAgentNode(llm, commentAnalysisTE)
+	commentAnalysisLLM := options.modelFor(NodeCommentAnalysisAgent, llm)
+	commentAnalysisTE := newToolExecutor(commentAnalysisTools, commentAnalysisLLM)
+	commentAnalysisAgent := comment_analysis.NewCommentAnalysisAgentNode(commentAnalysisLLM, commentAnalysisTE)
 
 	videoRecommendTools := selectToolsForAgent(mcpTools, types.AgentTypeVideoRecommend)
-	videoRecommendTE := base.NewToolExecutor(videoRecommendTools, llm)
-	videoRecommendAgent := video_recommend.NewVideoRecommendAgentNode(llm, videoRecommendTE)
+	videoRecommendLLM := options.modelFor(NodeVideoRecommendAgent, llm)
+	videoRecommendTE := newToolExecutor(videoRecommendTools, videoRecommendLLM)
+	videoRecommendAgent := video_recommend.NewVideoRecommendAgentNode(videoRecommendLLM, videoRecommendTE)
 
 	userLikedVideosTools := selectToolsForAgent(mcpTools, types.AgentTypeUserLikedVideos)
-	userLikedVideosTE := base.NewToolExecutor(userLikedVideosTools, llm)
-	userLikedVideosAgent := user_liked_videos.NewUserLikedVideosAgentNode(llm, userLikedVideosTE)
+	userLikedVideosLLM := options.modelFor(NodeUserLikedVideosAgent, llm)
+	userLikedVideosTE := newToolExecutor(userLikedVideosTools, userLikedVideosLLM)
+	userLikedVideosAgent := user_liked_videos.NewUserLikedVideosAgentNode(userLikedVideosLLM, userLikedVideosTE)
 
 	hotVideoTools := selectToolsForAgent(mcpTools, types.AgentTypeHotVideo)
-	hotVideoTE := base.NewToolExecutor(hotVideoTools, llm)
-	hotVideoAgent := hot_video.NewHotVideoAgentNode(llm, hotVideoTE)
+	hotVideoLLM := options.modelFor(NodeHotVideoAgent, llm)
+	hotVideoTE := newToolExecutor(hotVideoTools, hotVideoLLM)
+	hotVideoAgent := hot_video.NewHotVideoAgentNode(hotVideoLLM, hotVideoTE)
 
 	hotLiveTools := selectToolsForAgent(mcpTools, types.AgentTypeHotLive)
-	hotLiveTE := base.NewToolExecutor(hotLiveTools, llm)
-	hotLiveAgent := hot_live.NewHotLiveAgentNode(llm, hotLiveTE)
+	hotLiveLLM := options.modelFor(NodeHotLiveAgent, llm)
+	hotLiveTE := newToolExecutor(hotLiveTools, hotLiveLLM)
+	hotLiveAgent := hot_live.NewHotLiveAgentNode(hotLiveLLM, hotLiveTE)
 
 	videoSummaryTools := selectToolsForAgent(mcpTools, types.AgentTypeVideoSummary)
-	videoSummaryTE := base.NewToolExecutor(videoSummaryTools, llm)
-	videoSummaryAgent := video_summary.NewVideoSummaryAgentNode(llm, videoSummaryTE)
+	videoSummaryLLM := options.modelFor(NodeVideoSummaryAgent, llm)
+	videoSummaryTE := newToolExecutor(videoSummaryTools, videoSummaryLLM)
+	videoSummaryAgent := video_summary.NewVideoSummaryAgentNode(videoSummaryLLM, videoSummaryTE)
+
+	competitorTools := selectToolsForAgent(mcpTools, types.AgentTypeCompetitorAnalysis)
+	competitorLLM := options.modelFor(NodeCompetitorAgent, llm)
+	competitorTE := newToolExecutor(competitorTools, competitorLLM)
+	competitorAgent := competitor_analysis.NewCompetitorAnalysisAgentNode(competitorLLM, competitorTE)
 
 	vg := &VideoGraph{
 		llm:                   llm,
+		intentLLM:             options.modelFor(NodeIntentModel, llm),
+		ragLLM:                options.modelFor(NodeRAG, llm),
 		mcpTools:              mcpTools,
+		mcpClients:            mcpClients,
 		reportAgent:           reportAgent,
 		creativeAnalysisAgent: creativeAnalysisAgent,
 		ragSelectorAgent:      ragSelectorAgent,
@@ -136,15 +403,47 @@ func NewVideoGraph(llm model.ChatModel, mcpServers []types.MCPServer) (*VideoGra
 		hotVideoAgent:         hotVideoAgent,
 		hotLiveAgent:          hotLiveAgent,
 		videoSummaryAgent:     videoSummaryAgent,
+		competitorAgent:       competitorAgent,
+		captioner:             options.captioner,
+		intentRetries:         options.intentRetries,
+		log:                   options.log(),
+		moderator:             options.moderator,
+		prompts:               prompts,
+		chatRAGThreshold:      options.chatRAGThreshold,
+		chatRetriever:         rag.RetrieverRAGTop1,
 	}
 
 	if err := vg.buildGraph(); err != nil {
+		_ = vg.Close()
 		return nil, fmt.Errorf("build graph: %w", err)
 	}
 
 	return vg, nil
 }
 
+// tracedLog 返回带当前请求追踪 ID 的 Logger
+func (vg *VideoGraph) tracedLog(ctx context.Context) logger.Logger {
+	return logger.WithTrace(ctx, vg.log)
+}
+
+// MCPAvailable MCP 工具是否可用，不可用时图以降级模式运行（仅通用对话与知识库）
+func (vg *VideoGraph) MCPAvailable() bool {
+	return len(vg.mcpTools) > 0
+}
+
+// ToolInfos 返回当前加载的 MCP 工具描述（名称、说明、参数）
+func (vg *VideoGraph) ToolInfos(ctx context.Context) ([]*schema.ToolInfo, error) {
+	infos := make([]*schema.ToolInfo, 0, len(vg.mcpTools))
+	for _, t := range vg.mcpTools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get tool info: %w", err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 // createAgentLambda 创建 Agent 节点的 Lambda 函数（使用标准 Node 类型模式）
 func (vg *VideoGraph) createAgentLambda(agent AgentNode, agentType types.AgentType, agentName string) *compose.Lambda {
 	return compose.InvokableLambda(func(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
@@ -157,20 +456,28 @@ func (vg *VideoGraph) createAgentLambda(agent AgentNode, agentType types.AgentTy
 			return nil, err
 		}
 
-		log.Printf("[Graph] executing %s for query: %s", agentName, state.OriginalQuery)
+		vg.tracedLog(ctx).Infof("[Graph] executing %s for query: %s", agentName, state.OriginalQuery)
 
 		result, err := agent.Execute(ctx, state)
+		if errors.Is(err, base.ErrStepLimitReached) && result != nil {
+			// 步数耗尽：保留结果交给 Summary，让用户看到明确的未完成提示
+			vg.tracedLog(ctx).Warnf("[Graph] %s stopped: %v", agentName, err)
+			state.SetAgentResult(agentType, result)
+			runInfoFrom(ctx).setResult(agentType, result)
+			return []*schema.Message{}, nil
+		}
 		if err != nil {
-			log.Printf("[Graph] %s error: %v", agentName, err)
+			vg.tracedLog(ctx).Errorf("[Graph] %s error: %v", agentName, err)
 			return []*schema.Message{
 				schema.AssistantMessage(fmt.Sprintf("执行失败: %v", err), nil),
 			}, nil
 		}
 
 		state.SetAgentResult(agentType, result)
+		runInfoFrom(ctx).setResult(agentType, result)
 
 		nextAgent, _ := agent.Route(ctx, state, result)
-		log.Printf("[Graph] %s route result: %s", agentName, nextAgent)
+		vg.tracedLog(ctx).Debugf("[Graph] %s route result: %s", agentName, nextAgent)
 
 		// 返回包含 ToolCalls 的消息，让后续节点处理
 		if len(result.ToolCalls) > 0 {
@@ -258,6 +565,11 @@ func selectToolsForAgent(allTools []tool.BaseTool, agentType types.AgentType) []
 				strings.Contains(toolName, "stream") {
 				filtered = append(filtered, t)
 			}
+		case types.AgentTypeCompetitorAnalysis:
+			if strings.Contains(toolName, "creator") || strings.Contains(toolName, "user") ||
+				strings.Contains(toolName, "video") {
+				filtered = append(filtered, t)
+			}
 		case types.AgentTypeVideoSummary:
 			if strings.Contains(toolName, "video") || strings.Contains(toolName, "transcribe") ||
 				strings.Contains(toolName, "file") {
@@ -268,7 +580,7 @@ func selectToolsForAgent(allTools []tool.BaseTool, agentType types.AgentType) []
 		}
 	}
 
-	log.Printf("[Graph] agent %s selected %d tools", agentType, len(filtered))
+	logger.Default().Debugf("[Graph] agent %s selected %d tools", agentType, len(filtered))
 	return filtered
 }
 
@@ -288,6 +600,7 @@ func (vg *VideoGraph) buildGraph() error {
 			if state.OriginalQuery == "" && len(input) > 0 {
 				state.OriginalQuery = input[len(input)-1].Content
 				state.Messages = input
+				state.Language = languageFor(ctx, state.OriginalQuery)
 			}
 			return nil
 		})
@@ -295,92 +608,13 @@ func (vg *VideoGraph) buildGraph() error {
 			return nil, err
 		}
 
-		intentTemp := prompt.FromMessages(schema.FString,
-			schema.SystemMessage(`你是一个意图识别专家。请分析用户查询，只输出意图类型。
-
-【意图类型定义】
-1. RAG - 知识库查询：询问网站/系统/产品的功能、介绍、使用方法
-2. Report - 视频数据分析：分析视频数据、生成报表、统计数据、查询视频信息
-3. VideoSummary - 视频内容总结：总结视频内容、视频讲什么
-4. CommentAnalysis - 评论分析：分析评论、弹幕、观众反馈
-5. VideoRecommend - 视频推荐：推荐视频、找好看的内容（注意：不是分析已有视频）
-6. UserLikedVideos - 点赞查询：查询点赞记录、喜欢的视频
-7. HotVideo - 热门视频：查询最火视频、热门内容
-8. HotLive - 热门直播：查询热门直播、正在直播
-9. Creative - 创作分析：选题分析、趋势分析、竞品分析
-10. Chat - 闲聊：问候、日常对话
-
-【关键区分】
-- Report：用户想"分析/查看/查询"某个具体视频的数据或信息（有明确视频ID或想查某个视频）
-- VideoRecommend：用户想"推荐/找"视频（没有具体视频ID，想要推荐列表）
-- VideoSummary：用户想"总结/概括"视频内容（视频讲了什么）
-
-【Few-shot示例】
-Q: "这个网站干啥的"
-A: RAG
-
-Q: "VisionWorld是什么"
-A: RAG
-
-Q: "系统怎么用"
-A: RAG
-
-Q: "介绍一下产品功能"
-A: RAG
-
-Q: "分析一下视频123的数据"
-A: Report
-
-Q: "分析下视频1766329556"
-A: Report
-
-Q: "查看视频123的信息"
-A: Report
-
-Q: "查询视频数据"
-A: Report
-
-Q: "总结一下视频内容"
-A: VideoSummary
-
-Q: "这个视频讲了什么"
-A: VideoSummary
-
-Q: "推荐一些好看的视频"
-A: VideoRecommend
-
-Q: "有什么好看的视频"
-A: VideoRecommend
-
-Q: "最近什么视频最火"
-A: HotVideo
-
-Q: "帮我分析评论"
-A: CommentAnalysis
-
-Q: "你好"
-A: Chat
-
-【任务】
-分析以下查询，只输出意图类型（RAG/Report/VideoSummary/CommentAnalysis/VideoRecommend/UserLikedVideos/HotVideo/HotLive/Creative/Chat）：
-
-用户查询：{query}`),
-			schema.UserMessage("{query}"),
-		)
-
-		output, err := intentTemp.Format(ctx, map[string]any{
-			"query": state.OriginalQuery,
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := vg.llm.Generate(ctx, output)
+		progress.Report(ctx, progress.PhaseIntent, "识别意图中")
+		resp, intent, err := vg.recognizeIntent(ctx, state.OriginalQuery)
 		if err != nil {
 			return nil, err
 		}
-
-		log.Printf("[Graph] intent decision: %s", resp.Content)
+		runInfoFrom(ctx).setIntent(intent)
+		state.SetIntent(intent, resolveVideoID(ctx, state.OriginalQuery))
 
 		return []*schema.Message{resp}, nil
 	}))
@@ -407,7 +641,7 @@ A: Chat
 		if query == "" {
 			query = state.OriginalQuery
 		}
-		log.Printf("[Graph] RAG retrieval for query: %s", query)
+		vg.tracedLog(ctx).Infof("[Graph] RAG retrieval for query: %s", query)
 
 		// 企业级 RAG 流程：检索 → 阈值过滤 → LLM 生成
 		// 使用向量检索
@@ -417,26 +651,31 @@ A: Chat
 
 		// 记录检索结果到状态
 		if ragResult.HasResult && ragResult.TopDocument != nil {
-			var ragDocs []types.RAGDocument
-			ragDocs = append(ragDocs, types.RAGDocument{
-				ID:       ragResult.TopDocument.ID,
-				Content:  ragResult.TopDocument.Content,
-				Metadata: ragResult.TopDocument.MetaData,
-			})
+			// 与生成回答使用的文档及编号一致
+			ragDocs := make([]types.RAGDocument, 0, len(ragResult.Documents))
+			for _, doc := range ragResult.Documents {
+				ragDocs = append(ragDocs, types.RAGDocument{
+					ID:       doc.ID,
+					Content:  doc.Content,
+					Score:    doc.Score,
+					Metadata: doc.MetaData,
+				})
+			}
 			state.SetRAGDocuments(ragDocs)
-			log.Printf("[Graph] RAG Top-1: score=%.4f, level=%s",
+			runInfoFrom(ctx).setSources(ragDocs)
+			vg.tracedLog(ctx).Debugf("[Graph] RAG Top-1: score=%.4f, level=%s",
 				ragResult.TopDocument.Score, rag.GetSimilarityLevel(ragResult.TopDocument.Score))
 
 			// 使用检索到的文档生成回答
-			answer = generateRAGAnswer(ctx, vg.llm, query, ragResult)
+			answer = generateRAGAnswer(ctx, vg.ragLLM, query, ragResult)
 		} else {
 			// 没有检索到文档，尝试使用选中的知识库信息生成回答
-			log.Printf("[Graph] RAG no documents found, trying to use knowledge base info")
+			vg.tracedLog(ctx).Warnf("[Graph] RAG no documents found, trying to use knowledge base info")
 			if ragSelection := state.GetRAGSelection(); ragSelection != nil {
-				answer = generateAnswerFromKnowledgeBases(ctx, vg.llm, state.OriginalQuery, ragSelection)
+				answer = generateAnswerFromKnowledgeBases(ctx, vg.ragLLM, state.OriginalQuery, ragSelection)
 			} else {
 				// 没有知识库信息，使用默认回答
-				answer = generateRAGAnswer(ctx, vg.llm, query, ragResult)
+				answer = generateRAGAnswer(ctx, vg.ragLLM, query, ragResult)
 			}
 		}
 
@@ -463,7 +702,7 @@ A: Chat
 
 		hasToolCall := len(msg.ToolCalls) > 0
 		if !hasToolCall {
-			log.Printf("[Graph] no tool call in message, skip MCP")
+			vg.tracedLog(ctx).Debugf("[Graph] no tool call in message, skip MCP")
 			return input, nil
 		}
 
@@ -498,11 +737,11 @@ A: Chat
 				return nil, err
 			}
 
-			log.Printf("[Graph] executing RAG selector agent for query: %s", state.OriginalQuery)
+			vg.tracedLog(ctx).Infof("[Graph] executing RAG selector agent for query: %s", state.OriginalQuery)
 
 			result, err := vg.ragSelectorAgent.Execute(ctx, state)
 			if err != nil {
-				log.Printf("[Graph] RAG selector agent error: %v", err)
+				vg.tracedLog(ctx).Errorf("[Graph] RAG selector agent error: %v", err)
 				return []*schema.Message{
 					schema.AssistantMessage(fmt.Sprintf("RAG知识库选择失败: %v", err), nil),
 				}, nil
@@ -515,12 +754,12 @@ A: Chat
 				state.SetRAGSelection(selection)
 				// 保存优化后的查询
 				state.SetOptimizedQuery(selection.Query)
-				log.Printf("[Graph] RAG selected %d knowledge bases, optimized query: %s",
+				vg.tracedLog(ctx).Infof("[Graph] RAG selected %d knowledge bases, optimized query: %s",
 					len(selection.SelectedKBs), selection.Query)
 			}
 
 			nextAgent, err := vg.ragSelectorAgent.Route(ctx, state, result)
-			log.Printf("[Graph] RAG selector agent route result: %s", nextAgent)
+			vg.tracedLog(ctx).Debugf("[Graph] RAG selector agent route result: %s", nextAgent)
 
 			return []*schema.Message{
 				schema.AssistantMessage(result.Content, nil),
@@ -564,6 +803,12 @@ A: Chat
 		_ = g.AddLambdaNode(NodeVideoSummaryAgent, videoSummaryLambda)
 	}
 
+	// 添加竞品对比 Agent 节点（使用标准 Lambda 封装）
+	if vg.competitorAgent != nil {
+		competitorLambda := vg.createAgentLambda(vg.competitorAgent, types.AgentTypeCompetitorAnalysis, NodeCompetitorAgent)
+		_ = g.AddLambdaNode(NodeCompetitorAgent, competitorLambda)
+	}
+
 	// 添加 Summary 节点，用于整合和格式化最终结果（必须在路由分支之前添加）
 	_ = g.AddLambdaNode(NodeSummary, compose.InvokableLambda(func(ctx context.Context, input []*schema.Message) ([]*schema.Message, error) {
 		var state *states.GraphState
@@ -575,16 +820,23 @@ A: Chat
 			return nil, err
 		}
 
-		log.Printf("[Graph] executing summary node for query: %s", state.OriginalQuery)
+		vg.tracedLog(ctx).Infof("[Graph] executing summary node for query: %s", state.OriginalQuery)
+		progress.Report(ctx, progress.PhaseGenerate, "生成分析中")
+
+		if vg.chatRAGThreshold > 0 && len(state.AgentResults) == 0 && state.GetRAGContext() == "" {
+			vg.attachChatRAG(ctx, state)
+		}
 
 		result, err := vg.summaryNode.Execute(ctx, state)
 		if err != nil {
-			log.Printf("[Graph] summary node error: %v", err)
+			vg.tracedLog(ctx).Errorf("[Graph] summary node error: %v", err)
 			return []*schema.Message{
 				schema.AssistantMessage(fmt.Sprintf("整合结果失败: %v", err), nil),
 			}, nil
 		}
 
+		result = vg.Moderate(ctx, result)
+
 		state.FinalAnswer = result
 		return []*schema.Message{
 			schema.AssistantMessage(result, nil),
@@ -607,13 +859,13 @@ A: Chat
 			Tools: vg.mcpTools,
 		})
 		if err != nil {
-			log.Printf("[Graph] warning: create MCP tool node failed: %v", err)
+			vg.tracedLog(ctx).Warnf("[Graph] create MCP tool node failed: %v", err)
 		} else {
 			err = g.AddToolsNode(NodeMCP, mcpNode)
 			if err != nil {
-				log.Printf("[Graph] warning: add MCP tool node failed: %v", err)
+				vg.tracedLog(ctx).Warnf("[Graph] add MCP tool node failed: %v", err)
 			} else {
-				log.Printf("[Graph] MCP tool node registered, tools count: %d", len(vg.mcpTools))
+				vg.tracedLog(ctx).Infof("[Graph] MCP tool node registered, tools count: %d", len(vg.mcpTools))
 			}
 		}
 	}
@@ -622,70 +874,54 @@ A: Chat
 	_ = g.AddEdge(NodeIntentModel, NodeTransList)
 
 	// 使用标准 GraphBranch 进行意图路由
+	// 已注册的 Agent 节点，未注册的意图回退到 Summary（通用对话），避免路由到不存在的节点
+	agentNodes := map[string]bool{
+		NodeReportAgent:           vg.reportAgent != nil,
+		NodeCreativeAnalysisAgent: vg.creativeAnalysisAgent != nil,
+		NodeRAGSelectorAgent:      vg.ragSelectorAgent != nil,
+		NodeCommentAnalysisAgent:  vg.commentAnalysisAgent != nil,
+		NodeVideoRecommendAgent:   vg.videoRecommendAgent != nil,
+		NodeUserLikedVideosAgent:  vg.userLikedVideosAgent != nil,
+		NodeHotVideoAgent:         vg.hotVideoAgent != nil,
+		NodeHotLiveAgent:          vg.hotLiveAgent != nil,
+		NodeVideoSummaryAgent:     vg.videoSummaryAgent != nil,
+		NodeCompetitorAgent:       vg.competitorAgent != nil,
+	}
+	branchEnds := map[string]bool{NodeRAG: true, NodeSummary: true}
+	for node, ok := range agentNodes {
+		if ok {
+			branchEnds[node] = true
+		}
+	}
+
 	_ = g.AddBranch(NodeTransList, compose.NewGraphBranch(
 		func(ctx context.Context, msgs []*schema.Message) (string, error) {
 			if len(msgs) == 0 {
 				return compose.END, nil
 			}
-			content := strings.ToUpper(msgs[len(msgs)-1].Content)
-			if strings.Contains(content, "REPORT") {
-				return NodeReportAgent, nil
-			}
-			if strings.Contains(content, "CREATIVE") {
-				return NodeCreativeAnalysisAgent, nil
-			}
-			if strings.Contains(content, "RAG") || strings.Contains(content, "知识库") {
-				return NodeRAGSelectorAgent, nil
-			}
-			if strings.Contains(content, "COMMENTANALYSIS") || strings.Contains(content, "COMMENT_ANALYSIS") {
-				return NodeCommentAnalysisAgent, nil
-			}
-			if strings.Contains(content, "VIDEORECOMMEND") || strings.Contains(content, "VIDEO_RECOMMEND") {
-				return NodeVideoRecommendAgent, nil
-			}
-			if strings.Contains(content, "USERLIKEDVIDEOS") || strings.Contains(content, "USER_LIKED_VIDEOS") {
-				return NodeUserLikedVideosAgent, nil
-			}
-			if strings.Contains(content, "HOTVIDEO") || strings.Contains(content, "HOT_VIDEO") {
-				return NodeHotVideoAgent, nil
-			}
-			if strings.Contains(content, "HOTLIVE") || strings.Contains(content, "HOT_LIVE") {
-				return NodeHotLiveAgent, nil
-			}
-			if strings.Contains(content, "VIDEOSUMMARY") || strings.Contains(content, "VIDEO_SUMMARY") {
-				return NodeVideoSummaryAgent, nil
-			}
-			if strings.Contains(content, "CHAT") {
+			intent := parseIntent(msgs[len(msgs)-1].Content)
+			node := intentNode(intent)
+			if node != NodeSummary && !agentNodes[node] {
+				vg.tracedLog(ctx).Warnf("[Graph] agent node %s for intent %s not registered, falling back to %s", node, intent, NodeSummary)
 				return NodeSummary, nil
 			}
-			return NodeSummary, nil
-		},
-		map[string]bool{
-			NodeReportAgent:           true,
-			NodeCreativeAnalysisAgent: true,
-			NodeRAGSelectorAgent:      true,
-			NodeCommentAnalysisAgent:  true,
-			NodeVideoRecommendAgent:   true,
-			NodeUserLikedVideosAgent:  true,
-			NodeHotVideoAgent:         true,
-			NodeHotLiveAgent:          true,
-			NodeVideoSummaryAgent:     true,
-			NodeRAG:                   true,
-			NodeSummary:               true,
+			return node, nil
 		},
+		branchEnds,
 	))
 
-	// 使用常量定义节点连接边
-	_ = g.AddEdge(NodeReportAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeCreativeAnalysisAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeRAGSelectorAgent, NodeRAG)
+	// 使用常量定义节点连接边：RAG 选择后进入检索，其余 Agent 进入工具调用判断
+	for node, ok := range agentNodes {
+		if !ok {
+			continue
+		}
+		if node == NodeRAGSelectorAgent {
+			_ = g.AddEdge(NodeRAGSelectorAgent, NodeRAG)
+			continue
+		}
+		_ = g.AddEdge(node, NodeToToolCall)
+	}
 	_ = g.AddEdge(NodeRAG, NodeSummary)
-	_ = g.AddEdge(NodeCommentAnalysisAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeVideoRecommendAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeUserLikedVideosAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeHotVideoAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeHotLiveAgent, NodeToToolCall)
-	_ = g.AddEdge(NodeVideoSummaryAgent, NodeToToolCall)
 
 	if len(vg.mcpTools) > 0 {
 		_ = g.AddBranch(NodeToToolCall, compose.NewGraphBranch(
@@ -726,9 +962,193 @@ func (vg *VideoGraph) Run(ctx context.Context, messages []*schema.Message) ([]*s
 	if vg.runner == nil {
 		return nil, fmt.Errorf("graph not initialized")
 	}
+	ctx, _ = logger.EnsureTraceID(ctx)
 	return vg.runner.Invoke(ctx, messages)
 }
 
+// Moderate 按 WithModerator 配置的审核器审核对外返回的内容，返回可展示的内容（可能被脱敏或替换为 BlockedReply）；
+// 未配置审核器时原样返回
+func (vg *VideoGraph) Moderate(ctx context.Context, content string) string {
+	result, verdict, err := moderation.Apply(ctx, vg.moderator, content)
+	if err != nil {
+		vg.tracedLog(ctx).Errorf("[Graph] moderation failed, reply blocked: %v", err)
+	} else if verdict.Action != moderation.ActionAllow {
+		vg.tracedLog(ctx).Warnf("[Graph] reply moderated (action=%d): %s", verdict.Action, verdict.Reason)
+	}
+	return result
+}
+
+// AnalyzeVideo 跳过意图识别，直接由 Report Agent 分析指定视频，报告按 WithOutputFormat 指定的格式输出
+func (vg *VideoGraph) AnalyzeVideo(ctx context.Context, sessionID, userID, videoID, query string) (*types.AgentResult, error) {
+	if vg.reportAgent == nil {
+		return nil, fmt.Errorf("report agent not initialized")
+	}
+
+	// 在拼接视频ID说明之前检测，避免中文说明影响语言判断
+	lang := languageFor(ctx, query)
+	if query == "" {
+		query = fmt.Sprintf("分析一下视频%s的数据", videoID)
+	} else {
+		query = fmt.Sprintf("%s（视频ID: %s）", query, videoID)
+	}
+
+	format := outputFormatFor(ctx)
+	state := states.NewGraphState(query, sessionID, userID)
+	state.SetLanguage(lang)
+	state.SetOutputFormat(format)
+	state.SetIntent("Report", videoID)
+	rememberVideoID(ctx, videoID)
+	vg.tracedLog(ctx).Infof("[Graph] analyzing video %s directly, query: %s", videoID, query)
+
+	if frameContext := vg.describeVideoFrames(ctx, videoID); frameContext != "" {
+		state.SetFrameContext(frameContext)
+	}
+	if growthContext := vg.describeStatsGrowth(ctx, videoID); growthContext != "" {
+		state.SetGrowthContext(growthContext)
+	}
+
+	result, err := vg.reportAgent.Execute(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("report agent: %w", err)
+	}
+	if result.Content, err = vg.formatAnalysis(ctx, result.Content, format); err != nil {
+		return nil, fmt.Errorf("format analysis: %w", err)
+	}
+	return result, nil
+}
+
+// describeVideoFrames 通过 get_video_thumbnails 获取关键帧并由视觉模型描述，未配置视觉模型或工具不可用时返回空
+func (vg *VideoGraph) describeVideoFrames(ctx context.Context, videoID string) string {
+	if vg.captioner == nil {
+		return ""
+	}
+
+	for _, t := range vg.mcpTools {
+		info, err := t.Info(ctx)
+		if err != nil || info.Name != "get_video_thumbnails" {
+			continue
+		}
+		invokable, ok := t.(tool.InvokableTool)
+		if !ok {
+			return ""
+		}
+
+		args, _ := json.Marshal(map[string]interface{}{"video_id": videoID})
+		output, err := invokable.InvokableRun(ctx, string(args))
+		if err != nil {
+			vg.tracedLog(ctx).Warnf("[Graph] get video thumbnails failed: %v", err)
+			return ""
+		}
+
+		frames := vision.ParseFrames(output)
+		vg.tracedLog(ctx).Debugf("[Graph] describing %d frames of video %s", len(frames), videoID)
+		return vision.DescribeFrames(ctx, vg.captioner, frames)
+	}
+	return ""
+}
+
+// describeStatsGrowth 通过 get_video_stats_history 获取近两周的周粒度快照并计算环比增长，工具不可用或数据不足时返回空
+func (vg *VideoGraph) describeStatsGrowth(ctx context.Context, videoID string) string {
+	for _, t := range vg.mcpTools {
+		info, err := t.Info(ctx)
+		if err != nil || info.Name != "get_video_stats_history" {
+			continue
+		}
+		invokable, ok := t.(tool.InvokableTool)
+		if !ok {
+			return ""
+		}
+
+		now := time.Now()
+		args, _ := json.Marshal(map[string]interface{}{
+			"video_id":    videoID,
+			"start":       now.AddDate(0, 0, -14).Format(time.DateOnly),
+			"end":         now.Format(time.DateOnly),
+			"granularity": "week",
+		})
+		output, err := invokable.InvokableRun(ctx, string(args))
+		if err != nil {
+			vg.tracedLog(ctx).Warnf("[Graph] get video stats history failed: %v", err)
+			return ""
+		}
+
+		points := stats.ParseHistory(output)
+		vg.tracedLog(ctx).Debugf("[Graph] got %d stats snapshots of video %s", len(points), videoID)
+		return stats.DescribeGrowth(points)
+	}
+	return ""
+}
+
+// AnalyzeStructured 分析指定视频并输出结构化结果：先由 Report Agent 生成报告，再转换为 JSON，
+// 解析失败时带上错误让模型修复一次，仍失败则返回 report.ErrInvalidStructuredOutput
+func (vg *VideoGraph) AnalyzeStructured(ctx context.Context, sessionID, userID, videoID, query string) (*report.StructuredAnalysis, *types.AgentResult, error) {
+	result, err := vg.AnalyzeVideo(ctx, sessionID, userID, videoID, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	structured, err := vg.StructureReport(ctx, result.Content)
+	if err != nil {
+		return nil, result, err
+	}
+	report.ApplyToolStats(structured, result.ToolOutputs)
+	return structured, result, nil
+}
+
+// StructureReport 将分析报告文本转换为结构化结果，解析失败时请求模型修复一次，
+// 仍不合法时返回 report.ErrInvalidStructuredOutput
+func (vg *VideoGraph) StructureReport(ctx context.Context, content string) (*report.StructuredAnalysis, error) {
+	messages := []*schema.Message{
+		schema.SystemMessage(vg.prompts.Text(agentprompt.TemplateStructured)),
+		schema.UserMessage(content),
+	}
+	resp, err := vg.llm.Generate(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("generate structured analysis: %w", err)
+	}
+
+	structured, parseErr := report.ParseStructuredAnalysis(resp.Content)
+	if parseErr == nil {
+		return structured, nil
+	}
+
+	vg.tracedLog(ctx).Warnf("[Graph] structured analysis parse failed, attempting repair: %v", parseErr)
+	repair, err := vg.prompts.Render(agentprompt.TemplateStructuredRepair, map[string]string{
+		"error":  parseErr.Error(),
+		"output": resp.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages = append(messages, resp, schema.UserMessage(repair))
+	resp, err = vg.llm.Generate(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("repair structured analysis: %w", err)
+	}
+
+	return report.ParseStructuredAnalysis(resp.Content)
+}
+
+// attachChatRAG 为通用对话检索知识库，命中时写入 RAG 上下文，由 Summary 的直接回答作为参考知识使用
+func (vg *VideoGraph) attachChatRAG(ctx context.Context, state *states.GraphState) {
+	ragResult := vg.chatRetriever(state.OriginalQuery, vg.chatRAGThreshold)
+	if !ragResult.HasResult || ragResult.TopDocument == nil {
+		vg.tracedLog(ctx).Debugf("[Graph] chat RAG: no document above %.2f", vg.chatRAGThreshold)
+		return
+	}
+
+	sources := []types.RAGDocument{{
+		ID:       ragResult.TopDocument.ID,
+		Content:  ragResult.TopDocument.Content,
+		Score:    ragResult.TopDocument.Score,
+		Metadata: ragResult.TopDocument.MetaData,
+	}}
+	state.SetRAGDocuments(sources)
+	runInfoFrom(ctx).setSources(sources)
+	vg.tracedLog(ctx).Infof("[Graph] chat RAG: grounding answer with %s (score=%.4f)",
+		ragResult.TopDocument.ID, ragResult.TopDocument.Score)
+}
+
 // generateRAGAnswer 使用 LLM 生成自然语言回答
 func generateRAGAnswer(ctx context.Context, llm model.ChatModel, query string, ragResult *rag.RAGResult) string {
 	const ragAnswerPrompt = `你是一个专业的知识库助手。请根据检索到的知识库内容回答用户的问题。
@@ -738,6 +1158,7 @@ func generateRAGAnswer(ctx context.Context, llm model.ChatModel, query string, r
 2. 必须直接回答用户问题，不要绕弯子或给出模糊的"参考相关文档"之类的回答
 3. 如果知识库内容包含功能介绍，请直接列出具体功能，不要概括性描述
 4. 回答要简洁明了，突出核心信息，禁止出现"若需进一步信息"、"请参考相关文档"等推诿性语句
+5. 使用了某篇文档的内容时，在对应句子末尾用方括号标注文档编号，如 [1]
 
 【强制要求】
 - 用户问"这个网站是做什么的"或"有什么功能"时，必须直接列出网站的核心功能
@@ -774,7 +1195,7 @@ func generateRAGAnswer(ctx context.Context, llm model.ChatModel, query string, r
 	// 调用 LLM 生成回答
 	resp, err := llm.Generate(ctx, messages)
 	if err != nil {
-		log.Printf("[Graph] RAG answer generation failed: %v", err)
+		logger.Default().Errorf("[Graph] RAG answer generation failed: %v", err)
 		// 降级：直接返回文档内容或提示
 		if ragResult != nil && ragResult.HasResult && ragResult.TopDocument != nil {
 			return ragResult.TopDocument.Content
@@ -807,7 +1228,7 @@ func generateAnswerFromKnowledgeBases(
 	for _, kb := range selection.SelectedKBs {
 		kbNames = append(kbNames, kb.Name)
 	}
-	log.Printf("[Graph] RAG retrieval failed, selected KBs: %v", kbNames)
+	logger.Default().Warnf("[Graph] RAG retrieval failed, selected KBs: %v", kbNames)
 
 	// 返回明确的提示信息，告知用户无法获取具体信息
 	return "抱歉，我暂时无法获取相关文档内容来回答您的问题。可能原因：\n1. 知识库服务暂时不可用\n2. 相关文档尚未导入\n\n建议您：\n- 稍后再试\n- 联系管理员检查知识库配置"
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// recordingModel 依次返回 replies（用完后重复最后一条），并记录每次调用的输入
+type recordingModel struct {
+	mu      sync.Mutex
+	replies []string
+	inputs  [][]*schema.Message
+}
+
+func newRecordingModel(replies ...string) *recordingModel {
+	return &recordingModel{replies: replies}
+}
+
+func (m *recordingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs = append(m.inputs, input)
+	reply := m.replies[min(len(m.inputs), len(m.replies))-1]
+	return schema.AssistantMessage(reply, nil), nil
+}
+
+func (m *recordingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *recordingModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// Calls 返回调用次数
+func (m *recordingModel) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.inputs)
+}
+
+// Inputs 返回所有调用的输入
+func (m *recordingModel) Inputs() [][]*schema.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]*schema.Message(nil), m.inputs...)
+}
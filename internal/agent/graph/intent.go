@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	agentprompt "video_agent/internal/agent/prompt"
+	"video_agent/internal/agent/types"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// defaultIntentRetries 意图输出无法识别时默认重新提示的次数
+const defaultIntentRetries = 1
+
+// intentRetryPrompt 意图输出无法识别时的重新提示
+const intentRetryPrompt = "请只输出一个意图类型（RAG/Report/VideoSummary/CommentAnalysis/VideoRecommend/UserLikedVideos/HotVideo/HotLive/Creative/Competitor/Chat），不要输出代码块、解释或其他任何内容。"
+
+// intentLabels 意图类型、匹配关键字及对应的图节点与 Agent，顺序即匹配优先级；
+// Chat 与无法识别的意图由 Summary 节点按通用对话处理
+var intentLabels = []struct {
+	label    string
+	keywords []string
+	node     string
+	agent    types.AgentType
+}{
+	{"Report", []string{"REPORT"}, NodeReportAgent, types.AgentTypeReport},
+	{"Competitor", []string{"COMPETITOR"}, NodeCompetitorAgent, types.AgentTypeCompetitorAnalysis},
+	{"Creative", []string{"CREATIVE"}, NodeCreativeAnalysisAgent, types.AgentTypeCreativeAnalysis},
+	{"RAG", []string{"RAG", "知识库"}, NodeRAGSelectorAgent, types.AgentTypeRAGSelector},
+	{"CommentAnalysis", []string{"COMMENTANALYSIS"}, NodeCommentAnalysisAgent, types.AgentTypeCommentAnalysis},
+	{"VideoRecommend", []string{"VIDEORECOMMEND"}, NodeVideoRecommendAgent, types.AgentTypeVideoRecommend},
+	{"UserLikedVideos", []string{"USERLIKEDVIDEOS"}, NodeUserLikedVideosAgent, types.AgentTypeUserLikedVideos},
+	{"HotVideo", []string{"HOTVIDEO"}, NodeHotVideoAgent, types.AgentTypeHotVideo},
+	{"HotLive", []string{"HOTLIVE"}, NodeHotLiveAgent, types.AgentTypeHotLive},
+	{"VideoSummary", []string{"VIDEOSUMMARY"}, NodeVideoSummaryAgent, types.AgentTypeVideoSummary},
+	{"Chat", []string{"CHAT"}, NodeSummary, types.AgentTypeSummary},
+}
+
+// intentNode 意图类型对应的图节点，未知意图返回 NodeSummary
+func intentNode(intent string) string {
+	for _, it := range intentLabels {
+		if it.label == intent {
+			return it.node
+		}
+	}
+	return NodeSummary
+}
+
+// intentAgent 意图类型对应的 Agent，未知意图返回 AgentTypeSummary
+func intentAgent(intent string) types.AgentType {
+	for _, it := range intentLabels {
+		if it.label == intent {
+			return it.agent
+		}
+	}
+	return types.AgentTypeSummary
+}
+
+var (
+	thinkBlockRe = regexp.MustCompile(`(?s)<think>.*?</think>`)
+	codeFenceRe  = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*(.*?)\\s*```")
+)
+
+// parseIntent 从模型输出中识别意图类型：去掉思考过程与代码块包裹，兼容 "intent": "xxx" 形式的 JSON，
+// 无法识别时返回空字符串
+func parseIntent(content string) string {
+	content = thinkBlockRe.ReplaceAllString(content, "")
+	if m := codeFenceRe.FindStringSubmatch(content); m != nil {
+		content = m[1]
+	}
+
+	normalized := strings.ToUpper(strings.ReplaceAll(content, "_", ""))
+	for _, intent := range intentLabels {
+		for _, kw := range intent.keywords {
+			if strings.Contains(normalized, kw) {
+				return intent.label
+			}
+		}
+	}
+	return ""
+}
+
+// recognizeIntent 调用意图模型识别查询意图，输出无法识别（如带思考过程、代码块或解释）时重新提示；
+// 识别成功时返回的消息内容规范化为意图类型，仍失败则 intent 为空，由路由分支回退为通用对话
+func (vg *VideoGraph) recognizeIntent(ctx context.Context, query string) (*schema.Message, string, error) {
+	system, err := vg.prompts.Render(agentprompt.TemplateIntent, map[string]string{
+		"query": query,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	output := []*schema.Message{
+		schema.SystemMessage(system),
+		schema.UserMessage(query),
+	}
+
+	resp, err := vg.intentLLM.Generate(ctx, output)
+	if err != nil {
+		return nil, "", err
+	}
+
+	intent := parseIntent(resp.Content)
+	for attempt := 1; intent == "" && attempt <= vg.intentRetries; attempt++ {
+		vg.tracedLog(ctx).Warnf("[Graph] intent output unrecognized, retrying (%d/%d): %q", attempt, vg.intentRetries, resp.Content)
+		output = append(output, resp, schema.UserMessage(intentRetryPrompt))
+		resp, err = vg.intentLLM.Generate(ctx, output)
+		if err != nil {
+			return nil, "", err
+		}
+		intent = parseIntent(resp.Content)
+	}
+
+	vg.tracedLog(ctx).Infof("[Graph] intent decision: %s (raw: %q)", intent, resp.Content)
+	if intent != "" {
+		resp = schema.AssistantMessage(intent, nil)
+	}
+	return resp, intent, nil
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseIntent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain label", content: "Report", want: "Report"},
+		{name: "snake case", content: "video_summary", want: "VideoSummary"},
+		{name: "fenced JSON", content: "```json\n{\"intent\": \"CommentAnalysis\"}\n```", want: "CommentAnalysis"},
+		{name: "think block is ignored", content: "<think>用户可能想闲聊 Chat</think>\nHotVideo", want: "HotVideo"},
+		{name: "unrecognized", content: "我需要再想一想", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseIntent(tt.content); got != tt.want {
+				t.Errorf("parseIntent(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecognizeIntentRetry(t *testing.T) {
+	const fenced = "```json\n{\"intent\": \"Report\"}\n```"
+
+	tests := []struct {
+		name       string
+		replies    []string
+		opts       []GraphOption
+		wantIntent string
+		wantCalls  int
+	}{
+		{name: "fenced JSON parsed without retry", replies: []string{fenced}, wantIntent: "Report", wantCalls: 1},
+		{name: "recovered on the retry", replies: []string{"我需要再想一想", fenced}, wantIntent: "Report", wantCalls: 2},
+		{name: "retries disabled", replies: []string{"我需要再想一想", fenced}, opts: []GraphOption{WithIntentRetries(0)}, wantCalls: 1},
+		{name: "retries exhausted", replies: []string{"我需要再想一想"}, opts: []GraphOption{WithIntentRetries(2)}, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := newRecordingModel(tt.replies...)
+			vg, err := NewVideoGraph(llm, nil, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+
+			resp, intent, err := vg.recognizeIntent(context.Background(), "分析一下视频12345的数据")
+			if err != nil {
+				t.Fatalf("recognizeIntent: %v", err)
+			}
+			if intent != tt.wantIntent || llm.Calls() != tt.wantCalls {
+				t.Fatalf("intent = %q after %d calls, want %q after %d", intent, llm.Calls(), tt.wantIntent, tt.wantCalls)
+			}
+			if intent != "" && resp.Content != intent {
+				t.Errorf("response content = %q, want it normalized to %q", resp.Content, intent)
+			}
+			if tt.wantCalls > 1 {
+				retry := llm.Inputs()[1]
+				if last := retry[len(retry)-1]; last.Content != intentRetryPrompt {
+					t.Errorf("retry ends with %q, want the retry prompt", last.Content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package graph
+
+import (
+	"context"
+
+	agentprompt "video_agent/internal/agent/prompt"
+)
+
+type languageKey struct{}
+
+// WithLanguage 指定本次执行的回复语言，未指定时按查询内容检测
+func WithLanguage(ctx context.Context, lang agentprompt.Language) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// languageFor 返回 context 中指定的语言，没有指定时从查询检测
+func languageFor(ctx context.Context, query string) agentprompt.Language {
+	if lang, _ := ctx.Value(languageKey{}).(agentprompt.Language); lang != "" {
+		return lang
+	}
+	return agentprompt.DetectLanguage(query)
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agentprompt "video_agent/internal/agent/prompt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestAnalyzeVideoLanguage(t *testing.T) {
+	english := agentprompt.LanguageInstruction(agentprompt.LanguageEnglish)
+
+	tests := []struct {
+		name        string
+		lang        agentprompt.Language
+		query       string
+		wantEnglish bool
+	}{
+		{name: "requested English", lang: agentprompt.LanguageEnglish, query: "分析一下这个视频", wantEnglish: true},
+		{name: "detected from an English query", query: "How is this video performing", wantEnglish: true},
+		{name: "Chinese query gets no instruction", query: "分析一下这个视频"},
+		{name: "request overrides detection", lang: agentprompt.LanguageChinese, query: "How is this video performing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportModel := newRecordingModel("report")
+			vg, err := NewVideoGraph(newRecordingModel("default"), nil, WithNodeModels(map[string]model.ChatModel{NodeReportAgent: reportModel}))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			ctx := context.Background()
+			if tt.lang != "" {
+				ctx = WithLanguage(ctx, tt.lang)
+			}
+
+			if _, err := vg.AnalyzeVideo(ctx, "s1", "u1", "BV1", tt.query); err != nil {
+				t.Fatalf("AnalyzeVideo: %v", err)
+			}
+			var instructed bool
+			for _, input := range reportModel.Inputs() {
+				for _, msg := range input {
+					if msg.Role == schema.System && strings.Contains(msg.Content, english) {
+						instructed = true
+					}
+				}
+			}
+			if instructed != tt.wantEnglish {
+				t.Errorf("system prompt instructs English = %v, want %v", instructed, tt.wantEnglish)
+			}
+		})
+	}
+}
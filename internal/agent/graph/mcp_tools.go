@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"video_agent/internal/agent/types"
+	"video_agent/mcp_client"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// MCPClientFactory 按 MCP 服务配置创建客户端，图从返回的客户端加载工具
+type MCPClientFactory func(ctx context.Context, server types.MCPServer) (mcp_client.Client, error)
+
+// WithMCPClientFactory 自定义连接 MCP 服务的方式，未设置时按服务 URL 建立 SSE 连接
+func WithMCPClientFactory(factory MCPClientFactory) GraphOption {
+	return func(o *graphOptions) {
+		o.mcpClientFactory = factory
+	}
+}
+
+// newSSEClient 默认的 MCPClientFactory：通过服务 URL 建立 SSE 连接，RequestHeader 作为工具调用的请求头
+func newSSEClient(ctx context.Context, server types.MCPServer) (mcp_client.Client, error) {
+	if server.URL == "" {
+		return nil, fmt.Errorf("MCP server %s has no URL", server.Name)
+	}
+	headers, err := server.ToServerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("parse request header: %w", err)
+	}
+	return mcp_client.NewSSEClient(&mcp_client.ServerConfig{URL: server.URL, Headers: headers})
+}
+
+// loadMCPTools 逐个连接 servers 并合并其工具，连接或拉取工具失败的服务跳过（其余服务的工具照常可用），
+// 与先加载的工具重名的工具被忽略。返回的客户端由 VideoGraph.Close 关闭
+func (o *graphOptions) loadMCPTools(ctx context.Context, servers []types.MCPServer) ([]tool.BaseTool, []mcp_client.Client) {
+	newClient := o.mcpClientFactory
+	if newClient == nil {
+		newClient = newSSEClient
+	}
+
+	var tools []tool.BaseTool
+	var clients []mcp_client.Client
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		cli, err := newClient(ctx, server)
+		if err != nil {
+			o.log().Warnf("[Graph] connect MCP server %s failed: %v (continuing without its tools)", server.Name, err)
+			continue
+		}
+		serverTools, err := cli.GetTools(ctx)
+		if err != nil {
+			o.log().Warnf("[Graph] get MCP tools from %s failed: %v (continuing without its tools)", server.Name, err)
+			_ = cli.Close()
+			continue
+		}
+		clients = append(clients, cli)
+
+		for _, t := range serverTools {
+			info, err := t.Info(ctx)
+			if err != nil {
+				continue
+			}
+			if seen[info.Name] {
+				o.log().Warnf("[Graph] MCP tool %s from %s ignored: name already provided by another server", info.Name, server.Name)
+				continue
+			}
+			seen[info.Name] = true
+			tools = append(tools, t)
+		}
+	}
+	return tools, clients
+}
+
+// Close 关闭图加载工具时建立的 MCP 连接，之后图中的工具调用都会失败；
+// 重建图时应在新图就绪后再关闭旧图
+func (vg *VideoGraph) Close() error {
+	vg.closeMu.Lock()
+	clients := vg.mcpClients
+	vg.mcpClients = nil
+	vg.closeMu.Unlock()
+
+	var errs []error
+	for _, cli := range clients {
+		if err := cli.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
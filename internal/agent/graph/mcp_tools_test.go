@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"video_agent/internal/agent/types"
+	"video_agent/mcp_client"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// fakeMCPClient 返回固定工具的 MCP 客户端，记录是否已关闭
+type fakeMCPClient struct {
+	tools  []tool.BaseTool
+	err    error
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *fakeMCPClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
+	return c.tools, c.err
+}
+
+func (c *fakeMCPClient) GetTool(ctx context.Context, name string) (tool.BaseTool, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeMCPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeMCPClient) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// fakeMCPServers 按服务名返回客户端的 MCPClientFactory，未登记的服务连接失败
+type fakeMCPServers map[string]*fakeMCPClient
+
+func (f fakeMCPServers) factory(ctx context.Context, server types.MCPServer) (mcp_client.Client, error) {
+	cli, ok := f[server.Name]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return cli, nil
+}
+
+func newFakeMCPServers() fakeMCPServers {
+	return fakeMCPServers{
+		"video":  {tools: []tool.BaseTool{&namedTool{name: "get_video_stats"}, &namedTool{name: "get_video_comments"}}},
+		"trend":  {tools: []tool.BaseTool{&namedTool{name: "get_trending_topics"}, &namedTool{name: "get_video_stats"}}},
+		"broken": {err: errors.New("list tools failed")},
+	}
+}
+
+func TestNewVideoGraphLoadsToolsFromServers(t *testing.T) {
+	tests := []struct {
+		name          string
+		servers       []string
+		want          []string
+		wantAvailable bool
+	}{
+		{name: "no servers runs degraded"},
+		{name: "single server", servers: []string{"video"}, want: []string{"get_video_stats", "get_video_comments"}, wantAvailable: true},
+		{
+			name:          "tools of every server are merged, first name wins",
+			servers:       []string{"video", "trend"},
+			want:          []string{"get_video_stats", "get_video_comments", "get_trending_topics"},
+			wantAvailable: true,
+		},
+		{name: "failing servers are skipped", servers: []string{"offline", "broken", "trend"}, want: []string{"get_trending_topics", "get_video_stats"}, wantAvailable: true},
+		{name: "only failing servers runs degraded", servers: []string{"offline", "broken"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakes := newFakeMCPServers()
+			var servers []types.MCPServer
+			for _, name := range tt.servers {
+				servers = append(servers, types.MCPServer{Name: name})
+			}
+
+			vg, err := NewVideoGraph(newRecordingModel("ok"), servers, WithMCPClientFactory(fakes.factory))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			infos, err := vg.ToolInfos(context.Background())
+			if err != nil {
+				t.Fatalf("ToolInfos: %v", err)
+			}
+			var names []string
+			for _, info := range infos {
+				names = append(names, info.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("tools = %v, want %v", names, tt.want)
+			}
+			if vg.MCPAvailable() != tt.wantAvailable {
+				t.Errorf("MCPAvailable = %v, want %v", vg.MCPAvailable(), tt.wantAvailable)
+			}
+			if fakes["broken"].Closed() != slices.Contains(tt.servers, "broken") {
+				t.Errorf("broken server client closed = %v, want it closed once tool loading failed", fakes["broken"].Closed())
+			}
+
+			// Close 关闭全部已连接的服务
+			if err := vg.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			for _, name := range tt.servers {
+				if cli, ok := fakes[name]; ok && !cli.Closed() {
+					t.Errorf("client of %s not closed", name)
+				}
+			}
+		})
+	}
+}
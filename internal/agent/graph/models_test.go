@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+func TestWithNodeModels(t *testing.T) {
+	tests := []struct {
+		name string
+		// models 由默认模型与报告模型构造节点模型配置
+		models      func(report model.ChatModel) map[string]model.ChatModel
+		wantReport  string
+		wantDefault bool
+	}{
+		{
+			name: "analysis node uses the designated model",
+			models: func(report model.ChatModel) map[string]model.ChatModel {
+				return map[string]model.ChatModel{NodeReportAgent: report}
+			},
+			wantReport: "报告模型的分析",
+		},
+		{
+			name: "nil entry falls back to the default model",
+			models: func(model.ChatModel) map[string]model.ChatModel {
+				return map[string]model.ChatModel{NodeReportAgent: nil}
+			},
+			wantReport:  "默认模型的回答",
+			wantDefault: true,
+		},
+		{
+			name: "tool selection model does not answer for an agent without tools",
+			models: func(report model.ChatModel) map[string]model.ChatModel {
+				return map[string]model.ChatModel{NodeReportAgent: report, NodeToolSelection: newRecordingModel("选工具模型")}
+			},
+			wantReport: "报告模型的分析",
+		},
+		{
+			name: "unconfigured node uses the default model",
+			models: func(report model.ChatModel) map[string]model.ChatModel {
+				return map[string]model.ChatModel{NodeSummary: report}
+			},
+			wantReport:  "默认模型的回答",
+			wantDefault: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultModel := newRecordingModel("默认模型的回答")
+			reportModel := newRecordingModel("报告模型的分析")
+			vg, err := NewVideoGraph(defaultModel, nil, WithNodeModels(tt.models(reportModel)))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+
+			result, err := vg.AnalyzeVideo(context.Background(), "s1", "u1", "BV1", "")
+			if err != nil {
+				t.Fatalf("AnalyzeVideo: %v", err)
+			}
+			if result.Content != tt.wantReport {
+				t.Errorf("report = %q, want %q", result.Content, tt.wantReport)
+			}
+			if used := defaultModel.Calls() > 0; used != tt.wantDefault {
+				t.Errorf("default model used = %v, want %v", used, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestIntentUsesIntentModel(t *testing.T) {
+	defaultModel := newRecordingModel("Chat")
+	intentModel := newRecordingModel("Report")
+	vg, err := NewVideoGraph(defaultModel, nil, WithNodeModels(map[string]model.ChatModel{NodeIntentModel: intentModel}))
+	if err != nil {
+		t.Fatalf("NewVideoGraph: %v", err)
+	}
+
+	_, intent, err := vg.recognizeIntent(context.Background(), "分析一下视频12345的数据")
+	if err != nil {
+		t.Fatalf("recognizeIntent: %v", err)
+	}
+	if intent != "Report" || intentModel.Calls() != 1 || defaultModel.Calls() != 0 {
+		t.Errorf("intent = %q, intent model calls %d, default model calls %d; want Report from the intent model only",
+			intent, intentModel.Calls(), defaultModel.Calls())
+	}
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"context"
+	"regexp"
+
+	"video_agent/internal/agent/types"
+)
+
+// videoIDPattern 查询中的视频 ID（纯数字，至少 3 位）
+var videoIDPattern = regexp.MustCompile(`\d{3,}`)
+
+// ExecutionPlan 预演结果：图会走的意图分支、执行 Agent 及其可用工具
+type ExecutionPlan struct {
+	Intent  string          `json:"intent"`
+	Agent   types.AgentType `json:"agent"`
+	Tools   []string        `json:"tools"`
+	VideoID string          `json:"video_id,omitempty"`
+}
+
+// Plan 预演模式：只执行意图识别与工具筛选，不调用工具、不执行分析 Agent，也不写入会话记忆，
+// 用于调试和前端预览
+func (vg *VideoGraph) Plan(ctx context.Context, query string) (*ExecutionPlan, error) {
+	_, intent, err := vg.recognizeIntent(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与路由分支一致：无法识别的意图按通用对话处理
+	if intent == "" {
+		intent = "Chat"
+	}
+	agentType := intentAgent(intent)
+
+	plan := &ExecutionPlan{
+		Intent:  intent,
+		Agent:   agentType,
+		Tools:   []string{},
+		VideoID: videoIDPattern.FindString(query),
+	}
+	if agentType == types.AgentTypeSummary {
+		return plan, nil
+	}
+
+	for _, t := range selectToolsForAgent(vg.mcpTools, agentType) {
+		info, err := t.Info(ctx)
+		if err != nil {
+			continue
+		}
+		plan.Tools = append(plan.Tools, info.Name)
+	}
+	return plan, nil
+}
@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"video_agent/internal/agent/types"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func TestPlanDoesNotExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		intent    string
+		query     string
+		wantAgent types.AgentType
+		wantTools []string
+		wantVideo string
+	}{
+		{
+			name:      "report selects video tools",
+			intent:    "Report",
+			query:     "分析一下视频12345的数据",
+			wantAgent: types.AgentTypeReport,
+			wantTools: []string{"get_video_stats", "get_user_profile"},
+			wantVideo: "12345",
+		},
+		{name: "chat selects no tools", intent: "Chat", query: "你好", wantAgent: types.AgentTypeSummary, wantTools: []string{}},
+		{name: "unrecognized intent falls back to chat", intent: "我不确定", query: "嗯", wantAgent: types.AgentTypeSummary, wantTools: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := newRecordingModel(tt.intent)
+			vg, err := NewVideoGraph(llm, nil, WithIntentRetries(0))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			tools := []*namedTool{{name: "get_video_stats"}, {name: "get_user_profile"}, {name: "publish_draft"}}
+			vg.mcpTools = []tool.BaseTool{tools[0], tools[1], tools[2]}
+
+			plan, err := vg.Plan(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			if plan.Agent != tt.wantAgent || plan.VideoID != tt.wantVideo || !reflect.DeepEqual(plan.Tools, tt.wantTools) {
+				t.Errorf("plan = %+v, want agent %s, tools %v, video %q", plan, tt.wantAgent, tt.wantTools, tt.wantVideo)
+			}
+			if llm.Calls() != 1 {
+				t.Errorf("model called %d times, want only intent recognition", llm.Calls())
+			}
+			for _, tl := range tools {
+				if tl.calls != 0 {
+					t.Errorf("tool %s executed %d times during dry-run", tl.name, tl.calls)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	agentprompt "video_agent/internal/agent/prompt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestPromptRegistryOverrides(t *testing.T) {
+	prompts := agentprompt.NewRegistry()
+	if err := prompts.Override(agentprompt.TemplateIntent, "只输出意图类型。查询：{query}"); err != nil {
+		t.Fatalf("Override intent: %v", err)
+	}
+	if err := prompts.Override(agentprompt.TemplateReport, "你是实验组的数据分析师"); err != nil {
+		t.Fatalf("Override report: %v", err)
+	}
+
+	intentModel := newRecordingModel("Report")
+	reportModel := newRecordingModel("report")
+	vg, err := NewVideoGraph(newRecordingModel("default"), nil,
+		WithPromptRegistry(prompts),
+		WithNodeModels(map[string]model.ChatModel{NodeIntentModel: intentModel, NodeReportAgent: reportModel}))
+	if err != nil {
+		t.Fatalf("NewVideoGraph: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, _, err := vg.recognizeIntent(ctx, "分析视频12345"); err != nil {
+		t.Fatalf("recognizeIntent: %v", err)
+	}
+	if got := intentModel.Inputs()[0][0]; got.Role != schema.System || got.Content != "只输出意图类型。查询：分析视频12345" {
+		t.Errorf("intent system prompt = %q, want the rendered override", got.Content)
+	}
+
+	if _, err := vg.AnalyzeVideo(ctx, "s1", "u1", "12345", ""); err != nil {
+		t.Fatalf("AnalyzeVideo: %v", err)
+	}
+	if got := reportModel.Inputs()[0][0]; got.Role != schema.System || !strings.HasPrefix(got.Content, "你是实验组的数据分析师") {
+		t.Errorf("report system prompt = %q, want it to start with the override", got.Content)
+	}
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestIntentNode(t *testing.T) {
+	tests := []struct {
+		intent string
+		want   string
+	}{
+		{"Report", NodeReportAgent},
+		{"Competitor", NodeCompetitorAgent},
+		{"Creative", NodeCreativeAnalysisAgent},
+		{"RAG", NodeRAGSelectorAgent},
+		{"CommentAnalysis", NodeCommentAnalysisAgent},
+		{"VideoRecommend", NodeVideoRecommendAgent},
+		{"UserLikedVideos", NodeUserLikedVideosAgent},
+		{"HotVideo", NodeHotVideoAgent},
+		{"HotLive", NodeHotLiveAgent},
+		{"VideoSummary", NodeVideoSummaryAgent},
+		{"Chat", NodeSummary},
+		{"", NodeSummary},
+	}
+	for _, tt := range tests {
+		if got := intentNode(tt.intent); got != tt.want {
+			t.Errorf("intentNode(%q) = %s, want %s", tt.intent, got, tt.want)
+		}
+	}
+}
+
+func TestIntentRouting(t *testing.T) {
+	tests := []struct {
+		name         string
+		intent       string
+		unregister   bool
+		wantReport   bool
+		wantCompeted bool
+	}{
+		{name: "report intent runs the report agent", intent: "Report", wantReport: true},
+		{name: "competitor intent runs the competitor agent", intent: "Competitor", wantCompeted: true},
+		{name: "unregistered agent falls back to summary", intent: "Competitor", unregister: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportModel := newRecordingModel("报告分析")
+			competitorModel := newRecordingModel("竞品对比")
+			summaryModel := newRecordingModel("最终回答")
+			vg, err := NewVideoGraph(summaryModel, nil, WithNodeModels(map[string]model.ChatModel{
+				NodeIntentModel:     newRecordingModel(tt.intent),
+				NodeReportAgent:     reportModel,
+				NodeCompetitorAgent: competitorModel,
+			}))
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+			if tt.unregister {
+				vg.competitorAgent = nil
+				if err := vg.buildGraph(); err != nil {
+					t.Fatalf("buildGraph without the competitor agent: %v", err)
+				}
+			}
+
+			out, err := vg.Run(context.Background(), []*schema.Message{schema.UserMessage("对比一下账号10086和10010")})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if len(out) == 0 {
+				t.Fatal("Run returned no messages")
+			}
+			if ran := reportModel.Calls() > 0; ran != tt.wantReport {
+				t.Errorf("report agent ran = %v, want %v", ran, tt.wantReport)
+			}
+			if ran := competitorModel.Calls() > 0; ran != tt.wantCompeted {
+				t.Errorf("competitor agent ran = %v, want %v", ran, tt.wantCompeted)
+			}
+			if summaryModel.Calls() == 0 {
+				t.Error("summary node did not answer")
+			}
+		})
+	}
+}
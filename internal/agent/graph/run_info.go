@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"video_agent/internal/agent/types"
+)
+
+// RunInfo 记录一次图执行的中间结果（识别的意图、各 Agent 的输出、支撑回答的知识库文档），供调用方在 Run 返回后读取
+type RunInfo struct {
+	mu      sync.Mutex
+	intent  string
+	results map[types.AgentType]*types.AgentResult
+	sources []types.RAGDocument
+}
+
+type runInfoKey struct{}
+
+// WithRunInfo 返回携带 RunInfo 的 context，使用该 context 调用 Run 后即可读取执行详情
+func WithRunInfo(ctx context.Context) (context.Context, *RunInfo) {
+	info := &RunInfo{results: make(map[types.AgentType]*types.AgentResult)}
+	return context.WithValue(ctx, runInfoKey{}, info), info
+}
+
+func runInfoFrom(ctx context.Context) *RunInfo {
+	info, _ := ctx.Value(runInfoKey{}).(*RunInfo)
+	return info
+}
+
+func (r *RunInfo) setIntent(intent string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.intent = intent
+}
+
+func (r *RunInfo) setResult(agentType types.AgentType, result *types.AgentResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[agentType] = result
+}
+
+func (r *RunInfo) setSources(docs []types.RAGDocument) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = docs
+}
+
+// Intent 识别的意图类型（如 Report、Chat），未识别时为空
+func (r *RunInfo) Intent() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.intent
+}
+
+// Result 指定 Agent 的执行结果
+func (r *RunInfo) Result(agentType types.AgentType) (*types.AgentResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[agentType]
+	return result, ok
+}
+
+// Sources 支撑回答的知识库文档，顺序与回答中的引用编号 [n] 一致；未检索知识库时为空
+func (r *RunInfo) Sources() []types.RAGDocument {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sources
+}
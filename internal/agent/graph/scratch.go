@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"context"
+
+	"video_agent/internal/memory"
+)
+
+// scratchVideoID 会话最近一次解析出的视频 ID 在 Scratchpad 中的键
+const scratchVideoID = "video_id"
+
+// resolveVideoID 返回查询中的视频 ID 并记入 Scratchpad；查询中没有时（如追问“那评论呢”）
+// 沿用本会话之前解析出的视频 ID，未挂载 Scratchpad 时只从查询中提取
+func resolveVideoID(ctx context.Context, query string) string {
+	videoID := videoIDPattern.FindString(query)
+	if videoID == "" {
+		value, _ := memory.ScratchpadFrom(ctx).Get(scratchVideoID)
+		videoID, _ = value.(string)
+		return videoID
+	}
+	rememberVideoID(ctx, videoID)
+	return videoID
+}
+
+// rememberVideoID 将视频 ID 记入 Scratchpad 并在执行结束后保留，供同一会话后续的执行沿用
+func rememberVideoID(ctx context.Context, videoID string) {
+	pad := memory.ScratchpadFrom(ctx)
+	pad.Set(scratchVideoID, videoID)
+	pad.Persist(scratchVideoID)
+}
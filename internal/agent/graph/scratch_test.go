@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"video_agent/internal/memory"
+)
+
+func TestResolveVideoID(t *testing.T) {
+	tests := []struct {
+		name string
+		// previous 之前的执行中解析出的视频 ID，为空表示没有
+		previous string
+		query    string
+		want     string
+	}{
+		{name: "id in query", query: "分析一下视频12345", want: "12345"},
+		{name: "follow-up reuses earlier id", previous: "12345", query: "那评论呢", want: "12345"},
+		{name: "new id replaces earlier one", previous: "12345", query: "再看看67890", want: "67890"},
+		{name: "no id anywhere", query: "你好", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			working := memory.NewWorkingMemory(10)
+			if tt.previous != "" {
+				ctx, pad := memory.WithScratchpad(context.Background(), working, "s1")
+				resolveVideoID(ctx, "视频"+tt.previous)
+				pad.Release()
+			}
+
+			ctx, pad := memory.WithScratchpad(context.Background(), working, "s1")
+			if got := resolveVideoID(ctx, tt.query); got != tt.want {
+				t.Errorf("resolveVideoID = %q, want %q", got, tt.want)
+			}
+			pad.Release()
+
+			if got, _ := working.Get("s1", scratchVideoID); tt.want != "" && got != tt.want {
+				t.Errorf("persisted video id = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVideoIDWithoutScratchpad(t *testing.T) {
+	if got := resolveVideoID(context.Background(), "那评论呢"); got != "" {
+		t.Errorf("resolveVideoID = %q, want empty", got)
+	}
+}
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"video_agent/internal/agent/agents/report"
+)
+
+func TestStructureReportRepair(t *testing.T) {
+	const valid = `{"summary":"播放量稳步增长","metrics":{"views":1000},"sentiment":"positive","key_points":["完播率高"],"suggestions":["保持更新"]}`
+
+	tests := []struct {
+		name      string
+		replies   []string
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "valid output needs no repair", replies: []string{valid}, wantCalls: 1},
+		{name: "malformed output is repaired", replies: []string{`{"summary":"播放量稳步增长","sentiment":"很好"}`, valid}, wantCalls: 2},
+		{name: "repair fails", replies: []string{"报告如下", `{"summary":""}`}, wantCalls: 2, wantErr: report.ErrInvalidStructuredOutput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := newRecordingModel(tt.replies...)
+			vg, err := NewVideoGraph(llm, nil)
+			if err != nil {
+				t.Fatalf("NewVideoGraph: %v", err)
+			}
+
+			got, err := vg.StructureReport(context.Background(), "视频 BV1 的分析报告")
+			if llm.Calls() != tt.wantCalls {
+				t.Errorf("model called %d times, want %d", llm.Calls(), tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StructureReport: %v", err)
+			}
+			if got.Summary != "播放量稳步增长" || got.Sentiment != report.SentimentPositive || got.Metrics["views"] != 1000 {
+				t.Errorf("analysis = %+v, want the repaired fields", got)
+			}
+			if tt.wantCalls == 2 {
+				// 修复请求带上首次输出与解析错误
+				repair := llm.Inputs()[1]
+				if last := repair[len(repair)-1]; last.Content == "" || repair[len(repair)-2].Content != tt.replies[0] {
+					t.Errorf("repair request = %v, want the first output and a repair prompt", repair)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	base "video_agent/internal/agent/agents/base"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// namedTool 只有名称的工具，记录被调用的次数
+type namedTool struct {
+	name  string
+	calls int
+}
+
+func (t *namedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name}, nil
+}
+
+func (t *namedTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	t.calls++
+	return "{}", nil
+}
+
+func toolNames(t *testing.T, tools []tool.BaseTool) []string {
+	t.Helper()
+	var names []string
+	for _, tl := range tools {
+		info, err := tl.Info(context.Background())
+		if err != nil {
+			t.Fatalf("Info: %v", err)
+		}
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestFilterTools(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []GraphOption
+		want []string
+	}{
+		{name: "no lists keeps every tool", want: []string{"get_video_stats", "get_video_comments", "delete_video"}},
+		{name: "allowlist", opts: []GraphOption{WithToolAllowlist("get_video_stats", "get_video_comments")}, want: []string{"get_video_stats", "get_video_comments"}},
+		{name: "denylist", opts: []GraphOption{WithToolDenylist("delete_video")}, want: []string{"get_video_stats", "get_video_comments"}},
+		{
+			name: "denylist wins over allowlist",
+			opts: []GraphOption{WithToolAllowlist("get_video_stats", "delete_video"), WithToolDenylist("delete_video")},
+			want: []string{"get_video_stats"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &graphOptions{}
+			for _, opt := range tt.opts {
+				opt(options)
+			}
+			tools := []tool.BaseTool{&namedTool{name: "get_video_stats"}, &namedTool{name: "get_video_comments"}, &namedTool{name: "delete_video"}}
+
+			if got := toolNames(t, options.filterTools(context.Background(), tools)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filtered tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// hallucinatingModel 首次调用请求 toolName，之后给出最终回答；记录绑定的工具与每次调用的输入
+type hallucinatingModel struct {
+	toolName string
+	bound    []string
+	inputs   [][]*schema.Message
+}
+
+func (m *hallucinatingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.inputs = append(m.inputs, input)
+	if len(m.inputs) == 1 {
+		return schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       "call-1",
+			Function: schema.FunctionCall{Name: m.toolName, Arguments: `{"video_id":"BV1"}`},
+		}}), nil
+	}
+	return schema.AssistantMessage("无法删除视频", nil), nil
+}
+
+func (m *hallucinatingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *hallucinatingModel) BindTools(tools []*schema.ToolInfo) error {
+	m.bound = nil
+	for _, info := range tools {
+		m.bound = append(m.bound, info.Name)
+	}
+	return nil
+}
+
+func TestDeniedToolIsRefused(t *testing.T) {
+	options := &graphOptions{}
+	WithToolDenylist("delete_video")(options)
+	stats := &namedTool{name: "get_video_stats"}
+	denied := &namedTool{name: "delete_video"}
+	tools := options.filterTools(context.Background(), []tool.BaseTool{stats, denied})
+
+	llm := &hallucinatingModel{toolName: "delete_video"}
+	_, run, err := base.NewToolExecutor(tools, llm).ExecuteWithTools(context.Background(), []*schema.Message{schema.UserMessage("删除BV1")})
+	if err != nil {
+		t.Fatalf("ExecuteWithTools: %v", err)
+	}
+
+	if !reflect.DeepEqual(llm.bound, []string{"get_video_stats"}) {
+		t.Errorf("tools offered to the model = %v, want only get_video_stats", llm.bound)
+	}
+	if denied.calls != 0 {
+		t.Errorf("denied tool executed %d times", denied.calls)
+	}
+	if len(run.ToolsUsed) != 0 || len(run.Errors) != 1 {
+		t.Errorf("run = %+v, want the hallucinated call refused", run)
+	}
+	if len(llm.inputs) != 2 {
+		t.Fatalf("model called %d times, want the refusal sent back", len(llm.inputs))
+	}
+	var refused bool
+	for _, msg := range llm.inputs[1] {
+		if msg.Role == schema.Tool && strings.Contains(msg.Content, "delete_video") && strings.Contains(msg.Content, "不可用") {
+			refused = true
+		}
+	}
+	if !refused {
+		t.Error("second round has no refusal for delete_video")
+	}
+}
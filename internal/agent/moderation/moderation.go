@@ -0,0 +1,100 @@
+// Package moderation 对模型输出做内容安全审核，支持放行、脱敏和拦截
+package moderation
+
+import (
+	"context"
+	"strings"
+)
+
+// BlockedReply 内容被拦截时返回给用户的兜底回复
+const BlockedReply = "抱歉，这个问题我暂时无法回答，换个话题试试吧～"
+
+// Action 审核结论
+type Action int
+
+const (
+	// ActionAllow 原样返回
+	ActionAllow Action = iota
+	// ActionRedact 返回脱敏后的内容
+	ActionRedact
+	// ActionBlock 拦截，返回兜底回复
+	ActionBlock
+)
+
+// Result 审核结果
+type Result struct {
+	Action Action
+	// Content 脱敏后的内容，仅 ActionRedact 时有效
+	Content string
+	// Reason 命中原因，用于日志
+	Reason string
+}
+
+// Moderator 审核一段待返回给用户的文本
+type Moderator interface {
+	Moderate(ctx context.Context, content string) (Result, error)
+}
+
+// Nop 不做任何审核的默认实现
+type Nop struct{}
+
+func (Nop) Moderate(ctx context.Context, content string) (Result, error) {
+	return Result{Action: ActionAllow}, nil
+}
+
+// KeywordModerator 基于敏感词表的审核：命中时按 redact 配置脱敏为 *** 或整条拦截
+type KeywordModerator struct {
+	keywords []string
+	redact   bool
+}
+
+// NewKeywordModerator 创建敏感词审核，空白词会被忽略
+func NewKeywordModerator(keywords []string, redact bool) *KeywordModerator {
+	m := &KeywordModerator{redact: redact}
+	for _, kw := range keywords {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			m.keywords = append(m.keywords, kw)
+		}
+	}
+	return m
+}
+
+func (m *KeywordModerator) Moderate(ctx context.Context, content string) (Result, error) {
+	var hits []string
+	for _, kw := range m.keywords {
+		if strings.Contains(content, kw) {
+			hits = append(hits, kw)
+		}
+	}
+	if len(hits) == 0 {
+		return Result{Action: ActionAllow}, nil
+	}
+
+	reason := "命中敏感词: " + strings.Join(hits, ",")
+	if !m.redact {
+		return Result{Action: ActionBlock, Reason: reason}, nil
+	}
+	for _, kw := range hits {
+		content = strings.ReplaceAll(content, kw, strings.Repeat("*", len([]rune(kw))))
+	}
+	return Result{Action: ActionRedact, Content: content, Reason: reason}, nil
+}
+
+// Apply 审核并返回最终可展示的内容；审核服务出错时按拦截处理，避免未审核内容直接返回
+func Apply(ctx context.Context, m Moderator, content string) (string, Result, error) {
+	if m == nil {
+		return content, Result{Action: ActionAllow}, nil
+	}
+	result, err := m.Moderate(ctx, content)
+	if err != nil {
+		return BlockedReply, Result{Action: ActionBlock, Reason: "审核失败"}, err
+	}
+	switch result.Action {
+	case ActionRedact:
+		return result.Content, result, nil
+	case ActionBlock:
+		return BlockedReply, result, nil
+	default:
+		return content, result, nil
+	}
+}
@@ -0,0 +1,36 @@
+// Package progress 在图执行过程中向流式接口上报阶段进度（识别意图、调用工具、生成分析）
+package progress
+
+import "context"
+
+// 执行阶段
+const (
+	PhaseIntent   = "intent"
+	PhaseTool     = "tool"
+	PhaseGenerate = "generate"
+	// PhaseContent 回复内容片段，非进度事件
+	PhaseContent = "content"
+)
+
+// Event 进度事件
+type Event struct {
+	Phase   string
+	Message string
+}
+
+// Reporter 接收进度事件，可能被多个节点并发调用，实现不应阻塞
+type Reporter func(Event)
+
+type reporterKey struct{}
+
+// WithReporter 在 context 中注册进度接收方
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// Report 上报进度，context 中没有接收方时忽略
+func Report(ctx context.Context, phase, message string) {
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok && r != nil {
+		r(Event{Phase: phase, Message: message})
+	}
+}
@@ -0,0 +1,61 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OutputFormat 分析报告的输出格式，通过追加格式指令控制模型输出
+type OutputFormat string
+
+const (
+	// OutputFormatMarkdown 带标题与表格的 Markdown 报表，ReportAgentPrompt 的默认格式
+	OutputFormatMarkdown OutputFormat = "markdown"
+	// OutputFormatPlain 不含 Markdown 语法的纯文本，适合不渲染 Markdown 的客户端
+	OutputFormatPlain OutputFormat = "plain"
+	// OutputFormatJSON 符合 StructuredAnalysisPrompt Schema 的 JSON 对象
+	OutputFormatJSON OutputFormat = "json"
+)
+
+// ErrUnsupportedOutputFormat 请求的输出格式不在支持列表中
+var ErrUnsupportedOutputFormat = errors.New("unsupported output format")
+
+// outputFormatInstructions 各格式追加到系统提示词末尾的指令，Markdown 为默认格式无需追加
+var outputFormatInstructions = map[OutputFormat]string{
+	OutputFormatMarkdown: "",
+	OutputFormatPlain: `输出格式：纯文本。仍按上述报表的各部分组织内容，但不要使用任何 Markdown 语法（#标题、表格、**加粗**、代码块）；
+每部分以“概览摘要：”“核心数据指标：”这样的文字小标题开头，数据指标写成“播放量：879”的形式，每项一行。`,
+	OutputFormatJSON: `输出格式：JSON。忽略上面的报表结构要求，只输出一个 JSON 对象，不要输出 Markdown 代码块或任何解释文字，Schema 如下：
+{
+  "summary": "string，1-2句话的整体表现总结（必填）",
+  "metrics": {"指标名": number，如 "views": 12000, "likes": 800, "comments": 120, "engagement_rate": 0.077},
+  "sentiment": "positive | neutral | negative 之一（必填）",
+  "key_points": ["string，关键发现"],
+  "suggestions": ["string，优化建议"]
+}
+metrics 的值必须是数字，不要带单位；没有的数据不要编造。`,
+}
+
+// ParseOutputFormat 解析输出格式（不区分大小写，md 视为 markdown、text 视为 plain），为空时返回空字符串表示未指定
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		return "", nil
+	case "md":
+		return OutputFormatMarkdown, nil
+	case "text":
+		return OutputFormatPlain, nil
+	}
+	format := OutputFormat(value)
+	if _, ok := outputFormatInstructions[format]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedOutputFormat, value)
+	}
+	return format, nil
+}
+
+// OutputFormatInstruction 返回格式指令，Markdown 或未指定时为空
+func OutputFormatInstruction(format OutputFormat) string {
+	return outputFormatInstructions[format]
+}
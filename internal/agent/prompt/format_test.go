@@ -0,0 +1,50 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    OutputFormat
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "markdown", want: OutputFormatMarkdown},
+		{value: " MD ", want: OutputFormatMarkdown},
+		{value: "plain", want: OutputFormatPlain},
+		{value: "Text", want: OutputFormatPlain},
+		{value: "JSON", want: OutputFormatJSON},
+		{value: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOutputFormat(tt.value)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrUnsupportedOutputFormat)) {
+				t.Fatalf("ParseOutputFormat(%q) err = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOutputFormat(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFormatInstruction(t *testing.T) {
+	tests := []struct {
+		format    OutputFormat
+		wantEmpty bool
+	}{
+		{format: "", wantEmpty: true},
+		{format: OutputFormatMarkdown, wantEmpty: true},
+		{format: OutputFormatPlain},
+		{format: OutputFormatJSON},
+	}
+	for _, tt := range tests {
+		if got := OutputFormatInstruction(tt.format); (got == "") != tt.wantEmpty {
+			t.Errorf("OutputFormatInstruction(%q) = %q, want empty %v", tt.format, got, tt.wantEmpty)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Language 回复语言，提示词本身保持中文，通过追加语言指令控制输出语言
+type Language string
+
+const (
+	LanguageChinese  Language = "zh"
+	LanguageEnglish  Language = "en"
+	LanguageJapanese Language = "ja"
+)
+
+// ErrUnsupportedLanguage 请求的输出语言不在支持列表中
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// languageInstructions 各语言追加到系统提示词末尾的指令，中文为默认语言无需追加
+var languageInstructions = map[Language]string{
+	LanguageChinese:  "",
+	LanguageEnglish:  "Respond entirely in English, including headings and data labels, regardless of the language used above.",
+	LanguageJapanese: "見出しやデータ項目を含め、すべて日本語で回答してください。",
+}
+
+// ParseLanguage 解析语言代码（不区分大小写，支持 zh-CN / en-US 形式），为空时返回空字符串表示未指定
+func ParseLanguage(code string) (Language, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return "", nil
+	}
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	lang := Language(code)
+	if _, ok := languageInstructions[lang]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedLanguage, code)
+	}
+	return lang, nil
+}
+
+// DetectLanguage 按查询中的文字判断语言：含假名为日语，含汉字为中文，以拉丁字母为主为英语，否则默认中文
+func DetectLanguage(text string) Language {
+	var han, latin int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return LanguageJapanese
+		case unicode.Is(unicode.Han, r):
+			han++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+	if han == 0 && latin > 0 {
+		return LanguageEnglish
+	}
+	return LanguageChinese
+}
+
+// LanguageInstruction 返回语言指令，中文或未指定时为空
+func LanguageInstruction(lang Language) string {
+	return languageInstructions[lang]
+}
@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    Language
+		wantErr error
+	}{
+		{code: "", want: ""},
+		{code: "en", want: LanguageEnglish},
+		{code: " EN-us ", want: LanguageEnglish},
+		{code: "zh_CN", want: LanguageChinese},
+		{code: "ja", want: LanguageJapanese},
+		{code: "fr", wantErr: ErrUnsupportedLanguage},
+	}
+	for _, tt := range tests {
+		got, err := ParseLanguage(tt.code)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseLanguage(%q) = %q, %v; want %q, %v", tt.code, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want Language
+	}{
+		{text: "分析一下视频12345的数据", want: LanguageChinese},
+		{text: "Analyze video 12345 for me", want: LanguageEnglish},
+		{text: "この動画を分析して", want: LanguageJapanese},
+		{text: "分析 BV1xx video", want: LanguageChinese},
+		{text: "12345", want: LanguageChinese},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
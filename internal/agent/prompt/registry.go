@@ -0,0 +1,144 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 可通过 Registry 覆盖的模板名
+const (
+	TemplateIntent           = "intent"
+	TemplateReport           = "report"
+	TemplateStructured       = "structured_analysis"
+	TemplateStructuredRepair = "structured_repair"
+)
+
+// templateFileExt 模板目录中覆盖文件的扩展名，文件名即模板名，如 intent.tmpl
+const templateFileExt = ".tmpl"
+
+var (
+	// ErrUnknownTemplate 模板名未注册
+	ErrUnknownTemplate = errors.New("unknown prompt template")
+	// ErrMissingTemplateVar 模板缺少必需的 {变量} 占位符，或渲染时未提供变量值
+	ErrMissingTemplateVar = errors.New("missing prompt template variable")
+)
+
+// templateSpec 模板文本及其必须包含的 {变量} 占位符
+type templateSpec struct {
+	text     string
+	required []string
+}
+
+// defaultTemplates 内置默认模板，覆盖时必须保留相同的必需变量
+var defaultTemplates = map[string]templateSpec{
+	TemplateIntent:           {text: IntentPrompt, required: []string{"query"}},
+	TemplateReport:           {text: ReportAgentPrompt},
+	TemplateStructured:       {text: StructuredAnalysisPrompt},
+	TemplateStructuredRepair: {text: StructuredAnalysisRepairPrompt, required: []string{"error", "output"}},
+}
+
+// Registry 提示词模板注册表（模板名 -> 参数化模板），模板中的 {name} 在渲染时替换为变量值。
+// 以内置默认模板初始化，可在启动时按名称覆盖，便于 A/B 测试与本地化
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]templateSpec
+}
+
+// NewRegistry 创建只包含内置默认模板的注册表
+func NewRegistry() *Registry {
+	templates := make(map[string]templateSpec, len(defaultTemplates))
+	for name, spec := range defaultTemplates {
+		templates[name] = spec
+	}
+	return &Registry{templates: templates}
+}
+
+// LoadRegistry 创建注册表并以 dir 下的 <模板名>.tmpl 文件覆盖默认模板，
+// 未知模板名或缺少必需变量时返回错误；dir 为空时只使用默认模板
+func LoadRegistry(dir string) (*Registry, error) {
+	r := NewRegistry()
+	if dir == "" {
+		return r, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+templateFileExt))
+	if err != nil {
+		return nil, fmt.Errorf("list prompt templates: %w", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read prompt template %s: %w", file, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(file), templateFileExt)
+		if err := r.Override(name, string(data)); err != nil {
+			return nil, fmt.Errorf("load prompt template %s: %w", file, err)
+		}
+	}
+	return r, nil
+}
+
+// Override 覆盖指定模板，模板必须已注册且包含全部必需变量
+func (r *Registry) Override(name, text string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spec, ok := r.templates[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	if missing := missingPlaceholders(text, spec.required); len(missing) > 0 {
+		return fmt.Errorf("%w: template %s requires {%s}", ErrMissingTemplateVar, name, strings.Join(missing, "}, {"))
+	}
+	spec.text = text
+	r.templates[name] = spec
+	return nil
+}
+
+// Render 渲染模板，vars 必须提供全部必需变量；未声明的 {xxx}（如 JSON 示例）原样保留
+func (r *Registry) Render(name string, vars map[string]string) (string, error) {
+	r.mu.RLock()
+	spec, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	for _, v := range spec.required {
+		if _, ok := vars[v]; !ok {
+			return "", fmt.Errorf("%w: %s for template %s", ErrMissingTemplateVar, v, name)
+		}
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(vars)*2)
+	for _, k := range keys {
+		pairs = append(pairs, "{"+k+"}", vars[k])
+	}
+	return strings.NewReplacer(pairs...).Replace(spec.text), nil
+}
+
+// Text 返回不含变量的模板文本，模板未注册时返回空字符串
+func (r *Registry) Text(name string) string {
+	text, _ := r.Render(name, nil)
+	return text
+}
+
+func missingPlaceholders(text string, required []string) []string {
+	var missing []string
+	for _, v := range required {
+		if !strings.Contains(text, "{"+v+"}") {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,88 @@
+package prompt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		text     string
+		wantErr  error
+		vars     map[string]string
+		want     string
+	}{
+		{name: "override renders", template: TemplateIntent, text: "识别意图：{query}", vars: map[string]string{"query": "你好"}, want: "识别意图：你好"},
+		{name: "template without variables", template: TemplateReport, text: "你是数据分析师", want: "你是数据分析师"},
+		{name: "unknown template", template: "greeting", text: "你好", wantErr: ErrUnknownTemplate},
+		{name: "missing required variable", template: TemplateStructuredRepair, text: "修复：{output}", wantErr: ErrMissingTemplateVar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			err := r.Override(tt.template, tt.text)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Override err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			got, err := r.Render(tt.template, tt.vars)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render = %q, want %q", got, tt.want)
+			}
+			// 覆盖只影响当前注册表
+			if NewRegistry().Text(TemplateReport) != ReportAgentPrompt {
+				t.Error("override leaked into the default templates")
+			}
+		})
+	}
+}
+
+func TestRegistryRenderRequiresVariables(t *testing.T) {
+	if _, err := NewRegistry().Render(TemplateIntent, nil); !errors.Is(err, ErrMissingTemplateVar) {
+		t.Errorf("Render without query err = %v, want %v", err, ErrMissingTemplateVar)
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr error
+		want    string
+	}{
+		{name: "no overrides", want: ReportAgentPrompt},
+		{name: "file overrides the default", files: map[string]string{"report.tmpl": "自定义报表提示词"}, want: "自定义报表提示词"},
+		{name: "unknown template file", files: map[string]string{"greeting.tmpl": "你好"}, wantErr: ErrUnknownTemplate},
+		{name: "override missing a variable", files: map[string]string{"intent.tmpl": "识别意图"}, wantErr: ErrMissingTemplateVar},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, text := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+					t.Fatalf("write %s: %v", name, err)
+				}
+			}
+
+			r, err := LoadRegistry(dir)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadRegistry err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got := r.Text(TemplateReport); got != tt.want {
+				t.Errorf("report template = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
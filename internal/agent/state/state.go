@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 
+	"video_agent/internal/agent/prompt"
 	types "video_agent/internal/agent/types"
 
 	"github.com/cloudwego/eino/schema"
@@ -46,6 +47,22 @@ type GraphState struct {
 	RAGDocuments []types.RAGDocument
 	RAGContext   string
 
+	// FrameContext 视觉模型生成的视频关键帧描述
+	FrameContext string
+
+	// GrowthContext 根据历史数据快照计算的环比增长
+	GrowthContext string
+
+	// Intent 识别的意图类型（如 Report），VideoID 为查询中提取的视频 ID，用于按意图选择降级回复
+	Intent  string
+	VideoID string
+
+	// Language 回复语言，为空时按中文输出
+	Language prompt.Language
+
+	// OutputFormat 分析报告的输出格式，为空时按 Markdown 输出
+	OutputFormat prompt.OutputFormat
+
 	// RAGSelection RAG知识库选择结果
 	RAGSelection interface{}
 
@@ -150,6 +167,56 @@ func (s *GraphState) GetRAGContext() string {
 	return s.RAGContext
 }
 
+// SetFrameContext 设置视频关键帧描述，会作为上下文提供给各 Agent
+func (s *GraphState) SetFrameContext(frameContext string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FrameContext = frameContext
+}
+
+// SetGrowthContext 设置历史数据环比增长，会作为上下文提供给各 Agent
+func (s *GraphState) SetGrowthContext(growthContext string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.GrowthContext = growthContext
+}
+
+// SetIntent 设置识别的意图与查询中的视频 ID
+func (s *GraphState) SetIntent(intent, videoID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Intent = intent
+	s.VideoID = videoID
+}
+
+// SetLanguage 设置回复语言
+func (s *GraphState) SetLanguage(lang prompt.Language) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Language = lang
+}
+
+// LanguageInstruction 当前回复语言对应的指令，中文时为空
+func (s *GraphState) LanguageInstruction() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return prompt.LanguageInstruction(s.Language)
+}
+
+// SetOutputFormat 设置分析报告的输出格式
+func (s *GraphState) SetOutputFormat(format prompt.OutputFormat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.OutputFormat = format
+}
+
+// OutputFormatInstruction 当前输出格式对应的指令，Markdown 时为空
+func (s *GraphState) OutputFormatInstruction() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return prompt.OutputFormatInstruction(s.OutputFormat)
+}
+
 func (s *GraphState) BuildAgentContext(targetAgent types.AgentType) string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -162,6 +229,18 @@ func (s *GraphState) BuildAgentContext(targetAgent types.AgentType) string {
 		sb.WriteString("\n")
 	}
 
+	if s.FrameContext != "" {
+		sb.WriteString("## 视频关键帧画面描述\n")
+		sb.WriteString(s.FrameContext)
+		sb.WriteString("\n")
+	}
+
+	if s.GrowthContext != "" {
+		sb.WriteString("## 历史数据环比增长（已计算，请直接引用）\n")
+		sb.WriteString(s.GrowthContext)
+		sb.WriteString("\n")
+	}
+
 	if s.Plan != nil {
 		for _, agentType := range s.Plan.ExecutionOrder {
 			if agentType == targetAgent {
@@ -178,6 +257,19 @@ func (s *GraphState) BuildAgentContext(targetAgent types.AgentType) string {
 	return sb.String()
 }
 
+// History 返回当前查询之前的会话历史消息（由调用方按上下文窗口裁剪后传入）
+func (s *GraphState) History() []*schema.Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.Messages) <= 1 {
+		return nil
+	}
+	history := make([]*schema.Message, len(s.Messages)-1)
+	copy(history, s.Messages[:len(s.Messages)-1])
+	return history
+}
+
 func (s *GraphState) BuildMessagesForAgent(systemPrompt string, targetAgent types.AgentType) []*schema.Message {
 	context := s.BuildAgentContext(targetAgent)
 
@@ -195,7 +287,14 @@ func (s *GraphState) BuildMessagesForAgent(systemPrompt string, targetAgent type
 		toolInstruction += fmt.Sprintf("\n当前可用的工具: %v", s.SelectedTools)
 	}
 	msgs = append(msgs, schema.SystemMessage(toolInstruction))
+	if instruction := s.LanguageInstruction(); instruction != "" {
+		msgs = append(msgs, schema.SystemMessage(instruction))
+	}
+	if instruction := s.OutputFormatInstruction(); instruction != "" {
+		msgs = append(msgs, schema.SystemMessage(instruction))
+	}
 
+	msgs = append(msgs, s.History()...)
 	msgs = append(msgs, schema.UserMessage(s.OriginalQuery))
 
 	return msgs
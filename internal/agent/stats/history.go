@@ -0,0 +1,136 @@
+// Package stats 解析视频历史数据快照并计算环比增长，作为周报与趋势分析的补充上下文
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"video_agent/internal/mcp"
+)
+
+// Point 某一时间点的视频数据快照
+type Point struct {
+	Date         string
+	ViewCount    float64
+	LikeCount    float64
+	CommentCount float64
+}
+
+// Growth 单项指标相对上一快照的变化
+type Growth struct {
+	Metric   string
+	Previous float64
+	Current  float64
+	// Percent 增长百分比，上一快照为 0 时无法计算，Comparable 为 false
+	Percent    float64
+	Comparable bool
+}
+
+// ParseHistory 从 get_video_stats_history 的工具输出中提取快照序列。
+// 兼容 MCP 结果包装（content[].text 中嵌套 JSON 或 base64，经 mcp.DecodeToolResult 解码）与网关的 data/points/series 等包装层，
+// 快照对象需至少包含 view_count、like_count、comment_count 之一
+func ParseHistory(output string) []Point {
+	var data interface{}
+	if payload, err := mcp.DecodeToolResult(output); err == nil {
+		data = payload
+	} else if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return nil
+	}
+	var points []Point
+	collectPoints(data, &points)
+	return points
+}
+
+func collectPoints(v interface{}, points *[]Point) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if p, ok := toPoint(val); ok {
+			*points = append(*points, p)
+			return
+		}
+		for _, item := range val {
+			collectPoints(item, points)
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectPoints(item, points)
+		}
+	case string:
+		// MCP 文本结果中嵌套的 JSON
+		trimmed := strings.TrimSpace(val)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var nested interface{}
+			if err := json.Unmarshal([]byte(trimmed), &nested); err == nil {
+				collectPoints(nested, points)
+			}
+		}
+	}
+}
+
+func toPoint(m map[string]interface{}) (Point, bool) {
+	views, hasViews := m["view_count"].(float64)
+	likes, hasLikes := m["like_count"].(float64)
+	comments, hasComments := m["comment_count"].(float64)
+	if !hasViews && !hasLikes && !hasComments {
+		return Point{}, false
+	}
+
+	var date string
+	for _, key := range []string{"date", "time", "timestamp"} {
+		switch d := m[key].(type) {
+		case string:
+			date = d
+		case float64:
+			date = fmt.Sprintf("%.0f", d)
+		}
+		if date != "" {
+			break
+		}
+	}
+	return Point{Date: date, ViewCount: views, LikeCount: likes, CommentCount: comments}, true
+}
+
+// ComputeGrowth 计算最后一个快照相对前一个快照的各项指标变化，快照不足两个时返回 nil
+func ComputeGrowth(points []Point) []Growth {
+	if len(points) < 2 {
+		return nil
+	}
+	prev, cur := points[len(points)-2], points[len(points)-1]
+	return []Growth{
+		newGrowth("播放量", prev.ViewCount, cur.ViewCount),
+		newGrowth("点赞数", prev.LikeCount, cur.LikeCount),
+		newGrowth("评论数", prev.CommentCount, cur.CommentCount),
+	}
+}
+
+func newGrowth(metric string, prev, cur float64) Growth {
+	g := Growth{Metric: metric, Previous: prev, Current: cur}
+	if prev != 0 {
+		g.Percent = (cur - prev) / prev * 100
+		g.Comparable = true
+	}
+	return g
+}
+
+// DescribeGrowth 将快照序列的环比增长格式化为分析上下文，无法计算时返回空字符串
+func DescribeGrowth(points []Point) string {
+	growth := ComputeGrowth(points)
+	if len(growth) == 0 {
+		return ""
+	}
+
+	prev, cur := points[len(points)-2], points[len(points)-1]
+	var sb strings.Builder
+	if prev.Date != "" && cur.Date != "" {
+		sb.WriteString(fmt.Sprintf("对比区间: %s -> %s\n", prev.Date, cur.Date))
+	}
+	for _, g := range growth {
+		if g.Comparable {
+			sb.WriteString(fmt.Sprintf("- %s: %.0f -> %.0f (%+.1f%%)\n", g.Metric, g.Previous, g.Current, g.Percent))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s: %.0f -> %.0f (上期为0，无法计算增长率)\n", g.Metric, g.Previous, g.Current))
+		}
+	}
+	return sb.String()
+}
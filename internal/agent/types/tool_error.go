@@ -0,0 +1,91 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ToolErrorCode 工具执行错误码，与 MCP Server 错误结果中的 error_code 一致
+type ToolErrorCode string
+
+const (
+	ToolErrorNotFound        ToolErrorCode = "not_found"
+	ToolErrorUnauthorized    ToolErrorCode = "unauthorized"
+	ToolErrorTimeout         ToolErrorCode = "timeout"
+	ToolErrorInvalidArgument ToolErrorCode = "invalid_argument"
+	ToolErrorInternal        ToolErrorCode = "internal"
+)
+
+// ToolError 单次工具调用的错误
+type ToolError struct {
+	Tool    string        `json:"tool"`
+	Code    ToolErrorCode `json:"code"`
+	Message string        `json:"message"`
+}
+
+// UserMessage 返回面向用户的错误说明
+func (e ToolError) UserMessage() string {
+	switch e.Code {
+	case ToolErrorNotFound:
+		return "未找到相关数据，请确认视频或用户ID是否正确"
+	case ToolErrorUnauthorized:
+		return "暂无权限访问该数据"
+	case ToolErrorTimeout:
+		return "数据服务响应超时，请稍后重试"
+	case ToolErrorInvalidArgument:
+		return "请求参数有误，请检查输入的ID或条件"
+	default:
+		return "数据服务暂时不可用，请稍后重试"
+	}
+}
+
+// ToolErrorFromErr 将工具调用返回的 Go error 归类为错误码。
+// eino MCP 工具在服务端返回错误结果时会把结果 JSON 拼在错误信息中，优先从中解析 error_code
+func ToolErrorFromErr(tool string, err error) *ToolError {
+	msg := err.Error()
+	if i := strings.Index(msg, "{"); i >= 0 {
+		if toolErr := ParseToolError(tool, msg[i:]); toolErr != nil {
+			return toolErr
+		}
+	}
+
+	code := ToolErrorInternal
+	if errors.Is(err, context.DeadlineExceeded) {
+		code = ToolErrorTimeout
+	}
+	return &ToolError{Tool: tool, Code: code, Message: msg}
+}
+
+// ParseToolError 解析 MCP 工具的错误结果（isError 为 true），text 中带 error_code 时使用对应错误码，
+// 否则归为 internal；非错误结果返回 nil
+func ParseToolError(tool, output string) *ToolError {
+	var result struct {
+		IsError bool `json:"isError"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil || !result.IsError {
+		return nil
+	}
+
+	toolErr := &ToolError{Tool: tool, Code: ToolErrorInternal}
+	if len(result.Content) == 0 {
+		return toolErr
+	}
+
+	text := result.Content[0].Text
+	toolErr.Message = text
+
+	var payload struct {
+		ErrorCode ToolErrorCode `json:"error_code"`
+		Message   string        `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &payload); err == nil && payload.ErrorCode != "" {
+		toolErr.Code = payload.ErrorCode
+		toolErr.Message = payload.Message
+	}
+	return toolErr
+}
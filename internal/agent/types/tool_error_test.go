@@ -0,0 +1,107 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mcpToolErr 模拟 eino MCP 工具在服务端返回错误结果时的错误信息
+func mcpToolErr(t *testing.T, code, message string) error {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]string{"error_code": code, "message": message})
+	result, err := json.Marshal(mcp.NewToolResultError(string(payload)))
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	return fmt.Errorf("failed to call mcp tool, mcp server return error: %s", result)
+}
+
+func TestToolErrorFromErr(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         func(t *testing.T) error
+		wantCode    ToolErrorCode
+		wantMessage string
+		wantUser    string
+	}{
+		{
+			name:        "not found",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "not_found", "视频不存在") },
+			wantCode:    ToolErrorNotFound,
+			wantMessage: "视频不存在",
+			wantUser:    "未找到相关数据，请确认视频或用户ID是否正确",
+		},
+		{
+			name:        "unauthorized",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "unauthorized", "无权访问") },
+			wantCode:    ToolErrorUnauthorized,
+			wantMessage: "无权访问",
+			wantUser:    "暂无权限访问该数据",
+		},
+		{
+			name:        "timeout reported by the server",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "timeout", "Gateway超时") },
+			wantCode:    ToolErrorTimeout,
+			wantMessage: "Gateway超时",
+			wantUser:    "数据服务响应超时，请稍后重试",
+		},
+		{
+			name:        "invalid argument",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "invalid_argument", "video_id 为空") },
+			wantCode:    ToolErrorInvalidArgument,
+			wantMessage: "video_id 为空",
+			wantUser:    "请求参数有误，请检查输入的ID或条件",
+		},
+		{
+			name:        "internal",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "internal", "数据库错误") },
+			wantCode:    ToolErrorInternal,
+			wantMessage: "数据库错误",
+			wantUser:    "数据服务暂时不可用，请稍后重试",
+		},
+		{
+			name:        "error result without a code",
+			err:         func(t *testing.T) error { return mcpToolErr(t, "", "未知错误") },
+			wantCode:    ToolErrorInternal,
+			wantMessage: `{"error_code":"","message":"未知错误"}`,
+			wantUser:    "数据服务暂时不可用，请稍后重试",
+		},
+		{
+			name:        "client deadline",
+			err:         func(t *testing.T) error { return fmt.Errorf("call tool: %w", context.DeadlineExceeded) },
+			wantCode:    ToolErrorTimeout,
+			wantMessage: "call tool: context deadline exceeded",
+			wantUser:    "数据服务响应超时，请稍后重试",
+		},
+		{
+			name:        "plain error",
+			err:         func(t *testing.T) error { return errors.New("connection refused") },
+			wantCode:    ToolErrorInternal,
+			wantMessage: "connection refused",
+			wantUser:    "数据服务暂时不可用，请稍后重试",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToolErrorFromErr("get_video_stats", tt.err(t))
+			if got.Tool != "get_video_stats" || got.Code != tt.wantCode || got.Message != tt.wantMessage {
+				t.Errorf("ToolErrorFromErr = %+v, want code %q message %q", got, tt.wantCode, tt.wantMessage)
+			}
+			if msg := got.UserMessage(); msg != tt.wantUser {
+				t.Errorf("UserMessage = %q, want %q", msg, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestParseToolErrorIgnoresSuccessfulResults(t *testing.T) {
+	result, _ := json.Marshal(mcp.NewToolResultText(`{"view":100}`))
+	if got := ParseToolError("get_video_stats", string(result)); got != nil {
+		t.Errorf("ParseToolError = %+v, want nil for a successful result", got)
+	}
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"strings"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
@@ -28,12 +29,13 @@ const (
 	AgentTypeSummary          AgentType = "summary"
 	AgentTypeEnd              AgentType = "end"
 	// 新增 Agent 类型
-	AgentTypeCommentAnalysis  AgentType = "comment_analysis"
-	AgentTypeVideoRecommend   AgentType = "video_recommend"
-	AgentTypeUserLikedVideos  AgentType = "user_liked_videos"
-	AgentTypeHotVideo         AgentType = "hot_video"
-	AgentTypeHotLive          AgentType = "hot_live"
-	AgentTypeVideoSummary     AgentType = "video_summary"
+	AgentTypeCommentAnalysis    AgentType = "comment_analysis"
+	AgentTypeVideoRecommend     AgentType = "video_recommend"
+	AgentTypeUserLikedVideos    AgentType = "user_liked_videos"
+	AgentTypeHotVideo           AgentType = "hot_video"
+	AgentTypeHotLive            AgentType = "hot_live"
+	AgentTypeVideoSummary       AgentType = "video_summary"
+	AgentTypeCompetitorAnalysis AgentType = "competitor_analysis"
 )
 
 // AllAgentTypes 所有可用的Agent类型
@@ -62,6 +64,16 @@ type AgentResult struct {
 	NextAgent AgentType         `json:"next_agent,omitempty"`
 	Error     string            `json:"error,omitempty"`
 	ToolCalls []schema.ToolCall `json:"tool_calls,omitempty"`
+	// ToolErrors 本次执行中失败的工具调用及错误码
+	ToolErrors []ToolError `json:"tool_errors,omitempty"`
+	// ToolOutputs 本次执行中成功的工具调用输出
+	ToolOutputs []ToolOutput `json:"tool_outputs,omitempty"`
+}
+
+// ToolOutput 单次成功的工具调用输出（已解码的文本）
+type ToolOutput struct {
+	Tool   string `json:"tool"`
+	Output string `json:"output"`
 }
 
 // AgentConfig Agent配置
@@ -102,6 +114,31 @@ type RAGDocument struct {
 	Metadata map[string]any
 }
 
+// maxSourceSnippetRunes 来源片段的最大字符数
+const maxSourceSnippetRunes = 200
+
+// RAGSource 支撑回答的检索文档，Index 与回答中的引用编号 [n] 以及上下文中的文档编号一致
+type RAGSource struct {
+	Index   int     `json:"index"`
+	ID      string  `json:"id"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// NewRAGSource 按文档内容生成来源，内容过长时截断为片段；index 从 1 开始
+func NewRAGSource(index int, id, content string, score float64) RAGSource {
+	return RAGSource{Index: index, ID: id, Snippet: Snippet(content, maxSourceSnippetRunes), Score: score}
+}
+
+// Snippet 去除首尾空白后截断到 maxRunes 个字符，截断时追加 "..."
+func Snippet(content string, maxRunes int) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= maxRunes {
+		return string(runes)
+	}
+	return string(runes[:maxRunes]) + "..."
+}
+
 // RAGDocsRetriever RAG文档检索接口
 type RAGDocsRetriever interface {
 	RetrieveDocuments(ctx context.Context, query, sessionID string) ([]RAGDocument, error)
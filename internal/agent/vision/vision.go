@@ -0,0 +1,135 @@
+// Package vision 基于视觉模型为视频关键帧生成描述，作为视频分析的补充上下文
+package vision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"video_agent/internal/mcp"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxFrames 单次分析最多描述的关键帧数，避免视觉模型调用过多
+const maxFrames = 5
+
+const captionPrompt = "请用一到两句话客观描述这张视频画面的内容（人物、场景、文字、动作），不要推测画面之外的信息。"
+
+// Frame 视频关键帧
+type Frame struct {
+	URL string
+	// Timestamp 帧在视频中的时间点（秒），未知时为 0
+	Timestamp float64
+}
+
+// Captioner 为单个关键帧生成文字描述
+type Captioner interface {
+	Caption(ctx context.Context, frame Frame) (string, error)
+}
+
+// ModelCaptioner 使用支持图片输入的 ChatModel 生成描述
+type ModelCaptioner struct {
+	llm model.ChatModel
+}
+
+func NewModelCaptioner(llm model.ChatModel) *ModelCaptioner {
+	return &ModelCaptioner{llm: llm}
+}
+
+func (c *ModelCaptioner) Caption(ctx context.Context, frame Frame) (string, error) {
+	url := frame.URL
+	resp, err := c.llm.Generate(ctx, []*schema.Message{{
+		Role: schema.User,
+		UserInputMultiContent: []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeText, Text: captionPrompt},
+			{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{URL: &url},
+				Detail:            schema.ImageURLDetailLow,
+			}},
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// DescribeFrames 依次描述关键帧并拼接为分析上下文，单帧失败时跳过；没有可用描述时返回空字符串
+func DescribeFrames(ctx context.Context, captioner Captioner, frames []Frame) string {
+	if len(frames) > maxFrames {
+		frames = frames[:maxFrames]
+	}
+
+	var sb strings.Builder
+	for i, frame := range frames {
+		caption, err := captioner.Caption(ctx, frame)
+		if err != nil {
+			log.Printf("[Vision] caption frame %s failed: %v", frame.URL, err)
+			continue
+		}
+		if caption == "" {
+			continue
+		}
+		if frame.Timestamp > 0 {
+			sb.WriteString(fmt.Sprintf("[关键帧%d @%.1fs] %s\n", i+1, frame.Timestamp, caption))
+		} else {
+			sb.WriteString(fmt.Sprintf("[关键帧%d] %s\n", i+1, caption))
+		}
+	}
+	return sb.String()
+}
+
+// ParseFrames 从 get_video_thumbnails 的工具输出中提取关键帧。
+// 兼容 MCP 结果包装（content[].text 中嵌套 JSON 或 base64，经 mcp.DecodeToolResult 解码）与网关的多种字段格式：
+// 带 url/image_url/thumbnail 字段的对象，或直接的图片 URL 字符串
+func ParseFrames(output string) []Frame {
+	var data interface{}
+	if payload, err := mcp.DecodeToolResult(output); err == nil {
+		data = payload
+	} else if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return nil
+	}
+	var frames []Frame
+	collectFrames(data, &frames)
+	return frames
+}
+
+func collectFrames(v interface{}, frames *[]Frame) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{"url", "image_url", "thumbnail"} {
+			if url, ok := val[key].(string); ok && isHTTPURL(url) {
+				ts, _ := val["timestamp"].(float64)
+				*frames = append(*frames, Frame{URL: url, Timestamp: ts})
+				return
+			}
+		}
+		for _, item := range val {
+			collectFrames(item, frames)
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectFrames(item, frames)
+		}
+	case string:
+		if isHTTPURL(val) {
+			*frames = append(*frames, Frame{URL: val})
+			return
+		}
+		// MCP 文本结果中嵌套的 JSON
+		var nested interface{}
+		if strings.HasPrefix(strings.TrimSpace(val), "{") || strings.HasPrefix(strings.TrimSpace(val), "[") {
+			if err := json.Unmarshal([]byte(val), &nested); err == nil {
+				collectFrames(nested, frames)
+			}
+		}
+	}
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
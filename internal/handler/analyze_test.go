@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	agent_biz "video_agent/internal/agent/biz"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeChatModel 所有调用都返回固定回答，不触发工具调用
+type fakeChatModel struct {
+	answer string
+}
+
+func (m fakeChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return schema.AssistantMessage(m.answer, nil), nil
+}
+
+func (m fakeChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage(m.answer, nil)}), nil
+}
+
+func (fakeChatModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func newTestHandler(t *testing.T, answer string) *XiaovHandler {
+	t.Helper()
+	uc, err := agent_biz.NewVideoAssistantUsecase(nil, fakeChatModel{answer: answer}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	return NewXiaovHandler(uc)
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseSSE(body string) []sseEvent {
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			current.name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			current.data = strings.TrimPrefix(line, "data:")
+		case line == "" && current.name != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	if current.name != "" {
+		events = append(events, current)
+	}
+	return events
+}
+
+func TestAnalyzeVideo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	answer := strings.Repeat("播放量稳步增长。", 20)
+
+	tests := []struct {
+		name     string
+		body     string
+		stream   bool
+		wantCode int
+	}{
+		{name: "缺少 video_id", body: `{"video_id":"  "}`, wantCode: 400},
+		{name: "temperature 超出范围", body: `{"video_id":"BV1","temperature":2.5}`, wantCode: 400},
+		{name: "top_p 超出范围", body: `{"video_id":"BV1","top_p":0}`, wantCode: 400},
+		{name: "不支持的 output_format", body: `{"video_id":"BV1","output_format":"xml"}`, wantCode: 400},
+		{name: "纯文本格式", body: `{"video_id":"BV1","query":"分析播放数据","output_format":"plain"}`, wantCode: 200},
+		{name: "固定采样参数", body: `{"video_id":"BV1","query":"分析播放数据","temperature":0,"top_p":0.9,"seed":42}`, wantCode: 200},
+		{name: "一次性返回", body: `{"video_id":"BV1","query":"分析播放数据"}`, wantCode: 200},
+		{name: "SSE 分片返回", body: `{"video_id":"BV1","query":"分析播放数据","stream":true}`, stream: true, wantCode: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, answer)
+			r := gin.New()
+			h.RegisterRoutes(r)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/video/analyze", bytes.NewBufferString(tt.body)))
+
+			if !tt.stream {
+				var resp VideoAnalyzeResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v, body = %s", err, rec.Body.String())
+				}
+				if resp.Code != tt.wantCode {
+					t.Fatalf("code = %d, want %d (%s)", resp.Code, tt.wantCode, resp.Message)
+				}
+				if tt.wantCode == 200 && resp.Analysis != answer {
+					t.Errorf("analysis = %q, want %q", resp.Analysis, answer)
+				}
+				return
+			}
+
+			events := parseSSE(rec.Body.String())
+			if len(events) == 0 {
+				t.Fatalf("no SSE events, body = %s", rec.Body.String())
+			}
+			var content strings.Builder
+			messages := 0
+			for _, ev := range events[:len(events)-1] {
+				switch ev.name {
+				case "message":
+					messages++
+					content.WriteString(ev.data)
+				case "progress":
+				default:
+					t.Fatalf("unexpected event %q: %s", ev.name, ev.data)
+				}
+			}
+			if messages < 2 {
+				t.Errorf("got %d message events, want the report split into chunks", messages)
+			}
+			if content.String() != answer {
+				t.Errorf("streamed content = %q, want %q", content.String(), answer)
+			}
+
+			last := events[len(events)-1]
+			if last.name != "done" {
+				t.Fatalf("last event = %q, want done", last.name)
+			}
+			var done VideoAnalyzeResponse
+			if err := json.Unmarshal([]byte(last.data), &done); err != nil {
+				t.Fatalf("decode done event: %v", err)
+			}
+			if done.Code != 200 || done.SessionID == "" {
+				t.Errorf("done = %+v, want code 200 with a session id", done)
+			}
+		})
+	}
+}
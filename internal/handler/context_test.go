@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	agent_biz "video_agent/internal/agent/biz"
+	"video_agent/internal/logger"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// blockingChatModel 阻塞到 ctx 结束，并记录第一次观察到的 ctx 错误
+type blockingChatModel struct {
+	aborted chan error
+}
+
+func (m *blockingChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	<-ctx.Done()
+	select {
+	case m.aborted <- ctx.Err():
+	default:
+	}
+	return nil, ctx.Err()
+}
+
+func (m *blockingChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	_, err := m.Generate(ctx, input, opts...)
+	return nil, err
+}
+
+func (m *blockingChatModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestChatAbortsModelCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		// cancelAfter 大于 0 时模拟客户端在该时间后断开
+		cancelAfter time.Duration
+		wantErr     error
+	}{
+		{name: "request timeout", timeout: 50 * time.Millisecond, wantErr: context.DeadlineExceeded},
+		{name: "client disconnect", cancelAfter: 50 * time.Millisecond, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &blockingChatModel{aborted: make(chan error, 1)}
+			uc, err := agent_biz.NewVideoAssistantUsecase(nil, llm, nil, nil)
+			if err != nil {
+				t.Fatalf("NewVideoAssistantUsecase: %v", err)
+			}
+			h := NewXiaovHandler(uc)
+			h.SetRequestTimeout(tt.timeout)
+			r := gin.New()
+			h.RegisterRoutes(r)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/chat", bytes.NewBufferString(`{"session_id":"s1","message":"你好"}`)).WithContext(ctx)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}()
+
+			select {
+			case err := <-llm.aborted:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("model ctx err = %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("model call was not aborted")
+			}
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("handler did not return after the model call was aborted")
+			}
+		})
+	}
+}
+
+func TestSessionRoutesUseRequestContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "history", method: http.MethodGet, path: "/api/session/s1/history"},
+		{name: "clear", method: http.MethodDelete, path: "/api/session/s1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMemoryTestRouter(t, "播放量稳定增长")
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(logger.TraceIDHeader, "trace-session-1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(logger.TraceIDHeader); got != "trace-session-1" {
+				t.Errorf("%s = %q, want the request trace ID echoed", logger.TraceIDHeader, got)
+			}
+			var resp struct {
+				Code int `json:"code"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != 200 {
+				t.Errorf("response = %s, want code 200", rec.Body)
+			}
+		})
+	}
+}
+
+func TestClearSession(t *testing.T) {
+	r := newMemoryTestRouter(t, "播放量稳定增长")
+	for _, session := range []string{"s1", "s2"} {
+		if rec := streamChat(r, session, ""); rec.Code != http.StatusOK {
+			t.Fatalf("stream chat %s: status %d", session, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/session/s1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("clear session: status %d", rec.Code)
+	}
+
+	tests := []struct {
+		session string
+		want    int
+	}{
+		{session: "s1", want: 0},
+		{session: "s2", want: 2},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/session/"+tt.session+"/history", nil))
+		var resp SessionHistoryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode history: %v", err)
+		}
+		if resp.Total != tt.want {
+			t.Errorf("session %s has %d messages after clearing s1, want %d", tt.session, resp.Total, tt.want)
+		}
+	}
+}
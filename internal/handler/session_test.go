@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentChatSessions 以 -race 运行：并发对话与历史查询只经过 internal/memory 的会话存储
+func TestConcurrentChatSessions(t *testing.T) {
+	const workers = 8
+
+	tests := []struct {
+		name      string
+		sessionID func(i int) string
+		// wantHistory 每个会话最终的历史条数（每轮对话写入用户与助手两条）
+		wantHistory map[string]int
+	}{
+		{
+			name:      "distinct sessions",
+			sessionID: func(i int) string { return fmt.Sprintf("s%d", i) },
+			wantHistory: func() map[string]int {
+				want := make(map[string]int, workers)
+				for i := 0; i < workers; i++ {
+					want[fmt.Sprintf("s%d", i)] = 2
+				}
+				return want
+			}(),
+		},
+		{
+			name:        "identical session",
+			sessionID:   func(int) string { return "shared" },
+			wantHistory: map[string]int{"shared": 2 * workers},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMemoryTestRouter(t, "收到")
+
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(2)
+				sessionID := tt.sessionID(i)
+				go func() {
+					defer wg.Done()
+					body := `{"session_id":"` + sessionID + `","user_id":"u1","message":"你好"}`
+					rec := httptest.NewRecorder()
+					r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/chat", bytes.NewBufferString(body)))
+					var resp ChatResponse
+					if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != 200 {
+						t.Errorf("chat %s: code %d, err %v, body %s", sessionID, resp.Code, err, rec.Body.String())
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					rec := httptest.NewRecorder()
+					r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/session/"+sessionID+"/history", nil))
+				}()
+			}
+			wg.Wait()
+
+			for sessionID, want := range tt.wantHistory {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/session/"+sessionID+"/history?limit=100", nil))
+				var resp SessionHistoryResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode history: %v, body = %s", err, rec.Body.String())
+				}
+				if resp.Total != want {
+					t.Errorf("session %s has %d messages, want %d", sessionID, resp.Total, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	agent_biz "video_agent/internal/agent/biz"
+	"video_agent/internal/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newMemoryTestRouter 配置了记忆的处理器路由，会话历史可查询、流式回复可续传
+func newMemoryTestRouter(t *testing.T, answer string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	uc, err := agent_biz.NewVideoAssistantUsecase(nil, fakeChatModel{answer: answer}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewVideoAssistantUsecase: %v", err)
+	}
+	uc.SetMemoryManager(memory.NewMemoryManager(
+		memory.NewShortTermMemory(100, time.Hour),
+		memory.NewLongTermMemory(nil, nil, nil),
+		memory.NewWorkingMemory(100),
+	))
+	r := gin.New()
+	NewXiaovHandler(uc).RegisterRoutes(r)
+	return r
+}
+
+// messageEventIDs 按顺序返回 message 事件的 ID 与内容
+func messageEventIDs(body string) (ids []string, content string) {
+	var sb strings.Builder
+	var id, name, data string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimPrefix(line, "data:")
+		case line == "":
+			if name == "message" {
+				ids = append(ids, id)
+				sb.WriteString(data)
+			}
+			id, name, data = "", "", ""
+		}
+	}
+	return ids, sb.String()
+}
+
+func streamChat(r *gin.Engine, sessionID, lastEventID string) *httptest.ResponseRecorder {
+	body := `{"session_id":"` + sessionID + `","user_id":"u1","message":"我的视频数据怎么样"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/chat/stream", bytes.NewBufferString(body))
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStreamChatResume(t *testing.T) {
+	answer := strings.Repeat("播放", 100)
+
+	tests := []struct {
+		name string
+		// lastEventID 由首次请求的事件 ID 生成
+		lastEventID func(ids []string) string
+		wantCode    int
+		wantChunks  int
+	}{
+		{name: "resume after first chunk", lastEventID: func(ids []string) string { return ids[0] }, wantChunks: 3},
+		{name: "resume after last chunk", lastEventID: func(ids []string) string { return ids[len(ids)-1] }, wantChunks: 0},
+		{name: "unknown stream id", lastEventID: func([]string) string { return "other-stream:1" }, wantCode: 400},
+		{name: "bare chunk index", lastEventID: func([]string) string { return "1" }, wantCode: 400},
+		{name: "negative chunk index", lastEventID: func(ids []string) string {
+			streamID, _, _ := strings.Cut(ids[0], ":")
+			return streamID + ":-1"
+		}, wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMemoryTestRouter(t, answer)
+
+			ids, content := messageEventIDs(streamChat(r, "s1", "").Body.String())
+			if content != answer || len(ids) != 4 {
+				t.Fatalf("first stream: %d chunks, content %q", len(ids), content)
+			}
+			streamID, _, _ := strings.Cut(ids[0], ":")
+			for i, id := range ids {
+				if want := formatStreamEventID(streamID, i+1); id != want {
+					t.Fatalf("event id = %q, want %q", id, want)
+				}
+			}
+
+			rec := streamChat(r, "s1", tt.lastEventID(ids))
+			if tt.wantCode != 0 {
+				var resp ChatResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v, body = %s", err, rec.Body.String())
+				}
+				if resp.Code != tt.wantCode {
+					t.Fatalf("code = %d, want %d (%s)", resp.Code, tt.wantCode, resp.Message)
+				}
+				return
+			}
+
+			resumed, _ := messageEventIDs(rec.Body.String())
+			if len(resumed) != tt.wantChunks {
+				t.Fatalf("resumed %d chunks, want %d", len(resumed), tt.wantChunks)
+			}
+			if len(resumed) > 0 && resumed[len(resumed)-1] != ids[len(ids)-1] {
+				t.Errorf("last resumed id = %q, want %q", resumed[len(resumed)-1], ids[len(ids)-1])
+			}
+		})
+	}
+}
+
+func TestParseStreamEventID(t *testing.T) {
+	tests := []struct {
+		id         string
+		wantStream string
+		wantChunk  int
+		wantErr    bool
+	}{
+		{id: "abc:3", wantStream: "abc", wantChunk: 3},
+		{id: "abc:0", wantStream: "abc", wantChunk: 0},
+		{id: "3", wantErr: true},
+		{id: ":3", wantErr: true},
+		{id: "abc:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			stream, chunk, err := parseStreamEventID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stream != tt.wantStream || chunk != tt.wantChunk {
+				t.Errorf("got (%q, %d), want (%q, %d)", stream, chunk, tt.wantStream, tt.wantChunk)
+			}
+		})
+	}
+}
+
+func TestStreamChatProgressEvents(t *testing.T) {
+	r := newMemoryTestRouter(t, "播放量稳定增长")
+	body := streamChat(r, "s1", "").Body.String()
+
+	events := parseSSE(body)
+	firstMessage := -1
+	for i, e := range events {
+		if e.name == "message" {
+			firstMessage = i
+			break
+		}
+	}
+	if firstMessage <= 0 || events[0].name != "progress" {
+		t.Fatalf("events = %+v, want progress events before the first message", events)
+	}
+
+	var progress StreamProgressEvent
+	if err := json.Unmarshal([]byte(events[0].data), &progress); err != nil || progress.Phase == "" {
+		t.Errorf("progress payload = %q (%v), want a phase", events[0].data, err)
+	}
+	// 进度事件不带 ID，首个回复分片的序号仍为 1
+	if ids, _ := messageEventIDs(body); len(ids) == 0 || !strings.HasSuffix(ids[0], ":1") {
+		t.Errorf("message ids = %v, want the first chunk numbered 1", ids)
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 帧类型，与 gRPC ChatStream 的 StreamContent/StreamError/StreamDone 一一对应
+const (
+	WSFrameContent = "content"
+	WSFrameError   = "error"
+	WSFrameDone    = "done"
+)
+
+// wsWriteTimeout 单帧写入超时，避免慢客户端阻塞生成
+const wsWriteTimeout = 10 * time.Second
+
+// WSChatFrame WebSocket 下行消息
+type WSChatFrame struct {
+	Type string `json:"type"`
+	// Phase content 帧所处阶段，进度提示为 intent/tool/generate，回复内容为 content
+	Phase     string `json:"phase,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Code      int    `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+	SessionID string `json:"session_id"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+}
+
+// SetWebSocketOrigins 设置允许建立 WebSocket 连接的 Origin，为空时只允许同源连接
+func (h *XiaovHandler) SetWebSocketOrigins(origins ...string) {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	h.wsOrigins = allowed
+}
+
+// checkWSOrigin 未配置白名单时回退到同源校验（不带 Origin 的非浏览器客户端放行），防止跨站 WebSocket 劫持
+func (h *XiaovHandler) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(h.wsOrigins) > 0 {
+		return h.wsOrigins[origin]
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// WebSocketChat 流式对话的 WebSocket 入口：连接建立后客户端发送一条 ChatRequest JSON，
+// 服务端逐块推送 content 帧，结束时推送 done（或 error）帧后关闭连接；客户端断开会取消生成
+func (h *XiaovHandler) WebSocketChat(c *gin.Context) {
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkWSOrigin}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已向客户端写回 HTTP 错误
+		log.Printf("[Handler] websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var req ChatRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		writeWSFrame(conn, WSChatFrame{Type: WSFrameError, Code: 400, Message: "请求参数错误: " + err.Error()})
+		return
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	message, err := h.uc.CheckMessage(req.Message)
+	if err != nil {
+		writeWSFrame(conn, WSChatFrame{Type: WSFrameError, Code: 400, Message: "请求参数错误: " + err.Error(), SessionID: sessionID})
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+	ctx, err = withLanguage(ctx, req.Language)
+	if err != nil {
+		writeWSFrame(conn, WSChatFrame{Type: WSFrameError, Code: 400, Message: "请求参数错误: " + err.Error(), SessionID: sessionID})
+		return
+	}
+
+	// 持续读取以感知客户端关闭，任何读错误都视为断开并取消下游生成
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	reader, err := h.uc.StreamChat(ctx, sessionID, req.UserID, message)
+	if err != nil {
+		if ctx.Err() == nil {
+			writeWSFrame(conn, WSChatFrame{Type: WSFrameError, Code: 500, Message: "处理失败: " + err.Error(), SessionID: sessionID})
+		}
+		return
+	}
+
+	for {
+		chunk, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			writeWSFrame(conn, WSChatFrame{Type: WSFrameDone, SessionID: sessionID, Timestamp: time.Now().UnixMilli()})
+			closeWS(conn, websocket.CloseNormalClosure, "done")
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				writeWSFrame(conn, WSChatFrame{Type: WSFrameError, Code: 500, Message: err.Error(), SessionID: sessionID})
+			}
+			return
+		}
+
+		if err := writeWSFrame(conn, WSChatFrame{Type: WSFrameContent, Phase: chunk.Phase, Content: chunk.Content, SessionID: sessionID}); err != nil {
+			return
+		}
+	}
+}
+
+func writeWSFrame(conn *websocket.Conn, frame WSChatFrame) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(frame)
+}
+
+func closeWS(conn *websocket.Conn, code int, text string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(wsWriteTimeout))
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func newWSTestServer(t *testing.T, answer string, origins ...string) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := newTestHandler(t, answer)
+	if len(origins) > 0 {
+		h.SetWebSocketOrigins(origins...)
+	}
+	r := gin.New()
+	h.RegisterRoutes(r)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/chat"
+}
+
+func TestWebSocketChatStreamsUntilDone(t *testing.T) {
+	answer := strings.Repeat("播放", 100)
+	srv := newWSTestServer(t, answer)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(srv), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(ChatRequest{SessionID: "s1", UserID: "u1", Message: "我的视频数据怎么样"}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var content strings.Builder
+	var chunks int
+	var done bool
+	for {
+		var frame WSChatFrame
+		err := conn.ReadJSON(&frame)
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNormalClosure {
+				t.Fatalf("connection should close normally after done, got %v", err)
+			}
+			break
+		}
+		if done {
+			t.Fatalf("frame after done: %+v", frame)
+		}
+		switch frame.Type {
+		case WSFrameContent:
+			if frame.Phase == "content" {
+				chunks++
+				content.WriteString(frame.Content)
+			}
+		case WSFrameDone:
+			done = true
+		default:
+			t.Fatalf("unexpected frame: %+v", frame)
+		}
+		if frame.SessionID != "s1" {
+			t.Errorf("session id = %q, want s1", frame.SessionID)
+		}
+	}
+	if !done {
+		t.Fatal("no done frame before close")
+	}
+	if chunks < 2 || content.String() != answer {
+		t.Errorf("got %d chunks, content %q, want the answer streamed in several chunks", chunks, content.String())
+	}
+}
+
+func TestWebSocketChatOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		// origin 为 "self" 时使用测试服务器自身地址
+		origin string
+		wantOK bool
+	}{
+		{name: "no origin header", wantOK: true},
+		{name: "same origin", origin: "self", wantOK: true},
+		{name: "cross origin without allowlist", origin: "https://evil.example.com"},
+		{name: "allowlisted origin", allowed: []string{"https://app.example.com"}, origin: "https://app.example.com", wantOK: true},
+		{name: "origin not in allowlist", allowed: []string{"https://app.example.com"}, origin: "https://evil.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newWSTestServer(t, "ok", tt.allowed...)
+			header := http.Header{}
+			switch tt.origin {
+			case "":
+			case "self":
+				header.Set("Origin", srv.URL)
+			default:
+				header.Set("Origin", tt.origin)
+			}
+
+			conn, resp, err := websocket.DefaultDialer.Dial(wsURL(srv), header)
+			if tt.wantOK {
+				if err != nil {
+					t.Fatalf("Dial: %v", err)
+				}
+				conn.Close()
+				return
+			}
+			if err == nil {
+				conn.Close()
+				t.Fatal("cross-origin handshake should be rejected")
+			}
+			if resp == nil || resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("handshake response = %v, want 403", resp)
+			}
+		})
+	}
+}
@@ -1,10 +1,24 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	httpapi "video_agent/api"
+	"video_agent/internal/agent/agents/report"
 	agent_biz "video_agent/internal/agent/biz"
+	"video_agent/internal/agent/graph"
+	agentprompt "video_agent/internal/agent/prompt"
+	"video_agent/internal/health"
+	"video_agent/internal/llm"
+	"video_agent/internal/logger"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -13,29 +27,300 @@ type ChatRequest struct {
 	Message   string `json:"message" binding:"required"`
 	SessionID string `json:"session_id"`
 	UserID    string `json:"user_id"`
+	// Language 回复语言（zh/en/ja），为空时按消息内容检测
+	Language string `json:"language"`
+}
+
+// StreamProgressEvent 流式对话的进度事件（SSE event: progress），phase 取值见 progress 包
+type StreamProgressEvent struct {
+	Phase   string `json:"phase"`
+	Message string `json:"message"`
 }
 
 type ChatResponse struct {
 	Code      int    `json:"code"`
 	Message   string `json:"message"`
 	SessionID string `json:"session_id"`
+	TraceID   string `json:"trace_id,omitempty"`
 	Timestamp int64  `json:"timestamp"`
 }
 
+type ChatPlanResponse struct {
+	Code      int                  `json:"code"`
+	Message   string               `json:"message"`
+	Plan      *graph.ExecutionPlan `json:"plan,omitempty"`
+	Timestamp int64                `json:"timestamp"`
+}
+
+type VideoAnalyzeRequest struct {
+	VideoID   string `json:"video_id" binding:"required"`
+	Query     string `json:"query"`
+	Stream    bool   `json:"stream"`
+	SessionID string `json:"session_id"`
+	UserID    string `json:"user_id"`
+	// Structured 为 true 时额外返回按固定 JSON Schema 解析的结构化结果
+	Structured bool `json:"structured"`
+	// Language 分析报告语言（zh/en/ja），为空时按 query 内容检测
+	Language string `json:"language"`
+	// OutputFormat 分析报告格式（markdown/plain/json），为空时为 markdown；json 时 analysis 为可直接解析的 JSON 字符串
+	OutputFormat string `json:"output_format"`
+	// Temperature/TopP/Seed 采样参数，未传时使用模型默认配置；固定 seed 与 temperature=0 便于复现分析结果
+	Temperature *float32 `json:"temperature"`
+	TopP        *float32 `json:"top_p"`
+	Seed        *int     `json:"seed"`
+}
+
+type VideoAnalyzeResponse struct {
+	Code             int                        `json:"code"`
+	Message          string                     `json:"message"`
+	Analysis         string                     `json:"analysis,omitempty"`
+	Structured       *report.StructuredAnalysis `json:"structured,omitempty"`
+	ToolsUsed        []string                   `json:"tools_used,omitempty"`
+	ProcessingTimeMs int64                      `json:"processing_time_ms"`
+	SessionID        string                     `json:"session_id"`
+	Timestamp        int64                      `json:"timestamp"`
+}
+
+// maxBatchVideos 单次批量分析允许的最大视频数
+const maxBatchVideos = 20
+
+type BatchAnalyzeRequest struct {
+	VideoIDs  []string `json:"video_ids" binding:"required"`
+	Query     string   `json:"query"`
+	SessionID string   `json:"session_id"`
+	UserID    string   `json:"user_id"`
+}
+
+type BatchVideoAnalysis struct {
+	VideoID          string   `json:"video_id"`
+	Analysis         string   `json:"analysis,omitempty"`
+	ToolsUsed        []string `json:"tools_used,omitempty"`
+	ProcessingTimeMs int64    `json:"processing_time_ms"`
+	Error            string   `json:"error,omitempty"`
+}
+
+type BatchAnalyzeResponse struct {
+	Code      int                  `json:"code"`
+	Message   string               `json:"message"`
+	Results   []BatchVideoAnalysis `json:"results,omitempty"`
+	Failed    int                  `json:"failed"`
+	SessionID string               `json:"session_id"`
+	Timestamp int64                `json:"timestamp"`
+}
+
+// defaultRequestTimeout 单个请求的默认处理超时，需覆盖完整的 LLM + 工具调用链路
+const defaultRequestTimeout = 5 * time.Minute
+
 type XiaovHandler struct {
-	uc *agent_biz.VideoAssistantUsecase
+	uc             *agent_biz.VideoAssistantUsecase
+	requestTimeout time.Duration
+	health         *health.Checker
+	wsOrigins      map[string]bool
+	// adminAPIKeys 管理接口（/api/mcp/...）接受的 X-API-Key，为空时管理接口一律拒绝
+	adminAPIKeys []string
 }
 
 func NewXiaovHandler(uc *agent_biz.VideoAssistantUsecase) *XiaovHandler {
 	return &XiaovHandler{
-		uc: uc,
+		uc:             uc,
+		requestTimeout: defaultRequestTimeout,
 	}
 }
 
+// SetRequestTimeout 设置单个请求的处理超时，<=0 表示仅跟随客户端连接的生命周期
+func (h *XiaovHandler) SetRequestTimeout(timeout time.Duration) {
+	h.requestTimeout = timeout
+}
+
+// requestContext 基于 Gin 请求上下文派生处理上下文，客户端断开或超时都会取消下游调用；
+// 沿用请求头 X-Trace-ID 中的追踪 ID（没有或不合法时生成），并通过同名响应头返回
+func (h *XiaovHandler) requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx := c.Request.Context()
+	if traceID := c.GetHeader(logger.TraceIDHeader); logger.ValidTraceID(traceID) {
+		ctx = logger.WithTraceID(ctx, traceID)
+	}
+	ctx, traceID := logger.EnsureTraceID(ctx)
+	c.Header(logger.TraceIDHeader, traceID)
+
+	if h.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.requestTimeout)
+}
+
+// withLanguage 校验请求指定的回复语言并写入 context，未指定时保持原样
+func withLanguage(ctx context.Context, code string) (context.Context, error) {
+	lang, err := agentprompt.ParseLanguage(code)
+	if err != nil || lang == "" {
+		return ctx, err
+	}
+	return graph.WithLanguage(ctx, lang), nil
+}
+
+// withOutputFormat 校验请求指定的分析报告格式并写入 context，未指定时保持原样
+func withOutputFormat(ctx context.Context, value string) (context.Context, error) {
+	format, err := agentprompt.ParseOutputFormat(value)
+	if err != nil || format == "" {
+		return ctx, err
+	}
+	return graph.WithOutputFormat(ctx, format), nil
+}
+
+// SetHealthChecker 设置依赖健康检查器，未设置时健康检查只反映进程存活
+func (h *XiaovHandler) SetHealthChecker(checker *health.Checker) {
+	h.health = checker
+}
+
+// SetAdminAPIKeys 设置管理接口（查看、刷新 MCP 工具）接受的 X-API-Key，未设置时管理接口返回 401
+func (h *XiaovHandler) SetAdminAPIKeys(keys ...string) {
+	h.adminAPIKeys = keys
+}
+
 func (h *XiaovHandler) GetUsecase() *agent_biz.VideoAssistantUsecase {
 	return h.uc
 }
 
+// RegisterRoutes 注册小V助手HTTP路由
+func (h *XiaovHandler) RegisterRoutes(r gin.IRouter) {
+	api := r.Group("/api")
+	{
+		api.POST("/chat", h.Chat)
+		api.POST("/chat/stream", h.StreamChat)
+		api.POST("/chat/plan", h.PlanChat)
+		api.POST("/video/analyze", h.AnalyzeVideo)
+		api.POST("/video/batch_analyze", h.BatchAnalyze)
+		api.GET("/health", h.HealthCheck)
+		api.GET("/session/:session_id/history", h.GetSessionHistory)
+		api.DELETE("/session/:session_id", h.ClearSession)
+	}
+	// 管理接口会触发到 MCP 服务的重连，需要 X-API-Key
+	admin := r.Group("/api/mcp", httpapi.APIKeyMiddleware(h.adminAPIKeys))
+	{
+		admin.GET("/tools", h.ListMCPTools)
+		admin.POST("/tools/refresh", h.RefreshMCPTools)
+	}
+	r.GET("/ws/chat", h.WebSocketChat)
+}
+
+// 会话历史默认/最大返回条数
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+type HistoryMessage struct {
+	ID        string `json:"id"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type SessionHistoryResponse struct {
+	Code      int              `json:"code"`
+	Message   string           `json:"message"`
+	SessionID string           `json:"session_id"`
+	Title     string           `json:"title"`
+	Messages  []HistoryMessage `json:"messages"`
+	Total     int              `json:"total"`
+}
+
+// GetSessionHistory 获取会话历史及会话标题，limit 通过查询参数指定
+func (h *XiaovHandler) GetSessionHistory(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	limit := defaultHistoryLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusOK, SessionHistoryResponse{
+				Code:    400,
+				Message: "请求参数错误: limit必须为正整数",
+			})
+			return
+		}
+		limit = n
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	history, err := h.uc.GetSessionHistory(ctx, sessionID, limit)
+	if err != nil {
+		c.JSON(http.StatusOK, SessionHistoryResponse{
+			Code:      500,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+		})
+		return
+	}
+
+	messages := make([]HistoryMessage, 0, len(history.Messages))
+	for _, mem := range history.Messages {
+		messages = append(messages, HistoryMessage{
+			ID:        mem.ID,
+			Role:      string(mem.Type),
+			Content:   mem.Content,
+			Timestamp: mem.CreatedAt.UnixMilli(),
+		})
+	}
+
+	c.JSON(http.StatusOK, SessionHistoryResponse{
+		Code:      200,
+		Message:   "success",
+		SessionID: sessionID,
+		Title:     history.Title,
+		Messages:  messages,
+		Total:     len(messages),
+	})
+}
+
+type ClearSessionResponse struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	SessionID string `json:"session_id"`
+}
+
+// ClearSession 清除会话历史，之后的对话不再带入该会话此前的消息
+func (h *XiaovHandler) ClearSession(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	if err := h.uc.ClearSession(ctx, sessionID); err != nil {
+		c.JSON(http.StatusOK, ClearSessionResponse{
+			Code:      500,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ClearSessionResponse{
+		Code:      200,
+		Message:   "success",
+		SessionID: sessionID,
+	})
+}
+
+// HealthCheck 检查 Ollama、MCP 等依赖状态，unhealthy 时返回 503
+func (h *XiaovHandler) HealthCheck(c *gin.Context) {
+	if h.health == nil {
+		c.JSON(http.StatusOK, &health.Report{
+			Status:       health.StatusHealthy,
+			Dependencies: []health.DependencyStatus{},
+			CheckedAt:    time.Now(),
+		})
+		return
+	}
+
+	report := h.health.Check(c.Request.Context())
+	c.JSON(report.HTTPStatus(), report)
+}
+
 func (h *XiaovHandler) Chat(c *gin.Context) {
 	var req ChatRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -46,17 +331,39 @@ func (h *XiaovHandler) Chat(c *gin.Context) {
 		return
 	}
 
+	message, err := h.uc.CheckMessage(req.Message)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	req.Message = message
+
 	sessionID := req.SessionID
 	if sessionID == "" {
 		sessionID = uuid.New().String()
 	}
 
-	result, err := h.uc.Chat(c.Request.Context(), sessionID, req.UserID, req.Message)
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+	ctx, err = withLanguage(ctx, req.Language)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.uc.Chat(ctx, sessionID, req.UserID, req.Message)
 	if err != nil {
 		c.JSON(http.StatusOK, ChatResponse{
 			Code:      500,
 			Message:   "处理失败: " + err.Error(),
 			SessionID: sessionID,
+			TraceID:   logger.TraceID(ctx),
 			Timestamp: time.Now().UnixMilli(),
 		})
 		return
@@ -66,6 +373,7 @@ func (h *XiaovHandler) Chat(c *gin.Context) {
 		Code:      200,
 		Message:   result,
 		SessionID: sessionID,
+		TraceID:   logger.TraceID(ctx),
 		Timestamp: time.Now().UnixMilli(),
 	})
 }
@@ -80,14 +388,252 @@ func (h *XiaovHandler) StreamChat(c *gin.Context) {
 		return
 	}
 
+	message, err := h.uc.CheckMessage(req.Message)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	req.Message = message
+
 	sessionID := req.SessionID
 	if sessionID == "" {
 		sessionID = uuid.New().String()
 	}
 
-	result, err := h.uc.StreamChat(c.Request.Context(), sessionID, req.UserID, req.Message)
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+	ctx, err = withLanguage(ctx, req.Language)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 断线重连时客户端携带 Last-Event-ID，从已缓存的回复续传，避免重复生成和重复推送
+	var reader agent_biz.ChatStreamReader
+	eventID := 0
+	if lastID := c.GetHeader("Last-Event-ID"); lastID != "" && req.SessionID != "" {
+		var streamID string
+		streamID, eventID, err = parseStreamEventID(lastID)
+		if err != nil {
+			c.JSON(http.StatusOK, ChatResponse{
+				Code:    400,
+				Message: "请求参数错误: invalid Last-Event-ID",
+			})
+			return
+		}
+		reader, err = h.uc.ResumeStreamChat(sessionID, streamID, eventID)
+	} else {
+		reader, err = h.uc.StreamChat(ctx, sessionID, req.UserID, req.Message)
+	}
 	if err != nil {
+		code := 500
+		if errors.Is(err, agent_biz.ErrStreamNotFound) {
+			code = 400
+		}
 		c.JSON(http.StatusOK, ChatResponse{
+			Code:      code,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	for {
+		chunk, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			c.SSEvent("done", sessionID)
+			c.Writer.Flush()
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				c.SSEvent("error", err.Error())
+				c.Writer.Flush()
+			}
+			return
+		}
+
+		if chunk.IsProgress() {
+			// 进度事件不带 ID，续传只按回复分片计数
+			c.Render(-1, sse.Event{
+				Event: "progress",
+				Data:  StreamProgressEvent{Phase: chunk.Phase, Message: chunk.Content},
+			})
+			c.Writer.Flush()
+			continue
+		}
+
+		eventID++
+		c.Render(-1, sse.Event{
+			Id:    formatStreamEventID(reader.StreamID(), eventID),
+			Event: "message",
+			Data:  chunk.Content,
+		})
+		c.Writer.Flush()
+	}
+}
+
+// formatStreamEventID SSE 事件 ID：流式回复ID与分片序号，格式为 "<streamID>:<n>"
+func formatStreamEventID(streamID string, chunk int) string {
+	return streamID + ":" + strconv.Itoa(chunk)
+}
+
+// parseStreamEventID 解析 formatStreamEventID 生成的事件 ID
+func parseStreamEventID(id string) (string, int, error) {
+	streamID, n, ok := strings.Cut(id, ":")
+	if !ok || streamID == "" {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	chunk, err := strconv.Atoi(n)
+	if err != nil || chunk < 0 {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return streamID, chunk, nil
+}
+
+// PlanChat 预演对话，只返回会被选中的意图与工具，不执行工具调用和分析
+func (h *XiaovHandler) PlanChat(c *gin.Context) {
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, ChatPlanResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	message, err := h.uc.CheckMessage(req.Message)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatPlanResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	plan, err := h.uc.Plan(ctx, message)
+	if err != nil {
+		c.JSON(http.StatusOK, ChatPlanResponse{
+			Code:      500,
+			Message:   "处理失败: " + err.Error(),
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ChatPlanResponse{
+		Code:      200,
+		Message:   "success",
+		Plan:      plan,
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
+func (h *XiaovHandler) AnalyzeVideo(c *gin.Context) {
+	var req VideoAnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	videoID := strings.TrimSpace(req.VideoID)
+	if videoID == "" {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: video_id不能为空",
+		})
+		return
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+	ctx, err := withLanguage(ctx, req.Language)
+	if err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	ctx, err = withOutputFormat(ctx, req.OutputFormat)
+	if err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	sampling := llm.Sampling{Temperature: req.Temperature, TopP: req.TopP, Seed: req.Seed}
+	if err := sampling.Validate(); err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if !sampling.IsZero() {
+		ctx = llm.WithSampling(ctx, sampling)
+	}
+
+	if req.Structured {
+		h.analyzeStructured(ctx, c, sessionID, req.UserID, videoID, req.Query)
+		return
+	}
+	if req.Stream {
+		h.streamAnalyze(ctx, c, sessionID, req.UserID, videoID, req.Query)
+		return
+	}
+
+	result, err := h.uc.AnalyzeVideo(ctx, sessionID, req.UserID, videoID, req.Query)
+	if err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:      500,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoAnalyzeResponse{
+		Code:             200,
+		Message:          "success",
+		Analysis:         result.Content,
+		ToolsUsed:        result.ToolsUsed,
+		ProcessingTimeMs: result.ProcessingTime.Milliseconds(),
+		SessionID:        sessionID,
+		Timestamp:        time.Now().UnixMilli(),
+	})
+}
+
+// streamAnalyze 以 SSE 返回视频分析：执行期间推送 progress 事件，报告完成后分片推送 message 事件，
+// 最后的 done 事件携带 tools_used 与 processing_time_ms（不重复携带 analysis）
+func (h *XiaovHandler) streamAnalyze(ctx context.Context, c *gin.Context, sessionID, userID, videoID, query string) {
+	stream, err := h.uc.StreamAnalyzeVideo(ctx, sessionID, userID, videoID, query)
+	if err != nil {
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
 			Code:      500,
 			Message:   "处理失败: " + err.Error(),
 			SessionID: sessionID,
@@ -100,6 +646,206 @@ func (h *XiaovHandler) StreamChat(c *gin.Context) {
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 
-	c.SSEvent("message", result)
-	c.Writer.Flush()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			result := stream.Result()
+			c.SSEvent("done", VideoAnalyzeResponse{
+				Code:             200,
+				Message:          "success",
+				ToolsUsed:        result.ToolsUsed,
+				ProcessingTimeMs: result.ProcessingTime.Milliseconds(),
+				SessionID:        sessionID,
+				Timestamp:        time.Now().UnixMilli(),
+			})
+			c.Writer.Flush()
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				c.SSEvent("error", err.Error())
+				c.Writer.Flush()
+			}
+			return
+		}
+
+		if chunk.IsProgress() {
+			c.SSEvent("progress", StreamProgressEvent{Phase: chunk.Phase, Message: chunk.Content})
+		} else {
+			c.SSEvent("message", chunk.Content)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// analyzeStructured 返回结构化分析结果，模型输出修复后仍不合法时返回 400
+func (h *XiaovHandler) analyzeStructured(ctx context.Context, c *gin.Context, sessionID, userID, videoID, query string) {
+	result, err := h.uc.AnalyzeStructured(ctx, sessionID, userID, videoID, query)
+	if err != nil {
+		code := 500
+		if errors.Is(err, report.ErrInvalidStructuredOutput) {
+			code = 400
+		}
+		c.JSON(http.StatusOK, VideoAnalyzeResponse{
+			Code:      code,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, VideoAnalyzeResponse{
+		Code:             200,
+		Message:          "success",
+		Analysis:         result.Report.Content,
+		Structured:       result.Analysis,
+		ToolsUsed:        result.Report.ToolsUsed,
+		ProcessingTimeMs: result.Report.ProcessingTime.Milliseconds(),
+		SessionID:        sessionID,
+		Timestamp:        time.Now().UnixMilli(),
+	})
+}
+
+func (h *XiaovHandler) BatchAnalyze(c *gin.Context) {
+	var req BatchAnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, BatchAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	videoIDs := make([]string, 0, len(req.VideoIDs))
+	for _, id := range req.VideoIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			videoIDs = append(videoIDs, id)
+		}
+	}
+	if len(videoIDs) == 0 || len(videoIDs) > maxBatchVideos {
+		c.JSON(http.StatusOK, BatchAnalyzeResponse{
+			Code:    400,
+			Message: "请求参数错误: video_ids数量需在1到" + strconv.Itoa(maxBatchVideos) + "之间",
+		})
+		return
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	results, err := h.uc.BatchAnalyze(ctx, sessionID, req.UserID, videoIDs, req.Query)
+	if err != nil {
+		c.JSON(http.StatusOK, BatchAnalyzeResponse{
+			Code:      500,
+			Message:   "处理失败: " + err.Error(),
+			SessionID: sessionID,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	resp := BatchAnalyzeResponse{
+		Code:      200,
+		Message:   "success",
+		Results:   make([]BatchVideoAnalysis, 0, len(results)),
+		SessionID: sessionID,
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, r := range results {
+		item := BatchVideoAnalysis{VideoID: r.VideoID, Error: r.Error}
+		if r.Result != nil {
+			item.Analysis = r.Result.Content
+			item.ToolsUsed = r.Result.ToolsUsed
+			item.ProcessingTimeMs = r.Result.ProcessingTime.Milliseconds()
+		}
+		if item.Error != "" {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, item)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// MCPServerInfo MCP 服务连接信息，不返回请求头等认证配置
+type MCPServerInfo struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type MCPToolInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Params      interface{} `json:"params,omitempty"`
+}
+
+type MCPToolsResponse struct {
+	Code      int             `json:"code"`
+	Message   string          `json:"message"`
+	Available bool            `json:"available"`
+	Count     int             `json:"count"`
+	Servers   []MCPServerInfo `json:"servers"`
+	Tools     []MCPToolInfo   `json:"tools"`
+}
+
+// ListMCPTools 列出当前加载的 MCP 工具
+func (h *XiaovHandler) ListMCPTools(c *gin.Context) {
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	h.respondMCPTools(ctx, c)
+}
+
+// RefreshMCPTools 重新连接 MCP 服务并拉取工具列表，MCP Server 新增工具后无需重启服务
+func (h *XiaovHandler) RefreshMCPTools(c *gin.Context) {
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	if err := h.uc.RefreshMCPTools(ctx, h.uc.MCPServers()); err != nil {
+		c.JSON(http.StatusOK, MCPToolsResponse{
+			Code:    500,
+			Message: "刷新失败: " + err.Error(),
+		})
+		return
+	}
+	h.respondMCPTools(ctx, c)
+}
+
+func (h *XiaovHandler) respondMCPTools(ctx context.Context, c *gin.Context) {
+	info, err := h.uc.MCPTools(ctx)
+	if err != nil {
+		c.JSON(http.StatusOK, MCPToolsResponse{
+			Code:    500,
+			Message: "获取工具失败: " + err.Error(),
+		})
+		return
+	}
+
+	resp := MCPToolsResponse{
+		Code:      200,
+		Message:   "success",
+		Available: info.Available,
+		Count:     len(info.Tools),
+		Servers:   make([]MCPServerInfo, 0, len(info.Servers)),
+		Tools:     make([]MCPToolInfo, 0, len(info.Tools)),
+	}
+	for _, server := range info.Servers {
+		resp.Servers = append(resp.Servers, MCPServerInfo{Name: server.Name, URL: server.URL})
+	}
+	for _, t := range info.Tools {
+		item := MCPToolInfo{Name: t.Name, Description: t.Desc}
+		if t.ParamsOneOf != nil {
+			if params, err := t.ParamsOneOf.ToJSONSchema(); err == nil {
+				item.Params = params
+			}
+		}
+		resp.Tools = append(resp.Tools, item)
+	}
+	c.JSON(http.StatusOK, resp)
 }
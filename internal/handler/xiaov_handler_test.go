@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpapi "video_agent/api"
+	agent_biz "video_agent/internal/agent/biz"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMCPAdminRoutesRequireAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		adminKeys  []string
+		method     string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{name: "未配置 Key 时拒绝", method: http.MethodGet, path: "/api/mcp/tools", key: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "缺少 Key", adminKeys: []string{"secret"}, method: http.MethodGet, path: "/api/mcp/tools", wantStatus: http.StatusUnauthorized},
+		{name: "Key 错误", adminKeys: []string{"secret"}, method: http.MethodPost, path: "/api/mcp/tools/refresh", key: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "Key 正确", adminKeys: []string{"secret"}, method: http.MethodGet, path: "/api/mcp/tools", key: "secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewXiaovHandler(&agent_biz.VideoAssistantUsecase{})
+			h.SetAdminAPIKeys(tt.adminKeys...)
+			r := gin.New()
+			h.RegisterRoutes(r)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(httpapi.APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestChatMessageLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 10
+
+	tests := []struct {
+		name     string
+		path     string
+		message  string
+		wantCode int
+	}{
+		{name: "boundary length", path: "/api/chat", message: strings.Repeat("播", limit), wantCode: 200},
+		{name: "over limit", path: "/api/chat", message: strings.Repeat("播", limit+1), wantCode: 400},
+		{name: "over limit on stream", path: "/api/chat/stream", message: strings.Repeat("播", limit+1), wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, "你好")
+			h.uc.SetMessageLimit(limit, false)
+			r := gin.New()
+			h.RegisterRoutes(r)
+
+			body, _ := json.Marshal(ChatRequest{SessionID: "s1", UserID: "u1", Message: tt.message})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body)))
+
+			var resp ChatResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v, body = %s", err, w.Body.String())
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d (%s)", resp.Code, tt.wantCode, resp.Message)
+			}
+		})
+	}
+}
+
+func TestChatLanguageValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		language string
+		wantCode int
+	}{
+		{name: "no language", wantCode: 200},
+		{name: "supported language", language: "en-US", wantCode: 200},
+		{name: "unsupported language", language: "fr", wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, "hello")
+			r := gin.New()
+			h.RegisterRoutes(r)
+
+			body, _ := json.Marshal(ChatRequest{SessionID: "s1", UserID: "u1", Message: "hi", Language: tt.language})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", bytes.NewReader(body)))
+
+			var resp ChatResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v, body = %s", err, w.Body.String())
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d (%s)", resp.Code, tt.wantCode, resp.Message)
+			}
+		})
+	}
+}
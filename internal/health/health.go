@@ -0,0 +1,159 @@
+// Package health 提供依赖健康检查（Ollama、MCP 等）
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status 健康状态
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// CheckFunc 依赖探测函数，返回 nil 表示依赖可用
+type CheckFunc func(ctx context.Context) error
+
+// Dependency 被检查的依赖
+type Dependency struct {
+	Name  string
+	Check CheckFunc
+	// Critical 关键依赖不可用时整体状态为 unhealthy，否则为 degraded
+	Critical bool
+}
+
+// DependencyStatus 单个依赖的检查结果
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Status    Status `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at"`
+}
+
+// HTTPStatus 返回报告对应的 HTTP 状态码，unhealthy 时返回 503 供负载均衡摘除
+func (r *Report) HTTPStatus() int {
+	if r.Status == StatusUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// Checker 依赖健康检查器，结果会短暂缓存以免频繁探测依赖
+type Checker struct {
+	deps     []Dependency
+	cacheTTL time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+// NewChecker 创建健康检查器
+func NewChecker(cacheTTL time.Duration, deps ...Dependency) *Checker {
+	return &Checker{
+		deps:     deps,
+		cacheTTL: cacheTTL,
+		timeout:  3 * time.Second,
+	}
+}
+
+// Check 检查所有依赖，缓存有效期内直接返回上次结果。探测不受 ctx 取消或截止时间影响，
+// 只受检查器自身的超时限制；ctx 已结束时结果不写入缓存，避免一次调用方超时让所有调用方看到 unhealthy
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.cached.CheckedAt) < c.cacheTTL {
+		return c.cached
+	}
+
+	report := &Report{
+		Status:       StatusHealthy,
+		Dependencies: make([]DependencyStatus, len(c.deps)),
+		CheckedAt:    time.Now(),
+	}
+
+	probeCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for i, dep := range c.deps {
+		wg.Add(1)
+		go func(i int, dep Dependency) {
+			defer wg.Done()
+			report.Dependencies[i] = c.checkOne(probeCtx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	for i, dep := range c.deps {
+		if report.Dependencies[i].Status == StatusHealthy {
+			continue
+		}
+		if dep.Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+
+	if ctx.Err() == nil {
+		c.cached = report
+	}
+	return report
+}
+
+func (c *Checker) checkOne(ctx context.Context, dep Dependency) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.Check(ctx)
+	status := DependencyStatus{
+		Name:      dep.Name,
+		Status:    StatusHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = StatusUnhealthy
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// OllamaCheck 通过 /api/tags 探测 Ollama 服务，该接口只列出本地模型，开销很小
+func OllamaCheck(baseURL string) CheckFunc {
+	url := strings.TrimRight(baseURL, "/") + "/api/tags"
+	client := &http.Client{}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("request ollama: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("ollama returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
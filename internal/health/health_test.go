@@ -0,0 +1,163 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error   { return nil }
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestCheckerStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     []Dependency
+		want     Status
+		wantHTTP int
+	}{
+		{name: "all up", deps: []Dependency{{Name: "ollama", Check: up, Critical: true}, {Name: "mcp", Check: up}}, want: StatusHealthy, wantHTTP: 200},
+		{name: "optional down", deps: []Dependency{{Name: "ollama", Check: up, Critical: true}, {Name: "mcp", Check: down}}, want: StatusDegraded, wantHTTP: 200},
+		{name: "critical down", deps: []Dependency{{Name: "ollama", Check: down, Critical: true}, {Name: "mcp", Check: up}}, want: StatusUnhealthy, wantHTTP: 503},
+		{name: "no dependencies", want: StatusHealthy, wantHTTP: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewChecker(0, tt.deps...).Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("status = %s, want %s", report.Status, tt.want)
+			}
+			if got := report.HTTPStatus(); got != tt.wantHTTP {
+				t.Errorf("HTTPStatus = %d, want %d", got, tt.wantHTTP)
+			}
+			for i, dep := range report.Dependencies {
+				if dep.Name != tt.deps[i].Name {
+					t.Errorf("dependencies[%d] = %s, want %s", i, dep.Name, tt.deps[i].Name)
+				}
+				if (dep.Status == StatusUnhealthy) != (dep.Error != "") {
+					t.Errorf("%s: status %s with error %q", dep.Name, dep.Status, dep.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckerCachesReport(t *testing.T) {
+	var calls atomic.Int32
+	counting := func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wantCalls int32
+	}{
+		{name: "within ttl", ttl: time.Minute, wantCalls: 1},
+		{name: "no cache", ttl: 0, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			c := NewChecker(tt.ttl, Dependency{Name: "mcp", Check: counting})
+			for i := 0; i < 3; i++ {
+				c.Check(context.Background())
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("dependency probed %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOllamaCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "ollama up", status: http.StatusOK},
+		{name: "ollama error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/tags" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := OllamaCheck(srv.URL + "/")(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("ollama unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		if err := OllamaCheck(srv.URL)(context.Background()); err == nil {
+			t.Error("expected an error for a closed server")
+		}
+	})
+}
+
+func TestCheckerIgnoresCallerCancellation(t *testing.T) {
+	var calls atomic.Int32
+	// ctxAware 在 ctx 结束时失败，模拟依赖探测响应慢于调用方截止时间
+	ctxAware := func(ctx context.Context) error {
+		calls.Add(1)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ctx       func() (context.Context, context.CancelFunc)
+		wantCalls int32
+	}{
+		{name: "cancelled caller", ctx: func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, wantCalls: 2},
+		{name: "short caller deadline", ctx: func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), time.Millisecond)
+		}, wantCalls: 2},
+		{name: "patient caller", ctx: func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			c := NewChecker(time.Minute, Dependency{Name: "ollama", Check: ctxAware, Critical: true})
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			if report := c.Check(ctx); report.Status != StatusHealthy {
+				t.Errorf("status = %s, want the probe to outlive the caller", report.Status)
+			}
+			// 调用方已结束时结果不缓存，下一次调用重新探测
+			if report := c.Check(context.Background()); report.Status != StatusHealthy {
+				t.Errorf("next status = %s, want healthy", report.Status)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("dependency probed %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// DefaultBreakerThreshold 连续失败多少次后熔断
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown 熔断后等待多久放行一次探测请求
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen 熔断期间直接返回，不再等待模型超时；外层 FallbackChatModel 会立即切换到备用模型
+var ErrCircuitOpen = errors.New("llm circuit breaker is open")
+
+// BreakerConfig 熔断参数，<=0 的字段使用默认值
+type BreakerConfig struct {
+	// FailureThreshold 连续失败次数阈值
+	FailureThreshold int
+	// Cooldown 熔断持续时间，到期后进入半开状态放行一次探测
+	Cooldown time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreakerChatModel 在模型连续失败后短路调用，冷却期结束后放行单个探测请求，
+// 探测成功则恢复，失败则重新熔断
+type CircuitBreakerChatModel struct {
+	model     model.ChatModel
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreakerChatModel 为模型加上熔断保护
+func NewCircuitBreakerChatModel(m model.ChatModel, cfg BreakerConfig) *CircuitBreakerChatModel {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultBreakerThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultBreakerCooldown
+	}
+	return &CircuitBreakerChatModel{
+		model:     m,
+		threshold: cfg.FailureThreshold,
+		cooldown:  cfg.Cooldown,
+	}
+}
+
+// Open 返回当前是否处于熔断（含半开探测中）状态
+func (b *CircuitBreakerChatModel) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != breakerClosed
+}
+
+// allow 判断本次调用是否放行；熔断冷却到期后只放行一个探测请求
+func (b *CircuitBreakerChatModel) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		log.Printf("[LLM] circuit breaker half-open, probing model")
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreakerChatModel) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		if b.state != breakerClosed {
+			log.Printf("[LLM] circuit breaker closed, model recovered")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	// 调用方取消不代表模型不可用；半开探测时需要重新熔断以便下次再探测
+	if ctx.Err() != nil && b.state != breakerHalfOpen {
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state == breakerClosed {
+			log.Printf("[LLM] circuit breaker open after %d consecutive failures, cooldown %s: %v", b.failures, b.cooldown, err)
+		}
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreakerChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+	resp, err := b.model.Generate(ctx, input, opts...)
+	b.record(ctx, err)
+	return resp, err
+}
+
+// Stream 仅以建立流是否成功计入熔断统计
+func (b *CircuitBreakerChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+	sr, err := b.model.Stream(ctx, input, opts...)
+	b.record(ctx, err)
+	return sr, err
+}
+
+func (b *CircuitBreakerChatModel) BindTools(tools []*schema.ToolInfo) error {
+	return b.model.BindTools(tools)
+}
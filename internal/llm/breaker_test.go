@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerChatModel(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	errDown := errors.New("ollama unavailable")
+
+	tests := []struct {
+		name string
+		// probeErr 冷却期结束后探测请求的结果
+		probeErr     error
+		wantOpen     bool
+		wantProbeErr error
+	}{
+		{name: "successful probe closes the breaker", probeErr: nil, wantOpen: false},
+		{name: "failed probe reopens the breaker", probeErr: errDown, wantOpen: true, wantProbeErr: errDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeModel{err: errDown}
+			b := NewCircuitBreakerChatModel(primary, BreakerConfig{FailureThreshold: 3, Cooldown: cooldown})
+			ctx := context.Background()
+
+			for i := 0; i < 3; i++ {
+				if _, err := b.Generate(ctx, nil); !errors.Is(err, errDown) {
+					t.Fatalf("call %d: err = %v, want %v", i+1, err, errDown)
+				}
+			}
+			if !b.Open() {
+				t.Fatal("breaker should be open after reaching the failure threshold")
+			}
+
+			// 熔断期间不再调用模型
+			if _, err := b.Generate(ctx, nil); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("err = %v, want ErrCircuitOpen", err)
+			}
+			if primary.calls != 3 {
+				t.Fatalf("primary called %d times while open, want 3", primary.calls)
+			}
+
+			time.Sleep(cooldown)
+			primary.err = tt.probeErr
+			primary.reply = "ok"
+			if _, err := b.Generate(ctx, nil); !errors.Is(err, tt.wantProbeErr) {
+				t.Fatalf("probe err = %v, want %v", err, tt.wantProbeErr)
+			}
+			if primary.calls != 4 {
+				t.Fatalf("primary called %d times, want 4 (one probe)", primary.calls)
+			}
+			if b.Open() != tt.wantOpen {
+				t.Fatalf("Open() = %v after probe, want %v", b.Open(), tt.wantOpen)
+			}
+			if !tt.wantOpen {
+				if _, err := b.Generate(ctx, nil); err != nil {
+					t.Errorf("call after recovery: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerCancel(t *testing.T) {
+	primary := &fakeModel{block: true}
+	b := NewCircuitBreakerChatModel(primary, BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Generate(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if b.Open() {
+		t.Error("caller cancellation should not trip the breaker")
+	}
+}
+
+func TestCircuitBreakerFallsBackImmediately(t *testing.T) {
+	primary := &fakeModel{err: errors.New("ollama unavailable")}
+	backup := &fakeModel{reply: "backup"}
+	chain := NewFallbackChatModel(NewCircuitBreakerChatModel(primary, BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}), backup)
+
+	for i := 0; i < 5; i++ {
+		resp, err := chain.Generate(context.Background(), nil)
+		if err != nil || resp.Content != "backup" {
+			t.Fatalf("call %d: resp = %v, err = %v", i+1, resp, err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("primary called %d times, want 2 before the breaker opened", primary.calls)
+	}
+}
@@ -0,0 +1,116 @@
+// Package llm 根据配置构建 ChatModel，支持 Ollama 与 OpenAI 兼容接口
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/ollama"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+)
+
+const (
+	// DefaultTimeout 单次请求超时，需覆盖工具调用后的长文本生成
+	DefaultTimeout = 6 * time.Minute
+	// DefaultKeepAlive Ollama 请求结束后模型在内存中保留的时长。
+	// 保留越久越能避免每次请求从磁盘重新加载模型的冷启动延迟，但会持续占用显存/内存；
+	// 低频调用或显存紧张的部署可调小，常驻服务可设为负数让模型永不卸载
+	DefaultKeepAlive = 5 * time.Minute
+)
+
+// Config 大模型配置
+type Config struct {
+	// Provider 模型提供方：ollama（默认）/ openai（任意 OpenAI 兼容接口）
+	Provider string
+	BaseURL  string
+	Model    string
+	// APIKey 仅 openai 需要
+	APIKey string
+	// Timeout 单次请求超时，<=0 时使用 DefaultTimeout
+	Timeout time.Duration
+	// KeepAlive 仅 ollama 生效，0 时使用 DefaultKeepAlive，负数表示模型常驻
+	KeepAlive time.Duration
+}
+
+// DefaultConfig 返回本地 Ollama 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Provider:  ProviderOllama,
+		BaseURL:   "http://localhost:11434",
+		Model:     "qwen3:0.6b",
+		Timeout:   DefaultTimeout,
+		KeepAlive: DefaultKeepAlive,
+	}
+}
+
+// ProviderName 规范化后的提供方名称（小写，未设置时为 ProviderOllama），与 NewChatModel 的判断一致
+func (c *Config) ProviderName() string {
+	if c.Provider == "" {
+		return ProviderOllama
+	}
+	return strings.ToLower(c.Provider)
+}
+
+// NewChatModel 按配置构建 ChatModel
+func NewChatModel(ctx context.Context, cfg *Config) (model.ChatModel, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	switch cfg.ProviderName() {
+	case ProviderOllama:
+		llm, err := ollama.NewChatModel(ctx, ollamaConfig(cfg, timeout))
+		if err != nil {
+			return nil, fmt.Errorf("create ollama chat model failed: %w", err)
+		}
+		return llm, nil
+
+	case ProviderOpenAI:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api key is required for provider %s", ProviderOpenAI)
+		}
+		llm, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
+			BaseURL: cfg.BaseURL,
+			Model:   cfg.Model,
+			APIKey:  cfg.APIKey,
+			Timeout: timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create openai chat model failed: %w", err)
+		}
+		return llm, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported model provider: %s", cfg.Provider)
+	}
+}
+
+// ollamaConfig 将通用配置转换为 Ollama 配置
+func ollamaConfig(cfg *Config, timeout time.Duration) *ollama.ChatModelConfig {
+	keepAlive := cfg.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	return &ollama.ChatModelConfig{
+		BaseURL:   cfg.BaseURL,
+		Model:     cfg.Model,
+		Timeout:   timeout,
+		KeepAlive: &keepAlive,
+	}
+}
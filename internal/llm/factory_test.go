@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestConfigProviderName(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{provider: "", want: ProviderOllama},
+		{provider: "ollama", want: ProviderOllama},
+		{provider: "OLLAMA", want: ProviderOllama},
+		{provider: "OpenAI", want: ProviderOpenAI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			if got := (&Config{Provider: tt.provider}).ProviderName(); got != tt.want {
+				t.Fatalf("ProviderName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		keepAlive     time.Duration
+		timeout       time.Duration
+		wantKeepAlive time.Duration
+	}{
+		{name: "default keep-alive", timeout: DefaultTimeout, wantKeepAlive: DefaultKeepAlive},
+		{name: "explicit values", keepAlive: 30 * time.Minute, timeout: time.Minute, wantKeepAlive: 30 * time.Minute},
+		{name: "negative keeps the model loaded", keepAlive: -1, timeout: time.Minute, wantKeepAlive: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{BaseURL: "http://ollama:11434", Model: "qwen3:0.6b", KeepAlive: tt.keepAlive}
+			got := ollamaConfig(cfg, tt.timeout)
+
+			if got.BaseURL != cfg.BaseURL || got.Model != cfg.Model {
+				t.Errorf("base url/model = %q/%q, want %q/%q", got.BaseURL, got.Model, cfg.BaseURL, cfg.Model)
+			}
+			if got.Timeout != tt.timeout {
+				t.Errorf("Timeout = %s, want %s", got.Timeout, tt.timeout)
+			}
+			if got.KeepAlive == nil || *got.KeepAlive != tt.wantKeepAlive {
+				t.Errorf("KeepAlive = %v, want %s", got.KeepAlive, tt.wantKeepAlive)
+			}
+		})
+	}
+}
+
+func TestNewChatModelRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr string
+	}{
+		{name: "missing model", cfg: &Config{Provider: ProviderOllama}, wantErr: "model name is required"},
+		{name: "openai without api key", cfg: &Config{Provider: ProviderOpenAI, Model: "gpt-4o-mini"}, wantErr: "api key is required"},
+		{name: "unsupported provider", cfg: &Config{Provider: "claude", Model: "m"}, wantErr: "unsupported model provider: claude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewChatModel(context.Background(), tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewChatModel err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewChatModelProviders(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		apiKey   string
+		wantPath string
+		// reply 按提供方协议返回的非流式响应
+		reply map[string]any
+	}{
+		{
+			name:     "ollama",
+			provider: "",
+			wantPath: "/api/chat",
+			reply: map[string]any{
+				"model":   "qwen3:0.6b",
+				"message": map[string]any{"role": "assistant", "content": "pong"},
+				"done":    true,
+			},
+		},
+		{
+			name:     "openai compatible",
+			provider: "OpenAI",
+			apiKey:   "sk-test",
+			wantPath: "/chat/completions",
+			reply: map[string]any{
+				"id":     "chatcmpl-1",
+				"object": "chat.completion",
+				"model":  "qwen3:0.6b",
+				"choices": []map[string]any{{
+					"index":         0,
+					"message":       map[string]any{"role": "assistant", "content": "pong"},
+					"finish_reason": "stop",
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth, gotModel string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+				var body struct {
+					Model string `json:"model"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				gotModel = body.Model
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(tt.reply)
+			}))
+			defer srv.Close()
+
+			cm, err := NewChatModel(context.Background(), &Config{
+				Provider: tt.provider,
+				BaseURL:  srv.URL,
+				Model:    "qwen3:0.6b",
+				APIKey:   tt.apiKey,
+				Timeout:  5 * time.Second,
+			})
+			if err != nil {
+				t.Fatalf("NewChatModel: %v", err)
+			}
+			msg, err := cm.Generate(context.Background(), []*schema.Message{schema.UserMessage("ping")})
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			if msg.Content != "pong" {
+				t.Errorf("Content = %q, want pong", msg.Content)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("request path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotModel != "qwen3:0.6b" {
+				t.Errorf("request model = %q, want qwen3:0.6b", gotModel)
+			}
+			if tt.apiKey != "" && gotAuth != "Bearer "+tt.apiKey {
+				t.Errorf("Authorization = %q, want bearer api key", gotAuth)
+			}
+		})
+	}
+}
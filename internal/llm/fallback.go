@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// FallbackChatModel 主模型超时或出错时按顺序尝试备用模型，全部失败才返回错误，
+// 由各节点再使用各自的静态兜底文案
+type FallbackChatModel struct {
+	models []model.ChatModel
+	// attemptTimeout 单个模型 Generate 的超时，<=0 时不单独限制
+	attemptTimeout time.Duration
+}
+
+// FallbackConfig 模型链配置
+type FallbackConfig struct {
+	// AttemptTimeout 单个模型 Generate 的超时，超时后切换到下一个模型；应小于节点整体的超时，
+	// 否则节点超时时已没有时间留给备用模型。<=0 时不单独限制
+	AttemptTimeout time.Duration
+}
+
+// NewFallbackChatModel 构建模型链，fallbacks 中的 nil 会被忽略；没有备用模型时直接返回主模型
+func NewFallbackChatModel(primary model.ChatModel, fallbacks ...model.ChatModel) model.ChatModel {
+	return NewFallbackChatModelWithConfig(FallbackConfig{}, primary, fallbacks...)
+}
+
+// NewFallbackChatModelWithConfig 按配置构建模型链，见 NewFallbackChatModel
+func NewFallbackChatModelWithConfig(cfg FallbackConfig, primary model.ChatModel, fallbacks ...model.ChatModel) model.ChatModel {
+	models := []model.ChatModel{primary}
+	for _, m := range fallbacks {
+		if m != nil {
+			models = append(models, m)
+		}
+	}
+	if len(models) == 1 {
+		return primary
+	}
+	return &FallbackChatModel{models: models, attemptTimeout: cfg.AttemptTimeout}
+}
+
+func (f *FallbackChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var lastErr error
+	for i, m := range f.models {
+		resp, err := f.generate(ctx, m, input, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if stopFallback(ctx) {
+			break
+		}
+		if i < len(f.models)-1 {
+			log.Printf("[LLM] model #%d generate failed: %v, falling back to model #%d", i, err, i+1)
+		}
+	}
+	return nil, fmt.Errorf("all %d models failed: %w", len(f.models), lastErr)
+}
+
+// generate 在单次尝试的超时内调用模型，超时返回 context.DeadlineExceeded，由调用方切换到下一个模型
+func (f *FallbackChatModel) generate(ctx context.Context, m model.ChatModel, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if f.attemptTimeout <= 0 {
+		return m.Generate(ctx, input, opts...)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, f.attemptTimeout)
+	defer cancel()
+	return m.Generate(attemptCtx, input, opts...)
+}
+
+// stopFallback 调用方已取消时不再尝试备用模型；单次尝试超时（包括模型客户端自身的超时）仍会回退
+func stopFallback(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
+// Stream 只在建立流失败时回退；流在返回后持续读取，不受 attemptTimeout 限制
+func (f *FallbackChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var lastErr error
+	for i, m := range f.models {
+		sr, err := m.Stream(ctx, input, opts...)
+		if err == nil {
+			return sr, nil
+		}
+		lastErr = err
+		if stopFallback(ctx) {
+			break
+		}
+		if i < len(f.models)-1 {
+			log.Printf("[LLM] model #%d stream failed: %v, falling back to model #%d", i, err, i+1)
+		}
+	}
+	return nil, fmt.Errorf("all %d models failed: %w", len(f.models), lastErr)
+}
+
+// BindTools 同时绑定到链上的所有模型，保证回退后工具调用仍可用
+func (f *FallbackChatModel) BindTools(tools []*schema.ToolInfo) error {
+	for i, m := range f.models {
+		if err := m.BindTools(tools); err != nil {
+			return fmt.Errorf("bind tools to model #%d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeModel 按配置返回错误、阻塞到 ctx 结束或返回固定内容
+type fakeModel struct {
+	reply string
+	err   error
+	block bool
+	calls int
+}
+
+func (m *fakeModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	if m.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(m.reply, nil), nil
+}
+
+func (m *fakeModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *fakeModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestFallbackChatModelGenerate(t *testing.T) {
+	tests := []struct {
+		name           string
+		primary        *fakeModel
+		attemptTimeout time.Duration
+		cancelParent   bool
+		want           string
+		wantErr        bool
+		wantSecondary  int
+	}{
+		{name: "primary succeeds", primary: &fakeModel{reply: "primary"}, want: "primary"},
+		{name: "primary errors", primary: &fakeModel{err: errors.New("boom")}, want: "secondary", wantSecondary: 1},
+		{name: "client timeout", primary: &fakeModel{err: context.DeadlineExceeded}, want: "secondary", wantSecondary: 1},
+		{name: "attempt timeout", primary: &fakeModel{block: true}, attemptTimeout: 20 * time.Millisecond, want: "secondary", wantSecondary: 1},
+		{name: "caller cancelled", primary: &fakeModel{err: errors.New("boom")}, cancelParent: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secondary := &fakeModel{reply: "secondary"}
+			chain := NewFallbackChatModelWithConfig(FallbackConfig{AttemptTimeout: tt.attemptTimeout}, tt.primary, secondary)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelParent {
+				cancel()
+			}
+
+			resp, err := chain.Generate(ctx, []*schema.Message{schema.UserMessage("q")})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %q", resp.Content)
+				}
+			} else if err != nil {
+				t.Fatalf("Generate: %v", err)
+			} else if resp.Content != tt.want {
+				t.Errorf("content = %q, want %q", resp.Content, tt.want)
+			}
+			if secondary.calls != tt.wantSecondary {
+				t.Errorf("secondary called %d times, want %d", secondary.calls, tt.wantSecondary)
+			}
+		})
+	}
+}
+
+func TestNewFallbackChatModelWithoutFallbacks(t *testing.T) {
+	primary := &fakeModel{reply: "primary"}
+	if got := NewFallbackChatModel(primary, nil); got != primary {
+		t.Errorf("got %T, want the primary model itself", got)
+	}
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino-ext/components/model/ollama"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrInvalidSampling 采样参数超出取值范围
+var ErrInvalidSampling = errors.New("invalid sampling params")
+
+// Sampling 单次请求的采样参数，nil 字段保持模型配置的默认值
+type Sampling struct {
+	// Temperature 取值 [0, 2]，0 为贪心解码
+	Temperature *float32
+	// TopP 取值 (0, 1]
+	TopP *float32
+	// Seed 随机种子，后端不支持时忽略（Ollama 原生支持，OpenAI 兼容接口透传 seed 字段）
+	Seed *int
+}
+
+// Validate 校验取值范围
+func (s Sampling) Validate() error {
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return fmt.Errorf("%w: temperature must be in [0, 2]", ErrInvalidSampling)
+	}
+	if s.TopP != nil && (*s.TopP <= 0 || *s.TopP > 1) {
+		return fmt.Errorf("%w: top_p must be in (0, 1]", ErrInvalidSampling)
+	}
+	return nil
+}
+
+// IsZero 是否未指定任何参数
+func (s Sampling) IsZero() bool {
+	return s.Temperature == nil && s.TopP == nil && s.Seed == nil
+}
+
+// Options 转换为模型调用选项
+func (s Sampling) Options() []model.Option {
+	var opts []model.Option
+	if s.Temperature != nil {
+		opts = append(opts, model.WithTemperature(*s.Temperature))
+	}
+	if s.TopP != nil {
+		opts = append(opts, model.WithTopP(*s.TopP))
+	}
+	if s.Seed != nil {
+		opts = append(opts,
+			ollama.WithSeed(*s.Seed),
+			openai.WithExtraFields(map[string]any{"seed": *s.Seed}),
+		)
+	}
+	return opts
+}
+
+type samplingKey struct{}
+
+// WithSampling 指定本次请求的采样参数，经 SamplingChatModel 的每次模型调用都会带上
+func WithSampling(ctx context.Context, s Sampling) context.Context {
+	return context.WithValue(ctx, samplingKey{}, s)
+}
+
+// SamplingFrom 返回 context 中的采样参数
+func SamplingFrom(ctx context.Context) (Sampling, bool) {
+	s, ok := ctx.Value(samplingKey{}).(Sampling)
+	return s, ok
+}
+
+// SamplingChatModel 将 context 中的采样参数追加到模型调用选项，调用方显式传入的选项优先；
+// context 中没有采样参数时与原模型行为一致
+type SamplingChatModel struct {
+	model model.ChatModel
+}
+
+// NewSamplingChatModel 为模型加上按请求控制采样参数的能力
+func NewSamplingChatModel(m model.ChatModel) *SamplingChatModel {
+	return &SamplingChatModel{model: m}
+}
+
+func (s *SamplingChatModel) withOptions(ctx context.Context, opts []model.Option) []model.Option {
+	sampling, ok := SamplingFrom(ctx)
+	if !ok || sampling.IsZero() {
+		return opts
+	}
+	// 后应用的选项覆盖先应用的，采样参数放在前面
+	return append(sampling.Options(), opts...)
+}
+
+func (s *SamplingChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return s.model.Generate(ctx, input, s.withOptions(ctx, opts)...)
+}
+
+func (s *SamplingChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return s.model.Stream(ctx, input, s.withOptions(ctx, opts)...)
+}
+
+func (s *SamplingChatModel) BindTools(tools []*schema.ToolInfo) error {
+	return s.model.BindTools(tools)
+}
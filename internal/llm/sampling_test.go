@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// optionsModel 记录每次调用收到的模型选项
+type optionsModel struct {
+	opts []model.Option
+}
+
+func (m *optionsModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.opts = opts
+	return schema.AssistantMessage("ok", nil), nil
+}
+
+func (m *optionsModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *optionsModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func float32Ptr(v float32) *float32 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func TestSamplingChatModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling *Sampling
+		callOpts []model.Option
+		wantOpts int
+		wantTemp *float32
+		wantTopP *float32
+	}{
+		{name: "no sampling leaves options untouched"},
+		{name: "zero sampling leaves options untouched", sampling: &Sampling{}},
+		{
+			name:     "temperature and top_p",
+			sampling: &Sampling{Temperature: float32Ptr(0), TopP: float32Ptr(0.9)},
+			wantOpts: 2,
+			wantTemp: float32Ptr(0),
+			wantTopP: float32Ptr(0.9),
+		},
+		{name: "seed is passed to ollama and openai", sampling: &Sampling{Seed: intPtr(42)}, wantOpts: 2},
+		{
+			name:     "explicit call option wins",
+			sampling: &Sampling{Temperature: float32Ptr(0.2)},
+			callOpts: []model.Option{model.WithTemperature(1.5)},
+			wantOpts: 2,
+			wantTemp: float32Ptr(1.5),
+		},
+	}
+	for _, tt := range tests {
+		for _, stream := range []bool{false, true} {
+			name := tt.name
+			if stream {
+				name += " (stream)"
+			}
+			t.Run(name, func(t *testing.T) {
+				inner := &optionsModel{}
+				m := NewSamplingChatModel(inner)
+				ctx := context.Background()
+				if tt.sampling != nil {
+					ctx = WithSampling(ctx, *tt.sampling)
+				}
+
+				input := []*schema.Message{schema.UserMessage("分析BV1")}
+				var err error
+				if stream {
+					_, err = m.Stream(ctx, input, tt.callOpts...)
+				} else {
+					_, err = m.Generate(ctx, input, tt.callOpts...)
+				}
+				if err != nil {
+					t.Fatalf("call: %v", err)
+				}
+
+				if len(inner.opts) != tt.wantOpts {
+					t.Fatalf("model received %d options, want %d", len(inner.opts), tt.wantOpts)
+				}
+				common := model.GetCommonOptions(&model.Options{}, inner.opts...)
+				if !equalFloat32Ptr(common.Temperature, tt.wantTemp) {
+					t.Errorf("temperature = %v, want %v", deref(common.Temperature), deref(tt.wantTemp))
+				}
+				if !equalFloat32Ptr(common.TopP, tt.wantTopP) {
+					t.Errorf("top_p = %v, want %v", deref(common.TopP), deref(tt.wantTopP))
+				}
+			})
+		}
+	}
+}
+
+func equalFloat32Ptr(a, b *float32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(v *float32) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestSamplingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Sampling
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "greedy decoding", s: Sampling{Temperature: float32Ptr(0), Seed: intPtr(7)}},
+		{name: "upper bounds", s: Sampling{Temperature: float32Ptr(2), TopP: float32Ptr(1)}},
+		{name: "negative temperature", s: Sampling{Temperature: float32Ptr(-0.1)}, wantErr: true},
+		{name: "temperature above 2", s: Sampling{Temperature: float32Ptr(2.1)}, wantErr: true},
+		{name: "zero top_p", s: Sampling{TopP: float32Ptr(0)}, wantErr: true},
+		{name: "top_p above 1", s: Sampling{TopP: float32Ptr(1.1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidSampling)) {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package logger 提供分级日志接口（debug/info/warn/error），默认基于标准库 log 输出
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Level 日志级别
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String 返回级别名称，用于日志行前缀
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// ParseLevel 解析级别名称（不区分大小写），支持 debug/info/warn(warning)/error
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level: %s", s)
+	}
+}
+
+// Logger 分级日志接口，各组件通过 SetLogger / WithLogger 注入
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// StdLogger 基于标准库 log 的实现，输出形如 "2006/01/02 15:04:05 WARN [Graph] ..."
+type StdLogger struct {
+	out   *log.Logger
+	level Level
+}
+
+// NewStdLogger 创建输出到标准库默认 logger 的实现，低于 level 的日志被丢弃
+func NewStdLogger(level Level) *StdLogger {
+	return NewStdLoggerWithOutput(log.Default(), level)
+}
+
+// NewStdLoggerWithOutput 使用指定的 *log.Logger 输出，out 为 nil 时使用 log.Default()
+func NewStdLoggerWithOutput(out *log.Logger, level Level) *StdLogger {
+	if out == nil {
+		out = log.Default()
+	}
+	return &StdLogger{out: out, level: level}
+}
+
+func (l *StdLogger) logf(level Level, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	// calldepth 3: Output <- logf <- Debugf/Infof/... <- 调用方
+	_ = l.out.Output(3, level.String()+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *StdLogger) Debugf(format string, args ...interface{}) { l.logf(LevelDebug, format, args...) }
+func (l *StdLogger) Infof(format string, args ...interface{})  { l.logf(LevelInfo, format, args...) }
+func (l *StdLogger) Warnf(format string, args ...interface{})  { l.logf(LevelWarn, format, args...) }
+func (l *StdLogger) Errorf(format string, args ...interface{}) { l.logf(LevelError, format, args...) }
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger Logger = NewStdLogger(LevelInfo)
+)
+
+// Default 返回全局默认 Logger，未注入 Logger 的组件使用它
+func Default() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault 替换全局默认 Logger，传 nil 忽略
+func SetDefault(l Logger) {
+	if l == nil {
+		return
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// OrDefault l 为 nil 时返回 Default()
+func OrDefault(l Logger) Logger {
+	if l == nil {
+		return Default()
+	}
+	return l
+}
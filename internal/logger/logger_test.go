@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestStdLoggerLevel(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  []string
+	}{
+		{level: LevelDebug, want: []string{"DEBUG d", "INFO i", "WARN w", "ERROR e"}},
+		{level: LevelInfo, want: []string{"INFO i", "WARN w", "ERROR e"}},
+		{level: LevelError, want: []string{"ERROR e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewStdLoggerWithOutput(log.New(&buf, "", 0), tt.level)
+
+			l.Debugf("d")
+			l.Infof("i")
+			l.Warnf("w")
+			l.Errorf("e")
+
+			if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("log lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{in: "", want: LevelInfo},
+		{in: "DEBUG", want: LevelDebug},
+		{in: " warning ", want: LevelWarn},
+		{in: "error", want: LevelError},
+		{in: "verbose", want: LevelInfo, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TraceIDHeader 传递追踪 ID 的 HTTP 头 / gRPC metadata 键（gRPC metadata 键为小写）
+const TraceIDHeader = "X-Trace-ID"
+
+// maxTraceIDLength 接受的外部追踪 ID 最大长度
+const maxTraceIDLength = 64
+
+type traceIDKey struct{}
+
+// ValidTraceID 校验外部传入的追踪 ID：非空、不超过 maxTraceIDLength，且只包含字母、数字与 - _ .，
+// 避免把换行、格式化动词等写进日志
+func ValidTraceID(id string) bool {
+	if id == "" || len(id) > maxTraceIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithTraceID 将追踪 ID 写入 context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID 读取 context 中的追踪 ID，不存在时返回空字符串
+func TraceID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// EnsureTraceID context 中已有追踪 ID 时直接返回，否则生成新的 ID 写入 context
+func EnsureTraceID(ctx context.Context) (context.Context, string) {
+	if id := TraceID(ctx); id != "" {
+		return ctx, id
+	}
+	id := uuid.New().String()
+	return WithTraceID(ctx, id), id
+}
+
+// traceLogger 在每条日志前加上 [trace=<id>]，前缀作为参数传入，不参与格式化
+type traceLogger struct {
+	Logger
+	prefix string
+}
+
+// WithTrace 返回带上 context 中追踪 ID 的 Logger；没有追踪 ID 时原样返回
+func WithTrace(ctx context.Context, l Logger) Logger {
+	l = OrDefault(l)
+	id := TraceID(ctx)
+	if id == "" {
+		return l
+	}
+	return &traceLogger{Logger: l, prefix: "[trace=" + id + "] "}
+}
+
+func (t *traceLogger) Debugf(format string, args ...interface{}) {
+	t.Logger.Debugf("%s"+format, append([]interface{}{t.prefix}, args...)...)
+}
+func (t *traceLogger) Infof(format string, args ...interface{}) {
+	t.Logger.Infof("%s"+format, append([]interface{}{t.prefix}, args...)...)
+}
+func (t *traceLogger) Warnf(format string, args ...interface{}) {
+	t.Logger.Warnf("%s"+format, append([]interface{}{t.prefix}, args...)...)
+}
+func (t *traceLogger) Errorf(format string, args ...interface{}) {
+	t.Logger.Errorf("%s"+format, append([]interface{}{t.prefix}, args...)...)
+}
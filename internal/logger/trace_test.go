@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestValidTraceID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"", false},
+		{"4f9c2a1e-8b7d-4c3e-9a6f-1d2e3f4a5b6c", true},
+		{"req_123.abc", true},
+		{"%s%n", false},
+		{"abc\n2026/01/01 INFO forged", false},
+		{"有中文", false},
+		{strings.Repeat("a", maxTraceIDLength), true},
+		{strings.Repeat("a", maxTraceIDLength+1), false},
+	}
+	for _, tt := range tests {
+		if got := ValidTraceID(tt.id); got != tt.want {
+			t.Errorf("ValidTraceID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestWithTraceDoesNotFormatPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		traceID string
+		format  string
+		args    []interface{}
+		want    string
+	}{
+		{name: "plain", traceID: "abc", format: "done %d", args: []interface{}{3}, want: "[trace=abc] done 3"},
+		{name: "verbs in trace id", traceID: "%s%d", format: "user %s", args: []interface{}{"u1"}, want: "[trace=%s%d] user u1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewStdLoggerWithOutput(log.New(&buf, "", 0), LevelDebug)
+			ctx := WithTraceID(context.Background(), tt.traceID)
+
+			WithTrace(ctx, l).Infof(tt.format, tt.args...)
+
+			if got := strings.TrimSpace(buf.String()); got != "INFO "+tt.want {
+				t.Errorf("log = %q, want %q", got, "INFO "+tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrMalformedToolResult 工具结果无法解析为 JSON 对象（非法 JSON、非法 base64、空 content 等）
+	ErrMalformedToolResult = errors.New("malformed tool result")
+	// ErrToolResultIsError 工具返回了 isError 为 true 的错误结果，错误码可通过 types.ParseToolError 解析
+	ErrToolResultIsError = errors.New("tool returned error result")
+)
+
+// DecodeToolResult 解码 MCP 工具结果为 JSON 对象，兼容以下形式：
+//   - string / []byte / map[string]interface{}，JSON 字符串字面量会先去掉一层引号
+//   - MCP 包装 {"content":[{"type":"text","text":...}]}，text 为 JSON 或 base64 编码的 JSON
+//   - structuredContent 字段（对象，或 base64 编码的 JSON），优先于 content
+//   - 不带包装的 JSON 对象，原样返回
+//
+// 解析失败返回包装 ErrMalformedToolResult 的错误，isError 结果返回包装 ErrToolResultIsError 的错误
+func DecodeToolResult(raw interface{}) (map[string]interface{}, error) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		return decodeEnvelope(v)
+	case string:
+		return decodeText(v)
+	case []byte:
+		return decodeText(string(v))
+	case nil:
+		return nil, fmt.Errorf("%w: empty result", ErrMalformedToolResult)
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %T", ErrMalformedToolResult, raw)
+	}
+}
+
+// decodeText 解析文本形式的结果：JSON 字符串字面量先解一层，非 JSON 时按 base64 解码
+func decodeText(text string) (map[string]interface{}, error) {
+	text = strings.TrimSpace(text)
+	var inner string
+	if err := json.Unmarshal([]byte(text), &inner); err == nil {
+		text = strings.TrimSpace(inner)
+	}
+
+	payload, err := decodePayload(text)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEnvelope(payload)
+}
+
+// decodeEnvelope 拆开 MCP 结果包装，没有包装时返回对象本身
+func decodeEnvelope(m map[string]interface{}) (map[string]interface{}, error) {
+	if isError, _ := m["isError"].(bool); isError {
+		text, _ := firstContentText(m)
+		return nil, fmt.Errorf("%w: %s", ErrToolResultIsError, text)
+	}
+
+	switch structured := m["structuredContent"].(type) {
+	case map[string]interface{}:
+		return structured, nil
+	case string:
+		if structured != "" {
+			payload, err := decodePayload(structured)
+			if err != nil {
+				return nil, fmt.Errorf("structuredContent: %w", err)
+			}
+			return payload, nil
+		}
+	}
+
+	if _, ok := m["content"]; !ok {
+		return m, nil
+	}
+	text, err := firstContentText(m)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := decodePayload(strings.Trim(strings.TrimSpace(text), "\""))
+	if err != nil {
+		return nil, fmt.Errorf("content text: %w", err)
+	}
+	return payload, nil
+}
+
+// firstContentText 返回 content 中第一个带 text 字段的元素的文本
+func firstContentText(m map[string]interface{}) (string, error) {
+	content, ok := m["content"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("%w: content is %T, want array", ErrMalformedToolResult, m["content"])
+	}
+	for _, item := range content {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if text, ok := entry["text"].(string); ok {
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("%w: content has no text item", ErrMalformedToolResult)
+}
+
+// decodePayload 将文本解析为 JSON 对象，不以 '{' 开头时视为 base64 编码的 JSON
+func decodePayload(text string) (map[string]interface{}, error) {
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty payload", ErrMalformedToolResult)
+	}
+
+	data := []byte(text)
+	if !strings.HasPrefix(text, "{") {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: payload is neither a JSON object nor valid base64: %v", ErrMalformedToolResult, err)
+		}
+		data = decoded
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON object: %v", ErrMalformedToolResult, err)
+	}
+	return payload, nil
+}
+
+// ToolResultText 返回工具结果中供模型阅读的文本，不会因为结果不是 JSON 对象而丢失内容：
+//   - 载荷为 JSON 对象时返回紧凑的 JSON
+//   - MCP 包装中载荷不是对象（纯文本、JSON 数组、base64 字符串）时，返回 content 中全部文本项按行拼接，
+//     base64 编码的文本解码后使用；只有 base64 字符串形式的 structuredContent 时返回其解码内容
+//   - 不是 MCP 包装的文本原样返回（base64 编码的文本解码后返回）
+func ToolResultText(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if payload, err := DecodeToolResult(raw); err == nil {
+		data, _ := json.Marshal(payload)
+		return string(data)
+	}
+
+	text := raw
+	var inner string
+	if err := json.Unmarshal([]byte(text), &inner); err == nil {
+		text = strings.TrimSpace(inner)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &envelope); err != nil {
+		return decodeBase64Text(text)
+	}
+
+	var texts []string
+	if content, ok := envelope["content"].([]interface{}); ok {
+		for _, item := range content {
+			entry, _ := item.(map[string]interface{})
+			if t, ok := entry["text"].(string); ok && t != "" {
+				texts = append(texts, decodeBase64Text(strings.Trim(strings.TrimSpace(t), "\"")))
+			}
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n")
+	}
+	if structured, ok := envelope["structuredContent"].(string); ok && structured != "" {
+		return decodeBase64Text(structured)
+	}
+	return text
+}
+
+// decodeBase64Text 文本是 base64 编码的可读 UTF-8 文本时返回解码结果，否则原样返回
+func decodeBase64Text(text string) string {
+	decoded, err := base64.StdEncoding.DecodeString(text)
+	if err != nil || len(decoded) == 0 || !utf8.Valid(decoded) {
+		return text
+	}
+	for _, r := range string(decoded) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return text
+		}
+	}
+	return string(decoded)
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func b64(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+func TestDecodeToolResult(t *testing.T) {
+	want := map[string]interface{}{"views": float64(10)}
+	tests := []struct {
+		name    string
+		raw     interface{}
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{name: "plain object", raw: `{"views":10}`, want: want},
+		{name: "bytes", raw: []byte(`{"views":10}`), want: want},
+		{name: "json string literal", raw: `"{\"views\":10}"`, want: want},
+		{name: "content text", raw: `{"content":[{"type":"text","text":"{\"views\":10}"}]}`, want: want},
+		{name: "content base64", raw: `{"content":[{"type":"text","text":"` + b64(`{"views":10}`) + `"}]}`, want: want},
+		{name: "structured object", raw: map[string]interface{}{"structuredContent": want, "content": []interface{}{}}, want: want},
+		{name: "structured base64", raw: `{"structuredContent":"` + b64(`{"views":10}`) + `"}`, want: want},
+		{name: "is error", raw: `{"isError":true,"content":[{"type":"text","text":"not found"}]}`, wantErr: ErrToolResultIsError},
+		{name: "plain text content", raw: `{"content":[{"type":"text","text":"视频不存在"}]}`, wantErr: ErrMalformedToolResult},
+		{name: "array payload", raw: `{"content":[{"type":"text","text":"[1,2]"}]}`, wantErr: ErrMalformedToolResult},
+		{name: "nil", raw: nil, wantErr: ErrMalformedToolResult},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeToolResult(tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeToolResult: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolResultText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "object payload", raw: `{"content":[{"type":"text","text":"{\"views\":10}"}]}`, want: `{"views":10}`},
+		{name: "plain text content", raw: `{"content":[{"type":"text","text":"视频不存在"}]}`, want: "视频不存在"},
+		{name: "multiple text items", raw: `{"content":[{"type":"text","text":"第一段"},{"type":"image","data":"x"},{"type":"text","text":"第二段"}]}`, want: "第一段\n第二段"},
+		{name: "array payload", raw: `{"content":[{"type":"text","text":"[1,2]"}]}`, want: "[1,2]"},
+		{name: "base64 text content", raw: `{"content":[{"type":"text","text":"` + b64("hello world") + `"}]}`, want: "hello world"},
+		{name: "base64 array", raw: `{"content":[{"type":"text","text":"` + b64(`[{"id":1}]`) + `"}]}`, want: `[{"id":1}]`},
+		{name: "is error text", raw: `{"isError":true,"content":[{"type":"text","text":"not found"}]}`, want: "not found"},
+		{name: "raw text", raw: "  just text ", want: "just text"},
+		{name: "raw base64", raw: b64("你好"), want: "你好"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToolResultText(tt.raw); got != tt.want {
+				t.Errorf("ToolResultText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
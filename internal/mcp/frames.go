@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// FrameStrategy 关键帧提取策略
+type FrameStrategy string
+
+const (
+	// FrameStrategyInterval 按固定间隔取帧
+	FrameStrategyInterval FrameStrategy = "interval"
+	// FrameStrategySceneChange 在画面变化超过阈值处取帧，捕捉镜头切换等关键时刻
+	FrameStrategySceneChange FrameStrategy = "scene_change"
+)
+
+// DefaultSceneThreshold 场景切换的默认差异阈值，取值 [0, 1]
+const DefaultSceneThreshold = 0.3
+
+// ParseFrameStrategy 解析取帧策略，为空时为 FrameStrategyInterval
+func ParseFrameStrategy(s string) (FrameStrategy, error) {
+	switch FrameStrategy(s) {
+	case "", FrameStrategyInterval:
+		return FrameStrategyInterval, nil
+	case FrameStrategySceneChange:
+		return FrameStrategySceneChange, nil
+	default:
+		return "", fmt.Errorf("unsupported frame strategy %q, want %s or %s", s, FrameStrategyInterval, FrameStrategySceneChange)
+	}
+}
+
+// FrameSample 候选帧：时间戳（秒）与归一化颜色直方图
+type FrameSample struct {
+	Timestamp float64
+	Histogram []float64
+}
+
+// FrameSampler 解码视频，返回按时间排序的候选帧
+type FrameSampler interface {
+	Sample(ctx context.Context, videoURL string) ([]FrameSample, error)
+}
+
+// SelectFrames 从按时间排序的候选帧中选取关键帧，最多 maxFrames 帧（<=0 不限制）。
+// interval 策略每隔 interval 秒取一帧；scene_change 策略取首帧以及与上一选中帧差异超过 threshold 的帧，
+// threshold 为 0 时任何画面变化都会取帧，负数时使用 DefaultSceneThreshold
+func SelectFrames(samples []FrameSample, strategy FrameStrategy, interval, threshold float64, maxFrames int) []FrameSample {
+	var selected []FrameSample
+	full := func() bool { return maxFrames > 0 && len(selected) >= maxFrames }
+
+	switch strategy {
+	case FrameStrategySceneChange:
+		if threshold < 0 {
+			threshold = DefaultSceneThreshold
+		}
+		for i, sample := range samples {
+			if full() {
+				break
+			}
+			if i == 0 || histogramDistance(selected[len(selected)-1].Histogram, sample.Histogram) > threshold {
+				selected = append(selected, sample)
+			}
+		}
+	default:
+		next := math.Inf(-1)
+		for _, sample := range samples {
+			if full() {
+				break
+			}
+			if sample.Timestamp >= next {
+				selected = append(selected, sample)
+				next = sample.Timestamp + interval
+			}
+		}
+	}
+	return selected
+}
+
+// histogramDistance 两个归一化直方图的差异（L1 距离的一半），取值 [0, 1]；长度不同时按较长者补零
+func histogramDistance(a, b []float64) float64 {
+	n := max(len(a), len(b))
+	var sum float64
+	for i := 0; i < n; i++ {
+		var x, y float64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		sum += math.Abs(x - y)
+	}
+	return sum / 2
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// sceneSequence 三个镜头：0-3 秒、4-6 秒、7-9 秒，第 2 秒有轻微画面抖动
+func sceneSequence() []FrameSample {
+	sceneA, sceneB, sceneC := []float64{1, 0, 0}, []float64{0, 1, 0}, []float64{0, 0, 1}
+	samples := make([]FrameSample, 0, 10)
+	for ts := 0; ts < 10; ts++ {
+		hist := sceneA
+		switch {
+		case ts == 2:
+			hist = []float64{0.95, 0.05, 0}
+		case ts >= 7:
+			hist = sceneC
+		case ts >= 4:
+			hist = sceneB
+		}
+		samples = append(samples, FrameSample{Timestamp: float64(ts), Histogram: hist})
+	}
+	return samples
+}
+
+func timestamps(frames []FrameSample) []float64 {
+	ts := make([]float64, 0, len(frames))
+	for _, f := range frames {
+		ts = append(ts, f.Timestamp)
+	}
+	return ts
+}
+
+func TestSelectFrames(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  FrameStrategy
+		interval  float64
+		threshold float64
+		maxFrames int
+		want      []float64
+	}{
+		{name: "scene change picks transitions", strategy: FrameStrategySceneChange, threshold: DefaultSceneThreshold, want: []float64{0, 4, 7}},
+		{name: "zero threshold picks every change", strategy: FrameStrategySceneChange, threshold: 0, want: []float64{0, 2, 3, 4, 7}},
+		{name: "negative threshold uses default", strategy: FrameStrategySceneChange, threshold: -1, want: []float64{0, 4, 7}},
+		{name: "scene change respects max frames", strategy: FrameStrategySceneChange, threshold: DefaultSceneThreshold, maxFrames: 2, want: []float64{0, 4}},
+		{name: "fixed interval", strategy: FrameStrategyInterval, interval: 3, want: []float64{0, 3, 6, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timestamps(SelectFrames(sceneSequence(), tt.strategy, tt.interval, tt.threshold, tt.maxFrames))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSampler 返回固定的候选帧
+type fakeSampler struct {
+	samples []FrameSample
+}
+
+func (s fakeSampler) Sample(ctx context.Context, videoURL string) ([]FrameSample, error) {
+	return s.samples, nil
+}
+
+func TestFrameExtractionToolExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		tool       *FrameExtractionTool
+		params     map[string]interface{}
+		wantErr    bool
+		wantStatus string
+		want       []float64
+	}{
+		{name: "scene change with default threshold", tool: NewFrameExtractionTool(fakeSampler{sceneSequence()}),
+			params: map[string]interface{}{"strategy": "scene_change"}, wantStatus: "completed", want: []float64{0, 4, 7}},
+		{name: "explicit zero threshold is honored", tool: NewFrameExtractionTool(fakeSampler{sceneSequence()}),
+			params: map[string]interface{}{"strategy": "scene_change", "threshold": 0.0}, wantStatus: "completed", want: []float64{0, 2, 3, 4, 7}},
+		{name: "interval strategy", tool: NewFrameExtractionTool(fakeSampler{sceneSequence()}),
+			params: map[string]interface{}{"interval": 3.0}, wantStatus: "completed", want: []float64{0, 3, 6, 9}},
+		{name: "no sampler", tool: &FrameExtractionTool{},
+			params: map[string]interface{}{"strategy": "scene_change"}, wantStatus: "extracting", want: []float64{}},
+		{name: "threshold out of range", tool: &FrameExtractionTool{},
+			params: map[string]interface{}{"strategy": "scene_change", "threshold": 1.5}, wantErr: true},
+		{name: "unknown strategy", tool: &FrameExtractionTool{},
+			params: map[string]interface{}{"strategy": "random"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["video_url"] = "https://example.com/BV1.mp4"
+			out, err := tt.tool.Execute(context.Background(), tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			result := out.(map[string]interface{})
+			if result["status"] != tt.wantStatus {
+				t.Errorf("status = %v, want %s", result["status"], tt.wantStatus)
+			}
+			got := []float64{}
+			for _, frame := range result["frames"].([]map[string]interface{}) {
+				got = append(got, frame["timestamp"].(float64))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("frames %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -197,7 +197,6 @@ func (t *GatewayVideoTool) callGatewayAPI(ctx context.Context, videoID string) (
 	if err := json.Unmarshal(body, &gatewayResp); err == nil {
 		log.Printf("🔧 [GatewayVideoTool] 解析为包装格式 | Code: %d, Message: %s",
 			gatewayResp.Code, gatewayResp.Message)
-		log.Println("gatewayResp %v", gatewayResp.Data.Video)
 		if gatewayResp.Code == 0 || gatewayResp.Code == 200 {
 			if gatewayResp.Data != nil && gatewayResp.Data.Video != nil {
 				log.Printf("✅ [GatewayVideoTool] 成功解析视频数据 | VideoID: %d, Title: %s",
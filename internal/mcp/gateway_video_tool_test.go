@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGatewayVideoToolParsesResponses(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantTitle string
+	}{
+		{name: "wrapped video", body: `{"code":0,"message":"success","data":{"video":{"video_id":1,"title":"AI 绘画入门"}}}`, wantTitle: "AI 绘画入门"},
+		{name: "wrapped without data falls back to direct parsing", body: `{"code":0,"message":"success","data":null}`},
+		{name: "direct video", body: `{"video_id":1,"title":"折叠屏评测"}`, wantTitle: "折叠屏评测"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/video/1" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			result, err := NewGatewayVideoTool(srv.URL).Execute(context.Background(), map[string]interface{}{"video_id": "1"})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if title := result.(map[string]interface{})["title"]; title != tt.wantTitle {
+				t.Errorf("title = %v, want %q", title, tt.wantTitle)
+			}
+		})
+	}
+}
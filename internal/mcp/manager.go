@@ -5,9 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"sync"
+	"time"
 
+	"video_agent/internal/logger"
 	"video_agent/mcp_client"
 
 	"github.com/cloudwego/eino/components/tool"
@@ -20,11 +21,18 @@ type Manager struct {
 
 	// 缓存的工具列表
 	tools       []tool.BaseTool
-	toolsMu     sync.RWMutex
+	toolsMu     sync.Mutex
 	toolsLoaded bool
+	// loading 进行中的工具拉取，并发的 GetTools/RefreshTools 共享同一次拉取
+	loading *toolsLoad
 
 	// 配置
 	config *ManagerConfig
+
+	log logger.Logger
+
+	metrics       *ToolMetrics
+	slowThreshold time.Duration
 }
 
 // ManagerConfig MCP管理器配置
@@ -45,37 +53,93 @@ func NewManager(config *ManagerConfig) (*Manager, error) {
 		return nil, fmt.Errorf("连接远程MCP Server失败: %w", err)
 	}
 
-	log.Printf("✅ [MCP Manager] 远程MCP连接成功 | Transport: %s", config.RemoteConfig.Transport)
+	log := logger.Default()
+	log.Infof("[MCP Manager] 远程MCP连接成功 | Transport: %s", config.RemoteConfig.Transport)
 
 	return &Manager{
-		client: client,
-		config: config,
+		client:        client,
+		config:        config,
+		log:           log,
+		slowThreshold: DefaultSlowToolThreshold,
 	}, nil
 }
 
-// GetTools 从远程MCP Server获取所有可用工具
+// SetLogger 设置日志实现，传 nil 忽略（默认使用 logger.Default()）
+func (m *Manager) SetLogger(l logger.Logger) {
+	if l != nil {
+		m.log = l
+	}
+}
+
+// SetMetrics 设置工具调用指标，为 nil 时不记录
+func (m *Manager) SetMetrics(metrics *ToolMetrics) {
+	m.metrics = metrics
+}
+
+// SetSlowThreshold 设置慢调用告警阈值，<=0 关闭慢调用告警
+func (m *Manager) SetSlowThreshold(threshold time.Duration) {
+	m.slowThreshold = threshold
+}
+
+// toolsLoad 一次工具拉取，done 关闭后 tools/err 可读
+type toolsLoad struct {
+	done  chan struct{}
+	tools []tool.BaseTool
+	err   error
+}
+
+// GetTools 从远程MCP Server获取所有可用工具，刷新进行中时等待刷新结果而不是返回旧列表
 func (m *Manager) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
-	// 检查缓存
-	m.toolsMu.RLock()
+	return m.loadTools(ctx, false)
+}
+
+// loadTools 返回缓存的工具列表；缓存失效或 force 时拉取，已有拉取在进行中则复用其结果。
+// 共享的拉取使用发起者的 ctx，发起者取消时等待者会收到同样的错误
+func (m *Manager) loadTools(ctx context.Context, force bool) ([]tool.BaseTool, error) {
+	m.toolsMu.Lock()
+	if force {
+		m.toolsLoaded = false
+		m.tools = nil
+	}
 	if m.toolsLoaded {
 		tools := m.tools
-		m.toolsMu.RUnlock()
+		m.toolsMu.Unlock()
 		return tools, nil
 	}
-	m.toolsMu.RUnlock()
+	if load := m.loading; load != nil {
+		m.toolsMu.Unlock()
+		select {
+		case <-load.done:
+			return load.tools, load.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	load := &toolsLoad{done: make(chan struct{})}
+	m.loading = load
+	m.toolsMu.Unlock()
 
 	// 从远程MCP获取工具
 	tools, err := m.client.GetTools(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("从远程MCP获取工具失败: %w", err)
+		logger.WithTrace(ctx, m.log).Errorf("[MCP Manager] 从远程MCP获取工具失败: %v", err)
+		err = fmt.Errorf("从远程MCP获取工具失败: %w", err)
 	}
 
 	m.toolsMu.Lock()
-	m.tools = tools
-	m.toolsLoaded = true
+	if err == nil {
+		m.tools = tools
+		m.toolsLoaded = true
+	}
+	m.loading = nil
+	load.tools, load.err = tools, err
+	close(load.done)
 	m.toolsMu.Unlock()
 
-	log.Printf("✅ [MCP Manager] 从远程MCP加载 %d 个工具", len(tools))
+	if err != nil {
+		return nil, err
+	}
+	logger.WithTrace(ctx, m.log).Infof("[MCP Manager] 从远程MCP加载 %d 个工具", len(tools))
 	return tools, nil
 }
 
@@ -118,7 +182,7 @@ func (m *Manager) GetTool(ctx context.Context, name string) (tool.BaseTool, erro
 
 // ExecuteTool 通过远程MCP执行工具调用
 func (m *Manager) ExecuteTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
-	log.Printf("🔧 [MCP Manager] 执行远程工具: %s | Params: %v", toolName, params)
+	logger.WithTrace(ctx, m.log).Debugf("[MCP Manager] 执行远程工具: %s | Params: %v", toolName, params)
 
 	t, err := m.client.GetTool(ctx, toolName)
 	if err != nil {
@@ -131,24 +195,33 @@ func (m *Manager) ExecuteTool(ctx context.Context, toolName string, params map[s
 		return nil, fmt.Errorf("工具不支持调用: %s", toolName)
 	}
 
+	// 执行前按工具声明的参数结构校验，避免畸形调用到达MCP Server
+	params, err = ValidateParams(ctx, t, params)
+	if err != nil {
+		logger.WithTrace(ctx, m.log).Warnf("[MCP Manager] 工具参数校验失败: %s | %v", toolName, err)
+		return nil, err
+	}
+
 	paramsJSON, _ := json.Marshal(params)
+	start := time.Now()
 	result, err := invokable.InvokableRun(ctx, string(paramsJSON))
+	elapsed := time.Since(start)
+	m.metrics.Observe(toolName, elapsed, err)
+	if m.slowThreshold > 0 && elapsed > m.slowThreshold {
+		logger.WithTrace(ctx, m.log).Warnf("[MCP Manager] 远程工具执行缓慢: %s | 耗时: %s | ParamsHash: %s", toolName, elapsed, paramsHash(params))
+	}
 	if err != nil {
+		logger.WithTrace(ctx, m.log).Errorf("[MCP Manager] 远程工具执行失败: %s | 耗时: %s | %v", toolName, elapsed, err)
 		return nil, fmt.Errorf("远程工具执行失败: %w", err)
 	}
 
-	log.Printf("✅ [MCP Manager] 远程工具执行成功: %s", toolName)
+	logger.WithTrace(ctx, m.log).Debugf("[MCP Manager] 远程工具执行成功: %s | 耗时: %s", toolName, elapsed)
 	return result, nil
 }
 
-// RefreshTools 刷新工具列表（当MCP Server更新工具时调用）
+// RefreshTools 刷新工具列表（当MCP Server更新工具时调用），并发刷新只会拉取一次
 func (m *Manager) RefreshTools(ctx context.Context) error {
-	m.toolsMu.Lock()
-	m.toolsLoaded = false
-	m.tools = nil
-	m.toolsMu.Unlock()
-
-	_, err := m.GetTools(ctx)
+	_, err := m.loadTools(ctx, true)
 	return err
 }
 
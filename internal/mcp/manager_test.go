@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func TestManagerExecuteToolLogLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		tool       *sleepTool
+		wantErrors int
+	}{
+		{name: "success logs no error", tool: &sleepTool{}},
+		{name: "failure logs at error level", tool: &sleepTool{err: errors.New("gateway 503")}, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			m := &Manager{client: &fakeClient{tool: tt.tool}, log: log}
+
+			_, _ = m.ExecuteTool(context.Background(), "get_video_stats", map[string]interface{}{"video_id": "BV1"})
+
+			if len(log.errors) != tt.wantErrors {
+				t.Fatalf("error logs = %q, want %d", log.errors, tt.wantErrors)
+			}
+			for _, line := range log.errors {
+				if !strings.Contains(line, "get_video_stats") || !strings.Contains(line, "gateway 503") {
+					t.Errorf("error log %q missing the tool name or cause", line)
+				}
+			}
+		})
+	}
+}
+
+// gatedClient GetTools 在 gate 关闭前阻塞，记录实际拉取次数；每次拉取返回同样的两个工具
+type gatedClient struct {
+	gate    chan struct{}
+	entered chan struct{}
+	fetches atomic.Int32
+}
+
+func (c *gatedClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
+	if c.fetches.Add(1) == 1 && c.entered != nil {
+		close(c.entered)
+	}
+	if c.gate != nil {
+		<-c.gate
+	}
+	return []tool.BaseTool{&sleepTool{}, &sleepTool{}}, nil
+}
+
+func (c *gatedClient) GetTool(ctx context.Context, name string) (tool.BaseTool, error) {
+	return &sleepTool{}, nil
+}
+
+func (c *gatedClient) Close() error { return nil }
+
+func TestManagerToolLoadingIsSingleFlight(t *testing.T) {
+	client := &gatedClient{gate: make(chan struct{}), entered: make(chan struct{})}
+	m := &Manager{client: client, log: &recordingLogger{}}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make(chan int, 21)
+	load := func(refresh bool) {
+		defer wg.Done()
+		if refresh {
+			if err := m.RefreshTools(ctx); err != nil {
+				t.Errorf("RefreshTools: %v", err)
+			}
+			return
+		}
+		tools, err := m.GetTools(ctx)
+		if err != nil {
+			t.Errorf("GetTools: %v", err)
+		}
+		results <- len(tools)
+	}
+
+	wg.Add(1)
+	go load(false)
+	<-client.entered
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go load(i%2 == 0)
+	}
+	// 等待其余调用方挂到进行中的拉取上
+	time.Sleep(50 * time.Millisecond)
+	close(client.gate)
+	wg.Wait()
+	close(results)
+
+	if got := client.fetches.Load(); got != 1 {
+		t.Errorf("remote fetches = %d, want one shared fetch", got)
+	}
+	for n := range results {
+		if n != 2 {
+			t.Errorf("GetTools returned %d tools during a refresh, want 2", n)
+		}
+	}
+}
+
+func TestManagerConcurrentRefresh(t *testing.T) {
+	client := &gatedClient{}
+	m := &Manager{client: client, log: &recordingLogger{}}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := m.RefreshTools(ctx); err != nil {
+				t.Errorf("RefreshTools: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			tools, err := m.GetTools(ctx)
+			if err != nil || len(tools) != 2 {
+				t.Errorf("GetTools = %d tools, %v; want 2", len(tools), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 每次刷新至多触发一次拉取，另加首个 GetTools 的初次加载
+	if got := client.fetches.Load(); got > 101 {
+		t.Errorf("remote fetches = %d, want at most one per refresh plus the initial load", got)
+	}
+}
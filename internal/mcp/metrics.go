@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultSlowToolThreshold 单次工具调用超过该耗时记录慢调用告警
+const DefaultSlowToolThreshold = 5 * time.Second
+
+// ToolMetrics MCP 工具调用指标：按工具统计调用次数、成败与耗时
+type ToolMetrics struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewToolMetrics 在指定 registry 上注册指标，为 nil 时新建独立 registry；
+// 与 HTTP 指标共用 registry 时可通过同一个 /metrics 暴露
+func NewToolMetrics(registry *prometheus.Registry) *ToolMetrics {
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
+
+	m := &ToolMetrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_tool_calls_total",
+			Help: "MCP 工具调用总数",
+		}, []string{"tool", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mcp_tool_duration_seconds",
+			Help:    "MCP 工具调用耗时",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"tool"}),
+	}
+	registry.MustRegister(m.calls, m.duration)
+	return m
+}
+
+// Observe 记录一次工具调用的耗时与成败，m 为 nil 时不记录
+func (m *ToolMetrics) Observe(toolName string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	m.calls.WithLabelValues(toolName, status).Inc()
+	m.duration.WithLabelValues(toolName).Observe(elapsed.Seconds())
+}
+
+// paramsHash 参数摘要，用于在日志中关联同一组参数的调用而不输出原始参数
+func paramsHash(params map[string]interface{}) string {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// sleepTool 等待 delay 后返回 err
+type sleepTool struct {
+	delay time.Duration
+	err   error
+}
+
+func (t *sleepTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "get_video_stats"}, nil
+}
+
+func (t *sleepTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	time.Sleep(t.delay)
+	return "{}", t.err
+}
+
+// fakeClient 只提供一个工具的 MCP 客户端
+type fakeClient struct {
+	tool tool.BaseTool
+}
+
+func (c *fakeClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
+	return []tool.BaseTool{c.tool}, nil
+}
+
+func (c *fakeClient) GetTool(ctx context.Context, name string) (tool.BaseTool, error) {
+	return c.tool, nil
+}
+
+func (c *fakeClient) Close() error { return nil }
+
+// recordingLogger 记录 Warnf 与 Errorf 输出
+type recordingLogger struct {
+	mu     sync.Mutex
+	warns  []string
+	errors []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {}
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestManagerExecuteToolMetrics(t *testing.T) {
+	tests := []struct {
+		name      string
+		tool      *sleepTool
+		threshold time.Duration
+		wantSlow  bool
+		status    string
+	}{
+		{name: "fast success", tool: &sleepTool{}, threshold: time.Second, status: "success"},
+		{name: "slow success", tool: &sleepTool{delay: 20 * time.Millisecond}, threshold: 5 * time.Millisecond, wantSlow: true, status: "success"},
+		{name: "failure", tool: &sleepTool{err: errors.New("gateway 503")}, threshold: time.Second, status: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			metrics := NewToolMetrics(registry)
+			log := &recordingLogger{}
+			m := &Manager{client: &fakeClient{tool: tt.tool}, log: log, metrics: metrics, slowThreshold: tt.threshold}
+
+			_, err := m.ExecuteTool(context.Background(), "get_video_stats", map[string]interface{}{"video_id": "BV1"})
+			if (err != nil) != (tt.status == "error") {
+				t.Fatalf("ExecuteTool err = %v", err)
+			}
+
+			if got := testutil.ToFloat64(metrics.calls.WithLabelValues("get_video_stats", tt.status)); got != 1 {
+				t.Errorf("calls{status=%s} = %v, want 1", tt.status, got)
+			}
+			if got := testutil.CollectAndCount(metrics.duration); got != 1 {
+				t.Errorf("duration series = %d, want 1", got)
+			}
+
+			var slow bool
+			for _, w := range log.warns {
+				if strings.Contains(w, "执行缓慢") {
+					slow = true
+					if strings.Contains(w, "BV1") || !strings.Contains(w, "ParamsHash: "+paramsHash(map[string]interface{}{"video_id": "BV1"})) {
+						t.Errorf("slow warning should carry the params hash, not raw params: %s", w)
+					}
+				}
+			}
+			if slow != tt.wantSlow {
+				t.Errorf("slow warning = %v, want %v (%v)", slow, tt.wantSlow, log.warns)
+			}
+		})
+	}
+}
+
+func TestToolMetricsNilObserve(t *testing.T) {
+	var m *ToolMetrics
+	m.Observe("get_video_stats", time.Second, nil)
+}
@@ -51,7 +51,15 @@ func (t *VideoAnalysisTool) Execute(ctx context.Context, params map[string]inter
 }
 
 // FrameExtractionTool 关键帧提取工具
-type FrameExtractionTool struct{}
+type FrameExtractionTool struct {
+	// sampler 解码视频得到候选帧，为空时只返回提取参数、不包含帧
+	sampler FrameSampler
+}
+
+// NewFrameExtractionTool 创建使用 sampler 解码候选帧的关键帧提取工具
+func NewFrameExtractionTool(sampler FrameSampler) *FrameExtractionTool {
+	return &FrameExtractionTool{sampler: sampler}
+}
 
 func (t *FrameExtractionTool) Name() string {
 	return "frame_extraction"
@@ -77,6 +85,17 @@ func (t *FrameExtractionTool) Parameters() map[string]interface{} {
 			"description": "最大提取帧数",
 			"default":     10,
 		},
+		"strategy": map[string]interface{}{
+			"type":        "string",
+			"description": "取帧策略: interval 按固定间隔, scene_change 在画面变化处",
+			"enum":        []string{string(FrameStrategyInterval), string(FrameStrategySceneChange)},
+			"default":     string(FrameStrategyInterval),
+		},
+		"threshold": map[string]interface{}{
+			"type":        "number",
+			"description": "scene_change 策略的画面差异阈值（0-1）",
+			"default":     DefaultSceneThreshold,
+		},
 	}
 }
 
@@ -96,12 +115,45 @@ func (t *FrameExtractionTool) Execute(ctx context.Context, params map[string]int
 		maxFrames = 10
 	}
 
+	rawStrategy, _ := params["strategy"].(string)
+	strategy, err := ParseFrameStrategy(rawStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// 显式传入的 0 表示画面有任何变化都取帧，只有未传时才使用默认阈值
+	threshold, ok := params["threshold"].(float64)
+	if !ok {
+		threshold = DefaultSceneThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	status := "extracting"
+	var samples []FrameSample
+	if t.sampler != nil {
+		samples, err = t.sampler.Sample(ctx, videoURL)
+		if err != nil {
+			return nil, fmt.Errorf("sample frames: %w", err)
+		}
+		status = "completed"
+	}
+
+	selected := SelectFrames(samples, strategy, interval, threshold, int(maxFrames))
+	frames := make([]map[string]interface{}, 0, len(selected))
+	for _, frame := range selected {
+		frames = append(frames, map[string]interface{}{"timestamp": frame.Timestamp})
+	}
+
 	return map[string]interface{}{
 		"video_url":  videoURL,
 		"interval":   interval,
 		"max_frames": maxFrames,
-		"status":     "extracting",
-		"frames":     []map[string]interface{}{},
+		"strategy":   string(strategy),
+		"threshold":  threshold,
+		"status":     status,
+		"frames":     frames,
 	}, nil
 }
 
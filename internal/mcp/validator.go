@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ValidationError 工具参数校验失败，调用方可通过 errors.As 获取缺失/非法参数明细
+type ValidationError struct {
+	Tool    string
+	Missing []string          // 缺失的必填参数
+	Invalid map[string]string // 参数名 -> 类型不匹配原因
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "缺少必填参数: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		names := make([]string, 0, len(e.Invalid))
+		for name := range e.Invalid {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("参数 %s %s", name, e.Invalid[name]))
+		}
+	}
+	return fmt.Sprintf("工具 %s 参数校验失败: %s", e.Tool, strings.Join(parts, "; "))
+}
+
+// ValidateParams 按工具声明的 ParamsOneOf 校验参数：丢弃未声明的参数、检查必填参数、
+// 对明显的类型不匹配（如 "123" -> integer）做转换。工具未声明参数结构时原样返回；
+// 被丢弃的参数不在此记录，由调用方对比前后参数按需记录（见 base.ToolExecutor）
+func ValidateParams(ctx context.Context, t tool.BaseTool, params map[string]interface{}) (map[string]interface{}, error) {
+	info, err := t.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取工具信息失败: %w", err)
+	}
+	if info.ParamsOneOf == nil {
+		return params, nil
+	}
+
+	sch, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || sch == nil || sch.Properties == nil {
+		return params, nil
+	}
+
+	verr := &ValidationError{Tool: info.Name, Invalid: map[string]string{}}
+	validated := make(map[string]interface{}, len(params))
+
+	for name, value := range params {
+		prop, ok := sch.Properties.Get(name)
+		if !ok {
+			continue
+		}
+		if prop == nil || value == nil {
+			validated[name] = value
+			continue
+		}
+
+		coerced, err := coerceParam(value, schema.DataType(prop.Type))
+		if err != nil {
+			verr.Invalid[name] = err.Error()
+			continue
+		}
+		validated[name] = coerced
+	}
+
+	for _, name := range sch.Required {
+		value, ok := validated[name]
+		if _, invalid := verr.Invalid[name]; invalid {
+			continue
+		}
+		if !ok || value == nil || value == "" {
+			verr.Missing = append(verr.Missing, name)
+		}
+	}
+
+	if len(verr.Missing) > 0 || len(verr.Invalid) > 0 {
+		return nil, verr
+	}
+	return validated, nil
+}
+
+// CoerceInt 将 JSON 解码得到的整数参数（int、整数值的 float64 或数字字符串）转换为 int64
+func CoerceInt(value interface{}) (int64, error) {
+	coerced, err := coerceParam(value, schema.Integer)
+	if err != nil {
+		return 0, err
+	}
+	switch v := coerced.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	default:
+		return v.(int64), nil
+	}
+}
+
+// coerceParam 将参数转换为声明的类型，无法转换时返回错误
+func coerceParam(value interface{}, typ schema.DataType) (interface{}, error) {
+	switch typ {
+	case schema.String:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case int, int32, int64, bool:
+			return fmt.Sprint(v), nil
+		}
+	case schema.Integer:
+		switch v := value.(type) {
+		case int, int32, int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case schema.Number:
+		switch v := value.(type) {
+		case float64, float32, int, int32, int64:
+			return v, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case schema.Boolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case schema.Array:
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case string:
+			return []interface{}{v}, nil
+		}
+	case schema.Object:
+		if v, ok := value.(map[string]interface{}); ok {
+			return v, nil
+		}
+	default:
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("期望类型 %s，实际为 %T", typ, value)
+}
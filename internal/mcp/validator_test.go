@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// schemaTool 声明 video_id（必填）与 limit 参数，记录实际收到的参数
+type schemaTool struct {
+	args  string
+	calls int
+}
+
+func (t *schemaTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "get_video_comments",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"video_id": {Type: schema.String, Required: true},
+			"limit":    {Type: schema.Integer},
+		}),
+	}, nil
+}
+
+func (t *schemaTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	t.calls++
+	t.args = args
+	return "{}", nil
+}
+
+func TestManagerExecuteToolValidatesParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		wantArgs    map[string]interface{}
+		wantMissing []string
+		wantInvalid []string
+	}{
+		{
+			name:     "valid params pass through",
+			params:   map[string]interface{}{"video_id": "BV1", "limit": float64(20)},
+			wantArgs: map[string]interface{}{"video_id": "BV1", "limit": float64(20)},
+		},
+		{
+			name:        "missing required param",
+			params:      map[string]interface{}{"limit": float64(20)},
+			wantMissing: []string{"video_id"},
+		},
+		{
+			name:        "empty required param",
+			params:      map[string]interface{}{"video_id": ""},
+			wantMissing: []string{"video_id"},
+		},
+		{
+			name:     "extra param is dropped",
+			params:   map[string]interface{}{"video_id": "BV1", "参数值": "BV1"},
+			wantArgs: map[string]interface{}{"video_id": "BV1"},
+		},
+		{
+			name:     "numeric string is coerced",
+			params:   map[string]interface{}{"video_id": "BV1", "limit": "20"},
+			wantArgs: map[string]interface{}{"video_id": "BV1", "limit": float64(20)},
+		},
+		{
+			name:        "uncoercible type is rejected",
+			params:      map[string]interface{}{"video_id": "BV1", "limit": "很多"},
+			wantInvalid: []string{"limit"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &schemaTool{}
+			m := &Manager{client: &fakeClient{tool: st}, log: &recordingLogger{}}
+
+			_, err := m.ExecuteTool(context.Background(), "get_video_comments", tt.params)
+			if tt.wantMissing != nil || tt.wantInvalid != nil {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("err = %v, want *ValidationError", err)
+				}
+				if !reflect.DeepEqual(verr.Missing, tt.wantMissing) {
+					t.Errorf("Missing = %v, want %v", verr.Missing, tt.wantMissing)
+				}
+				for _, name := range tt.wantInvalid {
+					if _, ok := verr.Invalid[name]; !ok {
+						t.Errorf("Invalid = %v, want %s reported", verr.Invalid, name)
+					}
+				}
+				if st.calls != 0 {
+					t.Errorf("tool invoked %d times, want 0 for invalid params", st.calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteTool: %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(st.args), &got); err != nil {
+				t.Fatalf("decode tool args %q: %v", st.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("tool args = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCoerceInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int64
+		wantErr bool
+	}{
+		{name: "int", value: 3, want: 3},
+		{name: "int32", value: int32(4), want: 4},
+		{name: "int64", value: int64(5), want: 5},
+		{name: "integral float", value: 6.0, want: 6},
+		{name: "numeric string", value: " 7 ", want: 7},
+		{name: "fractional float", value: 2.5, wantErr: true},
+		{name: "non-numeric string", value: "many", wantErr: true},
+		{name: "bool", value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceInt(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CoerceInt(%v) err = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CoerceInt(%v) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
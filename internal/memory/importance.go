@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// defaultImportance 未设置 Importance 且未配置打分器时使用的重要性
+const defaultImportance = 0.5
+
+// longTermImportanceThreshold 重要性高于该值的记忆写入长期记忆
+const longTermImportanceThreshold = 0.7
+
+// importanceTimeout 后台打分（含写入长期记忆）的超时时间
+const importanceTimeout = 30 * time.Second
+
+// ImportanceScorer 为未设置 Importance 的记忆打分，返回 [0, 1]
+type ImportanceScorer interface {
+	Score(ctx context.Context, memory Memory) (float64, error)
+}
+
+// chitChatPatterns 问候、致谢等闲聊，几乎没有长期保存的价值
+var chitChatPatterns = []string{
+	"你好", "您好", "谢谢", "多谢", "好的", "嗯", "哈哈", "再见", "拜拜", "ok", "hi", "hello", "thanks", "bye",
+}
+
+// questionMarkers 提问的标志
+var questionMarkers = []string{"?", "？", "吗", "怎么", "如何", "为什么", "什么", "哪", "多少"}
+
+// preferenceMarkers 用户陈述自身情况或偏好，适合跨会话记住
+var preferenceMarkers = []string{"我喜欢", "我不喜欢", "我是", "我的", "我想", "我希望", "我在做", "记住", "以后", "每次"}
+
+// entityPattern 视频ID、数字等具体实体
+var entityPattern = regexp.MustCompile(`(?i)BV[0-9a-z]{10}|\d+`)
+
+// HeuristicScorer 按长度、提问、实体与偏好陈述等特征打分，不调用模型
+type HeuristicScorer struct{}
+
+// Score 实现 ImportanceScorer 接口
+func (HeuristicScorer) Score(ctx context.Context, memory Memory) (float64, error) {
+	content := strings.TrimSpace(memory.Content)
+	normalized := strings.ToLower(strings.TrimFunc(content, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}))
+	if normalized == "" {
+		return 0, nil
+	}
+
+	length := utf8.RuneCountInString(normalized)
+	for _, p := range chitChatPatterns {
+		if strings.HasPrefix(normalized, p) && length <= utf8.RuneCountInString(p)+4 {
+			return 0.1, nil
+		}
+	}
+
+	score := 0.3
+	switch {
+	case length > 200:
+		score += 0.3
+	case length > 80:
+		score += 0.2
+	case length > 20:
+		score += 0.1
+	}
+	if containsAny(content, questionMarkers) {
+		score += 0.1
+	}
+	if entityPattern.MatchString(content) {
+		score += 0.1
+	}
+	if memory.Type != MemoryTypeAssistant && containsAny(content, preferenceMarkers) {
+		score += 0.2
+	}
+	return clampImportance(score), nil
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func clampImportance(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// importancePrompt 模型打分提示词
+const importancePrompt = `请评估下面这条对话内容对于长期记住该用户的价值，只输出 0 到 10 之间的一个整数，不要输出其他内容。
+评分参考：问候、致谢等闲聊为 0-2；一般性问题为 3-5；包含具体视频、数据、分析结论或用户自身情况与偏好的为 6-10。`
+
+// LLMScorer 由模型按 0-10 打分，模型失败或输出无法解析时使用 fallback
+type LLMScorer struct {
+	llm      model.ChatModel
+	fallback ImportanceScorer
+}
+
+// NewLLMScorer 创建模型打分器，fallback 为 nil 时使用 HeuristicScorer
+func NewLLMScorer(llm model.ChatModel, fallback ImportanceScorer) *LLMScorer {
+	if fallback == nil {
+		fallback = HeuristicScorer{}
+	}
+	return &LLMScorer{llm: llm, fallback: fallback}
+}
+
+// Score 实现 ImportanceScorer 接口
+func (s *LLMScorer) Score(ctx context.Context, memory Memory) (float64, error) {
+	resp, err := s.llm.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(importancePrompt),
+		schema.UserMessage(fmt.Sprintf("[%s] %s", memory.Type, memory.Content)),
+	})
+	if err != nil {
+		return s.fallback.Score(ctx, memory)
+	}
+
+	rating, err := parseRating(resp.Content)
+	if err != nil {
+		return s.fallback.Score(ctx, memory)
+	}
+	return clampImportance(rating / 10), nil
+}
+
+var (
+	// ratingPattern 模型输出中的第一个数字
+	ratingPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+	// thinkBlockPattern 推理模型输出的 <think> 块，其中的数字不是评分
+	thinkBlockPattern = regexp.MustCompile(`(?s)<think>.*?</think>`)
+)
+
+func parseRating(output string) (float64, error) {
+	match := ratingPattern.FindString(thinkBlockPattern.ReplaceAllString(output, ""))
+	if match == "" {
+		return 0, fmt.Errorf("no rating in output: %q", output)
+	}
+	return strconv.ParseFloat(match, 64)
+}
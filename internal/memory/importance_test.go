@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// gatedScorer 在 release 关闭前阻塞，模拟耗时的模型打分
+type gatedScorer struct {
+	score   float64
+	release chan struct{}
+}
+
+func (s *gatedScorer) Score(ctx context.Context, memory Memory) (float64, error) {
+	select {
+	case <-s.release:
+		return s.score, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestHeuristicScorer(t *testing.T) {
+	tests := []struct {
+		name    string
+		memory  Memory
+		wantMin float64
+		wantMax float64
+	}{
+		{name: "greeting", memory: Memory{Type: MemoryTypeUser, Content: "你好！"}, wantMax: 0.2},
+		{name: "thanks", memory: Memory{Type: MemoryTypeUser, Content: "谢谢"}, wantMax: 0.2},
+		{name: "empty", memory: Memory{Type: MemoryTypeUser, Content: "  "}, wantMax: 0},
+		{
+			name:    "preference with video id",
+			memory:  Memory{Type: MemoryTypeUser, Content: "我喜欢美食类视频，帮我分析一下 BV1xx411c7mD 的数据表现为什么不好？"},
+			wantMin: 0.7,
+			wantMax: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeuristicScorer{}.Score(context.Background(), tt.memory)
+			if err != nil {
+				t.Fatalf("Score: %v", err)
+			}
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Score = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{output: "8", want: 8},
+		{output: "评分：7", want: 7},
+		{output: "<think>1 到 10 之间</think>6", want: 6},
+		{output: "无法评估", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			got, err := parseRating(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRating err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRating = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackgroundImportanceScorer(t *testing.T) {
+	tests := []struct {
+		name         string
+		score        float64
+		wantLongTerm int
+	}{
+		{name: "important memory is promoted", score: 0.9, wantLongTerm: 1},
+		{name: "unimportant memory stays short-term", score: 0.2, wantLongTerm: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			longTerm := newTestLongTermMemory(t)
+			m := NewMemoryManager(NewShortTermMemory(100, time.Hour), longTerm, NewWorkingMemory(10))
+			scorer := &gatedScorer{score: tt.score, release: make(chan struct{})}
+			m.SetBackgroundImportanceScorer(scorer)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- m.Store(ctx, Memory{SessionID: "s1", Type: MemoryTypeUser, Content: "我的频道主要做科技测评"})
+			}()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Store: %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Store blocked on the background scorer")
+			}
+
+			// 请求结束不应中断后台打分
+			cancel()
+			short := m.shortTerm.Get(context.Background(), "s1")
+			if len(short) != 1 || short[0].Importance != defaultImportance {
+				t.Fatalf("short-term = %+v, want one memory with provisional importance", short)
+			}
+
+			close(scorer.release)
+			m.scoring.Wait()
+
+			got, err := longTerm.Search(context.Background(), "科技", "s1", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(got) != tt.wantLongTerm {
+				t.Fatalf("long-term has %d memories, want %d", len(got), tt.wantLongTerm)
+			}
+			if len(got) == 1 && got[0].Importance != tt.score {
+				t.Errorf("long-term importance = %v, want %v", got[0].Importance, tt.score)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// LocalVectorStore 基于本地 JSON 文件的向量存储，适合单机部署与开发环境
+type LocalVectorStore struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]*localVectorEntry
+}
+
+type localVectorEntry struct {
+	Vector   []float64              `json:"vector"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NewLocalVectorStore 创建本地向量存储，文件已存在时加载已有数据
+func NewLocalVectorStore(path string) (*LocalVectorStore, error) {
+	s := &LocalVectorStore{
+		path:    path,
+		entries: make(map[string]*localVectorEntry),
+	}
+	if err := loadJSONFile(path, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Insert 插入或覆盖向量
+func (s *LocalVectorStore) Insert(ctx context.Context, id string, vector []float64, metadata map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[id] = &localVectorEntry{Vector: vector, Metadata: metadata}
+	return saveJSONFile(s.path, s.entries)
+}
+
+// VectorItem 批量写入的向量
+type VectorItem struct {
+	ID       string
+	Vector   []float64
+	Metadata map[string]interface{}
+}
+
+// InsertBatch 批量插入或覆盖向量，全部写入后只持久化一次；持久化失败时回滚，不写入任何一项
+func (s *LocalVectorStore) InsertBatch(ctx context.Context, items []VectorItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make(map[string]*localVectorEntry, len(items))
+	for _, item := range items {
+		if _, seen := previous[item.ID]; !seen {
+			previous[item.ID] = s.entries[item.ID]
+		}
+		s.entries[item.ID] = &localVectorEntry{Vector: item.Vector, Metadata: item.Metadata}
+	}
+
+	if err := saveJSONFile(s.path, s.entries); err != nil {
+		for id, entry := range previous {
+			if entry == nil {
+				delete(s.entries, id)
+			} else {
+				s.entries[id] = entry
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// Search 按余弦相似度返回 topK 个结果
+func (s *LocalVectorStore) Search(ctx context.Context, vector []float64, topK int) ([]SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]SearchResult, 0, len(s.entries))
+	for id, entry := range s.entries {
+		results = append(results, SearchResult{
+			ID:    id,
+			Score: cosineSimilarity(vector, entry.Vector),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if topK > 0 && topK < len(results) {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// Delete 删除向量
+func (s *LocalVectorStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return saveJSONFile(s.path, s.entries)
+}
+
+// LocalMetadataStore 基于本地 JSON 文件的记忆元数据存储
+type LocalMetadataStore struct {
+	path string
+
+	mu       sync.RWMutex
+	memories map[string]Memory
+}
+
+// NewLocalMetadataStore 创建本地元数据存储，文件已存在时加载已有数据
+func NewLocalMetadataStore(path string) (*LocalMetadataStore, error) {
+	s := &LocalMetadataStore{
+		path:     path,
+		memories: make(map[string]Memory),
+	}
+	if err := loadJSONFile(path, &s.memories); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save 保存记忆
+func (s *LocalMetadataStore) Save(ctx context.Context, memory Memory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memories[memory.ID] = memory
+	return saveJSONFile(s.path, s.memories)
+}
+
+// Get 按ID获取记忆
+func (s *LocalMetadataStore) Get(ctx context.Context, id string) (*Memory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	memory, ok := s.memories[id]
+	if !ok {
+		return nil, fmt.Errorf("memory not found: %s", id)
+	}
+	return &memory, nil
+}
+
+// GetBySession 获取会话下的全部记忆，按创建时间升序
+func (s *LocalMetadataStore) GetBySession(ctx context.Context, sessionID string) ([]Memory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var memories []Memory
+	for _, memory := range s.memories {
+		if memory.SessionID == sessionID {
+			memories = append(memories, memory)
+		}
+	}
+
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].CreatedAt.Before(memories[j].CreatedAt)
+	})
+	return memories, nil
+}
+
+// NewLocalLongTermMemory 使用本地 JSON 文件创建长期记忆，两个文件位于 dir 目录下
+func NewLocalLongTermMemory(
+	dir string,
+	embeddingFunc func(ctx context.Context, text string) ([]float64, error),
+) (*LongTermMemory, error) {
+	vectorStore, err := NewLocalVectorStore(filepath.Join(dir, "memory_vectors.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vector store: %w", err)
+	}
+	metadataStore, err := NewLocalMetadataStore(filepath.Join(dir, "memory_metadata.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata store: %w", err)
+	}
+	return NewLongTermMemory(vectorStore, metadataStore, embeddingFunc), nil
+}
+
+func cosineSimilarity(vec1, vec2 []float64) float64 {
+	if len(vec1) != len(vec2) || len(vec1) == 0 {
+		return 0.0
+	}
+
+	var dotProduct, norm1, norm2 float64
+	for i := range vec1 {
+		dotProduct += vec1[i] * vec2[i]
+		norm1 += vec1[i] * vec1[i]
+		norm2 += vec2[i] * vec2[i]
+	}
+
+	if norm1 == 0 || norm2 == 0 {
+		return 0.0
+	}
+	return dotProduct / (math.Sqrt(norm1) * math.Sqrt(norm2))
+}
+
+func loadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // 文件不存在是正常的
+		}
+		return fmt.Errorf("failed to read store file: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal store data: %w", err)
+	}
+	return nil
+}
+
+func saveJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal store data: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
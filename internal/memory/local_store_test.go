@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingVectorStore 记录 Insert 与 InsertBatch 的调用次数
+type countingVectorStore struct {
+	*LocalVectorStore
+	inserts, batches int
+}
+
+func (s *countingVectorStore) Insert(ctx context.Context, id string, vector []float64, metadata map[string]interface{}) error {
+	s.inserts++
+	return s.LocalVectorStore.Insert(ctx, id, vector, metadata)
+}
+
+func (s *countingVectorStore) InsertBatch(ctx context.Context, items []VectorItem) error {
+	s.batches++
+	return s.LocalVectorStore.InsertBatch(ctx, items)
+}
+
+// singleVectorStore 只支持逐条写入的向量存储
+type singleVectorStore struct {
+	store *countingVectorStore
+}
+
+func (s singleVectorStore) Insert(ctx context.Context, id string, vector []float64, metadata map[string]interface{}) error {
+	return s.store.Insert(ctx, id, vector, metadata)
+}
+
+func (s singleVectorStore) Search(ctx context.Context, vector []float64, topK int) ([]SearchResult, error) {
+	return s.store.Search(ctx, vector, topK)
+}
+
+func (s singleVectorStore) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+func TestLongTermStoreBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		batch       bool
+		wantInserts int
+		wantBatches int
+	}{
+		{name: "batch store persists once", batch: true, wantBatches: 1},
+		{name: "single store falls back to Insert", batch: false, wantInserts: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			local, err := NewLocalVectorStore(filepath.Join(dir, "vectors.json"))
+			if err != nil {
+				t.Fatalf("NewLocalVectorStore: %v", err)
+			}
+			metadata, err := NewLocalMetadataStore(filepath.Join(dir, "memories.json"))
+			if err != nil {
+				t.Fatalf("NewLocalMetadataStore: %v", err)
+			}
+			counting := &countingVectorStore{LocalVectorStore: local}
+			var vectors VectorStore = counting
+			if !tt.batch {
+				vectors = singleVectorStore{counting}
+			}
+			embed := func(ctx context.Context, text string) ([]float64, error) { return []float64{1, 0}, nil }
+			ltm := NewLongTermMemory(vectors, metadata, embed)
+
+			memories := make([]Memory, 5)
+			for i := range memories {
+				memories[i] = Memory{
+					ID: fmt.Sprintf("m%d", i), SessionID: "s1", Type: MemoryTypeUser, Content: fmt.Sprintf("记忆 %d", i),
+					Metadata: map[string]interface{}{MetadataUserID: "u1"}, CreatedAt: time.Now(),
+				}
+			}
+			if err := ltm.StoreBatch(context.Background(), memories); err != nil {
+				t.Fatalf("StoreBatch: %v", err)
+			}
+			if counting.inserts != tt.wantInserts || counting.batches != tt.wantBatches {
+				t.Errorf("inserts = %d, batches = %d; want %d, %d", counting.inserts, counting.batches, tt.wantInserts, tt.wantBatches)
+			}
+			if len(memories[0].Embedding) != 0 {
+				t.Error("StoreBatch should not modify the caller's memories")
+			}
+
+			got, err := ltm.SearchByUser(context.Background(), "记忆", "u1", 10)
+			if err != nil {
+				t.Fatalf("SearchByUser: %v", err)
+			}
+			if len(got) != len(memories) {
+				t.Errorf("retrieved %d memories, want %d", len(got), len(memories))
+			}
+		})
+	}
+}
+
+func TestLocalVectorStoreInsertBatchRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	store, err := NewLocalVectorStore(path)
+	if err != nil {
+		t.Fatalf("NewLocalVectorStore: %v", err)
+	}
+	if err := store.Insert(context.Background(), "kept", []float64{1, 0}, nil); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	// 用非空目录占住存储路径，使重命名失败
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	items := []VectorItem{{ID: "kept", Vector: []float64{0, 1}}, {ID: "new", Vector: []float64{1, 1}}}
+	if err := store.InsertBatch(context.Background(), items); err == nil {
+		t.Fatal("InsertBatch should fail when the store cannot be saved")
+	}
+
+	results, err := store.Search(context.Background(), []float64{1, 0}, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "kept" || results[0].Score < 0.99 {
+		t.Errorf("results = %+v, want only the original vector", results)
+	}
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestLongTermMemory(t *testing.T) *LongTermMemory {
+	t.Helper()
+	dir := t.TempDir()
+	vectors, err := NewLocalVectorStore(filepath.Join(dir, "vectors.json"))
+	if err != nil {
+		t.Fatalf("NewLocalVectorStore: %v", err)
+	}
+	metadata, err := NewLocalMetadataStore(filepath.Join(dir, "memories.json"))
+	if err != nil {
+		t.Fatalf("NewLocalMetadataStore: %v", err)
+	}
+	// 所有记忆与查询的向量相同，检索顺序与用户无关
+	embed := func(ctx context.Context, text string) ([]float64, error) { return []float64{1, 0}, nil }
+	return NewLongTermMemory(vectors, metadata, embed)
+}
+
+func TestSearchByUserAmongManyUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		otherUsers int
+		ownMemos   int
+		topK       int
+		want       int
+	}{
+		{name: "user only has a few memories", otherUsers: 300, ownMemos: 2, topK: 5, want: 2},
+		{name: "enough memories for topK", otherUsers: 300, ownMemos: 8, topK: 5, want: 5},
+		{name: "no memories", otherUsers: 50, ownMemos: 0, topK: 3, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ltm := newTestLongTermMemory(t)
+			ctx := context.Background()
+			store := func(id, user string) {
+				err := ltm.Store(ctx, Memory{
+					ID: id, SessionID: "s-" + user, Type: MemoryTypeUser, Content: id,
+					Metadata: map[string]interface{}{MetadataUserID: user}, CreatedAt: time.Now(),
+				})
+				if err != nil {
+					t.Fatalf("Store: %v", err)
+				}
+			}
+			for i := 0; i < tt.otherUsers; i++ {
+				store(fmt.Sprintf("other-%d", i), fmt.Sprintf("u%d", i))
+			}
+			for i := 0; i < tt.ownMemos; i++ {
+				store(fmt.Sprintf("own-%d", i), "me")
+			}
+
+			got, err := ltm.SearchByUser(ctx, "query", "me", tt.topK)
+			if err != nil {
+				t.Fatalf("SearchByUser: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("got %d memories, want %d", len(got), tt.want)
+			}
+			for _, mem := range got {
+				if mem.Metadata[MetadataUserID] != "me" {
+					t.Errorf("memory %s belongs to %v", mem.ID, mem.Metadata[MetadataUserID])
+				}
+			}
+		})
+	}
+}
+
+func TestLocalLongTermMemoryStoreAndSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		importance float64
+		wantStored bool
+	}{
+		{name: "high importance is promoted", importance: 0.9, wantStored: true},
+		{name: "low importance stays short-term", importance: 0.3, wantStored: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			embed := func(ctx context.Context, text string) ([]float64, error) { return []float64{1, 0}, nil }
+			ltm, err := NewLocalLongTermMemory(dir, embed)
+			if err != nil {
+				t.Fatalf("NewLocalLongTermMemory: %v", err)
+			}
+			m := NewMemoryManager(NewShortTermMemory(100, time.Hour), ltm, NewWorkingMemory(100))
+			ctx := context.Background()
+
+			err = m.Store(ctx, Memory{ID: "m1", SessionID: "s1", Type: MemoryTypeUser, Content: "我主要做科技区视频", Importance: tt.importance})
+			if err != nil {
+				t.Fatalf("Store: %v", err)
+			}
+
+			// 重新打开同一目录，长期记忆应已持久化
+			reopened, err := NewLocalLongTermMemory(dir, embed)
+			if err != nil {
+				t.Fatalf("reopen NewLocalLongTermMemory: %v", err)
+			}
+			got, err := reopened.Search(ctx, "科技区", "s1", 5)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if stored := len(got) == 1 && got[0].Content == "我主要做科技区视频"; stored != tt.wantStored {
+				t.Errorf("long-term memories = %+v, want stored %v", got, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestMemoryManagerWithoutLongTermBackends(t *testing.T) {
+	tests := []struct {
+		name     string
+		longTerm *LongTermMemory
+	}{
+		{name: "nil backends", longTerm: NewLongTermMemory(nil, nil, nil)},
+		{name: "nil long-term memory", longTerm: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryManager(NewShortTermMemory(100, time.Hour), tt.longTerm, NewWorkingMemory(100))
+			ctx := context.Background()
+
+			for i := 0; i < 10; i++ {
+				err := m.Store(ctx, Memory{SessionID: "s1", Type: MemoryTypeUser, Content: fmt.Sprintf("我主要做科技区视频 %d", i), Importance: 0.9})
+				if err != nil {
+					t.Fatalf("Store high-importance memory: %v", err)
+				}
+			}
+
+			got, err := m.Retrieve(ctx, "科技区", "s1", 3)
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			if len(got) != 3 {
+				t.Errorf("Retrieve returned %d memories, want 3 from short-term memory", len(got))
+			}
+			if mems, err := m.RetrieveUserMemories(ctx, "科技区", "u1", 3); err != nil || len(mems) != 0 {
+				t.Errorf("RetrieveUserMemories = %v, %v; want empty and no error", mems, err)
+			}
+			if err := m.Compress(ctx, "s1"); err != nil {
+				t.Errorf("Compress: %v", err)
+			}
+			if _, err := tt.longTerm.Search(ctx, "科技区", "s1", 3); !errors.Is(err, ErrLongTermNotReady) {
+				t.Errorf("Search err = %v, want ErrLongTermNotReady", err)
+			}
+			if err := tt.longTerm.Store(ctx, Memory{ID: "m1", Content: "x"}); !errors.Is(err, ErrLongTermNotReady) {
+				t.Errorf("Store err = %v, want ErrLongTermNotReady", err)
+			}
+		})
+	}
+}
@@ -3,12 +3,15 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/google/uuid"
 )
 
@@ -41,8 +44,16 @@ type Memory struct {
 	TTL         time.Duration          `json:"ttl,omitempty"`
 }
 
+// ShortTermStore 短期记忆存储接口，单机使用 ShortTermMemory，多副本部署使用 RedisShortTermMemory
+type ShortTermStore interface {
+	Get(ctx context.Context, sessionID string) []Memory
+	Set(ctx context.Context, memory Memory) error
+	Clear(sessionID string)
+}
+
 // ShortTermMemory 短期记忆
 type ShortTermMemory struct {
+	mu       sync.Mutex
 	store    map[string][]Memory
 	maxItems int
 	ttl      time.Duration
@@ -59,6 +70,9 @@ func NewShortTermMemory(maxItems int, ttl time.Duration) *ShortTermMemory {
 
 // Get 获取短期记忆
 func (m *ShortTermMemory) Get(ctx context.Context, sessionID string) []Memory {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	memories, exists := m.store[sessionID]
 	if !exists {
 		return nil
@@ -81,6 +95,9 @@ func (m *ShortTermMemory) Get(ctx context.Context, sessionID string) []Memory {
 
 // Set 设置短期记忆
 func (m *ShortTermMemory) Set(ctx context.Context, memory Memory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	memories := m.store[memory.SessionID]
 
 	// 添加新记忆
@@ -97,6 +114,9 @@ func (m *ShortTermMemory) Set(ctx context.Context, memory Memory) error {
 
 // Clear 清除短期记忆
 func (m *ShortTermMemory) Clear(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	delete(m.store, sessionID)
 }
 
@@ -114,6 +134,12 @@ type VectorStore interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// BatchVectorStore 支持批量写入的向量存储，LongTermMemory.StoreBatch 据此只持久化一次
+type BatchVectorStore interface {
+	VectorStore
+	InsertBatch(ctx context.Context, items []VectorItem) error
+}
+
 // MetadataStore 元数据存储接口
 type MetadataStore interface {
 	Save(ctx context.Context, memory Memory) error
@@ -140,11 +166,19 @@ func NewLongTermMemory(
 	}
 }
 
+// ErrLongTermNotReady 长期记忆未配置存储后端或嵌入函数
+var ErrLongTermNotReady = errors.New("long term memory not properly initialized")
+
+// Ready 是否已配置向量存储与元数据存储
+func (m *LongTermMemory) Ready() bool {
+	return m != nil && m.vectorStore != nil && m.metadataStore != nil
+}
+
 // Store 存储长期记忆
 func (m *LongTermMemory) Store(ctx context.Context, memory Memory) error {
 	// 如果 LongTermMemory 未正确初始化，跳过存储
-	if m.vectorStore == nil || m.metadataStore == nil {
-		return fmt.Errorf("long term memory not properly initialized")
+	if !m.Ready() {
+		return ErrLongTermNotReady
 	}
 
 	// 生成嵌入向量
@@ -169,38 +203,117 @@ func (m *LongTermMemory) Store(ctx context.Context, memory Memory) error {
 	return nil
 }
 
-// Search 搜索长期记忆
+// StoreBatch 批量存储长期记忆：先为全部记忆生成向量，向量存储实现 BatchVectorStore 时一次写入，
+// 否则逐条写入；任一向量生成失败时不写入任何记忆
+func (m *LongTermMemory) StoreBatch(ctx context.Context, memories []Memory) error {
+	if !m.Ready() {
+		return ErrLongTermNotReady
+	}
+
+	// 复制一份再填充向量，不修改调用方的切片
+	memories = append([]Memory(nil), memories...)
+	items := make([]VectorItem, len(memories))
+	for i := range memories {
+		if len(memories[i].Embedding) == 0 && m.embeddingFunc != nil {
+			embedding, err := m.embeddingFunc(ctx, memories[i].Content)
+			if err != nil {
+				return fmt.Errorf("failed to generate embedding for %s: %w", memories[i].ID, err)
+			}
+			memories[i].Embedding = embedding
+		}
+		items[i] = VectorItem{ID: memories[i].ID, Vector: memories[i].Embedding, Metadata: memories[i].Metadata}
+	}
+
+	if batch, ok := m.vectorStore.(BatchVectorStore); ok {
+		if err := batch.InsertBatch(ctx, items); err != nil {
+			return fmt.Errorf("failed to insert to vector store: %w", err)
+		}
+	} else {
+		for _, item := range items {
+			if err := m.vectorStore.Insert(ctx, item.ID, item.Vector, item.Metadata); err != nil {
+				return fmt.Errorf("failed to insert to vector store: %w", err)
+			}
+		}
+	}
+
+	for _, memory := range memories {
+		if err := m.metadataStore.Save(ctx, memory); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// maxSearchCandidates 长期记忆单次检索最多取回的候选数，过滤条件很少命中时避免扫描整个向量存储
+const maxSearchCandidates = 1000
+
+// MetadataUserID 记忆元数据中的用户ID字段，SearchByUser 据此跨会话检索
+const MetadataUserID = "user_id"
+
+// Search 搜索长期记忆，sessionID 为空时不按会话过滤
 func (m *LongTermMemory) Search(ctx context.Context, query string, sessionID string, topK int) ([]Memory, error) {
+	return m.search(ctx, query, topK, func(memory *Memory) bool {
+		return sessionID == "" || memory.SessionID == sessionID
+	})
+}
+
+// SearchByUser 跨会话检索用户的长期记忆，按元数据中的 user_id 过滤
+func (m *LongTermMemory) SearchByUser(ctx context.Context, query string, userID string, topK int) ([]Memory, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	return m.search(ctx, query, topK, func(memory *Memory) bool {
+		owner, _ := memory.Metadata[MetadataUserID].(string)
+		return owner == userID
+	})
+}
+
+func (m *LongTermMemory) search(ctx context.Context, query string, topK int, match func(*Memory) bool) ([]Memory, error) {
+	// 检索依赖查询向量，缺少存储后端或嵌入函数时无法检索
+	if !m.Ready() || m.embeddingFunc == nil {
+		return nil, ErrLongTermNotReady
+	}
+	if topK <= 0 {
+		return nil, nil
+	}
+
 	// 生成查询向量
 	queryVector, err := m.embeddingFunc(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// 向量搜索
-	results, err := m.vectorStore.Search(ctx, queryVector, topK*2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search vector store: %w", err)
-	}
-
-	// 获取完整记忆
+	// 向量存储只按相似度排序，过滤在取回之后进行：候选不足 topK 条时扩大检索范围重新检索，
+	// 直到凑满 topK、存储中已没有更多结果或达到 maxSearchCandidates
 	var memories []Memory
-	for _, result := range results {
-		memory, err := m.metadataStore.Get(ctx, result.ID)
+	seen := make(map[string]bool)
+	for fetch := topK * 2; ; fetch *= 4 {
+		fetch = min(fetch, maxSearchCandidates)
+		results, err := m.vectorStore.Search(ctx, queryVector, fetch)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to search vector store: %w", err)
 		}
 
-		// 过滤会话
-		if sessionID != "" && memory.SessionID != sessionID {
-			continue
+		for _, result := range results {
+			if seen[result.ID] {
+				continue
+			}
+			seen[result.ID] = true
+
+			memory, err := m.metadataStore.Get(ctx, result.ID)
+			if err != nil || !match(memory) {
+				continue
+			}
+
+			memory.AccessedAt = time.Now()
+			memory.AccessCount++
+			memories = append(memories, *memory)
+			if len(memories) >= topK {
+				return memories, nil
+			}
 		}
 
-		memory.AccessedAt = time.Now()
-		memory.AccessCount++
-		memories = append(memories, *memory)
-
-		if len(memories) >= topK {
+		if len(results) < fetch || fetch >= maxSearchCandidates {
 			break
 		}
 	}
@@ -208,8 +321,9 @@ func (m *LongTermMemory) Search(ctx context.Context, query string, sessionID str
 	return memories, nil
 }
 
-// WorkingMemory 工作记忆
+// WorkingMemory 工作记忆，可被同一会话中并发执行的工具与图节点读写
 type WorkingMemory struct {
+	mu      sync.RWMutex
 	store   map[string]map[string]interface{}
 	maxSize int
 }
@@ -224,6 +338,9 @@ func NewWorkingMemory(maxSize int) *WorkingMemory {
 
 // Get 获取工作记忆
 func (m *WorkingMemory) Get(sessionID string, key string) (interface{}, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	session, exists := m.store[sessionID]
 	if !exists {
 		return nil, false
@@ -235,6 +352,9 @@ func (m *WorkingMemory) Get(sessionID string, key string) (interface{}, bool) {
 
 // Set 设置工作记忆
 func (m *WorkingMemory) Set(sessionID string, key string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if _, exists := m.store[sessionID]; !exists {
 		m.store[sessionID] = make(map[string]interface{})
 	}
@@ -253,6 +373,9 @@ func (m *WorkingMemory) Set(sessionID string, key string, value interface{}) {
 
 // GetAll 获取所有工作记忆
 func (m *WorkingMemory) GetAll(sessionID string) map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	session, exists := m.store[sessionID]
 	if !exists {
 		return make(map[string]interface{})
@@ -267,22 +390,49 @@ func (m *WorkingMemory) GetAll(sessionID string) map[string]interface{} {
 	return result
 }
 
+// Delete 删除会话中的一项工作记忆
+func (m *WorkingMemory) Delete(sessionID string, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if session, exists := m.store[sessionID]; exists {
+		delete(session, key)
+		if len(session) == 0 {
+			delete(m.store, sessionID)
+		}
+	}
+}
+
 // Clear 清除工作记忆
 func (m *WorkingMemory) Clear(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	delete(m.store, sessionID)
 }
 
 // MemoryManager 记忆管理器
 type MemoryManager struct {
-	shortTerm  *ShortTermMemory
+	shortTerm  ShortTermStore
 	longTerm   *LongTermMemory
 	working    *WorkingMemory
 	compressor *MemoryCompressor
+	titleModel model.ChatModel
+	// titleInFlight 正在后台生成标题的会话
+	titleInFlight sync.Map
+	// scorer 为未设置 Importance 的记忆打分，为 nil 时使用 defaultImportance
+	scorer ImportanceScorer
+	// scoreInBackground 为 true 时 Store 不等待打分，见 SetBackgroundImportanceScorer
+	scoreInBackground bool
+	// scoring 进行中的后台打分任务
+	scoring sync.WaitGroup
+	// embeddingFunc 为所有记忆计算向量，Retrieve 据此按语义相似度排序；为 nil 时不计算
+	embeddingFunc func(ctx context.Context, text string) ([]float64, error)
 }
 
 // NewMemoryManager 创建记忆管理器
 func NewMemoryManager(
-	shortTerm *ShortTermMemory,
+	shortTerm ShortTermStore,
 	longTerm *LongTermMemory,
 	working *WorkingMemory,
 ) *MemoryManager {
@@ -294,6 +444,26 @@ func NewMemoryManager(
 	}
 }
 
+// SetImportanceScorer 设置重要性打分器，Store 时为未设置 Importance 的记忆打分，
+// 高于 0.7 的写入长期记忆；为 nil 时未设置的记忆按 0.5 处理
+func (m *MemoryManager) SetImportanceScorer(scorer ImportanceScorer) {
+	m.scorer = scorer
+	m.scoreInBackground = false
+}
+
+// SetBackgroundImportanceScorer 设置耗时较长的打分器（如 LLMScorer）：Store 不等待打分，记忆先按 0.5 写入短期记忆，
+// 后台打分高于 0.7 时再写入长期记忆，避免每轮对话多出同步的模型调用；后台任务不随请求取消，超时为 importanceTimeout
+func (m *MemoryManager) SetBackgroundImportanceScorer(scorer ImportanceScorer) {
+	m.scorer = scorer
+	m.scoreInBackground = scorer != nil
+}
+
+// SetEmbeddingFunc 设置记忆嵌入函数：Store 时为每条记忆（不只是写入长期记忆的）计算向量，
+// Retrieve 按与查询的语义相似度为短期记忆排序；每条记忆多一次嵌入调用，为 nil 时关闭
+func (m *MemoryManager) SetEmbeddingFunc(embeddingFunc func(ctx context.Context, text string) ([]float64, error)) {
+	m.embeddingFunc = embeddingFunc
+}
+
 // Store 存储记忆
 func (m *MemoryManager) Store(ctx context.Context, memory Memory) error {
 	// 生成ID
@@ -311,13 +481,35 @@ func (m *MemoryManager) Store(ctx context.Context, memory Memory) error {
 		return nil
 	}
 
+	scoreLater := memory.Importance == 0 && m.scoreInBackground
+	if scoreLater {
+		memory.Importance = defaultImportance
+	} else if memory.Importance == 0 {
+		memory.Importance = m.scoreImportance(ctx, memory)
+	}
+
+	// 已计算的向量随记忆写入长期记忆，不会重复嵌入；嵌入失败时该记忆按文本匹配排序
+	if len(memory.Embedding) == 0 && m.embeddingFunc != nil && memory.Content != "" {
+		embedding, err := m.embeddingFunc(ctx, memory.Content)
+		if err != nil {
+			log.Printf("⚠️ 记忆向量生成失败: %v", err)
+		} else {
+			memory.Embedding = embedding
+		}
+	}
+
 	// 存储到短期记忆
 	if err := m.shortTerm.Set(ctx, memory); err != nil {
 		return err
 	}
+	if scoreLater {
+		m.scoreAndPromote(ctx, memory)
+		return nil
+	}
 
 	// 根据重要性存储到长期记忆
-	if memory.Importance > 0.7 && m.longTerm != nil {
+	// 未配置长期存储后端（如 NewLongTermMemory(nil, nil, nil)）时直接跳过
+	if memory.Importance > longTermImportanceThreshold && m.longTerm.Ready() {
 		if err := m.longTerm.Store(ctx, memory); err != nil {
 			// 长期记忆存储失败不阻塞主流程，只记录日志
 			log.Printf("⚠️ 长期记忆存储失败: %v", err)
@@ -327,6 +519,40 @@ func (m *MemoryManager) Store(ctx context.Context, memory Memory) error {
 	return nil
 }
 
+// scoreAndPromote 后台为记忆打分，重要性高于阈值时写入长期记忆
+func (m *MemoryManager) scoreAndPromote(ctx context.Context, memory Memory) {
+	if !m.longTerm.Ready() {
+		return
+	}
+	m.scoring.Add(1)
+	go func() {
+		defer m.scoring.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), importanceTimeout)
+		defer cancel()
+
+		memory.Importance = m.scoreImportance(ctx, memory)
+		if memory.Importance <= longTermImportanceThreshold {
+			return
+		}
+		if err := m.longTerm.Store(ctx, memory); err != nil {
+			log.Printf("⚠️ 长期记忆存储失败: %v", err)
+		}
+	}()
+}
+
+// scoreImportance 为未设置 Importance 的记忆打分，打分失败时使用 defaultImportance
+func (m *MemoryManager) scoreImportance(ctx context.Context, memory Memory) float64 {
+	if m.scorer == nil {
+		return defaultImportance
+	}
+	score, err := m.scorer.Score(ctx, memory)
+	if err != nil {
+		log.Printf("⚠️ 记忆重要性打分失败: %v", err)
+		return defaultImportance
+	}
+	return score
+}
+
 // Retrieve 检索记忆
 func (m *MemoryManager) Retrieve(ctx context.Context, query string, sessionID string, topK int) ([]Memory, error) {
 	var allMemories []Memory
@@ -348,15 +574,20 @@ func (m *MemoryManager) Retrieve(ctx context.Context, query string, sessionID st
 	allMemories = append(allMemories, shortTermMemories...)
 
 	// 3. 从长期记忆检索
+	// 长期记忆不可用时降级为仅使用工作记忆与短期记忆
 	longTermMemories, err := m.longTerm.Search(ctx, query, sessionID, topK)
 	if err == nil {
 		allMemories = append(allMemories, longTermMemories...)
+	} else if !errors.Is(err, ErrLongTermNotReady) {
+		log.Printf("⚠️ 长期记忆检索失败: %v", err)
 	}
 
+	queryVector := m.queryEmbedding(ctx, query)
+
 	// 按相关性和重要性排序
 	sort.Slice(allMemories, func(i, j int) bool {
-		scoreI := m.calculateRelevance(allMemories[i], query) * allMemories[i].Importance
-		scoreJ := m.calculateRelevance(allMemories[j], query) * allMemories[j].Importance
+		scoreI := m.calculateRelevance(allMemories[i], query, queryVector) * allMemories[i].Importance
+		scoreJ := m.calculateRelevance(allMemories[j], query, queryVector) * allMemories[j].Importance
 		return scoreI > scoreJ
 	})
 
@@ -368,14 +599,38 @@ func (m *MemoryManager) Retrieve(ctx context.Context, query string, sessionID st
 	return allMemories, nil
 }
 
-// calculateRelevance 计算相关性
-func (m *MemoryManager) calculateRelevance(memory Memory, query string) float64 {
-	// 简化实现：基于文本匹配
-	// 实际项目中应该使用向量相似度
+// RetrieveUserMemories 跨会话检索用户的长期记忆，用于个性化；长期记忆未配置时返回空
+func (m *MemoryManager) RetrieveUserMemories(ctx context.Context, query string, userID string, topK int) ([]Memory, error) {
+	memories, err := m.longTerm.SearchByUser(ctx, query, userID, topK)
+	if errors.Is(err, ErrLongTermNotReady) {
+		return nil, nil
+	}
+	return memories, err
+}
+
+// queryEmbedding 为查询计算向量，未设置嵌入函数或嵌入失败时返回 nil，Retrieve 退回文本匹配
+func (m *MemoryManager) queryEmbedding(ctx context.Context, query string) []float64 {
+	if m.embeddingFunc == nil || query == "" {
+		return nil
+	}
+	vector, err := m.embeddingFunc(ctx, query)
+	if err != nil {
+		log.Printf("⚠️ 查询向量生成失败: %v", err)
+		return nil
+	}
+	return vector
+}
+
+// calculateRelevance 计算相关性：查询与记忆都有向量时使用余弦相似度，否则基于文本匹配与时间衰减
+func (m *MemoryManager) calculateRelevance(memory Memory, query string, queryVector []float64) float64 {
 	if memory.Content == query {
 		return 1.0
 	}
 
+	if len(queryVector) > 0 && len(memory.Embedding) == len(queryVector) {
+		return math.Max(cosineSimilarity(queryVector, memory.Embedding), 0)
+	}
+
 	// 时间衰减
 	timeDecay := math.Exp(-time.Since(memory.AccessedAt).Hours() / 24)
 
@@ -402,6 +657,10 @@ func (m *MemoryManager) Compress(ctx context.Context, sessionID string) error {
 		CreatedAt:  time.Now(),
 	}
 
+	if !m.longTerm.Ready() {
+		log.Printf("⚠️ 长期记忆未配置，跳过压缩记忆存储: %s", sessionID)
+		return nil
+	}
 	return m.longTerm.Store(ctx, compressedMemory)
 }
 
@@ -431,8 +690,8 @@ func (m *MemoryManager) GetSessionHistory(ctx context.Context, sessionID string,
 		}
 	}
 
-	// 按时间排序
-	sort.Slice(history, func(i, j int) bool {
+	// 按时间排序，时间相同时保持写入顺序
+	sort.SliceStable(history, func(i, j int) bool {
 		return history[i].CreatedAt.Before(history[j].CreatedAt)
 	})
 
@@ -525,6 +784,11 @@ func (b *ContextBuilder) AddMessage(role, content string) bool {
 
 // estimateTokens 估算token数
 func (b *ContextBuilder) estimateTokens(text string) int {
+	return EstimateTokens(text)
+}
+
+// EstimateTokens 估算文本的token数，与 ContextBuilder 的预算口径一致
+func EstimateTokens(text string) int {
 	// 简化估算：中文字符按1.5个token计算
 	return int(float64(len(text)) * 0.75)
 }
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// topicEmbed 按话题词表计数生成向量，与词表无交集的文本得到零向量
+func topicEmbed(topics ...string) func(ctx context.Context, text string) ([]float64, error) {
+	return func(ctx context.Context, text string) ([]float64, error) {
+		vector := make([]float64, len(topics))
+		for i, topic := range topics {
+			vector[i] = float64(strings.Count(text, topic))
+		}
+		return vector, nil
+	}
+}
+
+func TestRetrieveRanksByEmbedding(t *testing.T) {
+	memories := []string{"最近的弹幕互动很活跃", "投稿封面需要更换配色"}
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "matches the first stored memory", query: "弹幕", want: memories[0]},
+		{name: "matches the second stored memory", query: "封面", want: memories[1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryManager(NewShortTermMemory(100, time.Hour), NewLongTermMemory(nil, nil, nil), NewWorkingMemory(100))
+			m.SetEmbeddingFunc(topicEmbed("弹幕", "封面"))
+			ctx := context.Background()
+			for _, content := range memories {
+				if err := m.Store(ctx, Memory{SessionID: "s1", Type: MemoryTypeUser, Content: content}); err != nil {
+					t.Fatalf("Store: %v", err)
+				}
+			}
+
+			got, err := m.Retrieve(ctx, tt.query, "s1", 2)
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			if len(got) != 2 || got[0].Content != tt.want {
+				t.Fatalf("Retrieve(%q) = %+v, want %q ranked first", tt.query, got, tt.want)
+			}
+			if len(got[0].Embedding) == 0 {
+				t.Error("short-term memory stored without an embedding")
+			}
+		})
+	}
+}
+
+func TestStoreEmbeddingFailureKeepsMemory(t *testing.T) {
+	m := NewMemoryManager(NewShortTermMemory(100, time.Hour), NewLongTermMemory(nil, nil, nil), NewWorkingMemory(100))
+	m.SetEmbeddingFunc(func(ctx context.Context, text string) ([]float64, error) {
+		return nil, errors.New("embedding service down")
+	})
+	ctx := context.Background()
+	if err := m.Store(ctx, Memory{SessionID: "s1", Type: MemoryTypeUser, Content: "弹幕"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	got, err := m.Retrieve(ctx, "弹幕", "s1", 1)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(got) != 1 || got[0].Content != "弹幕" || len(got[0].Embedding) != 0 {
+		t.Errorf("Retrieve = %+v, want the memory kept without an embedding", got)
+	}
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisKeyPrefix 短期记忆在 Redis 中的键前缀
+const defaultRedisKeyPrefix = "xiaov:memory:short:"
+
+// RedisShortTermMemory 基于 Redis 的短期记忆，多副本部署时各实例共享会话历史。
+// 每个会话对应一个 List，写入时截断到 maxItems 并刷新过期时间
+type RedisShortTermMemory struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	maxItems  int
+	ttl       time.Duration
+}
+
+// NewRedisShortTermMemory 创建 Redis 短期记忆
+func NewRedisShortTermMemory(client redis.UniversalClient, maxItems int, ttl time.Duration) *RedisShortTermMemory {
+	return &RedisShortTermMemory{
+		client:    client,
+		keyPrefix: defaultRedisKeyPrefix,
+		maxItems:  maxItems,
+		ttl:       ttl,
+	}
+}
+
+func (m *RedisShortTermMemory) key(sessionID string) string {
+	return m.keyPrefix + sessionID
+}
+
+// Get 获取短期记忆，Redis 异常时记录日志并返回空
+func (m *RedisShortTermMemory) Get(ctx context.Context, sessionID string) []Memory {
+	values, err := m.client.LRange(ctx, m.key(sessionID), 0, -1).Result()
+	if err != nil {
+		log.Printf("⚠️ 读取Redis短期记忆失败: %v", err)
+		return nil
+	}
+
+	memories, err := decodeMemories(values, m.ttl, time.Now())
+	if err != nil {
+		log.Printf("⚠️ 解析Redis短期记忆失败: %v", err)
+	}
+	return memories
+}
+
+// Set 设置短期记忆
+func (m *RedisShortTermMemory) Set(ctx context.Context, memory Memory) error {
+	data, err := json.Marshal(memory)
+	if err != nil {
+		return fmt.Errorf("failed to marshal memory: %w", err)
+	}
+
+	key := m.key(memory.SessionID)
+	_, err = m.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, data)
+		if m.maxItems > 0 {
+			pipe.LTrim(ctx, key, int64(-m.maxItems), -1)
+		}
+		if m.ttl > 0 {
+			pipe.Expire(ctx, key, m.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save memory to redis: %w", err)
+	}
+	return nil
+}
+
+// Clear 清除短期记忆
+func (m *RedisShortTermMemory) Clear(sessionID string) {
+	if err := m.client.Del(context.Background(), m.key(sessionID)).Err(); err != nil {
+		log.Printf("⚠️ 清除Redis短期记忆失败: %v", err)
+	}
+}
+
+// decodeMemories 反序列化记忆列表并过滤过期项，单条解析失败时跳过并返回最后的错误
+func decodeMemories(values []string, ttl time.Duration, now time.Time) ([]Memory, error) {
+	var memories []Memory
+	var lastErr error
+	for _, value := range values {
+		var mem Memory
+		if err := json.Unmarshal([]byte(value), &mem); err != nil {
+			lastErr = err
+			continue
+		}
+		if ttl > 0 && now.Sub(mem.CreatedAt) >= ttl {
+			continue
+		}
+		memories = append(memories, mem)
+	}
+	return memories, lastErr
+}
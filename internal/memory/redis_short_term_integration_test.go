@@ -0,0 +1,51 @@
+//go:build integration
+
+package memory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 需要本地 Redis：REDIS_ADDR=localhost:6379 go test -tags integration ./internal/memory/
+func TestRedisShortTermMemoryIntegration(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis %s unavailable: %v", addr, err)
+	}
+
+	m := NewRedisShortTermMemory(client, 2, time.Minute)
+	sessionID := "it-" + uuid.New().String()
+	defer m.Clear(sessionID)
+
+	for _, content := range []string{"第一轮", "第二轮", "第三轮"} {
+		if err := m.Set(ctx, Memory{SessionID: sessionID, Type: MemoryTypeUser, Content: content, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	got := m.Get(ctx, sessionID)
+	if len(got) != 2 || got[0].Content != "第二轮" || got[1].Content != "第三轮" {
+		t.Fatalf("Get = %+v, want the last two memories", got)
+	}
+	ttl, err := client.TTL(ctx, m.key(sessionID)).Result()
+	if err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("key TTL = %v (err %v), want within one minute", ttl, err)
+	}
+
+	m.Clear(sessionID)
+	if got := m.Get(ctx, sessionID); len(got) != 0 {
+		t.Errorf("Get after Clear = %+v, want empty", got)
+	}
+}
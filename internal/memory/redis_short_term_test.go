@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis 以 go-redis Hook 在进程内处理 List 命令，不建立网络连接
+type fakeRedis struct {
+	mu      sync.Mutex
+	lists   map[string][]string
+	expires map[string]time.Duration
+}
+
+// newFakeRedisClient 返回命令全部由 fakeRedis 处理的客户端
+func newFakeRedisClient(t *testing.T) (*redis.Client, *fakeRedis) {
+	t.Helper()
+	fake := &fakeRedis{lists: map[string][]string{}, expires: map[string]time.Duration{}}
+	client := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	client.AddHook(fake)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, fake
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, fmt.Errorf("fake redis does not dial %s", addr)
+	}
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.process(cmd)
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, cmd := range cmds {
+			if err := f.process(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) error {
+	args := cmd.Args()
+	key := func() string { return fmt.Sprint(args[1]) }
+	switch strings.ToLower(cmd.Name()) {
+	case "multi", "exec":
+	case "rpush":
+		for _, v := range args[2:] {
+			switch v := v.(type) {
+			case []byte:
+				f.lists[key()] = append(f.lists[key()], string(v))
+			default:
+				f.lists[key()] = append(f.lists[key()], fmt.Sprint(v))
+			}
+		}
+		cmd.(*redis.IntCmd).SetVal(int64(len(f.lists[key()])))
+	case "ltrim":
+		list := f.lists[key()]
+		start := int(args[2].(int64))
+		if start < 0 {
+			start += len(list)
+		}
+		if start > 0 {
+			f.lists[key()] = list[start:]
+		}
+		cmd.(*redis.StatusCmd).SetVal("OK")
+	case "lrange":
+		cmd.(*redis.StringSliceCmd).SetVal(append([]string(nil), f.lists[key()]...))
+	case "expire":
+		f.expires[key()] = time.Duration(args[2].(int64)) * time.Second
+		cmd.(*redis.BoolCmd).SetVal(true)
+	case "del":
+		delete(f.lists, key())
+		cmd.(*redis.IntCmd).SetVal(1)
+	default:
+		return fmt.Errorf("fake redis: unsupported command %s", cmd.Name())
+	}
+	return nil
+}
+
+func TestRedisShortTermMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		maxItems int
+		ttl      time.Duration
+		stored   []Memory
+		want     []string
+	}{
+		{
+			name:     "round trip keeps order and fields",
+			maxItems: 10,
+			ttl:      time.Hour,
+			stored: []Memory{
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "我的视频数据怎么样", Metadata: map[string]interface{}{MetadataUserID: "u1"}, CreatedAt: now},
+				{SessionID: "s1", Type: MemoryTypeAssistant, Content: "播放量稳定增长", CreatedAt: now},
+			},
+			want: []string{"我的视频数据怎么样", "播放量稳定增长"},
+		},
+		{
+			name:     "list is capped at maxItems",
+			maxItems: 2,
+			ttl:      time.Hour,
+			stored: []Memory{
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "第一轮", CreatedAt: now},
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "第二轮", CreatedAt: now},
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "第三轮", CreatedAt: now},
+			},
+			want: []string{"第二轮", "第三轮"},
+		},
+		{
+			name:     "expired memories are filtered",
+			maxItems: 10,
+			ttl:      time.Hour,
+			stored: []Memory{
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "昨天的问题", CreatedAt: now.Add(-2 * time.Hour)},
+				{SessionID: "s1", Type: MemoryTypeUser, Content: "今天的问题", CreatedAt: now},
+			},
+			want: []string{"今天的问题"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake := newFakeRedisClient(t)
+			m := NewRedisShortTermMemory(client, tt.maxItems, tt.ttl)
+
+			for _, mem := range tt.stored {
+				if err := m.Set(ctx, mem); err != nil {
+					t.Fatalf("Set: %v", err)
+				}
+			}
+			if got := fake.expires[m.key("s1")]; got != tt.ttl {
+				t.Errorf("key expiry = %v, want %v", got, tt.ttl)
+			}
+
+			got := m.Get(ctx, "s1")
+			var contents []string
+			for _, mem := range got {
+				contents = append(contents, mem.Content)
+			}
+			if strings.Join(contents, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("Get = %v, want %v", contents, tt.want)
+			}
+			if first := got[0]; first.Type != tt.stored[len(tt.stored)-len(tt.want)].Type || !first.CreatedAt.Equal(tt.stored[len(tt.stored)-len(tt.want)].CreatedAt) {
+				t.Errorf("decoded memory = %+v, fields lost in serialization", first)
+			}
+
+			m.Clear("s1")
+			if got := m.Get(ctx, "s1"); len(got) != 0 {
+				t.Errorf("Get after Clear = %v, want empty", got)
+			}
+		})
+	}
+}
+
+func TestDecodeMemoriesSkipsInvalidEntries(t *testing.T) {
+	now := time.Now()
+	valid, _ := json.Marshal(Memory{SessionID: "s1", Type: MemoryTypeUser, Content: "你好", CreatedAt: now})
+
+	memories, err := decodeMemories([]string{"not json", string(valid)}, time.Hour, now)
+	if err == nil {
+		t.Error("decodeMemories should report the invalid entry")
+	}
+	if len(memories) != 1 || memories[0].Content != "你好" {
+		t.Errorf("memories = %+v, want the valid entry kept", memories)
+	}
+}
+
+func TestMemoryManagerWithRedisShortTerm(t *testing.T) {
+	client, _ := newFakeRedisClient(t)
+	m := NewMemoryManager(NewRedisShortTermMemory(client, 100, time.Hour), NewLongTermMemory(nil, nil, nil), NewWorkingMemory(100))
+	ctx := context.Background()
+
+	for _, mem := range []Memory{
+		{SessionID: "s1", Type: MemoryTypeUser, Content: "我的视频数据怎么样"},
+		{SessionID: "s1", Type: MemoryTypeAssistant, Content: "播放量稳定增长"},
+	} {
+		if err := m.Store(ctx, mem); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+	}
+	history, err := m.GetSessionHistory(ctx, "s1", 10)
+	if err != nil {
+		t.Fatalf("GetSessionHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Type != MemoryTypeUser || history[1].Type != MemoryTypeAssistant {
+		t.Errorf("history = %+v, want the user turn then the reply", history)
+	}
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// Scratchpad 单次执行内绑定到会话的工作记忆视图，工具与图节点通过 ScratchpadFrom(ctx) 共享中间状态
+// （如部分解析出的视频 ID）。本次执行写入的键只对本次执行可见，同一会话并发的执行互不影响；
+// 执行结束时 Release 将 Persist 过的键写入会话工作记忆供后续执行读取，其余丢弃。
+// 未挂载时 ScratchpadFrom 返回 nil，nil 上的方法均为空操作
+type Scratchpad struct {
+	working   *WorkingMemory
+	sessionID string
+
+	mu      sync.Mutex
+	values  map[string]interface{}
+	persist map[string]bool
+}
+
+type scratchpadKey struct{}
+
+// WithScratchpad 返回挂载了会话工作记忆的 context，调用方需要在执行结束后调用 Release
+func WithScratchpad(ctx context.Context, working *WorkingMemory, sessionID string) (context.Context, *Scratchpad) {
+	pad := &Scratchpad{
+		working:   working,
+		sessionID: sessionID,
+		values:    make(map[string]interface{}),
+		persist:   make(map[string]bool),
+	}
+	return context.WithValue(ctx, scratchpadKey{}, pad), pad
+}
+
+// ScratchpadFrom 返回 context 中的 Scratchpad，未挂载时为 nil
+func ScratchpadFrom(ctx context.Context) *Scratchpad {
+	pad, _ := ctx.Value(scratchpadKey{}).(*Scratchpad)
+	return pad
+}
+
+// Get 读取本次执行写入的键，不存在时读取之前执行中 Persist 到会话工作记忆的键
+func (s *Scratchpad) Get(key string) (interface{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	value, ok := s.values[key]
+	s.mu.Unlock()
+	if ok {
+		return value, true
+	}
+	return s.working.Get(s.sessionID, key)
+}
+
+// Set 写入本次执行的键，默认在执行结束时丢弃
+func (s *Scratchpad) Set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Persist 标记键在执行结束后写入会话工作记忆，可在 Set 之前或之后调用
+func (s *Scratchpad) Persist(key string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.persist[key] = true
+}
+
+// Release 将 Persist 过的键写入会话工作记忆并清空本次执行的键，可重复调用
+func (s *Scratchpad) Release() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.persist {
+		if value, ok := s.values[key]; ok {
+			s.working.Set(s.sessionID, key, value)
+		}
+	}
+	s.values = make(map[string]interface{})
+}
+
+// WithScratchpad 挂载会话的工作记忆，见 Scratchpad；未配置工作记忆时返回原 context 与 nil
+func (m *MemoryManager) WithScratchpad(ctx context.Context, sessionID string) (context.Context, *Scratchpad) {
+	if m.working == nil {
+		return ctx, nil
+	}
+	return WithScratchpad(ctx, m.working, sessionID)
+}
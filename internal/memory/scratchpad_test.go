@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+func TestScratchpadSharedAcrossNodes(t *testing.T) {
+	g := compose.NewGraph[string, string]()
+	_ = g.AddLambdaNode("resolve", compose.InvokableLambda(func(ctx context.Context, query string) (string, error) {
+		ScratchpadFrom(ctx).Set("video_id", "12345")
+		return query, nil
+	}))
+	_ = g.AddLambdaNode("analyze", compose.InvokableLambda(func(ctx context.Context, query string) (string, error) {
+		value, _ := ScratchpadFrom(ctx).Get("video_id")
+		videoID, _ := value.(string)
+		return videoID, nil
+	}))
+	_ = g.AddEdge(compose.START, "resolve")
+	_ = g.AddEdge("resolve", "analyze")
+	_ = g.AddEdge("analyze", compose.END)
+	r, err := g.Compile(context.Background())
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	working := NewWorkingMemory(10)
+	ctx, pad := WithScratchpad(context.Background(), working, "s1")
+	got, err := r.Invoke(ctx, "分析一下")
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if got != "12345" {
+		t.Errorf("later node read %q, want 12345", got)
+	}
+
+	pad.Release()
+	if _, ok := working.Get("s1", "video_id"); ok {
+		t.Error("unpersisted key survived Release")
+	}
+}
+
+func TestScratchpadConcurrentExecutions(t *testing.T) {
+	tests := []struct {
+		name string
+		// persistFirst 第一个执行是否 Persist 该键
+		persistFirst bool
+		// wantAfter 两个执行都结束后会话工作记忆中的值，空表示不存在
+		wantAfter string
+	}{
+		{name: "temporary keys are dropped", persistFirst: false, wantAfter: ""},
+		{name: "persisted key is kept", persistFirst: true, wantAfter: "first"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			working := NewWorkingMemory(10)
+			_, first := WithScratchpad(context.Background(), working, "s1")
+			_, second := WithScratchpad(context.Background(), working, "s1")
+
+			first.Set("step", "first")
+			if tt.persistFirst {
+				first.Persist("step")
+			}
+			second.Set("step", "second")
+
+			// 同一会话并发的执行互不可见、互不覆盖
+			if v, _ := first.Get("step"); v != "first" {
+				t.Fatalf("first execution reads %v, want first", v)
+			}
+
+			second.Release()
+			if v, _ := first.Get("step"); v != "first" {
+				t.Fatalf("second execution's Release removed the first's key, got %v", v)
+			}
+
+			first.Release()
+			v, ok := working.Get("s1", "step")
+			if tt.wantAfter == "" {
+				if ok {
+					t.Errorf("working memory kept %v, want nothing", v)
+				}
+				return
+			}
+			if v != tt.wantAfter {
+				t.Errorf("working memory = %v, want %s", v, tt.wantAfter)
+			}
+
+			// 后续执行可读到持久化的键
+			_, next := WithScratchpad(context.Background(), working, "s1")
+			if v, _ := next.Get("step"); v != tt.wantAfter {
+				t.Errorf("next execution reads %v, want %s", v, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestNilScratchpad(t *testing.T) {
+	pad := ScratchpadFrom(context.Background())
+	pad.Set("k", 1)
+	pad.Persist("k")
+	pad.Release()
+	if _, ok := pad.Get("k"); ok {
+		t.Error("nil scratchpad returned a value")
+	}
+}
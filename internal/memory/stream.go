@@ -0,0 +1,29 @@
+package memory
+
+// streamReplyKey 最近一次流式回复在工作记忆中的键
+const streamReplyKey = "stream_reply"
+
+// streamReply 以结构体存储，避免被 Retrieve 当作普通文本记忆召回
+type streamReply struct {
+	id      string
+	content string
+}
+
+// SaveStreamReply 缓存会话最近一次流式回复，客户端断线重连时据此续传而不重新生成；
+// 会话每次只保留一份，新的回复覆盖旧的
+func (m *MemoryManager) SaveStreamReply(sessionID, streamID, content string) {
+	m.working.Set(sessionID, streamReplyKey, streamReply{id: streamID, content: content})
+}
+
+// StreamReply 读取会话最近一次流式回复，streamID 与缓存的回复不一致时返回 false
+func (m *MemoryManager) StreamReply(sessionID, streamID string) (string, bool) {
+	value, ok := m.working.Get(sessionID, streamReplyKey)
+	if !ok {
+		return "", false
+	}
+	reply, ok := value.(streamReply)
+	if !ok || reply.id != streamID {
+		return "", false
+	}
+	return reply.content, true
+}
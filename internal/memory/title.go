@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// sessionTitleKey 会话标题在工作记忆中的键
+	sessionTitleKey = "session_title"
+	// titleTurns 生成标题时参考的前几条消息
+	titleTurns = 6
+	// maxTitleRunes 标题最大长度
+	maxTitleRunes = 20
+	// titleTimeout 后台生成标题的超时时间
+	titleTimeout = 30 * time.Second
+)
+
+const sessionTitlePrompt = `请根据以下对话内容生成一个简短的会话标题（不超过15个字），只输出标题本身，不要加引号、标点或解释。`
+
+// SetTitleModel 设置用于生成会话标题的模型，未设置时标题取首条用户消息
+func (m *MemoryManager) SetTitleModel(llm model.ChatModel) {
+	m.titleModel = llm
+}
+
+// SessionTitle 返回已缓存的会话标题，不调用模型；尚未生成时回退为截断的首条用户消息，会话没有消息时返回空字符串
+func (m *MemoryManager) SessionTitle(ctx context.Context, sessionID string) string {
+	if title := m.cachedTitle(sessionID); title != "" {
+		return title
+	}
+	history, _ := m.GetSessionHistory(ctx, sessionID, titleTurns)
+	for _, mem := range history {
+		if mem.Type == MemoryTypeUser {
+			return truncateRunes(strings.TrimSpace(mem.Content), maxTitleRunes)
+		}
+	}
+	return ""
+}
+
+// RefreshSessionTitle 在写入对话后调用：标题尚未生成时在后台生成并缓存，同一会话同时只有一个生成任务。
+// 后台任务不随请求 context 取消，使用 titleTimeout 作为超时
+func (m *MemoryManager) RefreshSessionTitle(ctx context.Context, sessionID string) {
+	if m.cachedTitle(sessionID) != "" {
+		return
+	}
+	if _, running := m.titleInFlight.LoadOrStore(sessionID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer m.titleInFlight.Delete(sessionID)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), titleTimeout)
+		defer cancel()
+		if _, err := m.GenerateSessionTitle(ctx, sessionID); err != nil {
+			log.Printf("[Memory] generate session title warning: %v", err)
+		}
+	}()
+}
+
+func (m *MemoryManager) cachedTitle(sessionID string) string {
+	cached, _ := m.working.Get(sessionID, sessionTitleKey)
+	title, _ := cached.(string)
+	return title
+}
+
+// GenerateSessionTitle 根据会话前几轮对话生成标题并缓存到工作记忆，模型失败时回退为截断的首条用户消息。
+// 会话没有任何消息时返回空字符串
+func (m *MemoryManager) GenerateSessionTitle(ctx context.Context, sessionID string) (string, error) {
+	if title := m.cachedTitle(sessionID); title != "" {
+		return title, nil
+	}
+
+	// GetSessionHistory 保留最新的 limit 条，标题需要会话开头的消息，因此取全量后截取
+	history, err := m.GetSessionHistory(ctx, sessionID, len(m.shortTerm.Get(ctx, sessionID)))
+	if err != nil {
+		return "", fmt.Errorf("load session history: %w", err)
+	}
+	if len(history) > titleTurns {
+		history = history[:titleTurns]
+	}
+
+	var firstUserMessage string
+	var transcript strings.Builder
+	for _, mem := range history {
+		role := "助手"
+		if mem.Type == MemoryTypeUser {
+			role = "用户"
+			if firstUserMessage == "" {
+				firstUserMessage = mem.Content
+			}
+		}
+		transcript.WriteString(fmt.Sprintf("%s: %s\n", role, mem.Content))
+	}
+	if firstUserMessage == "" {
+		return "", nil
+	}
+
+	title := m.generateTitle(ctx, transcript.String())
+	if title == "" {
+		title = truncateRunes(strings.TrimSpace(firstUserMessage), maxTitleRunes)
+	}
+
+	m.working.Set(sessionID, sessionTitleKey, title)
+	return title, nil
+}
+
+// generateTitle 调用模型生成标题，失败或输出为空时返回空字符串
+func (m *MemoryManager) generateTitle(ctx context.Context, transcript string) string {
+	if m.titleModel == nil {
+		return ""
+	}
+
+	resp, err := m.titleModel.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(sessionTitlePrompt),
+		schema.UserMessage(transcript),
+	})
+	if err != nil {
+		log.Printf("⚠️ 会话标题生成失败，使用首条消息: %v", err)
+		return ""
+	}
+
+	title := strings.Trim(strings.TrimSpace(resp.Content), "\"'“”《》「」")
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = strings.TrimSpace(title[:i])
+	}
+	return truncateRunes(title, maxTitleRunes)
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
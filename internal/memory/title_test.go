@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// countingModel 返回固定内容并统计调用次数
+type countingModel struct {
+	reply string
+	calls atomic.Int32
+}
+
+func (m *countingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls.Add(1)
+	return schema.AssistantMessage(m.reply, nil), nil
+}
+
+func (m *countingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, _ := m.Generate(ctx, input, opts...)
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *countingModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func newTitleTestManager(t *testing.T, llm model.ChatModel, messages ...Memory) *MemoryManager {
+	t.Helper()
+	shortTerm := NewShortTermMemory(100, time.Hour)
+	for _, mem := range messages {
+		if err := shortTerm.Set(context.Background(), mem); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	m := NewMemoryManager(shortTerm, nil, NewWorkingMemory(10))
+	m.SetTitleModel(llm)
+	return m
+}
+
+func TestSessionTitleDoesNotCallModel(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		messages []Memory
+		want     string
+	}{
+		{name: "empty session", want: ""},
+		{
+			name: "first user message",
+			messages: []Memory{
+				{ID: "1", SessionID: "s", Type: MemoryTypeUser, Content: " 帮我分析BV1xx ", CreatedAt: now},
+				{ID: "2", SessionID: "s", Type: MemoryTypeAssistant, Content: "好的", CreatedAt: now.Add(time.Millisecond)},
+			},
+			want: "帮我分析BV1xx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &countingModel{reply: "视频分析"}
+			m := newTitleTestManager(t, llm, tt.messages...)
+
+			if got := m.SessionTitle(context.Background(), "s"); got != tt.want {
+				t.Errorf("SessionTitle = %q, want %q", got, tt.want)
+			}
+			if n := llm.calls.Load(); n != 0 {
+				t.Errorf("model called %d times on read", n)
+			}
+		})
+	}
+}
+
+func TestRefreshSessionTitleGeneratesOnce(t *testing.T) {
+	llm := &countingModel{reply: "“视频分析”"}
+	m := newTitleTestManager(t, llm, Memory{ID: "1", SessionID: "s", Type: MemoryTypeUser, Content: "帮我分析", CreatedAt: time.Now()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.RefreshSessionTitle(ctx, "s")
+	// 请求结束不应取消后台生成
+	cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for m.cachedTitle("s") == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("title was not generated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	m.RefreshSessionTitle(context.Background(), "s")
+	for i := 0; i < 3; i++ {
+		if got := m.SessionTitle(context.Background(), "s"); got != "视频分析" {
+			t.Fatalf("SessionTitle = %q, want %q", got, "视频分析")
+		}
+	}
+	if n := llm.calls.Load(); n != 1 {
+		t.Errorf("model called %d times, want 1", n)
+	}
+}
+
+func TestWorkingMemoryConcurrentAccess(t *testing.T) {
+	w := NewWorkingMemory(1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("k%d-%d", i, j)
+				w.Set("s", key, j)
+				w.Get("s", key)
+				w.GetAll("s")
+				w.Delete("s", key)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
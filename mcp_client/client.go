@@ -4,9 +4,12 @@ package mcp_client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	eino_mcp "github.com/cloudwego/eino-ext/components/tool/mcp"
 	"github.com/cloudwego/eino/components/tool"
@@ -42,8 +45,26 @@ type ServerConfig struct {
 	URL string
 	// 自定义HTTP头
 	Headers map[string]string
+	// Restart 子进程退出后的自动重启策略（stdio模式使用），为 nil 时只检测存活不重启
+	Restart *RestartPolicy
+	// OnRestart 子进程重启并完成握手后调用（stdio模式使用）。此前取得的工具绑定在旧连接上已不可用，
+	// 持有这些工具的调用方（如已编译的图）应在回调中重新获取；回调在客户端锁外执行
+	OnRestart func()
 }
 
+// RestartPolicy stdio 子进程的重启策略
+type RestartPolicy struct {
+	// MaxRestarts 连续重启次数上限，子进程恢复响应后计数清零
+	MaxRestarts int
+	// Backoff 每次重启前的等待时间
+	Backoff time.Duration
+	// PingTimeout 存活检测的超时时间，<=0 使用 defaultPingTimeout
+	PingTimeout time.Duration
+}
+
+// defaultPingTimeout stdio 子进程存活检测的默认超时
+const defaultPingTimeout = 3 * time.Second
+
 // NewClient 创建MCP客户端
 func NewClient(conf *Config) (Client, error) {
 	switch conf.Transport {
@@ -58,14 +79,32 @@ func NewClient(conf *Config) (Client, error) {
 
 // StdioClient Stdio MCP客户端
 type StdioClient struct {
+	mu    sync.Mutex
 	cli   client.MCPClient
 	tools []tool.BaseTool
 	conf  *ServerConfig
+	// restarts 连续重启次数
+	restarts int
+	// generation 子进程成功重启的次数，工具在不同代之间不能复用
+	generation uint64
 }
 
 // NewStdioClient 创建Stdio MCP客户端
 // 通过启动子进程运行MCP Server
 func NewStdioClient(conf *ServerConfig) (*StdioClient, error) {
+	cli, err := startStdio(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StdioClient{
+		cli:  cli,
+		conf: conf,
+	}, nil
+}
+
+// startStdio 启动子进程并完成MCP握手
+func startStdio(conf *ServerConfig) (client.MCPClient, error) {
 	log.Printf("🔌 [MCP Client] 启动Stdio模式 | Command: %s %v", conf.Command, conf.Args)
 
 	// 创建stdio客户端
@@ -90,17 +129,88 @@ func NewStdioClient(conf *ServerConfig) (*StdioClient, error) {
 	}
 
 	log.Printf("✅ [MCP Client] Stdio连接成功")
+	return cli, nil
+}
 
-	return &StdioClient{
-		cli:  cli,
-		conf: conf,
-	}, nil
+// errNotRunning 上一次重启失败，当前没有可用的子进程
+var errNotRunning = errors.New("子进程未运行")
+
+// ensureAlive 通过 Ping 检测子进程存活，失败时按重启策略重启并重新握手，
+// 重启后旧连接上的工具全部失效，需要重新加载；restarted 表示本次调用中发生过成功的重启。调用方需持有 c.mu
+func (c *StdioClient) ensureAlive(ctx context.Context) (restarted bool, err error) {
+	policy := c.conf.Restart
+	timeout := defaultPingTimeout
+	if policy != nil && policy.PingTimeout > 0 {
+		timeout = policy.PingTimeout
+	}
+
+	for {
+		err := errNotRunning
+		if c.cli != nil {
+			pingCtx, cancel := context.WithTimeout(ctx, timeout)
+			err = c.cli.Ping(pingCtx)
+			cancel()
+		}
+		if err == nil {
+			c.restarts = 0
+			return restarted, nil
+		}
+		if ctx.Err() != nil {
+			return restarted, ctx.Err()
+		}
+		if policy == nil || c.restarts >= policy.MaxRestarts {
+			return restarted, fmt.Errorf("MCP Server子进程不可用: %w", err)
+		}
+
+		c.restarts++
+		log.Printf("⚠️ [MCP Client] Stdio子进程无响应，尝试重启 (%d/%d): %v", c.restarts, policy.MaxRestarts, err)
+		if c.cli != nil {
+			c.cli.Close()
+			c.cli = nil
+		}
+		c.tools = nil
+
+		if policy.Backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return restarted, ctx.Err()
+			case <-time.After(policy.Backoff):
+			}
+		}
+
+		cli, err := startStdio(c.conf)
+		if err != nil {
+			log.Printf("❌ [MCP Client] Stdio子进程重启失败: %v", err)
+			if c.restarts >= policy.MaxRestarts {
+				return restarted, fmt.Errorf("MCP Server子进程重启失败: %w", err)
+			}
+			continue
+		}
+		c.cli = cli
+		c.generation++
+		restarted = true
+	}
 }
 
-// GetTools 获取所有工具
+// GetTools 获取所有工具，子进程退出后会按重启策略恢复并重新加载工具，重启成功时调用 OnRestart
 func (c *StdioClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
+	tools, restarted, err := c.loadTools(ctx)
+	if restarted && c.conf.OnRestart != nil {
+		c.conf.OnRestart()
+	}
+	return tools, err
+}
+
+func (c *StdioClient) loadTools(ctx context.Context) ([]tool.BaseTool, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	restarted, err := c.ensureAlive(ctx)
+	if err != nil {
+		return nil, restarted, err
+	}
 	if c.tools != nil {
-		return c.tools, nil
+		return c.tools, restarted, nil
 	}
 
 	headers := http.Header{}
@@ -115,12 +225,19 @@ func (c *StdioClient) GetTools(ctx context.Context) ([]tool.BaseTool, error) {
 		CustomHeaders: c.conf.Headers,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("获取工具列表失败: %w", err)
+		return nil, restarted, fmt.Errorf("获取工具列表失败: %w", err)
 	}
 
 	c.tools = tools
 	log.Printf("✅ [MCP Client] Stdio模式加载 %d 个工具", len(c.tools))
-	return c.tools, nil
+	return c.tools, restarted, nil
+}
+
+// Generation 子进程成功重启的次数；与取得工具时的值不同说明那批工具已绑定在失效的连接上
+func (c *StdioClient) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
 }
 
 // GetTool 获取指定工具
@@ -145,6 +262,11 @@ func (c *StdioClient) GetTool(ctx context.Context, name string) (tool.BaseTool,
 
 // Close 关闭客户端
 func (c *StdioClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cli == nil {
+		return nil
+	}
 	return c.cli.Close()
 }
 
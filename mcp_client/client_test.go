@@ -0,0 +1,156 @@
+package mcp_client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// helperEnv 设置后测试二进制作为 stdio MCP Server 运行
+const helperEnv = "MCP_CLIENT_TEST_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperEnv) == "1" {
+		runHelperServer()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runHelperServer 提供 get_video_stats 与 crash 两个工具，调用 crash 时进程直接退出
+func runHelperServer() {
+	s := server.NewMCPServer("helper", "1.0.0")
+	s.AddTool(mcp.NewTool("get_video_stats"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"view":100}`), nil
+	})
+	s.AddTool(mcp.NewTool("crash"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		os.Exit(1)
+		return nil, nil
+	})
+	if err := server.ServeStdio(s); err != nil {
+		os.Exit(2)
+	}
+}
+
+// crashServer 通过 crash 工具让子进程退出
+func crashServer(t *testing.T, c *StdioClient) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	crash, err := c.GetTool(ctx, "crash")
+	if err != nil {
+		t.Fatalf("GetTool(crash): %v", err)
+	}
+	_, _ = crash.(tool.InvokableTool).InvokableRun(ctx, "{}")
+}
+
+func TestStdioClientRestart(t *testing.T) {
+	tests := []struct {
+		name    string
+		restart *RestartPolicy
+		wantErr bool
+	}{
+		{name: "restarts and reloads tools", restart: &RestartPolicy{MaxRestarts: 1, PingTimeout: 500 * time.Millisecond}},
+		{name: "no policy reports the dead subprocess", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exe, err := os.Executable()
+			if err != nil {
+				t.Fatalf("Executable: %v", err)
+			}
+			var restarts atomic.Int32
+			c, err := NewStdioClient(&ServerConfig{
+				Command:   exe,
+				Env:       []string{helperEnv + "=1"},
+				Restart:   tt.restart,
+				OnRestart: func() { restarts.Add(1) },
+			})
+			if err != nil {
+				t.Fatalf("NewStdioClient: %v", err)
+			}
+			defer c.Close()
+
+			crashServer(t, c)
+			if c.Generation() != 0 {
+				t.Fatalf("generation = %d before any restart", c.Generation())
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			tools, err := c.GetTools(ctx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetTools after the subprocess exited should fail without a restart policy")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTools after restart: %v", err)
+			}
+			if len(tools) != 2 || c.restarts != 0 {
+				t.Errorf("tools = %d, restarts = %d; want both tools reloaded and the counter reset", len(tools), c.restarts)
+			}
+			if c.Generation() != 1 || restarts.Load() != 1 {
+				t.Errorf("generation = %d, OnRestart called %d times; want one restart reported", c.Generation(), restarts.Load())
+			}
+
+			stats, err := c.GetTool(ctx, "get_video_stats")
+			if err != nil {
+				t.Fatalf("GetTool: %v", err)
+			}
+			if out, err := stats.(tool.InvokableTool).InvokableRun(ctx, "{}"); err != nil {
+				t.Errorf("tool call on the restarted subprocess: %v", err)
+			} else if out == "" {
+				t.Error("tool call returned no output")
+			}
+		})
+	}
+}
+
+func TestStdioClientRestartFailure(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Executable: %v", err)
+	}
+	conf := &ServerConfig{
+		Command: exe,
+		Env:     []string{helperEnv + "=1"},
+		Restart: &RestartPolicy{MaxRestarts: 2, PingTimeout: 500 * time.Millisecond},
+		OnRestart: func() {
+			t.Error("OnRestart called although no restart succeeded")
+		},
+	}
+	c, err := NewStdioClient(conf)
+	if err != nil {
+		t.Fatalf("NewStdioClient: %v", err)
+	}
+
+	crashServer(t, c)
+	// 子进程无法再启动：每次重启都失败
+	conf.Command = filepath.Join(t.TempDir(), "missing-mcp-server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetTools(ctx); err == nil {
+			t.Fatalf("GetTools %d succeeded without a running subprocess", i)
+		}
+		if c.cli != nil {
+			t.Fatalf("GetTools %d left the closed connection in place", i)
+		}
+	}
+	if c.restarts != 2 || c.Generation() != 0 {
+		t.Errorf("restarts = %d, generation = %d; want the bounded attempts used up and no successful restart", c.restarts, c.Generation())
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close without a subprocess: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package mcp_server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// 工具错误码，以 {"error_code": ..., "message": ...} 的形式写入错误结果，
+// 与 Agent 端 types.ToolErrorCode 的取值一致
+const (
+	errCodeNotFound        = "not_found"
+	errCodeUnauthorized    = "unauthorized"
+	errCodeTimeout         = "timeout"
+	errCodeInvalidArgument = "invalid_argument"
+	errCodeInternal        = "internal"
+)
+
+// gatewayError Gateway 返回的非 200 响应
+type gatewayError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *gatewayError) Error() string {
+	return fmt.Sprintf("Gateway返回错误状态码: %d, 响应: %s", e.StatusCode, e.Body)
+}
+
+// toolError 构建带错误码的工具错误结果
+func toolError(code, message string) *mcp.CallToolResult {
+	payload, _ := json.Marshal(map[string]string{
+		"error_code": code,
+		"message":    message,
+	})
+	return mcp.NewToolResultError(string(payload))
+}
+
+// gatewayToolError 按错误类型（Gateway 状态码、超时等）生成带错误码的工具错误结果
+func gatewayToolError(prefix string, err error) *mcp.CallToolResult {
+	return toolError(classifyError(err), fmt.Sprintf("%s: %v", prefix, err))
+}
+
+func classifyError(err error) string {
+	var gwErr *gatewayError
+	if errors.As(err, &gwErr) {
+		switch gwErr.StatusCode {
+		case http.StatusNotFound:
+			return errCodeNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return errCodeUnauthorized
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return errCodeInvalidArgument
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+			return errCodeTimeout
+		}
+		return errCodeInternal
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errCodeTimeout
+	}
+	return errCodeInternal
+}
@@ -0,0 +1,64 @@
+package mcp_server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// timeoutError 实现 net.Error 的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestGatewayToolError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "404", err: &gatewayError{StatusCode: http.StatusNotFound}, want: errCodeNotFound},
+		{name: "401", err: &gatewayError{StatusCode: http.StatusUnauthorized}, want: errCodeUnauthorized},
+		{name: "403", err: &gatewayError{StatusCode: http.StatusForbidden}, want: errCodeUnauthorized},
+		{name: "400", err: &gatewayError{StatusCode: http.StatusBadRequest}, want: errCodeInvalidArgument},
+		{name: "422", err: &gatewayError{StatusCode: http.StatusUnprocessableEntity}, want: errCodeInvalidArgument},
+		{name: "504", err: &gatewayError{StatusCode: http.StatusGatewayTimeout}, want: errCodeTimeout},
+		{name: "500", err: &gatewayError{StatusCode: http.StatusInternalServerError}, want: errCodeInternal},
+		{name: "wrapped gateway error", err: fmt.Errorf("请求失败: %w", &gatewayError{StatusCode: http.StatusNotFound}), want: errCodeNotFound},
+		{name: "context deadline", err: fmt.Errorf("请求失败: %w", context.DeadlineExceeded), want: errCodeTimeout},
+		{name: "network timeout", err: timeoutError{}, want: errCodeTimeout},
+		{name: "other error", err: errors.New("connection refused"), want: errCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := gatewayToolError("获取视频信息失败", tt.err)
+			if !result.IsError || len(result.Content) != 1 {
+				t.Fatalf("result = %+v, want a single error content", result)
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("content = %T, want text", result.Content[0])
+			}
+			var payload struct {
+				ErrorCode string `json:"error_code"`
+				Message   string `json:"message"`
+			}
+			if err := json.Unmarshal([]byte(text.Text), &payload); err != nil {
+				t.Fatalf("error payload %q: %v", text.Text, err)
+			}
+			if payload.ErrorCode != tt.want {
+				t.Errorf("error_code = %q, want %q", payload.ErrorCode, tt.want)
+			}
+			if payload.Message == "" {
+				t.Error("error payload has no message")
+			}
+		})
+	}
+}
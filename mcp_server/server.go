@@ -8,6 +8,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -76,7 +78,74 @@ func (vs *VideoServer) registerTools(s *server.MCPServer) {
 	s.AddTool(userTool, vs.handleGetUser)
 	log.Printf("✅ [MCP Server] 工具已注册: get_user_info")
 
-	log.Printf("✅ [MCP Server] 注册工具完成，共注册 2 个工具")
+	// 注册获取创作者画像工具
+	log.Printf("🔧 [MCP Server] 注册工具: get_creator_profile")
+	creatorTool := mcp.NewTool("get_creator_profile",
+		mcp.WithDescription("获取创作者画像，包括粉丝数、发布频率、内容主题、平均互动数据等，用于竞品对比"),
+		mcp.WithString("creator_id",
+			mcp.Required(),
+			mcp.Description("创作者（用户）的唯一标识ID"),
+		),
+	)
+	s.AddTool(creatorTool, vs.handleGetCreatorProfile)
+	log.Printf("✅ [MCP Server] 工具已注册: get_creator_profile")
+
+	// 注册获取趋势话题工具
+	log.Printf("🔧 [MCP Server] 注册工具: get_trending_topics")
+	trendTool := mcp.NewTool("get_trending_topics",
+		mcp.WithDescription("获取当前趋势话题排行，包含话题名称、热度和上升势头(momentum)，用于选题与趋势分析"),
+		mcp.WithString("category",
+			mcp.Description("话题分类/领域，如 科技、美食，不传表示全站"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("返回话题数量，默认10"),
+		),
+		mcp.WithString("time_window",
+			mcp.Description("统计时间窗口"),
+			mcp.Enum("day", "week", "month"),
+		),
+	)
+	s.AddTool(trendTool, vs.handleGetTrendingTopics)
+	log.Printf("✅ [MCP Server] 工具已注册: get_trending_topics")
+
+	// 注册获取视频关键帧工具
+	log.Printf("🔧 [MCP Server] 注册工具: get_video_thumbnails")
+	thumbnailTool := mcp.NewTool("get_video_thumbnails",
+		mcp.WithDescription("获取视频封面与关键帧图片URL列表（含时间点），用于基于画面内容的视频分析"),
+		mcp.WithString("video_id",
+			mcp.Required(),
+			mcp.Description("视频的唯一标识ID"),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("返回关键帧数量，默认5，最多20"),
+		),
+	)
+	s.AddTool(thumbnailTool, vs.handleGetVideoThumbnails)
+	log.Printf("✅ [MCP Server] 工具已注册: get_video_thumbnails")
+
+	// 注册获取视频历史数据工具
+	log.Printf("🔧 [MCP Server] 注册工具: get_video_stats_history")
+	historyTool := mcp.NewTool("get_video_stats_history",
+		mcp.WithDescription("获取视频在指定时间范围内的播放量、点赞数、评论数历史快照序列，用于计算环比增长（如比上周涨了多少）"),
+		mcp.WithString("video_id",
+			mcp.Required(),
+			mcp.Description("视频的唯一标识ID"),
+		),
+		mcp.WithString("start",
+			mcp.Description("开始日期，格式 YYYY-MM-DD，默认14天前"),
+		),
+		mcp.WithString("end",
+			mcp.Description("结束日期，格式 YYYY-MM-DD，默认今天"),
+		),
+		mcp.WithString("granularity",
+			mcp.Description("快照粒度，默认day"),
+			mcp.Enum("day", "week"),
+		),
+	)
+	s.AddTool(historyTool, vs.handleGetVideoStatsHistory)
+	log.Printf("✅ [MCP Server] 工具已注册: get_video_stats_history")
+
+	log.Printf("✅ [MCP Server] 注册工具完成，共注册 %d 个工具", len(s.ListTools()))
 }
 
 // printRegisteredTools 打印已注册的工具列表
@@ -102,7 +171,7 @@ func (vs *VideoServer) handleGetVideo(ctx context.Context, request mcp.CallToolR
 
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
-		return nil, fmt.Errorf("video_id参数不能为空")
+		return toolError(errCodeInvalidArgument, "video_id参数不能为空"), nil
 	}
 
 	log.Printf("🔧 [MCP Server] 获取视频 | VideoID: %s", videoID)
@@ -111,7 +180,7 @@ func (vs *VideoServer) handleGetVideo(ctx context.Context, request mcp.CallToolR
 	video, err := vs.fetchVideoFromGateway(ctx, videoID)
 	if err != nil {
 		log.Printf("❌ [MCP Server] 获取视频失败: %v", err)
-		return mcp.NewToolResultError(fmt.Sprintf("获取视频失败: %v", err)), nil
+		return gatewayToolError("获取视频失败", err), nil
 	}
 
 	// 返回JSON结果
@@ -134,7 +203,7 @@ func (vs *VideoServer) handleGetUser(ctx context.Context, request mcp.CallToolRe
 
 	userID, ok := args["user_id"].(string)
 	if !ok || userID == "" {
-		return nil, fmt.Errorf("user_id参数不能为空")
+		return toolError(errCodeInvalidArgument, "user_id参数不能为空"), nil
 	}
 
 	log.Printf("🔧 [MCP Server] 获取用户 | UserID: %s", userID)
@@ -143,7 +212,7 @@ func (vs *VideoServer) handleGetUser(ctx context.Context, request mcp.CallToolRe
 	user, err := vs.fetchUserFromGateway(ctx, userID)
 	if err != nil {
 		log.Printf("❌ [MCP Server] 获取用户失败: %v", err)
-		return mcp.NewToolResultError(fmt.Sprintf("获取用户失败: %v", err)), nil
+		return gatewayToolError("获取用户失败", err), nil
 	}
 
 	// 返回JSON结果
@@ -152,6 +221,205 @@ func (vs *VideoServer) handleGetUser(ctx context.Context, request mcp.CallToolRe
 	return mcp.NewToolResultJSON(resultJSON)
 }
 
+// handleGetCreatorProfile 处理获取创作者画像请求
+func (vs *VideoServer) handleGetCreatorProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("🛠️ [MCP Server] 工具被调用: get_creator_profile")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ [MCP Server] 参数类型错误: %T", request.Params.Arguments)
+		return nil, fmt.Errorf("invalid arguments type")
+	}
+
+	creatorID, ok := args["creator_id"].(string)
+	if !ok || creatorID == "" {
+		return toolError(errCodeInvalidArgument, "creator_id参数不能为空"), nil
+	}
+
+	log.Printf("🔧 [MCP Server] 获取创作者画像 | CreatorID: %s", creatorID)
+
+	profile, err := vs.getFromGateway(ctx, fmt.Sprintf("/api/creator/%s/profile", url.PathEscape(creatorID)))
+	if err != nil {
+		log.Printf("❌ [MCP Server] 获取创作者画像失败: %v", err)
+		return gatewayToolError("获取创作者画像失败", err), nil
+	}
+
+	resultJSON, _ := json.Marshal(profile)
+	log.Printf("✅ [MCP Server] 工具返回数据: %s", string(resultJSON))
+	return mcp.NewToolResultJSON(resultJSON)
+}
+
+// handleGetTrendingTopics 处理获取趋势话题请求
+func (vs *VideoServer) handleGetTrendingTopics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("🛠️ [MCP Server] 工具被调用: get_trending_topics")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ [MCP Server] 参数类型错误: %T", request.Params.Arguments)
+		return nil, fmt.Errorf("invalid arguments type")
+	}
+
+	query := url.Values{}
+	if category, ok := args["category"].(string); ok && category != "" {
+		query.Set("category", category)
+	}
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	query.Set("limit", strconv.Itoa(limit))
+	timeWindow := "day"
+	if tw, ok := args["time_window"].(string); ok && tw != "" {
+		timeWindow = tw
+	}
+	query.Set("time_window", timeWindow)
+
+	log.Printf("🔧 [MCP Server] 获取趋势话题 | %s", query.Encode())
+
+	topics, err := vs.getFromGateway(ctx, "/api/trending/topics?"+query.Encode())
+	if err != nil {
+		log.Printf("❌ [MCP Server] 获取趋势话题失败: %v", err)
+		return gatewayToolError("获取趋势话题失败", err), nil
+	}
+
+	resultJSON, _ := json.Marshal(topics)
+	log.Printf("✅ [MCP Server] 工具返回数据: %s", string(resultJSON))
+	return mcp.NewToolResultJSON(resultJSON)
+}
+
+// handleGetVideoThumbnails 处理获取视频关键帧请求
+func (vs *VideoServer) handleGetVideoThumbnails(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("🛠️ [MCP Server] 工具被调用: get_video_thumbnails")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ [MCP Server] 参数类型错误: %T", request.Params.Arguments)
+		return nil, fmt.Errorf("invalid arguments type")
+	}
+
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return toolError(errCodeInvalidArgument, "video_id参数不能为空"), nil
+	}
+	count := 5
+	if c, ok := args["count"].(float64); ok && c > 0 {
+		count = int(c)
+	}
+	if count > 20 {
+		count = 20
+	}
+
+	log.Printf("🔧 [MCP Server] 获取视频关键帧 | VideoID: %s, Count: %d", videoID, count)
+
+	thumbnails, err := vs.getFromGateway(ctx, fmt.Sprintf("/api/video/%s/thumbnails?count=%d", url.PathEscape(videoID), count))
+	if err != nil {
+		log.Printf("❌ [MCP Server] 获取视频关键帧失败: %v", err)
+		return gatewayToolError("获取视频关键帧失败", err), nil
+	}
+
+	resultJSON, _ := json.Marshal(thumbnails)
+	log.Printf("✅ [MCP Server] 工具返回数据: %s", string(resultJSON))
+	return mcp.NewToolResultJSON(resultJSON)
+}
+
+// handleGetVideoStatsHistory 处理获取视频历史数据请求
+func (vs *VideoServer) handleGetVideoStatsHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("🛠️ [MCP Server] 工具被调用: get_video_stats_history")
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		log.Printf("❌ [MCP Server] 参数类型错误: %T", request.Params.Arguments)
+		return nil, fmt.Errorf("invalid arguments type")
+	}
+
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return toolError(errCodeInvalidArgument, "video_id参数不能为空"), nil
+	}
+
+	now := time.Now()
+	start, err := parseDateArg(args, "start", now.AddDate(0, 0, -14))
+	if err != nil {
+		return toolError(errCodeInvalidArgument, err.Error()), nil
+	}
+	end, err := parseDateArg(args, "end", now)
+	if err != nil {
+		return toolError(errCodeInvalidArgument, err.Error()), nil
+	}
+	if end.Before(start) {
+		return toolError(errCodeInvalidArgument, "end不能早于start"), nil
+	}
+	granularity := "day"
+	if g, ok := args["granularity"].(string); ok && g != "" {
+		if g != "day" && g != "week" {
+			return toolError(errCodeInvalidArgument, "granularity仅支持day或week"), nil
+		}
+		granularity = g
+	}
+
+	query := url.Values{}
+	query.Set("start", start.Format(time.DateOnly))
+	query.Set("end", end.Format(time.DateOnly))
+	query.Set("granularity", granularity)
+
+	log.Printf("🔧 [MCP Server] 获取视频历史数据 | VideoID: %s, %s", videoID, query.Encode())
+
+	history, err := vs.getFromGateway(ctx, fmt.Sprintf("/api/video/%s/stats/history?%s", url.PathEscape(videoID), query.Encode()))
+	if err != nil {
+		log.Printf("❌ [MCP Server] 获取视频历史数据失败: %v", err)
+		return gatewayToolError("获取视频历史数据失败", err), nil
+	}
+
+	resultJSON, _ := json.Marshal(history)
+	log.Printf("✅ [MCP Server] 工具返回数据: %s", string(resultJSON))
+	return mcp.NewToolResultJSON(resultJSON)
+}
+
+// parseDateArg 解析 YYYY-MM-DD 格式的日期参数，未传时返回默认值
+func parseDateArg(args map[string]interface{}, key string, def time.Time) (time.Time, error) {
+	v, ok := args[key].(string)
+	if !ok || v == "" {
+		return def, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s参数格式错误，应为YYYY-MM-DD", key)
+	}
+	return t, nil
+}
+
+// getFromGateway 以 GET 请求Gateway并解析JSON响应
+func (vs *VideoServer) getFromGateway(ctx context.Context, path string) (map[string]interface{}, error) {
+	reqURL := vs.gatewayURL + path
+	log.Printf("🌐 [MCP Server] 请求Gateway: %s", reqURL)
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Gateway失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &gatewayError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var data map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return data, nil
+}
+
 // fetchVideoFromGateway 从Gateway获取视频信息
 func (vs *VideoServer) fetchVideoFromGateway(ctx context.Context, videoID string) (map[string]interface{}, error) {
 	url := fmt.Sprintf("%s/api/video/%s", vs.gatewayURL, videoID)
@@ -178,7 +446,7 @@ func (vs *VideoServer) fetchVideoFromGateway(ctx context.Context, videoID string
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Gateway返回错误状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, &gatewayError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// 解析响应
@@ -217,7 +485,7 @@ func (vs *VideoServer) fetchUserFromGateway(ctx context.Context, userID string)
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Gateway返回错误状态码: %d, 响应: %s", resp.StatusCode, string(body))
+		return nil, &gatewayError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// 解析响应
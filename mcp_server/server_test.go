@@ -0,0 +1,151 @@
+package mcp_server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestGatewayPathEscaping ID 中的 /、?、# 等字符需转义，不能改写 Gateway 的请求路径或查询参数
+func TestGatewayPathEscaping(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   func(*VideoServer, context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args      map[string]interface{}
+		wantPath  string
+		wantQuery string
+	}{
+		{
+			name:     "get_creator_profile",
+			handler:  (*VideoServer).handleGetCreatorProfile,
+			args:     map[string]interface{}{"creator_id": "../admin?x=1#frag"},
+			wantPath: "/api/creator/..%2Fadmin%3Fx=1%23frag/profile",
+		},
+		{
+			name:      "get_video_thumbnails",
+			handler:   (*VideoServer).handleGetVideoThumbnails,
+			args:      map[string]interface{}{"video_id": "BV1?count=100", "count": float64(3)},
+			wantPath:  "/api/video/BV1%3Fcount=100/thumbnails",
+			wantQuery: "count=3",
+		},
+		{
+			name:      "get_video_stats_history",
+			handler:   (*VideoServer).handleGetVideoStatsHistory,
+			args:      map[string]interface{}{"video_id": "BV1/../../user/1", "start": "2026-01-01", "end": "2026-01-14"},
+			wantPath:  "/api/video/BV1%2F..%2F..%2Fuser%2F1/stats/history",
+			wantQuery: "end=2026-01-14&granularity=day&start=2026-01-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotQuery string
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotQuery = r.URL.EscapedPath(), r.URL.RawQuery
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+			}))
+			defer gateway.Close()
+
+			vs := &VideoServer{gatewayURL: gateway.URL}
+			var req mcp.CallToolRequest
+			req.Params.Arguments = tt.args
+			result, err := tt.handler(vs, context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("tool returned error result: %+v", result.Content)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("query = %q, want %q", gotQuery, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestHandleGetTrendingTopics(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		wantQuery string
+	}{
+		{name: "defaults", args: map[string]interface{}{}, wantQuery: "limit=10&time_window=day"},
+		{
+			name:      "category and window",
+			args:      map[string]interface{}{"category": "科技", "limit": float64(3), "time_window": "week"},
+			wantQuery: "category=%E7%A7%91%E6%8A%80&limit=3&time_window=week",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotQuery string
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"topics": []map[string]interface{}{
+						{"name": "AI 绘画", "heat": 98, "momentum": 0.8},
+						{"name": "折叠屏评测", "heat": 75, "momentum": 0.3},
+					},
+				})
+			}))
+			defer gateway.Close()
+
+			vs := &VideoServer{gatewayURL: gateway.URL}
+			var req mcp.CallToolRequest
+			req.Params.Arguments = tt.args
+			result, err := vs.handleGetTrendingTopics(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("tool returned error result: %+v", result.Content)
+			}
+			if gotPath != "/api/trending/topics" || gotQuery != tt.wantQuery {
+				t.Errorf("request = %s?%s, want /api/trending/topics?%s", gotPath, gotQuery, tt.wantQuery)
+			}
+
+			raw, ok := result.StructuredContent.([]byte)
+			if !ok {
+				t.Fatalf("structured content = %T, want the gateway JSON", result.StructuredContent)
+			}
+			var got struct {
+				Topics []struct {
+					Name     string  `json:"name"`
+					Momentum float64 `json:"momentum"`
+				} `json:"topics"`
+			}
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatalf("decode result: %v", err)
+			}
+			if len(got.Topics) != 2 || got.Topics[0].Name != "AI 绘画" || got.Topics[0].Momentum != 0.8 {
+				t.Errorf("topics = %+v, want the ranked gateway topics", got.Topics)
+			}
+		})
+	}
+}
+
+func TestHandleGetTrendingTopicsGatewayError(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer gateway.Close()
+
+	vs := &VideoServer{gatewayURL: gateway.URL}
+	var req mcp.CallToolRequest
+	req.Params.Arguments = map[string]interface{}{}
+	result, err := vs.handleGetTrendingTopics(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("gateway failure should be reported as a tool error result, got %+v", result.Content)
+	}
+}
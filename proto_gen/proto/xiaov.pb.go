@@ -76,12 +76,13 @@ func (x *BaseResponse) GetMessage() string {
 
 // ========== 聊天请求 ==========
 type ChatRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`          // 用户ID（必填）
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                      // 用户发送的消息（必填）
-	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // 会话ID（可选，用于保持上下文）
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                         // 用户ID（必填）
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                                     // 用户发送的消息（必填）
+	SessionId      string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                // 会话ID（可选，用于保持上下文）
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // 幂等键（可选，重试时携带相同值可复用已完成的响应）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ChatRequest) Reset() {
@@ -135,6 +136,13 @@ func (x *ChatRequest) GetSessionId() string {
 	return ""
 }
 
+func (x *ChatRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 // ========== 聊天响应 ==========
 type ChatResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -145,6 +153,7 @@ type ChatResponse struct {
 	Intent        string                 `protobuf:"bytes,5,opt,name=intent,proto3" json:"intent,omitempty"`                                                                               // 识别的意图类型
 	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                        // 时间戳（毫秒）
 	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 元数据（如延迟、历史数量等）
+	Analysis      *VideoAnalysis         `protobuf:"bytes,8,opt,name=analysis,proto3" json:"analysis,omitempty"`                                                                           // 视频分析意图下的结构化结果，其他意图为空
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -228,6 +237,300 @@ func (x *ChatResponse) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *ChatResponse) GetAnalysis() *VideoAnalysis {
+	if x != nil {
+		return x.Analysis
+	}
+	return nil
+}
+
+// ========== 视频分析请求 ==========
+type AnalyzeVideoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                   // 用户ID（必填）
+	VideoId       string                 `protobuf:"bytes,2,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`                // 视频ID（必填）
+	Query         string                 `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`                                   // 分析要求（可选，为空时做整体数据分析）
+	AnalysisType  string                 `protobuf:"bytes,4,opt,name=analysis_type,json=analysisType,proto3" json:"analysis_type,omitempty"` // 分析类型：structured（默认，报告+结构化结果）/report（仅报告文本）
+	SessionId     string                 `protobuf:"bytes,5,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`          // 会话ID（可选）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeVideoRequest) Reset() {
+	*x = AnalyzeVideoRequest{}
+	mi := &file_proto_xiaov_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeVideoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeVideoRequest) ProtoMessage() {}
+
+func (x *AnalyzeVideoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_xiaov_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeVideoRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeVideoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnalyzeVideoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AnalyzeVideoRequest) GetVideoId() string {
+	if x != nil {
+		return x.VideoId
+	}
+	return ""
+}
+
+func (x *AnalyzeVideoRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *AnalyzeVideoRequest) GetAnalysisType() string {
+	if x != nil {
+		return x.AnalysisType
+	}
+	return ""
+}
+
+func (x *AnalyzeVideoRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+// ========== 视频分析响应 ==========
+type VideoAnalysisResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Code             int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`                                                   // 状态码：0-成功
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                                              // 状态描述
+	SessionId        string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                         // 会话ID
+	VideoId          string                 `protobuf:"bytes,4,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`                               // 视频ID
+	Report           string                 `protobuf:"bytes,5,opt,name=report,proto3" json:"report,omitempty"`                                                // 分析报告文本
+	Analysis         *VideoAnalysis         `protobuf:"bytes,6,opt,name=analysis,proto3" json:"analysis,omitempty"`                                            // 结构化结果，analysis_type=report 时为空
+	ToolsUsed        []string               `protobuf:"bytes,7,rep,name=tools_used,json=toolsUsed,proto3" json:"tools_used,omitempty"`                         // 使用的工具
+	ProcessingTimeMs int64                  `protobuf:"varint,8,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"` // 处理耗时（毫秒）
+	Timestamp        int64                  `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                         // 时间戳（毫秒）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *VideoAnalysisResponse) Reset() {
+	*x = VideoAnalysisResponse{}
+	mi := &file_proto_xiaov_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VideoAnalysisResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VideoAnalysisResponse) ProtoMessage() {}
+
+func (x *VideoAnalysisResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_xiaov_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VideoAnalysisResponse.ProtoReflect.Descriptor instead.
+func (*VideoAnalysisResponse) Descriptor() ([]byte, []int) {
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VideoAnalysisResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *VideoAnalysisResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *VideoAnalysisResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *VideoAnalysisResponse) GetVideoId() string {
+	if x != nil {
+		return x.VideoId
+	}
+	return ""
+}
+
+func (x *VideoAnalysisResponse) GetReport() string {
+	if x != nil {
+		return x.Report
+	}
+	return ""
+}
+
+func (x *VideoAnalysisResponse) GetAnalysis() *VideoAnalysis {
+	if x != nil {
+		return x.Analysis
+	}
+	return nil
+}
+
+func (x *VideoAnalysisResponse) GetToolsUsed() []string {
+	if x != nil {
+		return x.ToolsUsed
+	}
+	return nil
+}
+
+func (x *VideoAnalysisResponse) GetProcessingTimeMs() int64 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *VideoAnalysisResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// ========== 结构化视频分析结果 ==========
+type VideoAnalysis struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ViewCount     int64                  `protobuf:"varint,1,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`                                                       // 播放量
+	LikeCount     int64                  `protobuf:"varint,2,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`                                                       // 点赞数
+	CommentCount  int64                  `protobuf:"varint,3,opt,name=comment_count,json=commentCount,proto3" json:"comment_count,omitempty"`                                              // 评论数
+	Sentiment     string                 `protobuf:"bytes,4,opt,name=sentiment,proto3" json:"sentiment,omitempty"`                                                                         // 情感倾向：positive/neutral/negative
+	Summary       string                 `protobuf:"bytes,5,opt,name=summary,proto3" json:"summary,omitempty"`                                                                             // 整体表现总结
+	KeyPoints     []string               `protobuf:"bytes,6,rep,name=key_points,json=keyPoints,proto3" json:"key_points,omitempty"`                                                        // 关键发现
+	Suggestions   []string               `protobuf:"bytes,7,rep,name=suggestions,proto3" json:"suggestions,omitempty"`                                                                     // 优化建议
+	Metrics       map[string]float64     `protobuf:"bytes,8,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // 全部指标
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VideoAnalysis) Reset() {
+	*x = VideoAnalysis{}
+	mi := &file_proto_xiaov_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VideoAnalysis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VideoAnalysis) ProtoMessage() {}
+
+func (x *VideoAnalysis) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_xiaov_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VideoAnalysis.ProtoReflect.Descriptor instead.
+func (*VideoAnalysis) Descriptor() ([]byte, []int) {
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VideoAnalysis) GetViewCount() int64 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *VideoAnalysis) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+func (x *VideoAnalysis) GetCommentCount() int64 {
+	if x != nil {
+		return x.CommentCount
+	}
+	return 0
+}
+
+func (x *VideoAnalysis) GetSentiment() string {
+	if x != nil {
+		return x.Sentiment
+	}
+	return ""
+}
+
+func (x *VideoAnalysis) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *VideoAnalysis) GetKeyPoints() []string {
+	if x != nil {
+		return x.KeyPoints
+	}
+	return nil
+}
+
+func (x *VideoAnalysis) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *VideoAnalysis) GetMetrics() map[string]float64 {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
 // ========== 流式聊天响应 ==========
 type ChatStreamResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -243,7 +546,7 @@ type ChatStreamResponse struct {
 
 func (x *ChatStreamResponse) Reset() {
 	*x = ChatStreamResponse{}
-	mi := &file_proto_xiaov_proto_msgTypes[3]
+	mi := &file_proto_xiaov_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -255,7 +558,7 @@ func (x *ChatStreamResponse) String() string {
 func (*ChatStreamResponse) ProtoMessage() {}
 
 func (x *ChatStreamResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[3]
+	mi := &file_proto_xiaov_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -268,7 +571,7 @@ func (x *ChatStreamResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChatStreamResponse.ProtoReflect.Descriptor instead.
 func (*ChatStreamResponse) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{3}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ChatStreamResponse) GetPayload() isChatStreamResponse_Payload {
@@ -332,13 +635,14 @@ type StreamContent struct {
 	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`                      // 内容片段
 	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // 会话ID
 	Intent        string                 `protobuf:"bytes,3,opt,name=intent,proto3" json:"intent,omitempty"`                        // 意图类型
+	Phase         string                 `protobuf:"bytes,4,opt,name=phase,proto3" json:"phase,omitempty"`                          // 阶段：intent/tool/generate 为进度提示，content 为回复内容
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StreamContent) Reset() {
 	*x = StreamContent{}
-	mi := &file_proto_xiaov_proto_msgTypes[4]
+	mi := &file_proto_xiaov_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -350,7 +654,7 @@ func (x *StreamContent) String() string {
 func (*StreamContent) ProtoMessage() {}
 
 func (x *StreamContent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[4]
+	mi := &file_proto_xiaov_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -363,7 +667,7 @@ func (x *StreamContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamContent.ProtoReflect.Descriptor instead.
 func (*StreamContent) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{4}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *StreamContent) GetContent() string {
@@ -387,6 +691,13 @@ func (x *StreamContent) GetIntent() string {
 	return ""
 }
 
+func (x *StreamContent) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
 type StreamDone struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // 会话ID
@@ -398,7 +709,7 @@ type StreamDone struct {
 
 func (x *StreamDone) Reset() {
 	*x = StreamDone{}
-	mi := &file_proto_xiaov_proto_msgTypes[5]
+	mi := &file_proto_xiaov_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -410,7 +721,7 @@ func (x *StreamDone) String() string {
 func (*StreamDone) ProtoMessage() {}
 
 func (x *StreamDone) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[5]
+	mi := &file_proto_xiaov_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -423,7 +734,7 @@ func (x *StreamDone) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamDone.ProtoReflect.Descriptor instead.
 func (*StreamDone) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{5}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *StreamDone) GetSessionId() string {
@@ -458,7 +769,7 @@ type StreamError struct {
 
 func (x *StreamError) Reset() {
 	*x = StreamError{}
-	mi := &file_proto_xiaov_proto_msgTypes[6]
+	mi := &file_proto_xiaov_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +781,7 @@ func (x *StreamError) String() string {
 func (*StreamError) ProtoMessage() {}
 
 func (x *StreamError) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[6]
+	mi := &file_proto_xiaov_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +794,7 @@ func (x *StreamError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamError.ProtoReflect.Descriptor instead.
 func (*StreamError) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{6}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *StreamError) GetCode() int32 {
@@ -518,7 +829,7 @@ type GetSessionHistoryRequest struct {
 
 func (x *GetSessionHistoryRequest) Reset() {
 	*x = GetSessionHistoryRequest{}
-	mi := &file_proto_xiaov_proto_msgTypes[7]
+	mi := &file_proto_xiaov_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -530,7 +841,7 @@ func (x *GetSessionHistoryRequest) String() string {
 func (*GetSessionHistoryRequest) ProtoMessage() {}
 
 func (x *GetSessionHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[7]
+	mi := &file_proto_xiaov_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -543,7 +854,7 @@ func (x *GetSessionHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSessionHistoryRequest.ProtoReflect.Descriptor instead.
 func (*GetSessionHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{7}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetSessionHistoryRequest) GetSessionId() string {
@@ -566,13 +877,14 @@ type GetSessionHistoryResponse struct {
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	Messages      []*ChatMessage         `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"` // 历史消息列表
 	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`      // 总记录数
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`       // 会话标题（根据前几轮对话生成）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetSessionHistoryResponse) Reset() {
 	*x = GetSessionHistoryResponse{}
-	mi := &file_proto_xiaov_proto_msgTypes[8]
+	mi := &file_proto_xiaov_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -584,7 +896,7 @@ func (x *GetSessionHistoryResponse) String() string {
 func (*GetSessionHistoryResponse) ProtoMessage() {}
 
 func (x *GetSessionHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[8]
+	mi := &file_proto_xiaov_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -597,7 +909,7 @@ func (x *GetSessionHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSessionHistoryResponse.ProtoReflect.Descriptor instead.
 func (*GetSessionHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{8}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetSessionHistoryResponse) GetCode() int32 {
@@ -628,6 +940,13 @@ func (x *GetSessionHistoryResponse) GetTotal() int32 {
 	return 0
 }
 
+func (x *GetSessionHistoryResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
 // 聊天消息
 type ChatMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -644,7 +963,7 @@ type ChatMessage struct {
 
 func (x *ChatMessage) Reset() {
 	*x = ChatMessage{}
-	mi := &file_proto_xiaov_proto_msgTypes[9]
+	mi := &file_proto_xiaov_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -656,7 +975,7 @@ func (x *ChatMessage) String() string {
 func (*ChatMessage) ProtoMessage() {}
 
 func (x *ChatMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[9]
+	mi := &file_proto_xiaov_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -669,7 +988,7 @@ func (x *ChatMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
 func (*ChatMessage) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{9}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ChatMessage) GetId() string {
@@ -731,7 +1050,7 @@ type ClearSessionRequest struct {
 
 func (x *ClearSessionRequest) Reset() {
 	*x = ClearSessionRequest{}
-	mi := &file_proto_xiaov_proto_msgTypes[10]
+	mi := &file_proto_xiaov_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -743,7 +1062,7 @@ func (x *ClearSessionRequest) String() string {
 func (*ClearSessionRequest) ProtoMessage() {}
 
 func (x *ClearSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[10]
+	mi := &file_proto_xiaov_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,7 +1075,7 @@ func (x *ClearSessionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClearSessionRequest.ProtoReflect.Descriptor instead.
 func (*ClearSessionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{10}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ClearSessionRequest) GetSessionId() string {
@@ -777,7 +1096,7 @@ type ClearSessionResponse struct {
 
 func (x *ClearSessionResponse) Reset() {
 	*x = ClearSessionResponse{}
-	mi := &file_proto_xiaov_proto_msgTypes[11]
+	mi := &file_proto_xiaov_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +1108,7 @@ func (x *ClearSessionResponse) String() string {
 func (*ClearSessionResponse) ProtoMessage() {}
 
 func (x *ClearSessionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[11]
+	mi := &file_proto_xiaov_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +1121,7 @@ func (x *ClearSessionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClearSessionResponse.ProtoReflect.Descriptor instead.
 func (*ClearSessionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{11}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ClearSessionResponse) GetCode() int32 {
@@ -835,7 +1154,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_proto_xiaov_proto_msgTypes[12]
+	mi := &file_proto_xiaov_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -847,7 +1166,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[12]
+	mi := &file_proto_xiaov_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -860,23 +1179,24 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{12}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{15}
 }
 
 type HealthCheckResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`        // 状态：healthy/unhealthy
-	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`      // 版本号
-	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 时间戳
-	Features      []string               `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`    // 支持的功能列表
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                                                                                       // 状态：healthy/degraded/unhealthy
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`                                                                                     // 版本号
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                                // 时间戳
+	Features      []string               `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`                                                                                   // 支持的功能列表
+	Dependencies  map[string]string      `protobuf:"bytes,6,rep,name=dependencies,proto3" json:"dependencies,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 依赖状态（如 ollama/mcp -> healthy/unhealthy）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_proto_xiaov_proto_msgTypes[13]
+	mi := &file_proto_xiaov_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -888,7 +1208,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_xiaov_proto_msgTypes[13]
+	mi := &file_proto_xiaov_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -901,7 +1221,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_proto_xiaov_proto_rawDescGZIP(), []int{13}
+	return file_proto_xiaov_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *HealthCheckResponse) GetCode() int32 {
@@ -939,6 +1259,13 @@ func (x *HealthCheckResponse) GetFeatures() []string {
 	return nil
 }
 
+func (x *HealthCheckResponse) GetDependencies() map[string]string {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
 var File_proto_xiaov_proto protoreflect.FileDescriptor
 
 const file_proto_xiaov_proto_rawDesc = "" +
@@ -946,12 +1273,13 @@ const file_proto_xiaov_proto_rawDesc = "" +
 	"\x11proto/xiaov.proto\x12\axiaovpb\"<\n" +
 	"\fBaseResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"_\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x88\x01\n" +
 	"\vChatRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
 	"\n" +
-	"session_id\x18\x03 \x01(\tR\tsessionId\"\xa5\x02\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"\xd9\x02\n" +
 	"\fChatResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
@@ -960,20 +1288,56 @@ const file_proto_xiaov_proto_rawDesc = "" +
 	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x16\n" +
 	"\x06intent\x18\x05 \x01(\tR\x06intent\x12\x1c\n" +
 	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12?\n" +
-	"\bmetadata\x18\a \x03(\v2#.xiaovpb.ChatResponse.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\a \x03(\v2#.xiaovpb.ChatResponse.MetadataEntryR\bmetadata\x122\n" +
+	"\banalysis\x18\b \x01(\v2\x16.xiaovpb.VideoAnalysisR\banalysis\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xac\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x01\n" +
+	"\x13AnalyzeVideoRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bvideo_id\x18\x02 \x01(\tR\avideoId\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12#\n" +
+	"\ranalysis_type\x18\x04 \x01(\tR\fanalysisType\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x05 \x01(\tR\tsessionId\"\xb6\x02\n" +
+	"\x15VideoAnalysisResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x19\n" +
+	"\bvideo_id\x18\x04 \x01(\tR\avideoId\x12\x16\n" +
+	"\x06report\x18\x05 \x01(\tR\x06report\x122\n" +
+	"\banalysis\x18\x06 \x01(\v2\x16.xiaovpb.VideoAnalysisR\banalysis\x12\x1d\n" +
+	"\n" +
+	"tools_used\x18\a \x03(\tR\ttoolsUsed\x12,\n" +
+	"\x12processing_time_ms\x18\b \x01(\x03R\x10processingTimeMs\x12\x1c\n" +
+	"\ttimestamp\x18\t \x01(\x03R\ttimestamp\"\xe6\x02\n" +
+	"\rVideoAnalysis\x12\x1d\n" +
+	"\n" +
+	"view_count\x18\x01 \x01(\x03R\tviewCount\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\x02 \x01(\x03R\tlikeCount\x12#\n" +
+	"\rcomment_count\x18\x03 \x01(\x03R\fcommentCount\x12\x1c\n" +
+	"\tsentiment\x18\x04 \x01(\tR\tsentiment\x12\x18\n" +
+	"\asummary\x18\x05 \x01(\tR\asummary\x12\x1d\n" +
+	"\n" +
+	"key_points\x18\x06 \x03(\tR\tkeyPoints\x12 \n" +
+	"\vsuggestions\x18\a \x03(\tR\vsuggestions\x12=\n" +
+	"\ametrics\x18\b \x03(\v2#.xiaovpb.VideoAnalysis.MetricsEntryR\ametrics\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xac\x01\n" +
 	"\x12ChatStreamResponse\x122\n" +
 	"\acontent\x18\x01 \x01(\v2\x16.xiaovpb.StreamContentH\x00R\acontent\x12)\n" +
 	"\x04done\x18\x02 \x01(\v2\x13.xiaovpb.StreamDoneH\x00R\x04done\x12,\n" +
 	"\x05error\x18\x03 \x01(\v2\x14.xiaovpb.StreamErrorH\x00R\x05errorB\t\n" +
-	"\apayload\"`\n" +
+	"\apayload\"v\n" +
 	"\rStreamContent\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x16\n" +
-	"\x06intent\x18\x03 \x01(\tR\x06intent\"a\n" +
+	"\x06intent\x18\x03 \x01(\tR\x06intent\x12\x14\n" +
+	"\x05phase\x18\x04 \x01(\tR\x05phase\"a\n" +
 	"\n" +
 	"StreamDone\x12\x1d\n" +
 	"\n" +
@@ -988,12 +1352,13 @@ const file_proto_xiaov_proto_rawDesc = "" +
 	"\x18GetSessionHistoryRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\x91\x01\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xa7\x01\n" +
 	"\x19GetSessionHistoryResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
 	"\bmessages\x18\x03 \x03(\v2\x14.xiaovpb.ChatMessageR\bmessages\x12\x14\n" +
-	"\x05total\x18\x04 \x01(\x05R\x05total\"\x9e\x02\n" +
+	"\x05total\x18\x04 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\"\x9e\x02\n" +
 	"\vChatMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
@@ -1013,19 +1378,24 @@ const file_proto_xiaov_proto_rawDesc = "" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
 	"\acleared\x18\x03 \x01(\bR\acleared\"\x14\n" +
-	"\x12HealthCheckRequest\"\x95\x01\n" +
+	"\x12HealthCheckRequest\"\xaa\x02\n" +
 	"\x13HealthCheckResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
 	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1c\n" +
 	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x1a\n" +
-	"\bfeatures\x18\x05 \x03(\tR\bfeatures2\xf9\x02\n" +
+	"\bfeatures\x18\x05 \x03(\tR\bfeatures\x12R\n" +
+	"\fdependencies\x18\x06 \x03(\v2..xiaovpb.HealthCheckResponse.DependenciesEntryR\fdependencies\x1a?\n" +
+	"\x11DependenciesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xc7\x03\n" +
 	"\fXiaovService\x123\n" +
 	"\x04Chat\x12\x14.xiaovpb.ChatRequest\x1a\x15.xiaovpb.ChatResponse\x12A\n" +
 	"\n" +
 	"ChatStream\x12\x14.xiaovpb.ChatRequest\x1a\x1b.xiaovpb.ChatStreamResponse0\x01\x12Z\n" +
 	"\x11GetSessionHistory\x12!.xiaovpb.GetSessionHistoryRequest\x1a\".xiaovpb.GetSessionHistoryResponse\x12K\n" +
-	"\fClearSession\x12\x1c.xiaovpb.ClearSessionRequest\x1a\x1d.xiaovpb.ClearSessionResponse\x12H\n" +
+	"\fClearSession\x12\x1c.xiaovpb.ClearSessionRequest\x1a\x1d.xiaovpb.ClearSessionResponse\x12L\n" +
+	"\fAnalyzeVideo\x12\x1c.xiaovpb.AnalyzeVideoRequest\x1a\x1e.xiaovpb.VideoAnalysisResponse\x12H\n" +
 	"\vHealthCheck\x12\x1b.xiaovpb.HealthCheckRequest\x1a\x1c.xiaovpb.HealthCheckResponseB/Z-github.com/vision_world/video_agent/proto_genb\x06proto3"
 
 var (
@@ -1040,47 +1410,58 @@ func file_proto_xiaov_proto_rawDescGZIP() []byte {
 	return file_proto_xiaov_proto_rawDescData
 }
 
-var file_proto_xiaov_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_proto_xiaov_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_proto_xiaov_proto_goTypes = []any{
 	(*BaseResponse)(nil),              // 0: xiaovpb.BaseResponse
 	(*ChatRequest)(nil),               // 1: xiaovpb.ChatRequest
 	(*ChatResponse)(nil),              // 2: xiaovpb.ChatResponse
-	(*ChatStreamResponse)(nil),        // 3: xiaovpb.ChatStreamResponse
-	(*StreamContent)(nil),             // 4: xiaovpb.StreamContent
-	(*StreamDone)(nil),                // 5: xiaovpb.StreamDone
-	(*StreamError)(nil),               // 6: xiaovpb.StreamError
-	(*GetSessionHistoryRequest)(nil),  // 7: xiaovpb.GetSessionHistoryRequest
-	(*GetSessionHistoryResponse)(nil), // 8: xiaovpb.GetSessionHistoryResponse
-	(*ChatMessage)(nil),               // 9: xiaovpb.ChatMessage
-	(*ClearSessionRequest)(nil),       // 10: xiaovpb.ClearSessionRequest
-	(*ClearSessionResponse)(nil),      // 11: xiaovpb.ClearSessionResponse
-	(*HealthCheckRequest)(nil),        // 12: xiaovpb.HealthCheckRequest
-	(*HealthCheckResponse)(nil),       // 13: xiaovpb.HealthCheckResponse
-	nil,                               // 14: xiaovpb.ChatResponse.MetadataEntry
-	nil,                               // 15: xiaovpb.ChatMessage.MetadataEntry
+	(*AnalyzeVideoRequest)(nil),       // 3: xiaovpb.AnalyzeVideoRequest
+	(*VideoAnalysisResponse)(nil),     // 4: xiaovpb.VideoAnalysisResponse
+	(*VideoAnalysis)(nil),             // 5: xiaovpb.VideoAnalysis
+	(*ChatStreamResponse)(nil),        // 6: xiaovpb.ChatStreamResponse
+	(*StreamContent)(nil),             // 7: xiaovpb.StreamContent
+	(*StreamDone)(nil),                // 8: xiaovpb.StreamDone
+	(*StreamError)(nil),               // 9: xiaovpb.StreamError
+	(*GetSessionHistoryRequest)(nil),  // 10: xiaovpb.GetSessionHistoryRequest
+	(*GetSessionHistoryResponse)(nil), // 11: xiaovpb.GetSessionHistoryResponse
+	(*ChatMessage)(nil),               // 12: xiaovpb.ChatMessage
+	(*ClearSessionRequest)(nil),       // 13: xiaovpb.ClearSessionRequest
+	(*ClearSessionResponse)(nil),      // 14: xiaovpb.ClearSessionResponse
+	(*HealthCheckRequest)(nil),        // 15: xiaovpb.HealthCheckRequest
+	(*HealthCheckResponse)(nil),       // 16: xiaovpb.HealthCheckResponse
+	nil,                               // 17: xiaovpb.ChatResponse.MetadataEntry
+	nil,                               // 18: xiaovpb.VideoAnalysis.MetricsEntry
+	nil,                               // 19: xiaovpb.ChatMessage.MetadataEntry
+	nil,                               // 20: xiaovpb.HealthCheckResponse.DependenciesEntry
 }
 var file_proto_xiaov_proto_depIdxs = []int32{
-	14, // 0: xiaovpb.ChatResponse.metadata:type_name -> xiaovpb.ChatResponse.MetadataEntry
-	4,  // 1: xiaovpb.ChatStreamResponse.content:type_name -> xiaovpb.StreamContent
-	5,  // 2: xiaovpb.ChatStreamResponse.done:type_name -> xiaovpb.StreamDone
-	6,  // 3: xiaovpb.ChatStreamResponse.error:type_name -> xiaovpb.StreamError
-	9,  // 4: xiaovpb.GetSessionHistoryResponse.messages:type_name -> xiaovpb.ChatMessage
-	15, // 5: xiaovpb.ChatMessage.metadata:type_name -> xiaovpb.ChatMessage.MetadataEntry
-	1,  // 6: xiaovpb.XiaovService.Chat:input_type -> xiaovpb.ChatRequest
-	1,  // 7: xiaovpb.XiaovService.ChatStream:input_type -> xiaovpb.ChatRequest
-	7,  // 8: xiaovpb.XiaovService.GetSessionHistory:input_type -> xiaovpb.GetSessionHistoryRequest
-	10, // 9: xiaovpb.XiaovService.ClearSession:input_type -> xiaovpb.ClearSessionRequest
-	12, // 10: xiaovpb.XiaovService.HealthCheck:input_type -> xiaovpb.HealthCheckRequest
-	2,  // 11: xiaovpb.XiaovService.Chat:output_type -> xiaovpb.ChatResponse
-	3,  // 12: xiaovpb.XiaovService.ChatStream:output_type -> xiaovpb.ChatStreamResponse
-	8,  // 13: xiaovpb.XiaovService.GetSessionHistory:output_type -> xiaovpb.GetSessionHistoryResponse
-	11, // 14: xiaovpb.XiaovService.ClearSession:output_type -> xiaovpb.ClearSessionResponse
-	13, // 15: xiaovpb.XiaovService.HealthCheck:output_type -> xiaovpb.HealthCheckResponse
-	11, // [11:16] is the sub-list for method output_type
-	6,  // [6:11] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	17, // 0: xiaovpb.ChatResponse.metadata:type_name -> xiaovpb.ChatResponse.MetadataEntry
+	5,  // 1: xiaovpb.ChatResponse.analysis:type_name -> xiaovpb.VideoAnalysis
+	5,  // 2: xiaovpb.VideoAnalysisResponse.analysis:type_name -> xiaovpb.VideoAnalysis
+	18, // 3: xiaovpb.VideoAnalysis.metrics:type_name -> xiaovpb.VideoAnalysis.MetricsEntry
+	7,  // 4: xiaovpb.ChatStreamResponse.content:type_name -> xiaovpb.StreamContent
+	8,  // 5: xiaovpb.ChatStreamResponse.done:type_name -> xiaovpb.StreamDone
+	9,  // 6: xiaovpb.ChatStreamResponse.error:type_name -> xiaovpb.StreamError
+	12, // 7: xiaovpb.GetSessionHistoryResponse.messages:type_name -> xiaovpb.ChatMessage
+	19, // 8: xiaovpb.ChatMessage.metadata:type_name -> xiaovpb.ChatMessage.MetadataEntry
+	20, // 9: xiaovpb.HealthCheckResponse.dependencies:type_name -> xiaovpb.HealthCheckResponse.DependenciesEntry
+	1,  // 10: xiaovpb.XiaovService.Chat:input_type -> xiaovpb.ChatRequest
+	1,  // 11: xiaovpb.XiaovService.ChatStream:input_type -> xiaovpb.ChatRequest
+	10, // 12: xiaovpb.XiaovService.GetSessionHistory:input_type -> xiaovpb.GetSessionHistoryRequest
+	13, // 13: xiaovpb.XiaovService.ClearSession:input_type -> xiaovpb.ClearSessionRequest
+	3,  // 14: xiaovpb.XiaovService.AnalyzeVideo:input_type -> xiaovpb.AnalyzeVideoRequest
+	15, // 15: xiaovpb.XiaovService.HealthCheck:input_type -> xiaovpb.HealthCheckRequest
+	2,  // 16: xiaovpb.XiaovService.Chat:output_type -> xiaovpb.ChatResponse
+	6,  // 17: xiaovpb.XiaovService.ChatStream:output_type -> xiaovpb.ChatStreamResponse
+	11, // 18: xiaovpb.XiaovService.GetSessionHistory:output_type -> xiaovpb.GetSessionHistoryResponse
+	14, // 19: xiaovpb.XiaovService.ClearSession:output_type -> xiaovpb.ClearSessionResponse
+	4,  // 20: xiaovpb.XiaovService.AnalyzeVideo:output_type -> xiaovpb.VideoAnalysisResponse
+	16, // 21: xiaovpb.XiaovService.HealthCheck:output_type -> xiaovpb.HealthCheckResponse
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_xiaov_proto_init() }
@@ -1088,7 +1469,7 @@ func file_proto_xiaov_proto_init() {
 	if File_proto_xiaov_proto != nil {
 		return
 	}
-	file_proto_xiaov_proto_msgTypes[3].OneofWrappers = []any{
+	file_proto_xiaov_proto_msgTypes[6].OneofWrappers = []any{
 		(*ChatStreamResponse_Content)(nil),
 		(*ChatStreamResponse_Done)(nil),
 		(*ChatStreamResponse_Error)(nil),
@@ -1099,7 +1480,7 @@ func file_proto_xiaov_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_xiaov_proto_rawDesc), len(file_proto_xiaov_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   16,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
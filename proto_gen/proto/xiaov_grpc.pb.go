@@ -23,6 +23,7 @@ const (
 	XiaovService_ChatStream_FullMethodName        = "/xiaovpb.XiaovService/ChatStream"
 	XiaovService_GetSessionHistory_FullMethodName = "/xiaovpb.XiaovService/GetSessionHistory"
 	XiaovService_ClearSession_FullMethodName      = "/xiaovpb.XiaovService/ClearSession"
+	XiaovService_AnalyzeVideo_FullMethodName      = "/xiaovpb.XiaovService/AnalyzeVideo"
 	XiaovService_HealthCheck_FullMethodName       = "/xiaovpb.XiaovService/HealthCheck"
 )
 
@@ -38,6 +39,8 @@ type XiaovServiceClient interface {
 	GetSessionHistory(ctx context.Context, in *GetSessionHistoryRequest, opts ...grpc.CallOption) (*GetSessionHistoryResponse, error)
 	// 清空会话历史
 	ClearSession(ctx context.Context, in *ClearSessionRequest, opts ...grpc.CallOption) (*ClearSessionResponse, error)
+	// 直接分析指定视频（不经过意图识别），返回结构化结果
+	AnalyzeVideo(ctx context.Context, in *AnalyzeVideoRequest, opts ...grpc.CallOption) (*VideoAnalysisResponse, error)
 	// 健康检查
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -109,6 +112,15 @@ func (c *xiaovServiceClient) ClearSession(ctx context.Context, in *ClearSessionR
 	return out, nil
 }
 
+func (c *xiaovServiceClient) AnalyzeVideo(ctx context.Context, in *AnalyzeVideoRequest, opts ...grpc.CallOption) (*VideoAnalysisResponse, error) {
+	out := new(VideoAnalysisResponse)
+	err := c.cc.Invoke(ctx, XiaovService_AnalyzeVideo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *xiaovServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	out := new(HealthCheckResponse)
 	err := c.cc.Invoke(ctx, XiaovService_HealthCheck_FullMethodName, in, out, opts...)
@@ -130,6 +142,8 @@ type XiaovServiceServer interface {
 	GetSessionHistory(context.Context, *GetSessionHistoryRequest) (*GetSessionHistoryResponse, error)
 	// 清空会话历史
 	ClearSession(context.Context, *ClearSessionRequest) (*ClearSessionResponse, error)
+	// 直接分析指定视频（不经过意图识别），返回结构化结果
+	AnalyzeVideo(context.Context, *AnalyzeVideoRequest) (*VideoAnalysisResponse, error)
 	// 健康检查
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedXiaovServiceServer()
@@ -151,6 +165,9 @@ func (UnimplementedXiaovServiceServer) GetSessionHistory(context.Context, *GetSe
 func (UnimplementedXiaovServiceServer) ClearSession(context.Context, *ClearSessionRequest) (*ClearSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClearSession not implemented")
 }
+func (UnimplementedXiaovServiceServer) AnalyzeVideo(context.Context, *AnalyzeVideoRequest) (*VideoAnalysisResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeVideo not implemented")
+}
 func (UnimplementedXiaovServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -242,6 +259,24 @@ func _XiaovService_ClearSession_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _XiaovService_AnalyzeVideo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeVideoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XiaovServiceServer).AnalyzeVideo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: XiaovService_AnalyzeVideo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XiaovServiceServer).AnalyzeVideo(ctx, req.(*AnalyzeVideoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _XiaovService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -279,6 +314,10 @@ var XiaovService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ClearSession",
 			Handler:    _XiaovService_ClearSession_Handler,
 		},
+		{
+			MethodName: "AnalyzeVideo",
+			Handler:    _XiaovService_AnalyzeVideo_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _XiaovService_HealthCheck_Handler,
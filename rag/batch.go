@@ -0,0 +1,117 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DocumentInput 批量添加的文档
+type DocumentInput struct {
+	Content  string
+	Metadata map[string]interface{}
+}
+
+// AddDocuments 向默认集合批量添加文档，见 AddDocumentsToCollection
+func (rm *RAGManager) AddDocuments(docs []DocumentInput) ([]*Document, error) {
+	return rm.StoreDocuments(context.Background(), docs)
+}
+
+// StoreDocuments 向默认集合批量添加文档，ctx 取消时停止生成向量，见 AddDocumentsToCollection
+func (rm *RAGManager) StoreDocuments(ctx context.Context, docs []DocumentInput) ([]*Document, error) {
+	return rm.AddDocumentsToCollection(ctx, DefaultCollection, docs)
+}
+
+// AddDocumentsToCollection 批量添加文档：先按 DefaultIndexBatchSize 分批为全部文档生成向量，
+// 再一次性写入并只持久化一次。任一文档嵌入失败或写入存储失败时不添加任何文档，返回按输入顺序排列的文档
+func (rm *RAGManager) AddDocumentsToCollection(ctx context.Context, collection string, docs []DocumentInput) ([]*Document, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	collection = normalizeCollection(collection)
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Content
+	}
+	embeddings, err := rm.embedBatch(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+
+	now := time.Now()
+	added := make([]*Document, len(docs))
+	for i, in := range docs {
+		id := rm.documentID(collection, in.Content)
+		if !rm.contentHashIDs {
+			// 时间戳ID在同一批内可能重复
+			id = fmt.Sprintf("doc-%d-%d", now.UnixNano(), i)
+		}
+		added[i] = &Document{
+			ID:         id,
+			Content:    in.Content,
+			Metadata:   in.Metadata,
+			Embedding:  embeddings[i],
+			CreatedAt:  now,
+			Collection: storedCollection(collection),
+		}
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	// previous 记录被覆盖的文档（新增的为 nil），持久化失败时据此回滚
+	previous := make(map[string]*Document, len(added))
+	for _, doc := range added {
+		existing, ok := rm.documents[doc.ID]
+		if _, seen := previous[doc.ID]; !seen {
+			previous[doc.ID] = existing
+		}
+		if ok {
+			doc.CreatedAt = existing.CreatedAt
+		}
+		rm.documents[doc.ID] = doc
+	}
+
+	if err := rm.saveDocuments(); err != nil {
+		for id, doc := range previous {
+			if doc == nil {
+				delete(rm.documents, id)
+			} else {
+				rm.documents[id] = doc
+			}
+		}
+		return nil, err
+	}
+	return added, nil
+}
+
+// embedBatch 按 DefaultIndexBatchSize 分批生成向量，返回的向量与 texts 一一对应
+func (rm *RAGManager) embedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if rm.embedder == nil {
+		hash := &HashEmbedder{Dim: rm.embeddingDim}
+		return hash.Embed(ctx, texts)
+	}
+
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += DefaultIndexBatchSize {
+		end := min(start+DefaultIndexBatchSize, len(texts))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch, err := rm.embedder.EmbedStrings(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(batch))
+		}
+		for _, vector := range batch {
+			if len(vector) != rm.embeddingDim {
+				return nil, fmt.Errorf("%w: model returned %d dims, expected %d", ErrEmbeddingDimMismatch, len(vector), rm.embeddingDim)
+			}
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
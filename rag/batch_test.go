@@ -0,0 +1,129 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func batchInputs(n int) []DocumentInput {
+	docs := make([]DocumentInput, n)
+	for i := range docs {
+		docs[i] = DocumentInput{
+			Content:  fmt.Sprintf("视频 BV%d 的完播率分析 关键词%d", i, i),
+			Metadata: map[string]interface{}{"video_id": fmt.Sprintf("BV%d", i)},
+		}
+	}
+	return docs
+}
+
+func TestStoreDocumentsRetrievable(t *testing.T) {
+	tests := []struct {
+		name      string
+		docs      int
+		wantCalls int
+	}{
+		{name: "single document", docs: 1, wantCalls: 1},
+		{name: "one embedding batch", docs: DefaultIndexBatchSize, wantCalls: 1},
+		{name: "several embedding batches", docs: 2*DefaultIndexBatchSize + 3, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			embedder := newCountingEmbedder(32)
+			rm := newTestManagerAt(t, dir, embedder)
+			inputs := batchInputs(tt.docs)
+
+			added, err := rm.StoreDocuments(context.Background(), inputs)
+			if err != nil {
+				t.Fatalf("StoreDocuments: %v", err)
+			}
+			if len(added) != tt.docs {
+				t.Fatalf("added %d docs, want %d", len(added), tt.docs)
+			}
+			if got := embedder.Calls(); got != tt.wantCalls {
+				t.Errorf("embed calls = %d, want %d", got, tt.wantCalls)
+			}
+
+			// 重新加载后全部文档仍可按 ID 取回、按内容检索到
+			reloaded := newTestManagerAt(t, dir, NewHashEmbedder(32))
+			for i, doc := range added {
+				got, ok := reloaded.GetDocument(doc.ID)
+				if !ok || got.Content != inputs[i].Content || got.Metadata["video_id"] != inputs[i].Metadata["video_id"] {
+					t.Fatalf("GetDocument(%s) = %+v, %v; want %q", doc.ID, got, ok, inputs[i].Content)
+				}
+				results, err := reloaded.SearchSimilarDocuments(inputs[i].Content, 1)
+				if err != nil {
+					t.Fatalf("SearchSimilarDocuments: %v", err)
+				}
+				if len(results) != 1 || results[0].ID != doc.ID {
+					t.Errorf("search %q returned %v, want %s first", inputs[i].Content, results, doc.ID)
+				}
+			}
+		})
+	}
+}
+
+// failingEmbedder 第 failOn 次调用起返回错误
+type failingEmbedder struct {
+	calls  int
+	failOn int
+}
+
+func (e *failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	if e.calls >= e.failOn {
+		return nil, errors.New("embedding service unavailable")
+	}
+	return NewHashEmbedder(32).Embed(ctx, texts)
+}
+
+func TestStoreDocumentsAllOrNothing(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		embedder Embedder
+	}{
+		{name: "second embedding batch fails", ctx: context.Background(), embedder: &failingEmbedder{failOn: 2}},
+		{name: "context canceled", ctx: canceled, embedder: NewHashEmbedder(32)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestManagerAt(t, t.TempDir(), tt.embedder)
+			if _, err := rm.StoreDocuments(tt.ctx, batchInputs(DefaultIndexBatchSize+1)); err == nil {
+				t.Fatal("StoreDocuments should fail")
+			}
+			if docs := rm.GetAllDocuments(); len(docs) != 0 {
+				t.Errorf("%d documents added after a failed batch, want none", len(docs))
+			}
+		})
+	}
+}
+
+func BenchmarkAddDocuments(b *testing.B) {
+	const n = 50
+	inputs := batchInputs(n)
+
+	b.Run("single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rm := newTestManager(b)
+			for _, in := range inputs {
+				if err := rm.AddDocument(in.Content, in.Metadata); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rm := newTestManager(b)
+			if _, err := rm.StoreDocuments(context.Background(), inputs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
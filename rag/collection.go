@@ -0,0 +1,49 @@
+package rag
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultCollection 未指定集合时使用的集合，AddDocument、SearchWithScores 等不带集合参数的方法均作用于它
+const DefaultCollection = "default"
+
+// normalizeCollection 去除首尾空白，为空时返回 DefaultCollection
+func normalizeCollection(collection string) string {
+	collection = strings.TrimSpace(collection)
+	if collection == "" {
+		return DefaultCollection
+	}
+	return collection
+}
+
+// storedCollection 写入 Document.Collection 的值，默认集合存为空，保持存储文件与旧版本一致
+func storedCollection(collection string) string {
+	if collection == DefaultCollection {
+		return ""
+	}
+	return collection
+}
+
+// CollectionName 文档所属集合
+func (d *Document) CollectionName() string {
+	return normalizeCollection(d.Collection)
+}
+
+// Collections 返回已有文档的集合名，按名称排序；没有文档时为空
+func (rm *RAGManager) Collections() []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var collections []string
+	for _, doc := range rm.documents {
+		name := doc.CollectionName()
+		if !seen[name] {
+			seen[name] = true
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections
+}
@@ -0,0 +1,88 @@
+package rag
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newVocabManagerAt 在 dir 下使用 vocabEmbedder 创建 RAGManager
+func newVocabManagerAt(t *testing.T, dir string, vocab vocabEmbedder) *RAGManager {
+	t.Helper()
+	rm, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&EmbeddingConfig{Embedder: vocab, Dimension: len(vocab), CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	return rm
+}
+
+func TestCollectionsAreIsolated(t *testing.T) {
+	vocab := vocabEmbedder{"video", "danmaku", "creator"}
+	dir := t.TempDir()
+	rm := newVocabManagerAt(t, dir, vocab)
+	for _, d := range []struct{ collection, content string }{
+		{"video_analysis", "video danmaku report"},
+		{"kb", "creator danmaku guide"},
+		{"", "video creator faq"},
+	} {
+		if err := rm.AddDocumentToCollection(d.collection, d.content, nil); err != nil {
+			t.Fatalf("AddDocumentToCollection: %v", err)
+		}
+	}
+	rm.SetKeywordFallback(0.5)
+
+	tests := []struct {
+		name       string
+		collection string
+		query      string
+		want       []string
+	}{
+		{name: "video analysis collection", collection: "video_analysis", query: "danmaku", want: []string{"video danmaku report"}},
+		{name: "kb collection", collection: "kb", query: "danmaku", want: []string{"creator danmaku guide"}},
+		{name: "blank name is the default collection", collection: "  ", query: "video", want: []string{"video creator faq"}},
+		{name: "keyword fallback matches in its collection", collection: "kb", query: "guide", want: []string{"creator danmaku guide"}},
+		// 其他集合的关键词命中不会返回，仍为本集合的向量结果
+		{name: "keyword fallback ignores other collections", collection: "video_analysis", query: "guide", want: []string{"video danmaku report"}},
+		{name: "unknown collection", collection: "missing", query: "video", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := rm.SearchCollection(tt.collection, tt.query, 5, 0)
+			if err != nil {
+				t.Fatalf("SearchCollection: %v", err)
+			}
+			var got []string
+			for _, doc := range results {
+				got = append(got, doc.Content)
+				if doc.CollectionName() != normalizeCollection(tt.collection) {
+					t.Errorf("result %q from collection %s, want %s", doc.Content, doc.CollectionName(), normalizeCollection(tt.collection))
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchCollection(%q, %q) = %v, want %v", tt.collection, tt.query, got, tt.want)
+			}
+		})
+	}
+
+	want := []string{DefaultCollection, "kb", "video_analysis"}
+	if got := rm.Collections(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Collections = %v, want %v", got, want)
+	}
+	if got := newVocabManagerAt(t, dir, vocab).Collections(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Collections after reload = %v, want %v", got, want)
+	}
+}
+
+func TestContentHashIDsPerCollection(t *testing.T) {
+	rm := newTestManager(t)
+	rm.SetContentHashIDs(true, "kb")
+	for _, collection := range []string{"video_analysis", "kb", "kb"} {
+		if err := rm.AddDocumentToCollection(collection, "完播率", nil); err != nil {
+			t.Fatalf("AddDocumentToCollection: %v", err)
+		}
+	}
+	if docs := rm.GetAllDocuments(); len(docs) != 2 {
+		t.Errorf("stored %d documents, want one per collection", len(docs))
+	}
+}
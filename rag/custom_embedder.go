@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
@@ -16,13 +18,49 @@ type OllamaEmbedder struct {
 	baseURL string
 	model   string
 	timeout time.Duration
+	retry   RetryPolicy
 }
 
 // OllamaEmbedderConfig Ollama 嵌入器配置
 type OllamaEmbedderConfig struct {
 	BaseURL string
 	Model   string
+	// Timeout 单次 HTTP 请求的超时时间
 	Timeout time.Duration
+	// Retry 连接错误与 5xx 响应的重试策略，为 nil 时使用 DefaultRetryPolicy
+	Retry *RetryPolicy
+}
+
+// RetryPolicy Ollama 请求的指数退避重试策略，4xx 响应不重试
+type RetryPolicy struct {
+	// MaxRetries 首次请求失败后的最大重试次数，0 表示不重试
+	MaxRetries int
+	// InitialBackoff 第一次重试前的等待时间，之后每次翻倍
+	InitialBackoff time.Duration
+	// MaxBackoff 单次等待时间上限，<=0 时不设上限
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy Ollama 短暂繁忙（503）或重启时的默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     2,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// backoff 第 attempt 次重试（从 1 开始）前的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
 }
 
 // NewOllamaEmbedder 创建自定义 Ollama 嵌入器
@@ -36,18 +74,32 @@ func NewOllamaEmbedder(config *OllamaEmbedderConfig) (*OllamaEmbedder, error) {
 	if config.Timeout == 0 {
 		config.Timeout = 10 * time.Second
 	}
+	retry := DefaultRetryPolicy
+	if config.Retry != nil {
+		retry = *config.Retry
+	}
 
 	return &OllamaEmbedder{
 		baseURL: config.BaseURL,
 		model:   config.Model,
 		timeout: config.Timeout,
+		retry:   retry,
 	}, nil
 }
 
-// EmbedStrings 实现 embedding.Embedder 接口
+// EmbedStrings 实现 embedding.Embedder 接口，通过 /api/embed 一次请求批量生成；
+// 旧版本 Ollama 不支持批量接口（404）时逐条调用 /api/embeddings
 func (e *OllamaEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
-	results := make([][]float64, len(texts))
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+
+	results, err := e.getEmbeddings(ctx, texts)
+	if !errors.Is(err, errBatchEmbedUnsupported) {
+		return results, err
+	}
 
+	results = make([][]float64, len(texts))
 	for i, text := range texts {
 		embedding, err := e.getEmbedding(ctx, text)
 		if err != nil {
@@ -59,50 +111,118 @@ func (e *OllamaEmbedder) EmbedStrings(ctx context.Context, texts []string, opts
 	return results, nil
 }
 
-// ollamaEmbeddingRequest Ollama API 请求结构
-type ollamaEmbeddingRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
+// errBatchEmbedUnsupported Ollama 未提供 /api/embed 批量接口
+var errBatchEmbedUnsupported = errors.New("batch embedding not supported")
+
+// ollamaBatchEmbeddingRequest Ollama 批量嵌入请求结构
+type ollamaBatchEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
 }
 
-// ollamaEmbeddingResponse Ollama API 响应结构
-type ollamaEmbeddingResponse struct {
-	Embedding []float64 `json:"embedding"`
+// ollamaBatchEmbeddingResponse Ollama 批量嵌入响应结构
+type ollamaBatchEmbeddingResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
 }
 
-// getEmbedding 调用 Ollama API 获取嵌入向量
-func (e *OllamaEmbedder) getEmbedding(ctx context.Context, text string) ([]float64, error) {
-	reqBody := ollamaEmbeddingRequest{
-		Model:  e.model,
-		Prompt: text,
+// getEmbeddings 调用 Ollama /api/embed 批量获取嵌入向量
+func (e *OllamaEmbedder) getEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	var result ollamaBatchEmbeddingResponse
+	err := e.post(ctx, "/api/embed", ollamaBatchEmbeddingRequest{Model: e.model, Input: texts}, &result)
+	var statusErr *ollamaStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, errBatchEmbedUnsupported
 	}
-
-	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Embeddings))
 	}
+	return result.Embeddings, nil
+}
+
+// ollamaStatusError Ollama 返回非 200 状态码
+type ollamaStatusError struct {
+	StatusCode int
+}
+
+func (e *ollamaStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/api/embeddings", bytes.NewBuffer(jsonData))
+// post 发送 JSON 请求并解码响应，连接错误与 5xx 响应按重试策略指数退避重试
+func (e *OllamaEmbedder) post(ctx context.Context, path string, body, out interface{}) error {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	client := &http.Client{Timeout: e.timeout}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			wait := e.retry.backoff(attempt)
+			log.Printf("[OllamaEmbedder] %s failed, retrying in %s (%d/%d): %v", path, wait, attempt, e.retry.MaxRetries, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		var retryable bool
+		retryable, err = e.doPost(ctx, client, path, jsonData, out)
+		if err == nil || !retryable || attempt >= e.retry.MaxRetries || ctx.Err() != nil {
+			return err
+		}
 	}
+}
 
+// doPost 发送一次请求，返回的 retryable 表示错误是否值得重试
+func (e *OllamaEmbedder) doPost(ctx context.Context, client *http.Client, path string, jsonData []byte, out interface{}) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: e.timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return true, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return resp.StatusCode >= http.StatusInternalServerError, &ollamaStatusError{StatusCode: resp.StatusCode}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return false, nil
+}
+
+// ollamaEmbeddingRequest Ollama API 请求结构
+type ollamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// ollamaEmbeddingResponse Ollama API 响应结构
+type ollamaEmbeddingResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+// getEmbedding 调用 Ollama API 获取嵌入向量
+func (e *OllamaEmbedder) getEmbedding(ctx context.Context, text string) ([]float64, error) {
+	reqBody := ollamaEmbeddingRequest{
+		Model:  e.model,
+		Prompt: text,
 	}
 
 	var result ollamaEmbeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := e.post(ctx, "/api/embeddings", reqBody, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Embedding, nil
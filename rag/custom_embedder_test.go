@@ -0,0 +1,135 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedOllama 按 statuses 依次返回状态码，之后返回 200 与嵌入向量；记录每个路径收到的请求数
+type scriptedOllama struct {
+	mu       sync.Mutex
+	statuses []int
+	requests map[string]int
+}
+
+func (s *scriptedOllama) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	var status int
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	s.mu.Unlock()
+
+	if status != 0 && status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/embed":
+		var req ollamaBatchEmbeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp := ollamaBatchEmbeddingResponse{}
+		for range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float64{0.1, 0.2})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case "/api/embeddings":
+		_ = json.NewEncoder(w).Encode(ollamaEmbeddingResponse{Embedding: []float64{0.3, 0.4}})
+	}
+}
+
+func TestOllamaEmbedderRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantStatus   int
+		wantRequests map[string]int
+	}{
+		{name: "succeeds first time", wantRequests: map[string]int{"/api/embed": 1}},
+		{name: "503 twice then 200", statuses: []int{503, 503}, wantRequests: map[string]int{"/api/embed": 3}},
+		{name: "retries exhausted", statuses: []int{503, 502, 500}, wantErr: true, wantStatus: 500, wantRequests: map[string]int{"/api/embed": 3}},
+		{name: "4xx is not retried", statuses: []int{400}, wantErr: true, wantStatus: 400, wantRequests: map[string]int{"/api/embed": 1}},
+		{name: "404 falls back to the single-text endpoint", statuses: []int{404}, wantRequests: map[string]int{"/api/embed": 1, "/api/embeddings": 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ollama := &scriptedOllama{statuses: tt.statuses, requests: map[string]int{}}
+			srv := httptest.NewServer(ollama)
+			defer srv.Close()
+
+			e, err := NewOllamaEmbedder(&OllamaEmbedderConfig{
+				BaseURL: srv.URL,
+				Retry:   &RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond},
+			})
+			if err != nil {
+				t.Fatalf("NewOllamaEmbedder: %v", err)
+			}
+
+			vectors, err := e.EmbedStrings(context.Background(), []string{"完播率", "点赞率"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EmbedStrings err = %v, want error %v", err, tt.wantErr)
+			}
+			var statusErr *ollamaStatusError
+			if tt.wantErr && (!errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus) {
+				t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+			}
+			if !tt.wantErr && len(vectors) != 2 {
+				t.Errorf("got %d vectors, want 2", len(vectors))
+			}
+			for path, want := range tt.wantRequests {
+				if got := ollama.requests[path]; got != want {
+					t.Errorf("%s requests = %d, want %d", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOllamaEmbedderRetryStopsOnCancel(t *testing.T) {
+	ollama := &scriptedOllama{statuses: []int{503, 503, 503}, requests: map[string]int{}}
+	srv := httptest.NewServer(ollama)
+	defer srv.Close()
+
+	e, _ := NewOllamaEmbedder(&OllamaEmbedderConfig{
+		BaseURL: srv.URL,
+		Retry:   &RetryPolicy{MaxRetries: 5, InitialBackoff: time.Hour},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := e.EmbedStrings(ctx, []string{"完播率"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context deadline", err)
+	}
+	if got := ollama.requests["/api/embed"]; got != 1 {
+		t.Errorf("requests = %d, want 1 before the backoff was cut short", got)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 350 * time.Millisecond}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 350 * time.Millisecond},
+		{attempt: 10, want: 350 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+	if got := (RetryPolicy{InitialBackoff: time.Second}).backoff(4); got != 8*time.Second {
+		t.Errorf("uncapped backoff(4) = %v, want 8s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// Embedder 文本嵌入接口，Milvus 索引/检索、RAGManager 与长期记忆均可通过它接入
+// OpenAI、本地 ONNX 等任意嵌入模型，返回的向量与 texts 一一对应
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Embed 实现 Embedder 接口
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return e.EmbedStrings(ctx, texts)
+}
+
+// FromEinoEmbedder 将 eino 的 embedding.Embedder（如 eino-ext 的 OpenAI 嵌入器）适配为 Embedder
+func FromEinoEmbedder(e embedding.Embedder) Embedder {
+	if adapter, ok := e.(*einoEmbedder); ok {
+		return adapter.embedder
+	}
+	return einoSource{embedder: e}
+}
+
+// einoSource 以 eino 嵌入器实现 Embedder
+type einoSource struct {
+	embedder embedding.Embedder
+}
+
+func (s einoSource) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return s.embedder.EmbedStrings(ctx, texts)
+}
+
+// einoEmbedder 将 Embedder 适配为 eino 的 embedding.Embedder，供 Milvus 组件与 CachedEmbedder 使用
+type einoEmbedder struct {
+	embedder Embedder
+}
+
+// toEinoEmbedder 返回 Embedder 对应的 eino 嵌入器，本身已是 eino 嵌入器时直接返回
+func toEinoEmbedder(e Embedder) embedding.Embedder {
+	switch v := e.(type) {
+	case embedding.Embedder:
+		return v
+	case einoSource:
+		return v.embedder
+	}
+	return &einoEmbedder{embedder: e}
+}
+
+func (e *einoEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	vectors, err := e.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+// EmbedFunc 将 Embedder 转换为单条文本的嵌入函数，供 memory.NewLongTermMemory 等使用
+func EmbedFunc(e Embedder) func(ctx context.Context, text string) ([]float64, error) {
+	return func(ctx context.Context, text string) ([]float64, error) {
+		vectors, err := e.Embed(ctx, []string{text})
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) == 0 {
+			return nil, fmt.Errorf("embedder returned no vector")
+		}
+		return vectors[0], nil
+	}
+}
+
+// HashEmbedder 按词哈希生成的确定性向量，不依赖任何模型服务，
+// 未配置嵌入模型时 RAGManager 使用它，也可用于本地开发与测试
+type HashEmbedder struct {
+	Dim int
+}
+
+// NewHashEmbedder 创建哈希嵌入器，dim<=0 时使用 defaultEmbeddingDim
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		dim = defaultEmbeddingDim
+	}
+	return &HashEmbedder{Dim: dim}
+}
+
+// Embed 实现 Embedder 接口，相同文本总是得到相同向量
+func (h *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = h.embed(text)
+	}
+	return vectors, nil
+}
+
+func (h *HashEmbedder) embed(text string) []float64 {
+	embedding := make([]float64, h.Dim)
+
+	// 基于文本内容生成简单的特征向量
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		if i < h.Dim {
+			// 简单的哈希函数来生成嵌入值
+			hash := 0
+			for _, char := range word {
+				hash = (hash*31 + int(char)) % 100
+			}
+			embedding[i] = float64(hash) / 100.0
+		}
+	}
+
+	return embedding
+}
+
+// Ensure 各嵌入器实现了 Embedder 接口
+var (
+	_ Embedder           = (*OllamaEmbedder)(nil)
+	_ Embedder           = (*HashEmbedder)(nil)
+	_ embedding.Embedder = (*einoEmbedder)(nil)
+)
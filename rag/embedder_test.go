@@ -0,0 +1,131 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// fixedEmbedder 返回固定数量的向量，用于校验适配器对数量不符的处理
+type fixedEmbedder struct {
+	vectors [][]float64
+}
+
+func (e fixedEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return e.vectors, nil
+}
+
+func TestHashEmbedder(t *testing.T) {
+	tests := []struct {
+		name    string
+		dim     int
+		wantDim int
+	}{
+		{name: "explicit dimension", dim: 16, wantDim: 16},
+		{name: "default dimension", dim: 0, wantDim: defaultEmbeddingDim},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewHashEmbedder(tt.dim)
+			first, err := e.Embed(context.Background(), []string{"B站 视频 推荐", "完播率"})
+			if err != nil {
+				t.Fatalf("Embed: %v", err)
+			}
+			if len(first) != 2 || len(first[0]) != tt.wantDim {
+				t.Fatalf("got %d vectors of dim %d, want 2 of dim %d", len(first), len(first[0]), tt.wantDim)
+			}
+			again, _ := e.Embed(context.Background(), []string{"B站 视频 推荐"})
+			if !reflect.DeepEqual(first[0], again[0]) {
+				t.Error("same text embedded to different vectors")
+			}
+		})
+	}
+}
+
+func TestEinoEmbedderAdapters(t *testing.T) {
+	hash := NewHashEmbedder(8)
+	if got := FromEinoEmbedder(toEinoEmbedder(hash)); got != hash {
+		t.Errorf("round trip through eino = %T, want the original embedder", got)
+	}
+
+	tests := []struct {
+		name     string
+		embedder Embedder
+		wantErr  bool
+	}{
+		{name: "matching count", embedder: fixedEmbedder{vectors: [][]float64{{1}, {2}}}},
+		{name: "count mismatch", embedder: fixedEmbedder{vectors: [][]float64{{1}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e embedding.Embedder = toEinoEmbedder(tt.embedder)
+			_, err := e.EmbedStrings(context.Background(), []string{"a", "b"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EmbedStrings err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmbedFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		embedder Embedder
+		wantErr  bool
+	}{
+		{name: "first vector", embedder: fixedEmbedder{vectors: [][]float64{{0.5, 0.5}}}},
+		{name: "no vector", embedder: fixedEmbedder{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector, err := EmbedFunc(tt.embedder)(context.Background(), "完播率")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(vector, []float64{0.5, 0.5}) {
+				t.Errorf("vector = %v, want the first embedding", vector)
+			}
+		})
+	}
+}
+
+func TestRAGManagerSearchWithCustomEmbedder(t *testing.T) {
+	vocab := vocabEmbedder{"video", "danmaku", "creator"}
+	dir := t.TempDir()
+	rm, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&EmbeddingConfig{Embedder: vocab, Dimension: len(vocab), CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	for _, content := range []string{"video editing", "danmaku culture", "creator income"} {
+		if err := rm.AddDocument(content, nil); err != nil {
+			t.Fatalf("AddDocument: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "video", want: "video editing"},
+		{query: "danmaku", want: "danmaku culture"},
+		{query: "creator", want: "creator income"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := rm.SearchWithScores(tt.query, 1, 0)
+			if err != nil {
+				t.Fatalf("SearchWithScores: %v", err)
+			}
+			if len(results) != 1 || results[0].Content != tt.want {
+				t.Fatalf("results = %+v, want %q", results, tt.want)
+			}
+			if len(results[0].Embedding) != len(vocab) {
+				t.Errorf("stored embedding dim = %d, want %d from the custom embedder", len(results[0].Embedding), len(vocab))
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package rag
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// defaultEmbeddingCacheSize 默认缓存的文本向量条数
+const defaultEmbeddingCacheSize = 1000
+
+// EmbeddingCacheStats 嵌入缓存命中统计
+type EmbeddingCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+// CachedEmbedder 按文本缓存向量的 LRU 嵌入器，入库与检索共用一个实例时重复的查询不再调用模型
+type CachedEmbedder struct {
+	embedder embedding.Embedder
+	capacity int
+
+	mu     sync.Mutex
+	items  map[string]*list.Element
+	order  *list.List
+	hits   int64
+	misses int64
+}
+
+type embeddingCacheEntry struct {
+	text   string
+	vector []float64
+}
+
+// NewCachedEmbedder 包装嵌入器，capacity<=0 时使用 defaultEmbeddingCacheSize
+func NewCachedEmbedder(embedder embedding.Embedder, capacity int) *CachedEmbedder {
+	if capacity <= 0 {
+		capacity = defaultEmbeddingCacheSize
+	}
+	return &CachedEmbedder{
+		embedder: embedder,
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// EmbedStrings 实现 embedding.Embedder 接口，只对未命中的文本调用底层嵌入器
+func (c *CachedEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	results := make([][]float64, len(texts))
+	var missTexts []string
+	var missIndexes []int
+
+	c.mu.Lock()
+	for i, text := range texts {
+		if elem, ok := c.items[text]; ok {
+			c.order.MoveToFront(elem)
+			results[i] = copyVector(elem.Value.(*embeddingCacheEntry).vector)
+			c.hits++
+			continue
+		}
+		c.misses++
+		missTexts = append(missTexts, text)
+		missIndexes = append(missIndexes, i)
+	}
+	c.mu.Unlock()
+
+	if len(missTexts) == 0 {
+		return results, nil
+	}
+
+	vectors, err := c.embedder.EmbedStrings(ctx, missTexts, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for j, vector := range vectors {
+		if j >= len(missIndexes) {
+			break
+		}
+		results[missIndexes[j]] = vector
+		c.add(missTexts[j], copyVector(vector))
+	}
+	return results, nil
+}
+
+// add 写入缓存并淘汰最久未使用的条目，调用方需持有 c.mu
+func (c *CachedEmbedder) add(text string, vector []float64) {
+	if elem, ok := c.items[text]; ok {
+		elem.Value.(*embeddingCacheEntry).vector = vector
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[text] = c.order.PushFront(&embeddingCacheEntry{text: text, vector: vector})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*embeddingCacheEntry).text)
+	}
+}
+
+// Stats 返回命中统计
+func (c *CachedEmbedder) Stats() EmbeddingCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return EmbeddingCacheStats{Hits: c.hits, Misses: c.misses, Size: c.order.Len()}
+}
+
+// copyVector 缓存与调用方各持一份，避免调用方修改向量污染缓存
+func copyVector(v []float64) []float64 {
+	cp := make([]float64, len(v))
+	copy(cp, v)
+	return cp
+}
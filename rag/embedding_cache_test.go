@@ -0,0 +1,95 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddingCacheRepeatedQuery(t *testing.T) {
+	dir := t.TempDir()
+	embedder := newCountingEmbedder(32)
+	rm, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&EmbeddingConfig{Embedder: embedder, Dimension: 32, CacheSize: 10})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	if err := rm.AddDocument("B站视频推荐算法更看重完播率", nil); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	indexed := embedder.Calls()
+
+	for i := 0; i < 2; i++ {
+		if _, err := rm.SearchSimilarDocuments("完播率怎么提升", 1); err != nil {
+			t.Fatalf("search %d: %v", i+1, err)
+		}
+	}
+	if got := embedder.Calls() - indexed; got != 1 {
+		t.Errorf("embedder called %d times for the repeated query, want 1", got)
+	}
+
+	// 入库与检索共用缓存：按文档原文检索不再调用嵌入器
+	if _, err := rm.SearchSimilarDocuments("B站视频推荐算法更看重完播率", 1); err != nil {
+		t.Fatalf("search by document text: %v", err)
+	}
+	if got := embedder.Calls() - indexed; got != 1 {
+		t.Errorf("embedder called %d times after searching the indexed text, want the cached vector reused", got)
+	}
+
+	stats, ok := rm.EmbeddingCacheStats()
+	if !ok {
+		t.Fatal("embedding cache not enabled")
+	}
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Size != 2 {
+		t.Errorf("stats = %+v, want 2 hits, 2 misses, 2 entries", stats)
+	}
+}
+
+func TestCachedEmbedderEvictsLeastRecentlyUsed(t *testing.T) {
+	embedder := newCountingEmbedder(8)
+	cache := NewCachedEmbedder(toEinoEmbedder(embedder), 2)
+	ctx := context.Background()
+
+	tests := []struct {
+		text      string
+		wantCalls int
+	}{
+		{text: "a", wantCalls: 1},
+		{text: "b", wantCalls: 2},
+		{text: "a", wantCalls: 2}, // 命中，a 变为最近使用
+		{text: "c", wantCalls: 3}, // 淘汰 b
+		{text: "a", wantCalls: 3},
+		{text: "b", wantCalls: 4},
+	}
+	for _, tt := range tests {
+		if _, err := cache.EmbedStrings(ctx, []string{tt.text}); err != nil {
+			t.Fatalf("EmbedStrings(%q): %v", tt.text, err)
+		}
+		if got := embedder.Calls(); got != tt.wantCalls {
+			t.Errorf("after %q embedder calls = %d, want %d", tt.text, got, tt.wantCalls)
+		}
+	}
+	if size := cache.Stats().Size; size != 2 {
+		t.Errorf("cache size = %d, want capacity 2", size)
+	}
+}
+
+func TestCachedEmbedderReturnsCopies(t *testing.T) {
+	cache := NewCachedEmbedder(toEinoEmbedder(NewHashEmbedder(4)), 10)
+	ctx := context.Background()
+
+	first, err := cache.EmbedStrings(ctx, []string{"完播率 点赞"})
+	if err != nil {
+		t.Fatalf("EmbedStrings: %v", err)
+	}
+	want := first[0][0]
+	first[0][0] = 99
+
+	second, err := cache.EmbedStrings(ctx, []string{"完播率 点赞"})
+	if err != nil {
+		t.Fatalf("EmbedStrings: %v", err)
+	}
+	if second[0][0] != want {
+		t.Errorf("cached vector = %v, caller mutation leaked into the cache", second[0])
+	}
+}
@@ -0,0 +1,93 @@
+package rag
+
+import (
+	"log"
+	"maps"
+	"time"
+)
+
+// MetadataExpiresAt 文档过期时间的元数据键，值为 RFC3339 时间字符串或 Unix 秒，过期文档不参与检索
+const MetadataExpiresAt = "expires_at"
+
+// DefaultExpirySweepInterval 定期清理过期文档的默认间隔
+const DefaultExpirySweepInterval = 10 * time.Minute
+
+// ExpiresAt 返回文档的过期时间，未设置或无法解析时 ok 为 false（永不过期）
+func (d *Document) ExpiresAt() (expiresAt time.Time, ok bool) {
+	switch v := d.Metadata[MetadataExpiresAt].(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case time.Time:
+		return v, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Expired 文档在 now 时是否已过期
+func (d *Document) Expired(now time.Time) bool {
+	expiresAt, ok := d.ExpiresAt()
+	return ok && !now.Before(expiresAt)
+}
+
+// AddDocumentWithTTL 添加在 ttl 后过期的文档，适用于会随数据变化而过时的分析结论（如视频总结）；
+// ttl<=0 时等同于 AddDocument
+func (rm *RAGManager) AddDocumentWithTTL(content string, metadata map[string]interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return rm.AddDocument(content, metadata)
+	}
+	withExpiry := make(map[string]interface{}, len(metadata)+1)
+	maps.Copy(withExpiry, metadata)
+	withExpiry[MetadataExpiresAt] = time.Now().Add(ttl).UTC().Format(time.RFC3339Nano)
+	return rm.AddDocument(content, withExpiry)
+}
+
+// SweepExpired 删除已过期的文档并回写存储，返回删除数量
+func (rm *RAGManager) SweepExpired() (int, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for id, doc := range rm.documents {
+		if doc.Expired(now) {
+			delete(rm.documents, id)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, rm.saveDocuments()
+}
+
+// StartExpirySweep 每隔 interval 清理一次过期文档，返回停止函数；检索本身已排除过期文档，
+// 定期清理只用于回收存储
+func (rm *RAGManager) StartExpirySweep(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			removed, err := rm.SweepExpired()
+			if err != nil {
+				log.Printf("[RAG] sweep expired documents failed: %v", err)
+			} else if removed > 0 {
+				log.Printf("[RAG] swept %d expired documents", removed)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
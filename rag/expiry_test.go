@@ -0,0 +1,94 @@
+package rag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDocumentExpiresAt(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 600_000_000, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "未设置", value: nil, wantOK: false},
+		{name: "RFC3339", value: "2026-01-02T03:04:05Z", want: at.Truncate(time.Second), wantOK: true},
+		{name: "RFC3339Nano", value: at.Format(time.RFC3339Nano), want: at, wantOK: true},
+		{name: "int", value: int(at.Unix()), want: at.Truncate(time.Second), wantOK: true},
+		{name: "int64", value: at.Unix(), want: at.Truncate(time.Second), wantOK: true},
+		{name: "float64", value: float64(at.Unix()), want: at.Truncate(time.Second), wantOK: true},
+		{name: "time.Time", value: at, want: at, wantOK: true},
+		{name: "无法解析", value: "tomorrow", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{Metadata: map[string]interface{}{}}
+			if tt.value != nil {
+				doc.Metadata[MetadataExpiresAt] = tt.value
+			}
+			got, ok := doc.ExpiresAt()
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Fatalf("ExpiresAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepExpired(t *testing.T) {
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		wait        time.Duration
+		wantRemoved int
+	}{
+		{name: "未过期", ttl: time.Hour, wantRemoved: 0},
+		{name: "亚秒级 TTL 到期", ttl: 20 * time.Millisecond, wait: 50 * time.Millisecond, wantRemoved: 1},
+		{name: "无 TTL", ttl: 0, wantRemoved: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestManager(t)
+			if err := rm.AddDocumentWithTTL("video summary", nil, tt.ttl); err != nil {
+				t.Fatalf("AddDocumentWithTTL: %v", err)
+			}
+			time.Sleep(tt.wait)
+
+			removed, err := rm.SweepExpired()
+			if err != nil {
+				t.Fatalf("SweepExpired: %v", err)
+			}
+			if removed != tt.wantRemoved {
+				t.Fatalf("removed = %d, want %d", removed, tt.wantRemoved)
+			}
+			if got := len(rm.GetAllDocuments()); got != 1-tt.wantRemoved {
+				t.Fatalf("documents = %d, want %d", got, 1-tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestStartExpirySweep(t *testing.T) {
+	rm := newTestManager(t)
+	if err := rm.AddDocumentWithTTL("video summary", nil, 10*time.Millisecond); err != nil {
+		t.Fatalf("AddDocumentWithTTL: %v", err)
+	}
+
+	stop := rm.StartExpirySweep(5 * time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rm.GetAllDocuments()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired document was not swept")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,48 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newTestManager 使用临时目录与 HashEmbedder 创建 RAGManager，不依赖外部嵌入服务
+func newTestManager(t testing.TB) *RAGManager {
+	return newTestManagerAt(t, t.TempDir(), NewHashEmbedder(32))
+}
+
+// newTestManagerAt 在 dir 下使用 embedder 创建 RAGManager，同一 dir 再次创建时加载已持久化的文档
+func newTestManagerAt(t testing.TB, dir string, embedder Embedder) *RAGManager {
+	t.Helper()
+	rm, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&EmbeddingConfig{Embedder: embedder, Dimension: 32, CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	return rm
+}
+
+// countingEmbedder 以 HashEmbedder 生成向量并记录 Embed 调用次数
+type countingEmbedder struct {
+	mu    sync.Mutex
+	calls int
+	hash  *HashEmbedder
+}
+
+func newCountingEmbedder(dim int) *countingEmbedder {
+	return &countingEmbedder{hash: NewHashEmbedder(dim)}
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.mu.Lock()
+	e.calls++
+	e.mu.Unlock()
+	return e.hash.Embed(ctx, texts)
+}
+
+func (e *countingEmbedder) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
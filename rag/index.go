@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/cloudwego/eino-ext/components/indexer/milvus"
+	"github.com/cloudwego/eino/components/indexer"
 	"github.com/cloudwego/eino/schema"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 )
@@ -42,14 +43,64 @@ var fields = []*entity.Field{
 	},
 }
 
+// DefaultIndexBatchSize 索引时每批嵌入与写入 Milvus 的文档数
+const DefaultIndexBatchSize = 16
+
+// IndexConfig 索引配置，nil 或零值字段使用默认值
+type IndexConfig struct {
+	// BatchSize 每批嵌入与写入的文档数，<=0 时使用 DefaultIndexBatchSize
+	BatchSize int
+	// Embedder 嵌入器，为空时使用本地 Ollama 的 qwen3-embedding:0.6b
+	Embedder Embedder
+}
+
+// IndexFailure 写入失败的一批文档
+type IndexFailure struct {
+	DocIDs []string
+	Err    error
+}
+
+// IndexResult 索引结果，单批失败不影响其他批次
+type IndexResult struct {
+	Indexed int
+	Failed  []IndexFailure
+}
+
+// FailedIDs 返回所有写入失败的文档ID
+func (r *IndexResult) FailedIDs() []string {
+	var ids []string
+	for _, f := range r.Failed {
+		ids = append(ids, f.DocIDs...)
+	}
+	return ids
+}
+
 // IndexerRAG 索引文档（支持语义分块）
 func IndexerRAG(docs []*schema.Document) {
-	IndexerRAGWithChunking(docs, nil)
+	IndexerRAGWithChunking(docs, nil, nil)
+}
+
+// IndexerRAGWithChunking 索引文档（带分块），按 indexConfig.BatchSize 分批写入，indexConfig 为 nil 时使用默认配置
+func IndexerRAGWithChunking(docs []*schema.Document, chunkConfig *ChunkConfig, indexConfig *IndexConfig) {
+	result, err := IndexDocuments(context.Background(), docs, chunkConfig, indexConfig)
+	if err != nil {
+		log.Fatalf("Failed to create indexer: %v", err)
+	}
+	for _, f := range result.Failed {
+		log.Printf("存储文档失败 %v: %v", f.DocIDs, f.Err)
+	}
 }
 
-// IndexerRAGWithChunking 索引文档（带分块）
-func IndexerRAGWithChunking(docs []*schema.Document, chunkConfig *ChunkConfig) {
-	ctx := context.Background()
+// IndexDocuments 分块后按 BatchSize 分批嵌入并写入 Milvus，N 个片段只需 ceil(N/BatchSize) 次往返。
+// 某批失败时记录该批文档ID并继续后续批次，只有嵌入器或索引器创建失败时返回错误
+func IndexDocuments(ctx context.Context, docs []*schema.Document, chunkConfig *ChunkConfig, config *IndexConfig) (*IndexResult, error) {
+	if config == nil {
+		config = &IndexConfig{}
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultIndexBatchSize
+	}
 
 	// 如果配置了分块，先进行分块
 	var docsToIndex []*schema.Document
@@ -71,45 +122,59 @@ func IndexerRAGWithChunking(docs []*schema.Document, chunkConfig *ChunkConfig) {
 		docsToIndex = docs
 	}
 
-	// 初始化自定义嵌入器（确保返回 Float64 向量）
-	embedder, err := NewOllamaEmbedder(&OllamaEmbedderConfig{
-		BaseURL: "http://localhost:11434",
-		Model:   "qwen3-embedding:0.6b",
-		Timeout: 10 * time.Second,
-	})
-	if err != nil {
-		panic(err)
+	embedder := config.Embedder
+	if embedder == nil {
+		// 初始化自定义嵌入器（确保返回 Float64 向量）
+		ollamaEmbedder, err := NewOllamaEmbedder(&OllamaEmbedderConfig{
+			BaseURL: "http://localhost:11434",
+			Model:   "qwen3-embedding:0.6b",
+			Timeout: 10 * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create embedder: %w", err)
+		}
+		embedder = ollamaEmbedder
 	}
 
-	indexer, err := milvus.NewIndexer(ctx, &milvus.IndexerConfig{
+	milvusIndexer, err := milvus.NewIndexer(ctx, &milvus.IndexerConfig{
 		Client:            MilvusCli,
 		Collection:        collection,
 		Fields:            fields,
-		Embedding:         embedder,
+		Embedding:         toEinoEmbedder(embedder),
 		DocumentConverter: floatDocumentConverter,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create indexer: %v", err)
+		return nil, fmt.Errorf("create indexer: %w", err)
 	}
 
-	// 批量存储
-	for _, doc := range docsToIndex {
-		storeDoc := []*schema.Document{
-			{
+	result := storeInBatches(ctx, milvusIndexer, docsToIndex, batchSize)
+	log.Printf("共索引 %d 个文档片段，失败 %d 个", result.Indexed, len(result.FailedIDs()))
+	return result, nil
+}
+
+// storeInBatches 每 batchSize 个文档调用一次 Store（即一次嵌入与写入），某批失败时记录其文档ID并继续
+func storeInBatches(ctx context.Context, idx indexer.Indexer, docs []*schema.Document, batchSize int) *IndexResult {
+	result := &IndexResult{}
+	for start := 0; start < len(docs); start += batchSize {
+		end := min(start+batchSize, len(docs))
+		batch := make([]*schema.Document, 0, end-start)
+		ids := make([]string, 0, end-start)
+		for _, doc := range docs[start:end] {
+			batch = append(batch, &schema.Document{
 				ID:       doc.ID,
 				Content:  doc.Content,
 				MetaData: doc.MetaData,
-			},
+			})
+			ids = append(ids, doc.ID)
 		}
-		fmt.Println("开始存储")
-		fmt.Println(doc.ID)
-		_, err := indexer.Store(ctx, storeDoc)
-		if err != nil {
-			log.Fatalf("Failed to store documents: %v", err)
+
+		if _, err := idx.Store(ctx, batch); err != nil {
+			result.Failed = append(result.Failed, IndexFailure{DocIDs: ids, Err: err})
+			continue
 		}
+		result.Indexed += len(batch)
 	}
-
-	log.Printf("共索引 %d 个文档片段", len(docsToIndex))
+	return result
 }
 
 func binaryDocumentConverter(ctx context.Context, docs []*schema.Document, vectors [][]float64) ([]interface{}, error) {
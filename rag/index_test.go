@@ -0,0 +1,77 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fakeIndexer 像 Milvus 索引器一样每次 Store 嵌入整批文档，批内含 failID 时整批失败
+type fakeIndexer struct {
+	embedder Embedder
+	failID   string
+}
+
+func (f *fakeIndexer) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) ([]string, error) {
+	texts := make([]string, 0, len(docs))
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if doc.ID == f.failID {
+			return nil, errors.New("milvus insert failed")
+		}
+		texts = append(texts, doc.Content)
+		ids = append(ids, doc.ID)
+	}
+	if _, err := f.embedder.Embed(ctx, texts); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func testDocs(n int) []*schema.Document {
+	docs := make([]*schema.Document, n)
+	for i := range docs {
+		docs[i] = &schema.Document{ID: fmt.Sprintf("doc-%d", i), Content: fmt.Sprintf("视频 %d 的数据分析", i)}
+	}
+	return docs
+}
+
+func TestStoreInBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		docs        int
+		batchSize   int
+		failID      string
+		wantCalls   int
+		wantIndexed int
+		wantFailed  []string
+	}{
+		{name: "empty input", docs: 0, batchSize: 4, wantCalls: 0},
+		{name: "exact batches", docs: 8, batchSize: 4, wantCalls: 2, wantIndexed: 8},
+		{name: "partial last batch", docs: 10, batchSize: 4, wantCalls: 3, wantIndexed: 10},
+		{name: "single batch", docs: 5, batchSize: DefaultIndexBatchSize, wantCalls: 1, wantIndexed: 5},
+		{name: "failed batch is reported", docs: 10, batchSize: 4, failID: "doc-5", wantCalls: 2, wantIndexed: 6,
+			wantFailed: []string{"doc-4", "doc-5", "doc-6", "doc-7"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedder := newCountingEmbedder(8)
+			result := storeInBatches(context.Background(), &fakeIndexer{embedder: embedder, failID: tt.failID}, testDocs(tt.docs), tt.batchSize)
+
+			if got := embedder.Calls(); got != tt.wantCalls {
+				t.Errorf("embed calls = %d, want %d", got, tt.wantCalls)
+			}
+			if result.Indexed != tt.wantIndexed {
+				t.Errorf("indexed = %d, want %d", result.Indexed, tt.wantIndexed)
+			}
+			if got := result.FailedIDs(); !slices.Equal(got, tt.wantFailed) {
+				t.Errorf("failed ids = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
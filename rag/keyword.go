@@ -0,0 +1,106 @@
+package rag
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RetrievalMode 检索结果的来源
+type RetrievalMode string
+
+const (
+	// RetrievalVector 向量相似度检索
+	RetrievalVector RetrievalMode = "vector"
+	// RetrievalKeyword 向量检索效果不佳时回退的关键词匹配
+	RetrievalKeyword RetrievalMode = "keyword"
+)
+
+// SetKeywordFallback 设置关键词回退阈值：向量检索的最高分低于 minVectorScore 时改用关键词匹配，
+// 有匹配结果时返回关键词结果（Mode 为 RetrievalKeyword），否则仍返回向量结果；<=0 关闭回退
+func (rm *RAGManager) SetKeywordFallback(minVectorScore float64) {
+	rm.keywordFallbackScore = minVectorScore
+}
+
+// keywordSearch 按查询词在文档中的命中比例打分，完整包含查询时记为 1，返回集合中命中的前 topK 个未过期文档；
+// 调用方需持有 rm.mu
+func (rm *RAGManager) keywordSearch(collection, query string, topK int) []*ScoredDocument {
+	normalized := strings.ToLower(strings.TrimSpace(query))
+	terms := keywordTerms(normalized)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var results []*ScoredDocument
+	for _, doc := range rm.documents {
+		if doc.Expired(now) || doc.CollectionName() != collection {
+			continue
+		}
+		content := strings.ToLower(doc.Content)
+		score := 1.0
+		if !strings.Contains(content, normalized) {
+			hits := 0
+			for _, term := range terms {
+				if strings.Contains(content, term) {
+					hits++
+				}
+			}
+			score = float64(hits) / float64(len(terms))
+		}
+		if score > 0 {
+			results = append(results, &ScoredDocument{Document: doc, Score: score, Mode: RetrievalKeyword})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results[:max(0, min(topK, len(results)))]
+}
+
+// keywordTerms 切分查询词：非中文按空白与标点分词，中文按相邻两字切分，单个汉字单独成词
+func keywordTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		add(string(word))
+		word = word[:0]
+	}
+	flushHan := func() {
+		if len(han) == 1 {
+			add(string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			add(string(han[i : i+2]))
+		}
+		han = han[:0]
+	}
+
+	for _, r := range query {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return terms
+}
@@ -0,0 +1,92 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// vocabEmbedder 按固定词表计数生成向量，词表外的查询与所有文档的相似度为 0
+type vocabEmbedder []string
+
+func (v vocabEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(v))
+		for _, word := range strings.Fields(text) {
+			for j, term := range v {
+				if word == term {
+					vectors[i][j]++
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestKeywordFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallback  float64
+		query     string
+		wantMode  RetrievalMode
+		wantFirst string
+	}{
+		{name: "weak vector match falls back to keywords", fallback: 0.5, query: "完播率", wantMode: RetrievalKeyword, wantFirst: "完播率 决定 推荐量"},
+		{name: "strong vector match keeps vector results", fallback: 0.5, query: "video", wantMode: RetrievalVector, wantFirst: "video danmaku tips"},
+		{name: "fallback disabled", query: "完播率", wantMode: RetrievalVector},
+		{name: "no keyword hit keeps vector results", fallback: 0.5, query: "直播带货", wantMode: RetrievalVector},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocab := vocabEmbedder{"video", "danmaku"}
+			dir := t.TempDir()
+			rm, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+				&EmbeddingConfig{Embedder: vocab, Dimension: len(vocab), CacheSize: -1})
+			if err != nil {
+				t.Fatalf("NewRAGManagerWithConfig: %v", err)
+			}
+			for _, content := range []string{"video danmaku tips", "完播率 决定 推荐量"} {
+				if err := rm.AddDocument(content, nil); err != nil {
+					t.Fatalf("AddDocument: %v", err)
+				}
+			}
+			rm.SetKeywordFallback(tt.fallback)
+
+			results, err := rm.SearchWithScores(tt.query, 2, 0)
+			if err != nil {
+				t.Fatalf("SearchWithScores: %v", err)
+			}
+			if len(results) == 0 {
+				t.Fatal("no results")
+			}
+			for _, r := range results {
+				if r.Mode != tt.wantMode {
+					t.Errorf("result %q mode = %s, want %s", r.Content, r.Mode, tt.wantMode)
+				}
+			}
+			if tt.wantFirst != "" && results[0].Content != tt.wantFirst {
+				t.Errorf("first result = %q, want %q", results[0].Content, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestKeywordTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "完播率", want: []string{"完播", "播率"}},
+		{query: "b站 tips", want: []string{"b", "站", "tips"}},
+		{query: "video, video!", want: []string{"video"}},
+		{query: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		if got := keywordTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keywordTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
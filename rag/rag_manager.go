@@ -1,14 +1,20 @@
 package rag
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"sync"
 	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
 )
 
 type Document struct {
@@ -17,21 +23,90 @@ type Document struct {
 	Metadata  map[string]interface{} `json:"metadata"`
 	Embedding []float64              `json:"embedding"`
 	CreatedAt time.Time              `json:"created_at"`
+	// Collection 文档所属集合，为空表示 DefaultCollection（兼容集合引入之前存储的文档）
+	Collection string `json:"collection,omitempty"`
+}
+
+// ErrEmbeddingDimMismatch 已存储文档的向量维度与配置的维度不一致
+var ErrEmbeddingDimMismatch = errors.New("embedding dimension mismatch")
+
+// defaultEmbeddingDim 内置哈希嵌入的默认维度
+const defaultEmbeddingDim = 100
+
+// EmbeddingConfig RAGManager 嵌入配置
+type EmbeddingConfig struct {
+	// Embedder 自定义嵌入器，设置后忽略 Model/BaseURL
+	Embedder Embedder
+	// Model Ollama 嵌入模型名称，与 Embedder 均为空时使用内置的 HashEmbedder
+	Model   string
+	BaseURL string
+	// Dimension 向量维度，必须与模型实际输出一致（如 nomic-embed-text 为 768）
+	Dimension int
+	// ReembedOnMismatch 加载时发现维度不一致的文档是否重新嵌入，为 false 时直接报错
+	ReembedOnMismatch bool
+	// CacheSize 嵌入缓存条数，0 使用默认值，<0 关闭缓存
+	CacheSize int
 }
 
 type RAGManager struct {
+	// mu 保护 documents，过期清理可能在后台执行
+	mu           sync.RWMutex
 	documents    map[string]*Document
 	vectorStore  string
 	ragStore     string
 	embeddingDim int
+	embedder     embedding.Embedder
+	// embeddingCache 嵌入缓存，未配置嵌入模型或关闭缓存时为 nil
+	embeddingCache *CachedEmbedder
+
+	// contentHashIDs 为 true 时按 namespace + 内容的 SHA-256 生成文档ID，相同内容重复添加时原地更新
+	contentHashIDs bool
+	idNamespace    string
+
+	// keywordFallbackScore 向量检索最高分低于该值时回退到关键词匹配，<=0 关闭
+	keywordFallbackScore float64
 }
 
 func NewRAGManager(vectorStorePath, ragStorePath string) (*RAGManager, error) {
+	return NewRAGManagerWithConfig(vectorStorePath, ragStorePath, nil)
+}
+
+// NewRAGManagerWithConfig 按嵌入配置创建 RAGManager，加载时校验已存储文档的向量维度
+func NewRAGManagerWithConfig(vectorStorePath, ragStorePath string, config *EmbeddingConfig) (*RAGManager, error) {
+	if config == nil {
+		config = &EmbeddingConfig{}
+	}
+
 	rm := &RAGManager{
 		documents:    make(map[string]*Document),
 		vectorStore:  vectorStorePath,
 		ragStore:     ragStorePath,
-		embeddingDim: 100, // 默认维度
+		embeddingDim: config.Dimension,
+	}
+
+	embedder := config.Embedder
+	if embedder == nil && config.Model != "" {
+		ollamaEmbedder, err := NewOllamaEmbedder(&OllamaEmbedderConfig{
+			BaseURL: config.BaseURL,
+			Model:   config.Model,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embedder: %w", err)
+		}
+		embedder = ollamaEmbedder
+	}
+
+	if embedder != nil {
+		if config.Dimension <= 0 {
+			return nil, fmt.Errorf("embedding dimension is required for custom embedder or model %s", config.Model)
+		}
+		rm.embedder = toEinoEmbedder(embedder)
+		if config.CacheSize >= 0 {
+			rm.embeddingCache = NewCachedEmbedder(rm.embedder, config.CacheSize)
+			rm.embedder = rm.embeddingCache
+		}
+	} else if rm.embeddingDim <= 0 {
+		rm.embeddingDim = defaultEmbeddingDim
 	}
 
 	// 加载现有文档
@@ -39,9 +114,71 @@ func NewRAGManager(vectorStorePath, ragStorePath string) (*RAGManager, error) {
 		return nil, fmt.Errorf("failed to load documents: %w", err)
 	}
 
+	if err := rm.validateEmbeddings(config.ReembedOnMismatch); err != nil {
+		return nil, err
+	}
+
+	if _, err := rm.SweepExpired(); err != nil {
+		return nil, fmt.Errorf("failed to sweep expired documents: %w", err)
+	}
+
 	return rm, nil
 }
 
+// validateEmbeddings 校验文档向量维度，允许时对不一致的文档重新嵌入并回写存储
+func (rm *RAGManager) validateEmbeddings(reembed bool) error {
+	reembedded := 0
+	for id, doc := range rm.documents {
+		if len(doc.Embedding) == rm.embeddingDim {
+			continue
+		}
+		if !reembed {
+			return fmt.Errorf("%w: document %s has %d dims, expected %d",
+				ErrEmbeddingDimMismatch, id, len(doc.Embedding), rm.embeddingDim)
+		}
+
+		embedding, err := rm.embed(doc.Content)
+		if err != nil {
+			return fmt.Errorf("failed to re-embed document %s: %w", id, err)
+		}
+		doc.Embedding = embedding
+		reembedded++
+	}
+
+	if reembedded > 0 {
+		return rm.saveDocuments()
+	}
+	return nil
+}
+
+// EmbeddingCacheStats 返回嵌入缓存命中统计，未启用缓存时 ok 为 false
+func (rm *RAGManager) EmbeddingCacheStats() (stats EmbeddingCacheStats, ok bool) {
+	if rm.embeddingCache == nil {
+		return EmbeddingCacheStats{}, false
+	}
+	return rm.embeddingCache.Stats(), true
+}
+
+// embed 生成文本向量：配置了嵌入器时调用嵌入器，否则使用内置 HashEmbedder
+func (rm *RAGManager) embed(text string) ([]float64, error) {
+	if rm.embedder == nil {
+		return (&HashEmbedder{Dim: rm.embeddingDim}).embed(text), nil
+	}
+
+	embeddings, err := rm.embedder.EmbedStrings(context.Background(), []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 || len(embeddings[0]) != rm.embeddingDim {
+		got := 0
+		if len(embeddings) > 0 {
+			got = len(embeddings[0])
+		}
+		return nil, fmt.Errorf("%w: model returned %d dims, expected %d", ErrEmbeddingDimMismatch, got, rm.embeddingDim)
+	}
+	return embeddings[0], nil
+}
+
 func (rm *RAGManager) loadDocuments() error {
 	// 加载向量存储
 	if err := rm.loadFromStore(rm.vectorStore); err != nil {
@@ -98,25 +235,70 @@ func (rm *RAGManager) saveToStore(storePath string) error {
 		return fmt.Errorf("failed to marshal documents: %w", err)
 	}
 
-	if err := os.WriteFile(storePath, data, 0644); err != nil {
+	// 先写临时文件再重命名，写入中断时保留上一次完整的存储
+	tmp := storePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
 		return fmt.Errorf("failed to write store file: %w", err)
 	}
+	if err := os.Rename(tmp, storePath); err != nil {
+		return fmt.Errorf("failed to replace store file: %w", err)
+	}
 
 	return nil
 }
 
+// SetContentHashIDs 开启后文档ID由 namespace 与内容的哈希确定，默认关闭（使用时间戳ID，兼容已有数据）
+func (rm *RAGManager) SetContentHashIDs(enabled bool, namespace string) {
+	rm.contentHashIDs = enabled
+	rm.idNamespace = namespace
+}
+
+// documentID 生成文档ID
+func (rm *RAGManager) documentID(collection, content string) string {
+	if !rm.contentHashIDs {
+		return fmt.Sprintf("doc-%d", time.Now().UnixNano())
+	}
+	return rm.contentHashID(collection, content)
+}
+
+// contentHashID 按 namespace 与内容的 SHA-256 生成文档ID，非默认集合的集合名参与哈希，
+// 相同内容写入不同集合时不会互相覆盖
+func (rm *RAGManager) contentHashID(collection, content string) string {
+	key := rm.idNamespace + "\x00" + content
+	if collection != DefaultCollection {
+		key = collection + "\x00" + key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "doc-" + hex.EncodeToString(sum[:])
+}
+
+// AddDocument 向默认集合添加文档
 func (rm *RAGManager) AddDocument(content string, metadata map[string]interface{}) error {
-	docID := fmt.Sprintf("doc-%d", time.Now().UnixNano())
+	return rm.AddDocumentToCollection(DefaultCollection, content, metadata)
+}
+
+// AddDocumentToCollection 向指定集合添加文档，collection 为空时使用 DefaultCollection
+func (rm *RAGManager) AddDocumentToCollection(collection, content string, metadata map[string]interface{}) error {
+	collection = normalizeCollection(collection)
+	docID := rm.documentID(collection, content)
 
-	// 生成简单的嵌入向量（实际项目中应该使用真实的嵌入模型）
-	embedding := rm.generateSimpleEmbedding(content)
+	embedding, err := rm.embed(content)
+	if err != nil {
+		return fmt.Errorf("failed to embed document: %w", err)
+	}
 
 	doc := &Document{
-		ID:        docID,
-		Content:   content,
-		Metadata:  metadata,
-		Embedding: embedding,
-		CreatedAt: time.Now(),
+		ID:         docID,
+		Content:    content,
+		Metadata:   metadata,
+		Embedding:  embedding,
+		CreatedAt:  time.Now(),
+		Collection: storedCollection(collection),
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if existing, ok := rm.documents[docID]; ok {
+		doc.CreatedAt = existing.CreatedAt
 	}
 
 	rm.documents[docID] = doc
@@ -124,63 +306,117 @@ func (rm *RAGManager) AddDocument(content string, metadata map[string]interface{
 	return rm.saveDocuments()
 }
 
-func (rm *RAGManager) generateSimpleEmbedding(text string) []float64 {
-	// 这是一个简化的嵌入生成函数
-	// 实际项目中应该使用真实的嵌入模型，如OpenAI、Ollama等
-	embedding := make([]float64, rm.embeddingDim)
-
-	// 基于文本内容生成简单的特征向量
-	words := strings.Fields(strings.ToLower(text))
-	for i, word := range words {
-		if i < rm.embeddingDim {
-			// 简单的哈希函数来生成嵌入值
-			hash := 0
-			for _, char := range word {
-				hash = (hash*31 + int(char)) % 100
-			}
-			embedding[i] = float64(hash) / 100.0
-		}
+// Upsert 按 ID 写入文档：已存在时替换内容、元数据与向量并保留 ID、创建时间与所属集合，不存在时新建到默认集合；
+// id 为空时使用 namespace 与内容的哈希作为 ID（与 SetContentHashIDs 的规则一致），返回是否为新建
+func (rm *RAGManager) Upsert(id, content string, metadata map[string]interface{}) (doc *Document, created bool, err error) {
+	if id == "" {
+		id = rm.contentHashID(DefaultCollection, content)
+	}
+
+	embedding, err := rm.embed(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to embed document: %w", err)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	doc = &Document{
+		ID:        id,
+		Content:   content,
+		Metadata:  metadata,
+		Embedding: embedding,
+		CreatedAt: time.Now(),
 	}
+	existing, exists := rm.documents[id]
+	if exists {
+		doc.CreatedAt = existing.CreatedAt
+		doc.Collection = existing.Collection
+	}
+	rm.documents[id] = doc
 
-	return embedding
+	if err := rm.saveDocuments(); err != nil {
+		return nil, false, err
+	}
+	return doc, !exists, nil
 }
 
 func (rm *RAGManager) SearchSimilarDocuments(query string, topK int) ([]*Document, error) {
-	if len(rm.documents) == 0 {
-		return []*Document{}, nil
+	scored, err := rm.SearchWithScores(query, topK, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	// 生成查询嵌入
-	queryEmbedding := rm.generateSimpleEmbedding(query)
+	results := make([]*Document, len(scored))
+	for i, sd := range scored {
+		results[i] = sd.Document
+	}
+	return results, nil
+}
+
+// ScoredDocument 带相似度分数的检索结果
+type ScoredDocument struct {
+	*Document
+	Score float64
+	// Mode 检索方式，关键词回退时 Score 为查询词命中比例
+	Mode RetrievalMode
+}
 
-	// 计算相似度并排序
-	type docScore struct {
-		doc   *Document
-		score float64
+// SearchWithScores 在默认集合中检索，见 SearchCollection
+func (rm *RAGManager) SearchWithScores(query string, topK int, minScore float64) ([]*ScoredDocument, error) {
+	return rm.SearchCollection(DefaultCollection, query, topK, minScore)
+}
+
+// SearchCollection 返回集合中相似度不低于 minScore 的前 topK 个文档，按分数降序，已过期的文档不参与检索；
+// 开启关键词回退且向量最高分低于回退阈值时，优先返回关键词匹配结果。collection 为空时使用 DefaultCollection
+func (rm *RAGManager) SearchCollection(collection, query string, topK int, minScore float64) ([]*ScoredDocument, error) {
+	collection = normalizeCollection(collection)
+
+	rm.mu.RLock()
+	empty := len(rm.documents) == 0
+	rm.mu.RUnlock()
+	if empty {
+		return []*ScoredDocument{}, nil
+	}
+
+	// 生成查询嵌入
+	queryEmbedding, err := rm.embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
 	}
 
-	var scores []docScore
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	// 计算相似度并过滤低分文档
+	now := time.Now()
+	var scores []*ScoredDocument
+	bestScore := 0.0
 	for _, doc := range rm.documents {
+		if doc.Expired(now) || doc.CollectionName() != collection {
+			continue
+		}
 		score := rm.cosineSimilarity(queryEmbedding, doc.Embedding)
-		scores = append(scores, docScore{doc: doc, score: score})
+		bestScore = math.Max(bestScore, score)
+		if score < minScore {
+			continue
+		}
+		scores = append(scores, &ScoredDocument{Document: doc, Score: score, Mode: RetrievalVector})
+	}
+
+	if rm.keywordFallbackScore > 0 && bestScore < rm.keywordFallbackScore {
+		if keywordDocs := rm.keywordSearch(collection, query, topK); len(keywordDocs) > 0 {
+			return keywordDocs, nil
+		}
 	}
 
 	// 按分数降序排序
 	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].score > scores[j].score
+		return scores[i].Score > scores[j].Score
 	})
 
-	// 返回前K个结果
-	if topK > len(scores) {
-		topK = len(scores)
-	}
-
-	var results []*Document
-	for i := 0; i < topK; i++ {
-		results = append(results, scores[i].doc)
-	}
-
-	return results, nil
+	// 返回前K个结果，topK<=0 时返回空结果
+	return scores[:max(0, min(topK, len(scores)))], nil
 }
 
 func (rm *RAGManager) cosineSimilarity(vec1, vec2 []float64) float64 {
@@ -203,11 +439,15 @@ func (rm *RAGManager) cosineSimilarity(vec1, vec2 []float64) float64 {
 }
 
 func (rm *RAGManager) GetDocument(id string) (*Document, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
 	doc, exists := rm.documents[id]
 	return doc, exists
 }
 
 func (rm *RAGManager) GetAllDocuments() []*Document {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
 	var docs []*Document
 	for _, doc := range rm.documents {
 		docs = append(docs, doc)
@@ -0,0 +1,235 @@
+package rag
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadValidatesEmbeddingDimension(t *testing.T) {
+	tests := []struct {
+		name    string
+		reembed bool
+		wantErr error
+	}{
+		{name: "mismatch without re-embedding fails", wantErr: ErrEmbeddingDimMismatch},
+		{name: "mismatch is re-embedded", reembed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rm := newTestManagerAt(t, dir, NewHashEmbedder(32))
+			if err := rm.AddDocument("B站视频推荐算法更看重完播率", nil); err != nil {
+				t.Fatalf("AddDocument: %v", err)
+			}
+
+			// 换用 64 维嵌入器重新加载 32 维的已存储文档
+			open := func(reembed bool) (*RAGManager, error) {
+				return NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+					&EmbeddingConfig{Embedder: NewHashEmbedder(64), Dimension: 64, ReembedOnMismatch: reembed, CacheSize: -1})
+			}
+			reloaded, err := open(tt.reembed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("load err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			docs := reloaded.GetAllDocuments()
+			if len(docs) != 1 || len(docs[0].Embedding) != 64 {
+				t.Fatalf("documents after re-embedding = %+v, want one 64-dim document", docs)
+			}
+			results, err := reloaded.SearchSimilarDocuments("完播率", 1)
+			if err != nil || len(results) != 1 {
+				t.Fatalf("search after re-embedding: %v, %d results", err, len(results))
+			}
+
+			// 重新嵌入的结果已回写，再次加载无需重新嵌入
+			if _, err := open(false); err != nil {
+				t.Errorf("load after re-embedding: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewRAGManagerRequiresDimensionForEmbedder(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&EmbeddingConfig{Embedder: NewHashEmbedder(32)})
+	if err == nil {
+		t.Fatal("custom embedder without a dimension should be rejected")
+	}
+}
+
+func TestContentHashIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		hashing    bool
+		namespaces []string
+		contents   []string
+		wantDocs   int
+	}{
+		{name: "timestamp IDs keep duplicates", contents: []string{"完播率", "完播率"}, namespaces: []string{"", ""}, wantDocs: 2},
+		{name: "duplicate content updates in place", hashing: true, contents: []string{"完播率", "完播率"}, namespaces: []string{"kb", "kb"}, wantDocs: 1},
+		{name: "different content", hashing: true, contents: []string{"完播率", "点赞率"}, namespaces: []string{"kb", "kb"}, wantDocs: 2},
+		{name: "namespace separates identical content", hashing: true, contents: []string{"完播率", "完播率"}, namespaces: []string{"kb", "faq"}, wantDocs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestManager(t)
+			for i, content := range tt.contents {
+				rm.SetContentHashIDs(tt.hashing, tt.namespaces[i])
+				if err := rm.AddDocument(content, map[string]interface{}{"version": i}); err != nil {
+					t.Fatalf("AddDocument: %v", err)
+				}
+			}
+
+			docs := rm.GetAllDocuments()
+			if len(docs) != tt.wantDocs {
+				t.Fatalf("stored %d documents, want %d", len(docs), tt.wantDocs)
+			}
+			if tt.wantDocs == 1 {
+				if v := docs[0].Metadata["version"]; v != 1 {
+					t.Errorf("metadata version = %v, want the second write to replace the first", v)
+				}
+			}
+		})
+	}
+}
+
+func TestContentHashIDStableAcrossReload(t *testing.T) {
+	dir := t.TempDir()
+	rm := newTestManagerAt(t, dir, NewHashEmbedder(32))
+	rm.SetContentHashIDs(true, "kb")
+	if err := rm.AddDocument("完播率", nil); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	first := rm.GetAllDocuments()[0]
+
+	reloaded := newTestManagerAt(t, dir, NewHashEmbedder(32))
+	reloaded.SetContentHashIDs(true, "kb")
+	if err := reloaded.AddDocument("完播率", nil); err != nil {
+		t.Fatalf("AddDocument after reload: %v", err)
+	}
+	docs := reloaded.GetAllDocuments()
+	if len(docs) != 1 || docs[0].ID != first.ID {
+		t.Fatalf("documents after reload = %+v, want the single document %s", docs, first.ID)
+	}
+	if !docs[0].CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want the original %v preserved", docs[0].CreatedAt, first.CreatedAt)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	type write struct {
+		id, content string
+	}
+	tests := []struct {
+		name        string
+		writes      []write
+		wantCreated []bool
+		wantDocs    int
+		wantContent string
+	}{
+		{name: "new id is created", writes: []write{{"faq-1", "完播率"}}, wantCreated: []bool{true}, wantDocs: 1, wantContent: "完播率"},
+		{
+			name:        "existing id is updated in place",
+			writes:      []write{{"faq-1", "完播率"}, {"faq-1", "点赞率"}},
+			wantCreated: []bool{true, false},
+			wantDocs:    1,
+			wantContent: "点赞率",
+		},
+		{
+			name:        "empty id uses the content hash",
+			writes:      []write{{"", "完播率"}, {"", "完播率"}},
+			wantCreated: []bool{true, false},
+			wantDocs:    1,
+			wantContent: "完播率",
+		},
+		{name: "different ids", writes: []write{{"faq-1", "完播率"}, {"faq-2", "完播率"}}, wantCreated: []bool{true, true}, wantDocs: 2, wantContent: "完播率"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			rm := newTestManagerAt(t, dir, NewHashEmbedder(32))
+
+			var first *Document
+			for i, w := range tt.writes {
+				doc, created, err := rm.Upsert(w.id, w.content, map[string]interface{}{"version": i})
+				if err != nil {
+					t.Fatalf("Upsert: %v", err)
+				}
+				if created != tt.wantCreated[i] {
+					t.Errorf("write %d created = %v, want %v", i, created, tt.wantCreated[i])
+				}
+				if first == nil {
+					first = doc
+				}
+			}
+
+			// 写入已持久化，重新加载后内容一致
+			reloaded := newTestManagerAt(t, dir, NewHashEmbedder(32))
+			if docs := reloaded.GetAllDocuments(); len(docs) != tt.wantDocs {
+				t.Fatalf("stored %d documents after reload, want %d", len(docs), tt.wantDocs)
+			}
+			if tt.wantDocs != 1 {
+				return
+			}
+			doc, ok := reloaded.GetDocument(first.ID)
+			if !ok {
+				t.Fatalf("document %s missing after reload", first.ID)
+			}
+			if doc.Content != tt.wantContent || len(doc.Embedding) != 32 {
+				t.Errorf("document = %q with %d-dim embedding, want %q re-embedded", doc.Content, len(doc.Embedding), tt.wantContent)
+			}
+			if v := doc.Metadata["version"]; v != float64(len(tt.writes)-1) {
+				t.Errorf("metadata version = %v, want the last write", v)
+			}
+			if !doc.CreatedAt.Equal(first.CreatedAt) {
+				t.Errorf("CreatedAt = %v, want the original %v preserved", doc.CreatedAt, first.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestSearchCollectionTopK(t *testing.T) {
+	tests := []struct {
+		name     string
+		topK     int
+		fallback float64
+		want     int
+	}{
+		{name: "negative topK returns nothing", topK: -1},
+		{name: "zero topK returns nothing", topK: 0},
+		{name: "topK limits results", topK: 2, want: 2},
+		{name: "topK larger than the collection", topK: 10, want: 3},
+		{name: "negative topK with keyword fallback", topK: -1, fallback: 1.1},
+		{name: "keyword fallback honors topK", topK: 2, fallback: 1.1, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := newTestManager(t)
+			// 阈值高于任何相似度时总是走关键词回退
+			rm.SetKeywordFallback(tt.fallback)
+			for _, content := range []string{"完播率 决定推荐", "完播率 与点赞率", "完播率 的优化"} {
+				if err := rm.AddDocument(content, nil); err != nil {
+					t.Fatalf("AddDocument: %v", err)
+				}
+			}
+
+			results, err := rm.SearchWithScores("完播率", tt.topK, 0)
+			if err != nil {
+				t.Fatalf("SearchWithScores: %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("got %d results, want %d", len(results), tt.want)
+			}
+			for _, r := range results {
+				if tt.fallback > 0 && r.Mode != RetrievalKeyword {
+					t.Errorf("result mode = %v, want keyword fallback", r.Mode)
+				}
+			}
+		})
+	}
+}
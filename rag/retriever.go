@@ -20,6 +20,12 @@ func RetrieverRAG(query string) []*schema.Document {
 	if err != nil {
 		panic(err)
 	}
+	return RetrieverRAGWithEmbedder(query, FromEinoEmbedder(embedder))
+}
+
+// RetrieverRAGWithEmbedder 使用指定嵌入器检索，嵌入器必须与入库时使用的一致
+func RetrieverRAGWithEmbedder(query string, embedder Embedder) []*schema.Document {
+	ctx := context.Background()
 	retriever, err := milvus.NewRetriever(ctx, &milvus.RetrieverConfig{
 		Client:      MilvusCli,
 		Collection:  "test_index",
@@ -31,7 +37,7 @@ func RetrieverRAG(query string) []*schema.Document {
 			"metadata",
 		},
 		TopK:      9,
-		Embedding: embedder,
+		Embedding: toEinoEmbedder(embedder),
 	})
 	if err != nil {
 		panic(err)
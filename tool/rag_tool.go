@@ -2,52 +2,302 @@ package tool
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"video_agent/internal/mcp"
+	"video_agent/internal/memory"
 	"video_agent/rag"
 )
 
+// NoRelevantDocuments 没有文档达到相似度阈值时的返回值
+const NoRelevantDocuments = "未找到相关文档"
+
+// defaultTopK 未指定 topK 时返回的文档数
+const defaultTopK = 3
+
+// maxTopK 工具调用时允许的最大 top_k
+const maxTopK = 20
+
+// DefaultContextTokenBudget CreateEnhancedRAGNode 注入检索上下文的默认 token 预算
+const DefaultContextTokenBudget = 2000
+
+// nearDuplicateThreshold 两段内容的字符二元组 Jaccard 相似度达到该值时视为重复片段
+const nearDuplicateThreshold = 0.9
+
+// minTruncatedTokens 剩余预算不足该值时不再截断注入，避免只剩半句话的片段
+const minTruncatedTokens = 50
+
+// documentSeparator 文档之间的分隔符
+const documentSeparator = "\n---\n"
+
+// DefaultContextTemplate CreateEnhancedRAGNode 注入检索结果的默认系统提示词，{context} 替换为文档上下文
+const DefaultContextTemplate = "基于以下检索到的文档内容回答问题：\n\n{context}\n\n请根据这些文档信息提供准确、相关的回答。如果文档中没有相关信息，请明确说明。"
+
+// contextPlaceholder 上下文模板中的文档占位符
+const contextPlaceholder = "{context}"
+
 type RAGTool struct {
 	ragManager *rag.RAGManager
 	topK       int
+	minScore   float64
+
+	// contextTemplate 注入检索结果的系统提示词模板
+	contextTemplate string
+	// metadataKeys 允许出现在检索结果中的元数据字段，为空时不输出元数据
+	metadataKeys []string
 }
 
+// NewRAGTool topK<=0 时使用默认值 3
 func NewRAGTool(ragManager *rag.RAGManager, topK int) *RAGTool {
+	if topK <= 0 {
+		topK = defaultTopK
+	}
 	return &RAGTool{
-		ragManager: ragManager,
-		topK:       topK,
+		ragManager:      ragManager,
+		topK:            topK,
+		contextTemplate: DefaultContextTemplate,
+	}
+}
+
+// SetContextTemplate 设置注入检索结果的系统提示词模板，模板必须包含 {context} 占位符
+func (rt *RAGTool) SetContextTemplate(template string) error {
+	if !strings.Contains(template, contextPlaceholder) {
+		return fmt.Errorf("context template must contain %s", contextPlaceholder)
+	}
+	rt.contextTemplate = template
+	return nil
+}
+
+// SetMetadataKeys 设置检索结果中输出的元数据字段及顺序，未列出的字段（如内部ID、时间戳）不会进入模型上下文
+func (rt *RAGTool) SetMetadataKeys(keys ...string) {
+	rt.metadataKeys = keys
+}
+
+// RenderContext 将文档上下文填入系统提示词模板
+func (rt *RAGTool) RenderContext(contextDocs string) string {
+	return strings.ReplaceAll(rt.contextTemplate, contextPlaceholder, contextDocs)
+}
+
+// SetMinScore 设置最低相似度，低于该分数的文档不会返回，避免无关内容进入上下文
+func (rt *RAGTool) SetMinScore(minScore float64) {
+	rt.minScore = minScore
+}
+
+// RAGToolName 作为 Agent 工具提供给模型时的工具名
+const RAGToolName = "rag_knowledge_base"
+
+// Info 实现 tool.BaseTool 接口，声明 query 与可选的 top_k 参数；描述中只列出 SetMetadataKeys 配置的元数据字段
+func (rt *RAGTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	returns := "内容与相似度"
+	if len(rt.metadataKeys) > 0 {
+		returns = fmt.Sprintf("内容、相似度与元数据（%s）", strings.Join(rt.metadataKeys, "、"))
+	}
+	return &schema.ToolInfo{
+		Name: RAGToolName,
+		Desc: fmt.Sprintf("在本地知识库中检索与问题相关的文档片段，返回%s。需要平台规则、运营经验等背景知识时使用", returns),
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"query": {
+				Desc:     "检索问题或关键词",
+				Type:     schema.String,
+				Required: true,
+			},
+			"top_k": {
+				Desc:     fmt.Sprintf("返回的文档数量，取值 1-%d，默认 %d", maxTopK, rt.topK),
+				Type:     schema.Integer,
+				Required: false,
+			},
+		}),
+	}, nil
+}
+
+// InvokableRun 实现 tool.InvokableTool 接口
+func (rt *RAGTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	return rt.invokeWithMap(ctx, args)
+}
+
+// invokeWithMap 按 query 与可选的 top_k 检索；模型传入的 top_k 可能是 float64 或字符串，统一转换为整数
+func (rt *RAGTool) invokeWithMap(ctx context.Context, args map[string]interface{}) (string, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("missing query parameter")
+	}
+
+	topK := rt.topK
+	if raw, ok := args["top_k"]; ok && raw != nil {
+		n, err := mcp.CoerceInt(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid top_k: %w", err)
+		}
+		if n < 1 || n > maxTopK {
+			return "", fmt.Errorf("invalid top_k: %d, must be in [1, %d]", n, maxTopK)
+		}
+		topK = int(n)
 	}
+	return rt.search(ctx, query, topK)
 }
 
 func (rt *RAGTool) SearchDocuments(ctx context.Context, query string) (string, error) {
-	documents, err := rt.ragManager.SearchSimilarDocuments(query, rt.topK)
+	return rt.search(ctx, query, rt.topK)
+}
+
+func (rt *RAGTool) search(ctx context.Context, query string, topK int) (string, error) {
+	documents, err := rt.ragManager.SearchWithScores(query, topK, rt.minScore)
 	if err != nil {
 		return "", fmt.Errorf("failed to search documents: %w", err)
 	}
 
 	if len(documents) == 0 {
-		return "未找到相关文档", nil
+		return NoRelevantDocuments, nil
 	}
 
 	var results []string
 	for i, doc := range documents {
-		result := fmt.Sprintf("文档 %d:\n内容: %s\n", i+1, doc.Content)
-		if len(doc.Metadata) > 0 {
-			result += fmt.Sprintf("元数据: %v\n", doc.Metadata)
+		results = append(results, rt.formatDocument(i+1, doc, doc.Content))
+	}
+
+	return strings.Join(results, documentSeparator), nil
+}
+
+// formatDocument 格式化单个检索结果，content 可以是截断后的内容；只输出 metadataKeys 中存在的元数据字段
+func (rt *RAGTool) formatDocument(index int, doc *rag.ScoredDocument, content string) string {
+	var sb strings.Builder
+	if doc.Mode == rag.RetrievalKeyword {
+		sb.WriteString(fmt.Sprintf("文档 %d (关键词匹配: %.2f，向量检索无可靠结果):\n内容: %s\n", index, doc.Score, content))
+	} else {
+		sb.WriteString(fmt.Sprintf("文档 %d (相似度: %.2f):\n内容: %s\n", index, doc.Score, content))
+	}
+	for _, key := range rt.metadataKeys {
+		if value, ok := doc.Metadata[key]; ok && value != nil {
+			sb.WriteString(fmt.Sprintf("%s: %v\n", key, value))
 		}
-		results = append(results, result)
 	}
+	return sb.String()
+}
 
-	return strings.Join(results, "\n---\n"), nil
+// BuildContext 检索并拼接注入模型的文档上下文：去除近似重复的片段，按相似度从高到低装入
+// maxTokens 预算（memory.EstimateTokens 估算），超出预算时先舍弃低分文档，
+// 装不下的最高分文档截断后放入；maxTokens<=0 时不限制。没有相关文档时返回 NoRelevantDocuments
+func (rt *RAGTool) BuildContext(ctx context.Context, query string, maxTokens int) (string, error) {
+	documents, err := rt.ragManager.SearchWithScores(query, rt.topK, rt.minScore)
+	if err != nil {
+		return "", fmt.Errorf("failed to search documents: %w", err)
+	}
+	documents = dedupeDocuments(documents)
+	if len(documents) == 0 {
+		return NoRelevantDocuments, nil
+	}
+
+	var results []string
+	for _, doc := range documents {
+		entry := rt.formatDocument(len(results)+1, doc, doc.Content)
+		if maxTokens <= 0 || joinedTokens(results, entry) <= maxTokens {
+			results = append(results, entry)
+			continue
+		}
+
+		// 预算不足：截断当前文档后结束，剩余更低分的文档全部舍弃。估算按拼接后的全文计，
+		// 分段估算的取整误差可能让拼接结果略超预算，此时继续缩短
+		remaining := maxTokens - joinedTokens(results, rt.formatDocument(len(results)+1, doc, ""))
+		for ; remaining >= minTruncatedTokens; remaining-- {
+			entry = rt.formatDocument(len(results)+1, doc, truncateToTokens(doc.Content, remaining))
+			if joinedTokens(results, entry) <= maxTokens {
+				results = append(results, entry)
+				break
+			}
+		}
+		break
+	}
+
+	if len(results) == 0 {
+		return NoRelevantDocuments, nil
+	}
+	return strings.Join(results, documentSeparator), nil
+}
+
+// joinedTokens 估算 entries 追加 next 后拼接成上下文的 token 数
+func joinedTokens(entries []string, next string) int {
+	return memory.EstimateTokens(strings.Join(append(entries[:len(entries):len(entries)], next), documentSeparator))
+}
+
+// dedupeDocuments 去除与更高分文档近似重复的片段，输入已按相似度降序
+func dedupeDocuments(documents []*rag.ScoredDocument) []*rag.ScoredDocument {
+	kept := make([]*rag.ScoredDocument, 0, len(documents))
+	keptGrams := make([]map[string]struct{}, 0, len(documents))
+	for _, doc := range documents {
+		grams := bigrams(doc.Content)
+		duplicate := false
+		for _, other := range keptGrams {
+			if jaccard(grams, other) >= nearDuplicateThreshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, doc)
+			keptGrams = append(keptGrams, grams)
+		}
+	}
+	return kept
+}
+
+// bigrams 忽略大小写与空白后的字符二元组集合
+func bigrams(text string) map[string]struct{} {
+	runes := []rune(strings.ToLower(strings.Join(strings.Fields(text), "")))
+	grams := make(map[string]struct{}, len(runes))
+	if len(runes) == 1 {
+		grams[string(runes)] = struct{}{}
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+// truncateToTokens 按字符边界截断文本，使估算 token 数不超过 maxTokens
+func truncateToTokens(text string, maxTokens int) string {
+	if memory.EstimateTokens(text) <= maxTokens {
+		return text
+	}
+	const ellipsis = "..."
+	limit := maxTokens - memory.EstimateTokens(ellipsis)
+	end := 0
+	for i := range text {
+		if memory.EstimateTokens(text[:i]) > limit {
+			break
+		}
+		end = i
+	}
+	return text[:end] + ellipsis
 }
 
 func (rt *RAGTool) AddDocument(ctx context.Context, content string, metadata map[string]interface{}) error {
 	return rt.ragManager.AddDocument(content, metadata)
 }
 
+var _ tool.InvokableTool = (*RAGTool)(nil)
+
 // 创建RAG搜索工具节点
 func CreateRAGSearchNode(ragManager *rag.RAGManager, topK int) *compose.Lambda {
 	ragTool := NewRAGTool(ragManager, topK)
@@ -62,9 +312,18 @@ func CreateRAGSearchNode(ragManager *rag.RAGManager, topK int) *compose.Lambda {
 	})
 }
 
-// 创建增强的RAG节点，直接处理消息
+// 创建增强的RAG节点，直接处理消息，注入的文档上下文不超过 DefaultContextTokenBudget
 func CreateEnhancedRAGNode(ragManager *rag.RAGManager, topK int) *compose.Lambda {
-	ragTool := NewRAGTool(ragManager, topK)
+	return CreateEnhancedRAGNodeWithBudget(ragManager, topK, DefaultContextTokenBudget)
+}
+
+// CreateEnhancedRAGNodeWithBudget 创建增强的RAG节点，maxContextTokens 为注入文档上下文的 token 预算，<=0 不限制
+func CreateEnhancedRAGNodeWithBudget(ragManager *rag.RAGManager, topK int, maxContextTokens int) *compose.Lambda {
+	return CreateEnhancedRAGNodeWithTool(NewRAGTool(ragManager, topK), maxContextTokens)
+}
+
+// CreateEnhancedRAGNodeWithTool 使用已配置的 RAGTool（提示词模板、元数据字段等）创建增强的RAG节点
+func CreateEnhancedRAGNodeWithTool(ragTool *RAGTool, maxContextTokens int) *compose.Lambda {
 
 	return compose.InvokableLambda(func(ctx context.Context, messages []*schema.Message) (output []*schema.Message, err error) {
 		if len(messages) == 0 {
@@ -85,20 +344,20 @@ func CreateEnhancedRAGNode(ragManager *rag.RAGManager, topK int) *compose.Lambda
 		}
 
 		// 搜索相关文档
-		contextDocs, err := ragTool.SearchDocuments(ctx, userQuery)
+		contextDocs, err := ragTool.BuildContext(ctx, userQuery, maxContextTokens)
 		if err != nil {
 			return messages, fmt.Errorf("failed to search documents: %w", err)
 		}
 
 		// 如果没有找到相关文档，返回原消息
-		if contextDocs == "未找到相关文档" {
+		if contextDocs == NoRelevantDocuments {
 			return messages, nil
 		}
 
 		// 创建增强的系统消息
 		enhancedSystemMsg := &schema.Message{
 			Role:    schema.System,
-			Content: fmt.Sprintf("基于以下检索到的文档内容回答问题：\n\n%s\n\n请根据这些文档信息提供准确、相关的回答。如果文档中没有相关信息，请明确说明。", contextDocs),
+			Content: ragTool.RenderContext(contextDocs),
 		}
 
 		// 构建新的消息列表，在系统消息和用户消息之间插入上下文
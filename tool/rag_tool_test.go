@@ -0,0 +1,390 @@
+package tool
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"video_agent/internal/memory"
+	"video_agent/rag"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// newTestRAGTool 使用临时目录与 HashEmbedder 创建只含一篇文档的 RAGTool
+func newTestRAGTool(t *testing.T) *RAGTool {
+	t.Helper()
+	dir := t.TempDir()
+	rm, err := rag.NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&rag.EmbeddingConfig{Embedder: rag.NewHashEmbedder(32), Dimension: 32, CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	err = rm.AddDocument("视频标题应控制在二十字以内，并包含核心关键词。", map[string]interface{}{
+		"source":      "运营手册",
+		"internal_id": "doc-42",
+	})
+	if err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	return NewRAGTool(rm, 3)
+}
+
+func TestRAGToolMetadataKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantContext []string
+		dropContext []string
+		wantDesc    string
+		dropDesc    string
+	}{
+		{
+			name:        "no keys",
+			dropContext: []string{"source", "internal_id"},
+			wantDesc:    "返回内容与相似度",
+			dropDesc:    "元数据",
+		},
+		{
+			name:        "allowlisted key only",
+			keys:        []string{"source"},
+			wantContext: []string{"source: 运营手册"},
+			dropContext: []string{"internal_id", "doc-42"},
+			wantDesc:    "元数据（source）",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newTestRAGTool(t)
+			rt.SetMetadataKeys(tt.keys...)
+			ctx := context.Background()
+
+			got, err := rt.BuildContext(ctx, "视频标题怎么写", 0)
+			if err != nil {
+				t.Fatalf("BuildContext: %v", err)
+			}
+			for _, want := range tt.wantContext {
+				if !strings.Contains(got, want) {
+					t.Errorf("context %q missing %q", got, want)
+				}
+			}
+			for _, drop := range tt.dropContext {
+				if strings.Contains(got, drop) {
+					t.Errorf("context %q should not contain %q", got, drop)
+				}
+			}
+
+			info, err := rt.Info(ctx)
+			if err != nil {
+				t.Fatalf("Info: %v", err)
+			}
+			if !strings.Contains(info.Desc, tt.wantDesc) {
+				t.Errorf("Desc %q missing %q", info.Desc, tt.wantDesc)
+			}
+			if tt.dropDesc != "" && strings.Contains(info.Desc, tt.dropDesc) {
+				t.Errorf("Desc %q should not mention %q", info.Desc, tt.dropDesc)
+			}
+		})
+	}
+}
+
+func TestRAGToolContextTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+		want     string
+	}{
+		{name: "default template", want: "基于以下检索到的文档内容回答问题：\n\n文档片段"},
+		{name: "custom template", template: "参考资料：{context}", want: "参考资料：文档片段"},
+		{name: "missing placeholder", template: "参考资料", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newTestRAGTool(t)
+			if tt.template != "" {
+				err := rt.SetContextTemplate(tt.template)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("SetContextTemplate err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+			}
+			if got := rt.RenderContext("文档片段"); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("RenderContext = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContextTokenBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      string
+	}{
+		{name: "unlimited", maxTokens: 0, want: "核心关键词。"},
+		{name: "too small for any document", maxTokens: 10, want: NoRelevantDocuments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestRAGTool(t).BuildContext(context.Background(), "视频标题怎么写", tt.maxTokens)
+			if err != nil {
+				t.Fatalf("BuildContext: %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("BuildContext = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// vocabEmbedder 按固定词表计数生成向量，与词表无交集的文本相似度为 0
+type vocabEmbedder []string
+
+func (v vocabEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(v))
+		for _, word := range strings.Fields(text) {
+			for j, term := range v {
+				if word == term {
+					vectors[i][j]++
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestRAGToolMinScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		minScore float64
+		args     string
+		wantDocs bool
+	}{
+		{name: "no threshold returns the closest document", args: `{"query":"quantum physics"}`, wantDocs: true},
+		{name: "unrelated query below threshold", minScore: 0.5, args: `{"query":"quantum physics"}`},
+		{name: "threshold applies with explicit top_k", minScore: 0.5, args: `{"query":"quantum physics","top_k":5}`},
+		{name: "matching query passes threshold", minScore: 0.5, args: `{"query":"video title"}`, wantDocs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocab := vocabEmbedder{"video", "title", "keyword"}
+			dir := t.TempDir()
+			rm, err := rag.NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+				&rag.EmbeddingConfig{Embedder: vocab, Dimension: len(vocab), CacheSize: -1})
+			if err != nil {
+				t.Fatalf("NewRAGManagerWithConfig: %v", err)
+			}
+			if err := rm.AddDocument("video title keyword", nil); err != nil {
+				t.Fatalf("AddDocument: %v", err)
+			}
+			rt := NewRAGTool(rm, 0)
+			rt.SetMinScore(tt.minScore)
+
+			got, err := rt.InvokableRun(context.Background(), tt.args)
+			if err != nil {
+				t.Fatalf("InvokableRun: %v", err)
+			}
+			if empty := got == NoRelevantDocuments; empty == tt.wantDocs {
+				t.Errorf("result = %q, want documents %v", got, tt.wantDocs)
+			}
+			if doc, _ := rt.SearchDocuments(context.Background(), "quantum physics"); tt.minScore > 0 && doc != NoRelevantDocuments {
+				t.Errorf("SearchDocuments = %q, want %q", doc, NoRelevantDocuments)
+			}
+		})
+	}
+}
+
+func TestNewRAGToolDefaultTopK(t *testing.T) {
+	if rt := NewRAGTool(nil, 0); rt.topK != defaultTopK {
+		t.Errorf("topK = %d, want default %d", rt.topK, defaultTopK)
+	}
+}
+
+func TestRAGToolInfo(t *testing.T) {
+	info, err := newTestRAGTool(t).Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.Name != RAGToolName || info.Desc == "" {
+		t.Errorf("info = %q / %q, want the tool name and a description", info.Name, info.Desc)
+	}
+	if info.ParamsOneOf == nil {
+		t.Fatal("Info declares no parameters")
+	}
+	params, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema: %v", err)
+	}
+
+	tests := []struct {
+		param        string
+		wantType     string
+		wantRequired bool
+	}{
+		{param: "query", wantType: "string", wantRequired: true},
+		{param: "top_k", wantType: "integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			prop, ok := params.Properties.Get(tt.param)
+			if !ok {
+				t.Fatalf("parameter %s not declared", tt.param)
+			}
+			if prop.Type != tt.wantType || prop.Description == "" {
+				t.Errorf("%s = type %q desc %q, want type %q with a description", tt.param, prop.Type, prop.Description, tt.wantType)
+			}
+			if got := slices.Contains(params.Required, tt.param); got != tt.wantRequired {
+				t.Errorf("%s required = %v, want %v", tt.param, got, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestRAGToolInToolNode(t *testing.T) {
+	ctx := context.Background()
+	var _ tool.InvokableTool = (*RAGTool)(nil)
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: []tool.BaseTool{newTestRAGTool(t)}})
+	if err != nil {
+		t.Fatalf("NewToolNode: %v", err)
+	}
+
+	msgs, err := node.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call-1",
+		Function: schema.FunctionCall{Name: RAGToolName, Arguments: `{"query":"视频标题怎么写"}`},
+	}}))
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ToolCallID != "call-1" || !strings.Contains(msgs[0].Content, "核心关键词") {
+		t.Errorf("tool messages = %+v, want the retrieved document", msgs)
+	}
+}
+
+func TestRAGToolTopKCoercion(t *testing.T) {
+	dir := t.TempDir()
+	rm, err := rag.NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&rag.EmbeddingConfig{Embedder: rag.NewHashEmbedder(32), Dimension: 32, CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := rm.AddDocument(strings.Repeat("视频 ", i+1), nil); err != nil {
+			t.Fatalf("AddDocument: %v", err)
+		}
+	}
+	rt := NewRAGTool(rm, 3)
+
+	tests := []struct {
+		name     string
+		args     string
+		wantDocs int
+		wantErr  bool
+	}{
+		{name: "default", args: `{"query":"视频"}`, wantDocs: 3},
+		{name: "integer", args: `{"query":"视频","top_k":2}`, wantDocs: 2},
+		{name: "float", args: `{"query":"视频","top_k":5.0}`, wantDocs: 5},
+		{name: "string", args: `{"query":"视频","top_k":"4"}`, wantDocs: 4},
+		{name: "null keeps the default", args: `{"query":"视频","top_k":null}`, wantDocs: 3},
+		{name: "fractional float", args: `{"query":"视频","top_k":2.5}`, wantErr: true},
+		{name: "non-numeric string", args: `{"query":"视频","top_k":"many"}`, wantErr: true},
+		{name: "zero", args: `{"query":"视频","top_k":0}`, wantErr: true},
+		{name: "above max", args: `{"query":"视频","top_k":21}`, wantErr: true},
+		{name: "missing query", args: `{"top_k":2}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rt.InvokableRun(context.Background(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InvokableRun err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if n := strings.Count(got, "内容: "); n != tt.wantDocs {
+				t.Errorf("returned %d documents, want %d:\n%s", n, tt.wantDocs, got)
+			}
+		})
+	}
+}
+
+func TestBuildContextBudgetedDocuments(t *testing.T) {
+	// 按与查询的词表重合度，A（及其近似重复 A2）> B > C；标记放在内容末尾，截断后不再出现
+	vocab := vocabEmbedder{"video", "title", "keyword"}
+	docA := "video title keyword " + strings.Repeat("完播率决定推荐量，", 40) + "MARK_A"
+	docs := []string{
+		docA,
+		docA + "。",
+		"video title " + strings.Repeat("标题控制在二十字以内，", 40) + "MARK_B",
+		"video " + strings.Repeat("封面使用高对比色，", 40) + "MARK_C",
+	}
+	dir := t.TempDir()
+	rm, err := rag.NewRAGManagerWithConfig(filepath.Join(dir, "vector.json"), filepath.Join(dir, "rag.json"),
+		&rag.EmbeddingConfig{Embedder: vocab, Dimension: len(vocab), CacheSize: -1})
+	if err != nil {
+		t.Fatalf("NewRAGManagerWithConfig: %v", err)
+	}
+	for _, content := range docs {
+		if err := rm.AddDocument(content, nil); err != nil {
+			t.Fatalf("AddDocument: %v", err)
+		}
+	}
+	rt := NewRAGTool(rm, len(docs))
+	query := "video title keyword"
+
+	unlimited, err := rt.BuildContext(context.Background(), query, 0)
+	if err != nil {
+		t.Fatalf("BuildContext: %v", err)
+	}
+	entries := strings.Split(unlimited, documentSeparator)
+	if len(entries) != 3 {
+		t.Fatalf("unlimited context has %d documents, want the near-duplicate removed:\n%s", len(entries), unlimited)
+	}
+	twoDocs := memory.EstimateTokens(entries[0] + documentSeparator + entries[1])
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      []string
+		drop      []string
+	}{
+		{name: "unlimited keeps every distinct document", want: []string{"MARK_A", "MARK_B", "MARK_C"}},
+		{name: "lowest score is cut first", maxTokens: twoDocs + minTruncatedTokens - 1, want: []string{"MARK_A", "MARK_B"}, drop: []string{"MARK_C"}},
+		{name: "lowest score is truncated into the remaining budget", maxTokens: twoDocs + 3*minTruncatedTokens, want: []string{"MARK_A", "MARK_B", "文档 3"}, drop: []string{"MARK_C"}},
+		{name: "oversized top document is truncated", maxTokens: memory.EstimateTokens(entries[0]) / 2, want: []string{"文档 1", "..."}, drop: []string{"MARK_A", "MARK_B", "文档 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rt.BuildContext(context.Background(), query, tt.maxTokens)
+			if err != nil {
+				t.Fatalf("BuildContext: %v", err)
+			}
+			if tt.maxTokens > 0 {
+				if tokens := memory.EstimateTokens(got); tokens > tt.maxTokens {
+					t.Errorf("context is %d tokens, want at most %d", tokens, tt.maxTokens)
+				}
+			}
+			if strings.Count(got, "MARK_A") > 1 {
+				t.Errorf("near-duplicate document injected twice")
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("context missing %q", s)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(got, s) {
+					t.Errorf("context should not contain %q", s)
+				}
+			}
+		})
+	}
+}